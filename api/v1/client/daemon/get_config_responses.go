@@ -0,0 +1,67 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package daemon
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+)
+
+// GetConfigReader is a Reader for the GetConfig structure.
+type GetConfigReader struct {
+	formats strfmt.Registry
+}
+
+// ReadResponse reads a server response into the received o.
+func (o *GetConfigReader) ReadResponse(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	switch response.Code() {
+	case 200:
+		result := NewGetConfigOK()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return result, nil
+	default:
+		return nil, runtime.NewAPIError("response status code does not match any response statuses defined for this endpoint in the swagger spec", response, response.Code())
+	}
+}
+
+// NewGetConfigOK creates a GetConfigOK with default headers values
+func NewGetConfigOK() *GetConfigOK {
+	return &GetConfigOK{}
+}
+
+/*GetConfigOK describes a response with status code 200, with default header values.
+
+Success
+*/
+type GetConfigOK struct {
+	Payload *models.DaemonConfiguration
+}
+
+func (o *GetConfigOK) Error() string {
+	return fmt.Sprintf("[GET /config][%d] getConfigOK  %+v", 200, o.Payload)
+}
+func (o *GetConfigOK) GetPayload() *models.DaemonConfiguration {
+	return o.Payload
+}
+
+func (o *GetConfigOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.DaemonConfiguration)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package daemon
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+)
+
+// GetHealthzReader is a Reader for the GetHealthz structure.
+type GetHealthzReader struct {
+	formats strfmt.Registry
+}
+
+// ReadResponse reads a server response into the received o.
+func (o *GetHealthzReader) ReadResponse(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	switch response.Code() {
+	case 200:
+		result := NewGetHealthzOK()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case 503:
+		result := NewGetHealthzServiceUnavailable()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	default:
+		return nil, runtime.NewAPIError("response status code does not match any response statuses defined for this endpoint in the swagger spec", response, response.Code())
+	}
+}
+
+// NewGetHealthzOK creates a GetHealthzOK with default headers values
+func NewGetHealthzOK() *GetHealthzOK {
+	return &GetHealthzOK{}
+}
+
+/*GetHealthzOK describes a response with status code 200, with default header values.
+
+Success
+*/
+type GetHealthzOK struct {
+	Payload *models.StatusResponse
+}
+
+func (o *GetHealthzOK) Error() string {
+	return fmt.Sprintf("[GET /healthz][%d] getHealthzOK  %+v", 200, o.Payload)
+}
+func (o *GetHealthzOK) GetPayload() *models.StatusResponse {
+	return o.Payload
+}
+
+func (o *GetHealthzOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.StatusResponse)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewGetHealthzServiceUnavailable creates a GetHealthzServiceUnavailable with default headers values
+func NewGetHealthzServiceUnavailable() *GetHealthzServiceUnavailable {
+	return &GetHealthzServiceUnavailable{}
+}
+
+/*GetHealthzServiceUnavailable describes a response with status code 503, with default header values.
+
+A required bpf program is not running
+*/
+type GetHealthzServiceUnavailable struct {
+	Payload *models.StatusResponse
+}
+
+func (o *GetHealthzServiceUnavailable) Error() string {
+	return fmt.Sprintf("[GET /healthz][%d] getHealthzServiceUnavailable  %+v", 503, o.Payload)
+}
+func (o *GetHealthzServiceUnavailable) GetPayload() *models.StatusResponse {
+	return o.Payload
+}
+
+func (o *GetHealthzServiceUnavailable) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.StatusResponse)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
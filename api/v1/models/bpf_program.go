@@ -7,9 +7,12 @@ package models
 
 import (
 	"context"
+	"encoding/json"
 
+	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
 )
 
 // BpfProgram bpf program
@@ -17,6 +20,9 @@ import (
 // swagger:model BpfProgram
 type BpfProgram struct {
 
+	// Bpf programs that must be started before this one, '*' for all the others
+	After []string `json:"after"`
+
 	// Command line arguments passed to the bpf program launcher
 	Args []string `json:"args"`
 
@@ -29,12 +35,82 @@ type BpfProgram struct {
 	// Name of bpf program
 	Name string `json:"name,omitempty"`
 
+	// What to do when the bpf program fails to start: exit the bpflock daemon, degrade and report a failure, or retry with backoff. Defaults to exit for required programs and degrade for the others
+	// Enum: [exit degrade retry]
+	OnFailure string `json:"onFailure,omitempty"`
+
 	// Launch priority of the bpf program
 	Priority int32 `json:"priority,omitempty"`
+
+	// The bpflock daemon is not healthy while a required bpf program is not running
+	Required bool `json:"required,omitempty"`
+
+	// Number of retries of the retry on-failure policy
+	Retries int32 `json:"retries,omitempty"`
+
+	// Delay before the first retry, doubled after each retry
+	RetryBackoff string `json:"retryBackoff,omitempty"`
+
+	// Time the bpf program launcher has to load and attach the bpf program
+	Timeout string `json:"timeout,omitempty"`
 }
 
 // Validate validates this bpf program
 func (m *BpfProgram) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateOnFailure(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+var bpfProgramTypeOnFailurePropEnum []interface{}
+
+func init() {
+	var res []string
+	if err := json.Unmarshal([]byte(`["exit","degrade","retry"]`), &res); err != nil {
+		panic(err)
+	}
+	for _, v := range res {
+		bpfProgramTypeOnFailurePropEnum = append(bpfProgramTypeOnFailurePropEnum, v)
+	}
+}
+
+const (
+
+	// BpfProgramOnFailureExit captures enum value "exit"
+	BpfProgramOnFailureExit string = "exit"
+
+	// BpfProgramOnFailureDegrade captures enum value "degrade"
+	BpfProgramOnFailureDegrade string = "degrade"
+
+	// BpfProgramOnFailureRetry captures enum value "retry"
+	BpfProgramOnFailureRetry string = "retry"
+)
+
+// prop value enum
+func (m *BpfProgram) validateOnFailureEnum(path, location string, value string) error {
+	if err := validate.EnumCase(path, location, value, bpfProgramTypeOnFailurePropEnum, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *BpfProgram) validateOnFailure(formats strfmt.Registry) error {
+	if swag.IsZero(m.OnFailure) { // not required
+		return nil
+	}
+
+	// value enum
+	if err := m.validateOnFailureEnum("onFailure", "body", m.OnFailure); err != nil {
+		return err
+	}
+
 	return nil
 }
 
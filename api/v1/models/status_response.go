@@ -7,6 +7,7 @@ package models
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
@@ -23,8 +24,23 @@ type StatusResponse struct {
 	// bpflock
 	Bpflock *Status `json:"bpflock,omitempty"`
 
+	// Active temporary exceptions of bpf programs
+	Exceptions []*Exception `json:"exceptions"`
+
+	// Status of the integrity of pinned bpf programs, links and of the bpf filesystem
+	Integrity *Status `json:"integrity,omitempty"`
+
+	// Status of each configured bpf program
+	Programs []*ProgramStatus `json:"programs"`
+
+	// Status of the seal of the bpflock policy
+	Seal *Status `json:"seal,omitempty"`
+
 	// List of stale information in the status
 	Stale map[string]strfmt.DateTime `json:"stale,omitempty"`
+
+	// Status of the applied sysctl hardening profile
+	Sysctl *Status `json:"sysctl,omitempty"`
 }
 
 // Validate validates this status response
@@ -35,10 +51,30 @@ func (m *StatusResponse) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateExceptions(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateIntegrity(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validatePrograms(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateSeal(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateStale(formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.validateSysctl(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
@@ -64,6 +100,96 @@ func (m *StatusResponse) validateBpflock(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *StatusResponse) validateExceptions(formats strfmt.Registry) error {
+	if swag.IsZero(m.Exceptions) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Exceptions); i++ {
+		if swag.IsZero(m.Exceptions[i]) { // not required
+			continue
+		}
+
+		if m.Exceptions[i] != nil {
+			if err := m.Exceptions[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("exceptions" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("exceptions" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *StatusResponse) validateIntegrity(formats strfmt.Registry) error {
+	if swag.IsZero(m.Integrity) { // not required
+		return nil
+	}
+
+	if m.Integrity != nil {
+		if err := m.Integrity.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("integrity")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("integrity")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *StatusResponse) validatePrograms(formats strfmt.Registry) error {
+	if swag.IsZero(m.Programs) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Programs); i++ {
+		if swag.IsZero(m.Programs[i]) { // not required
+			continue
+		}
+
+		if m.Programs[i] != nil {
+			if err := m.Programs[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("programs" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("programs" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *StatusResponse) validateSeal(formats strfmt.Registry) error {
+	if swag.IsZero(m.Seal) { // not required
+		return nil
+	}
+
+	if m.Seal != nil {
+		if err := m.Seal.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("seal")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("seal")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (m *StatusResponse) validateStale(formats strfmt.Registry) error {
 	if swag.IsZero(m.Stale) { // not required
 		return nil
@@ -80,6 +206,25 @@ func (m *StatusResponse) validateStale(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *StatusResponse) validateSysctl(formats strfmt.Registry) error {
+	if swag.IsZero(m.Sysctl) { // not required
+		return nil
+	}
+
+	if m.Sysctl != nil {
+		if err := m.Sysctl.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("sysctl")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("sysctl")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 // ContextValidate validate this status response based on the context it is used
 func (m *StatusResponse) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	var res []error
@@ -88,6 +233,26 @@ func (m *StatusResponse) ContextValidate(ctx context.Context, formats strfmt.Reg
 		res = append(res, err)
 	}
 
+	if err := m.contextValidateExceptions(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateIntegrity(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidatePrograms(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateSeal(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateSysctl(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
@@ -110,6 +275,94 @@ func (m *StatusResponse) contextValidateBpflock(ctx context.Context, formats str
 	return nil
 }
 
+func (m *StatusResponse) contextValidateExceptions(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Exceptions); i++ {
+
+		if m.Exceptions[i] != nil {
+			if err := m.Exceptions[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("exceptions" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("exceptions" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *StatusResponse) contextValidateIntegrity(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Integrity != nil {
+		if err := m.Integrity.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("integrity")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("integrity")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *StatusResponse) contextValidatePrograms(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Programs); i++ {
+
+		if m.Programs[i] != nil {
+			if err := m.Programs[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("programs" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("programs" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *StatusResponse) contextValidateSeal(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Seal != nil {
+		if err := m.Seal.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("seal")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("seal")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *StatusResponse) contextValidateSysctl(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Sysctl != nil {
+		if err := m.Sysctl.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("sysctl")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("sysctl")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 // MarshalBinary interface implementation
 func (m *StatusResponse) MarshalBinary() ([]byte, error) {
 	if m == nil {
@@ -20,6 +20,38 @@ func (in *StatusResponse) DeepCopyInto(out *StatusResponse) {
 		*out = new(Status)
 		**out = **in
 	}
+	if in.Exceptions != nil {
+		in, out := &in.Exceptions, &out.Exceptions
+		*out = make([]*Exception, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Exception)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Integrity != nil {
+		in, out := &in.Integrity, &out.Integrity
+		*out = new(Status)
+		**out = **in
+	}
+	if in.Programs != nil {
+		in, out := &in.Programs, &out.Programs
+		*out = make([]*ProgramStatus, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ProgramStatus)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Seal != nil {
+		in, out := &in.Seal, &out.Seal
+		*out = new(Status)
+		**out = **in
+	}
 	if in.Stale != nil {
 		in, out := &in.Stale, &out.Stale
 		*out = make(map[string]strfmt.DateTime, len(*in))
@@ -27,6 +59,11 @@ func (in *StatusResponse) DeepCopyInto(out *StatusResponse) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Sysctl != nil {
+		in, out := &in.Sysctl, &out.Sysctl
+		*out = new(Status)
+		**out = **in
+	}
 	return
 }
 
@@ -39,3 +76,47 @@ func (in *StatusResponse) DeepCopy() *StatusResponse {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProgramStatus) DeepCopyInto(out *ProgramStatus) {
+	*out = *in
+	if in.After != nil {
+		in, out := &in.After, &out.After
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(Status)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProgramStatus.
+func (in *ProgramStatus) DeepCopy() *ProgramStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProgramStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Exception) DeepCopyInto(out *Exception) {
+	*out = *in
+	in.Created.DeepCopyInto(&out.Created)
+	in.Expires.DeepCopyInto(&out.Expires)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Exception.
+func (in *Exception) DeepCopy() *Exception {
+	if in == nil {
+		return nil
+	}
+	out := new(Exception)
+	in.DeepCopyInto(out)
+	return out
+}
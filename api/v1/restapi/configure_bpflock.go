@@ -14,6 +14,9 @@ import (
 
 	"github.com/linux-lock/bpflock/api/v1/restapi/operations"
 	"github.com/linux-lock/bpflock/api/v1/restapi/operations/daemon"
+	"github.com/linux-lock/bpflock/api/v1/restapi/operations/events"
+	"github.com/linux-lock/bpflock/api/v1/restapi/operations/policy"
+	"github.com/linux-lock/bpflock/api/v1/restapi/operations/programs"
 	"github.com/linux-lock/bpflock/pkg/logging"
 )
 
@@ -46,11 +49,46 @@ func configureAPI(api *operations.BpflockAPI) http.Handler {
 			return middleware.NotImplemented("operation daemon.GetConfig has not yet been implemented")
 		})
 	}
+	if api.EventsGetEventsHistoryHandler == nil {
+		api.EventsGetEventsHistoryHandler = events.GetEventsHistoryHandlerFunc(func(params events.GetEventsHistoryParams) middleware.Responder {
+			return middleware.NotImplemented("operation events.GetEventsHistory has not yet been implemented")
+		})
+	}
+	if api.PolicyGetPolicyHistoryHandler == nil {
+		api.PolicyGetPolicyHistoryHandler = policy.GetPolicyHistoryHandlerFunc(func(params policy.GetPolicyHistoryParams) middleware.Responder {
+			return middleware.NotImplemented("operation policy.GetPolicyHistory has not yet been implemented")
+		})
+	}
+	if api.ProgramsGetProgramsHandler == nil {
+		api.ProgramsGetProgramsHandler = programs.GetProgramsHandlerFunc(func(params programs.GetProgramsParams) middleware.Responder {
+			return middleware.NotImplemented("operation programs.GetPrograms has not yet been implemented")
+		})
+	}
 	if api.DaemonGetHealthzHandler == nil {
 		api.DaemonGetHealthzHandler = daemon.GetHealthzHandlerFunc(func(params daemon.GetHealthzParams) middleware.Responder {
 			return middleware.NotImplemented("operation daemon.GetHealthz has not yet been implemented")
 		})
 	}
+	if api.ProgramsPostProgramsNameExceptionsHandler == nil {
+		api.ProgramsPostProgramsNameExceptionsHandler = programs.PostProgramsNameExceptionsHandlerFunc(func(params programs.PostProgramsNameExceptionsParams) middleware.Responder {
+			return middleware.NotImplemented("operation programs.PostProgramsNameExceptions has not yet been implemented")
+		})
+	}
+	if api.PolicyPostPolicyRollbackIDHandler == nil {
+		api.PolicyPostPolicyRollbackIDHandler = policy.PostPolicyRollbackIDHandlerFunc(func(params policy.PostPolicyRollbackIDParams) middleware.Responder {
+			return middleware.NotImplemented("operation policy.PostPolicyRollbackID has not yet been implemented")
+		})
+	}
+	if api.PolicyPutPolicyHandler == nil {
+		api.PolicyPutPolicyHandler = policy.PutPolicyHandlerFunc(func(params policy.PutPolicyParams) middleware.Responder {
+			return middleware.NotImplemented("operation policy.PutPolicy has not yet been implemented")
+		})
+	}
+	if api.DaemonPostSealHandler == nil {
+		api.DaemonPostSealHandler = daemon.PostSealHandlerFunc(func(params daemon.PostSealParams) middleware.Responder {
+			return middleware.NotImplemented("operation daemon.PostSeal has not yet been implemented")
+		})
+	}
 
 	api.PreServerShutdown = func() {}
 
@@ -80,6 +118,7 @@ func configureServer(s *http.Server, scheme, addr string) {
 	s.BaseContext = func(_ net.Listener) context.Context {
 		return ServerCtx
 	}
+	s.ConnContext = peerContext
 }
 
 // The middleware configuration is for the handler executors. These do not apply to the swagger.json document.
@@ -56,6 +56,84 @@ func init() {
         }
       }
     },
+    "/events/history": {
+      "get": {
+        "description": "Returns security events stored in the local audit log of the node, oldest first. Events are kept even when the node has no network access, up to the configured log size.",
+        "tags": [
+          "events"
+        ],
+        "summary": "Retrieve persisted security events",
+        "parameters": [
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "Only return events that happened at or after this time.",
+            "name": "since",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "Only return events that happened before this time.",
+            "name": "until",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return events reported by this bpf program.",
+            "name": "program",
+            "in": "query"
+          },
+          {
+            "enum": [
+              "allowed",
+              "denied"
+            ],
+            "type": "string",
+            "description": "Only return events with this access decision.",
+            "name": "decision",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return events of processes running inside this container. The container ID may be abbreviated.",
+            "name": "container",
+            "in": "query"
+          },
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "Only return events with a sequence number greater than cursor. Use the next value of a previous response to get the next page.",
+            "name": "cursor",
+            "in": "query"
+          },
+          {
+            "maximum": 1000,
+            "minimum": 1,
+            "type": "integer",
+            "format": "int64",
+            "default": 100,
+            "description": "Maximum number of events to return.",
+            "name": "limit",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "$ref": "#/definitions/EventHistory"
+            }
+          },
+          "500": {
+            "description": "Unable to read the event log",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
     "/healthz": {
       "get": {
         "description": "Returns health and status information of the bpflock daemon.",
@@ -79,6 +157,237 @@ func init() {
             "schema": {
               "$ref": "#/definitions/StatusResponse"
             }
+          },
+          "503": {
+            "description": "A required bpf program is not running",
+            "schema": {
+              "$ref": "#/definitions/StatusResponse"
+            }
+          }
+        }
+      }
+    },
+    "/policy": {
+      "put": {
+        "description": "Validates a complete bpf programs configuration with the same rules as the configuration directory, and applies all program changes as one transaction. If any bpf program fails to start, the previous configuration is restored. The restore is best-effort: bpf programs that changed are stopped before their new configuration starts, so they are not enforced until the previous one runs again, and the restore can fail too, see the restored field of the error. It is refused if the policy is sealed, if it would loosen the profile or the arguments of bpf programs running with the restricted profile, or if signed configurations are required.",
+        "tags": [
+          "policy"
+        ],
+        "summary": "Apply a new policy",
+        "parameters": [
+          {
+            "name": "bpfmeta",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/BpfMeta"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Policy applied",
+            "schema": {
+              "$ref": "#/definitions/PolicySnapshot"
+            }
+          },
+          "400": {
+            "description": "Invalid policy",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "403": {
+            "description": "The policy is sealed, the new policy would loosen bpf programs running with the restricted profile, or signed configurations are required",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Unable to apply the policy, the previous policy was restored unless restored is false",
+            "schema": {
+              "$ref": "#/definitions/PolicyApplyError"
+            }
+          }
+        }
+      }
+    },
+    "/policy/history": {
+      "get": {
+        "description": "Returns the snapshots of the bpf programs configurations that were applied, newest first. Snapshots are identified by the digest of their content.",
+        "tags": [
+          "policy"
+        ],
+        "summary": "Retrieve the history of applied policies",
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "$ref": "#/definitions/PolicyHistory"
+            }
+          },
+          "500": {
+            "description": "Unable to read the policy history",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/policy/rollback/{id}": {
+      "post": {
+        "description": "Applies again the bpf programs configuration of a previous snapshot. It is validated and applied like any new configuration, with the same best-effort restore on failure, and it is refused if the policy is sealed, if it would loosen bpf programs running with the restricted profile, or if signed configurations are required.",
+        "tags": [
+          "policy"
+        ],
+        "summary": "Roll back to a previous policy",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Identifier of the snapshot, or a unique prefix of it",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Policy applied",
+            "schema": {
+              "$ref": "#/definitions/PolicySnapshot"
+            }
+          },
+          "400": {
+            "description": "Invalid policy",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "403": {
+            "description": "The policy is sealed, the rollback would loosen bpf programs running with the restricted profile, or signed configurations are required",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "No such snapshot"
+          },
+          "500": {
+            "description": "Unable to apply the policy, the previous policy was restored",
+            "schema": {
+              "$ref": "#/definitions/PolicyApplyError"
+            }
+          }
+        }
+      }
+    },
+    "/programs": {
+      "get": {
+        "description": "Returns the configured bpf programs in the order they are started, with their dependencies and status. Programs of the same stage are started concurrently.",
+        "tags": [
+          "programs"
+        ],
+        "summary": "List the configured bpf programs",
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/ProgramStatus"
+              }
+            }
+          }
+        }
+      }
+    },
+    "/programs/{name}/exceptions": {
+      "post": {
+        "description": "Temporarily allows operations that the baseline profile of the bpf program denies, scoped by operation, cgroup or executable. The exception is revoked when it expires and when the bpflock agent restarts. Sealed programs and programs running with the restricted profile can not be loosened.",
+        "tags": [
+          "programs"
+        ],
+        "summary": "Grant a temporary exception to a bpf program",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Name of the bpf program",
+            "name": "name",
+            "in": "path",
+            "required": true
+          },
+          {
+            "name": "exception",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/ExceptionRequest"
+            }
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "Exception granted",
+            "schema": {
+              "$ref": "#/definitions/Exception"
+            }
+          },
+          "400": {
+            "description": "Invalid exception",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "403": {
+            "description": "The bpf program is sealed or runs with the restricted profile",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "No such bpf program"
+          },
+          "409": {
+            "description": "Too many active exceptions",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Unable to load the exception",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/seal": {
+      "post": {
+        "description": "Freezes the current policy until the next reboot. The configuration maps of the bpf programs are frozen, active exceptions are revoked and the bpflock daemon refuses any change that could loosen the policy, even after a restart. Sealing again has no effect.",
+        "tags": [
+          "daemon"
+        ],
+        "summary": "Seal the bpflock policy",
+        "responses": {
+          "200": {
+            "description": "Policy sealed",
+            "schema": {
+              "$ref": "#/definitions/Status"
+            }
+          },
+          "412": {
+            "description": "The selflock bpf program is not running",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Unable to seal the policy",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
         }
       }
@@ -116,6 +425,13 @@ func init() {
     "BpfProgram": {
       "type": "object",
       "properties": {
+        "after": {
+          "description": "Bpf programs that must be started before this one, '*' for all the others",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
         "args": {
           "description": "Command line arguments passed to the bpf program launcher",
           "type": "array",
@@ -135,10 +451,36 @@ func init() {
           "description": "Name of bpf program",
           "type": "string"
         },
+        "onFailure": {
+          "description": "What to do when the bpf program fails to start: exit the bpflock daemon, degrade and report a failure, or retry with backoff. Defaults to exit for required programs and degrade for the others",
+          "type": "string",
+          "enum": [
+            "exit",
+            "degrade",
+            "retry"
+          ]
+        },
         "priority": {
           "description": "Launch priority of the bpf program",
           "type": "integer",
           "format": "int32"
+        },
+        "required": {
+          "description": "The bpflock daemon is not healthy while a required bpf program is not running",
+          "type": "boolean"
+        },
+        "retries": {
+          "description": "Number of retries of the retry on-failure policy",
+          "type": "integer",
+          "format": "int32"
+        },
+        "retryBackoff": {
+          "description": "Delay before the first retry, doubled after each retry",
+          "type": "string"
+        },
+        "timeout": {
+          "description": "Time the bpf program launcher has to load and attach the bpf program",
+          "type": "string"
         }
       }
     },
@@ -216,120 +558,717 @@ func init() {
         "daemonConfigurationMap": ""
       }
     },
-    "Status": {
-      "description": "Status of an individual component",
+    "Error": {
+      "type": "string"
+    },
+    "Event": {
+      "description": "Security event reported by a bpf program",
       "type": "object",
       "properties": {
-        "msg": {
-          "description": "Human readable status/error/warning message",
+        "comm": {
+          "description": "Command name of the task that triggered the event",
           "type": "string"
         },
-        "state": {
-          "description": "State the component is in",
+        "container": {
+          "description": "ID of the container of the task, empty for host tasks",
+          "type": "string"
+        },
+        "decision": {
+          "description": "Access decision",
           "type": "string",
           "enum": [
-            "Ok",
-            "Warning",
-            "Failure",
-            "Disabled"
+            "allowed",
+            "denied"
           ]
+        },
+        "operation": {
+          "description": "Operation that was checked",
+          "type": "string"
+        },
+        "pid": {
+          "description": "Process ID of the task that triggered the event",
+          "type": "integer",
+          "format": "int32"
+        },
+        "program": {
+          "description": "Name of the bpf program that reported the event",
+          "type": "string"
+        },
+        "reason": {
+          "description": "Profile that made the decision",
+          "type": "string"
+        },
+        "rule": {
+          "description": "User or group rule of the baseline trust that matched the task, empty if no rule matched",
+          "type": "string"
+        },
+        "seq": {
+          "description": "Sequence number of the event in the local audit log",
+          "type": "integer",
+          "format": "int64"
+        },
+        "time": {
+          "description": "Time when the event was received",
+          "type": "string",
+          "format": "date-time"
         }
-      },
-      "example": {
-        "msg": "msg",
-        "state": "Ok"
       }
     },
-    "StatusResponse": {
-      "description": "Health and status information of daemon",
+    "EventHistory": {
+      "description": "Page of persisted security events",
       "type": "object",
       "properties": {
-        "bpflock": {
-          "$ref": "#/definitions/Status"
+        "events": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Event"
+          }
         },
-        "stale": {
-          "description": "List of stale information in the status",
-          "type": "object",
-          "additionalProperties": {
-            "description": "Timestamp when the probe was started",
-            "type": "string",
-            "format": "date-time"
+        "next": {
+          "description": "Cursor to fetch the next page, zero if there are no more events",
+          "type": "integer",
+          "format": "int64"
+        }
+      }
+    },
+    "Exception": {
+      "description": "Active temporary exception of a bpf program",
+      "type": "object",
+      "properties": {
+        "cgroup": {
+          "description": "Path of the cgroup whose tasks are allowed, empty for all cgroups",
+          "type": "string"
+        },
+        "created": {
+          "description": "Time when the exception was granted",
+          "type": "string",
+          "format": "date-time"
+        },
+        "executable": {
+          "description": "Path of the executable that is allowed, empty for all executables",
+          "type": "string"
+        },
+        "expires": {
+          "description": "Time when the exception is revoked",
+          "type": "string",
+          "format": "date-time"
+        },
+        "id": {
+          "description": "Unique identifier of the exception",
+          "type": "string"
+        },
+        "operation": {
+          "description": "Operation that is allowed, empty for all operations",
+          "type": "string"
+        },
+        "program": {
+          "description": "Name of the bpf program",
+          "type": "string"
+        },
+        "reason": {
+          "description": "Why the exception was granted",
+          "type": "string"
+        }
+      }
+    },
+    "ExceptionRequest": {
+      "description": "Temporary exception to grant to a bpf program, at least one scope among operation, cgroup and executable must be set",
+      "type": "object",
+      "required": [
+        "ttl",
+        "reason"
+      ],
+      "properties": {
+        "cgroup": {
+          "description": "Path of the cgroup v2 whose tasks are allowed, relative to the cgroup filesystem root",
+          "type": "string"
+        },
+        "executable": {
+          "description": "Path of the executable that is allowed",
+          "type": "string"
+        },
+        "operation": {
+          "description": "Operation that is allowed, as named by the block option of the bpf program",
+          "type": "string"
+        },
+        "reason": {
+          "description": "Why the exception is needed, it is stored in the audit log",
+          "type": "string",
+          "minLength": 1
+        },
+        "ttl": {
+          "description": "Duration of the exception, like 30m",
+          "type": "string",
+          "minLength": 1
+        }
+      }
+    },
+    "PolicyApplyError": {
+      "description": "Failure to apply a policy",
+      "type": "object",
+      "properties": {
+        "msg": {
+          "description": "Human readable error message",
+          "type": "string"
+        },
+        "programs": {
+          "description": "Outcome of each bpf program of the policy",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ProgramResult"
+          }
+        },
+        "restored": {
+          "description": "True if the previous policy was restored",
+          "type": "boolean"
+        }
+      }
+    },
+    "PolicyHistory": {
+      "description": "Snapshots of applied bpf programs configurations, newest first",
+      "type": "object",
+      "properties": {
+        "snapshots": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/PolicySnapshot"
+          }
+        }
+      }
+    },
+    "PolicySnapshot": {
+      "description": "Applied bpf programs configuration",
+      "type": "object",
+      "properties": {
+        "bpfmeta": {
+          "$ref": "#/definitions/BpfMeta"
+        },
+        "current": {
+          "description": "True for the configuration that is currently applied",
+          "type": "boolean"
+        },
+        "id": {
+          "description": "Digest of the bpf programs configuration",
+          "type": "string"
+        },
+        "identity": {
+          "description": "Process that applied the configuration",
+          "type": "string"
+        },
+        "source": {
+          "description": "Where the configuration came from",
+          "type": "string"
+        },
+        "time": {
+          "description": "Time when the configuration was applied",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "ProgramResult": {
+      "description": "Outcome of starting a bpf program",
+      "type": "object",
+      "properties": {
+        "error": {
+          "description": "Why the bpf program failed or is disabled",
+          "type": "string"
+        },
+        "name": {
+          "description": "Name of the bpf program",
+          "type": "string"
+        },
+        "result": {
+          "description": "Outcome of the bpf program",
+          "type": "string",
+          "enum": [
+            "adopted",
+            "started",
+            "failed",
+            "skipped",
+            "disabled"
+          ]
+        }
+      }
+    },
+    "ProgramStatus": {
+      "description": "Status of a bpf program",
+      "type": "object",
+      "properties": {
+        "after": {
+          "description": "Configured bpf programs that are started before this one",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "name": {
+          "description": "Name of the bpf program",
+          "type": "string"
+        },
+        "required": {
+          "description": "The bpflock daemon is not healthy while the bpf program is not running",
+          "type": "boolean"
+        },
+        "stage": {
+          "description": "Stage in which the bpf program is started, programs of the same stage are started concurrently",
+          "type": "integer",
+          "format": "int32"
+        },
+        "status": {
+          "$ref": "#/definitions/Status"
+        }
+      }
+    },
+    "Status": {
+      "description": "Status of an individual component",
+      "type": "object",
+      "properties": {
+        "msg": {
+          "description": "Human readable status/error/warning message",
+          "type": "string"
+        },
+        "state": {
+          "description": "State the component is in",
+          "type": "string",
+          "enum": [
+            "Ok",
+            "Warning",
+            "Failure",
+            "Disabled"
+          ]
+        }
+      },
+      "example": {
+        "msg": "msg",
+        "state": "Ok"
+      }
+    },
+    "StatusResponse": {
+      "description": "Health and status information of daemon",
+      "type": "object",
+      "properties": {
+        "bpflock": {
+          "$ref": "#/definitions/Status"
+        },
+        "exceptions": {
+          "description": "Active temporary exceptions of bpf programs",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Exception"
+          }
+        },
+        "integrity": {
+          "description": "Status of the integrity of pinned bpf programs, links and of the bpf filesystem",
+          "$ref": "#/definitions/Status"
+        },
+        "programs": {
+          "description": "Status of each configured bpf program",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ProgramStatus"
+          }
+        },
+        "seal": {
+          "description": "Status of the seal of the bpflock policy",
+          "$ref": "#/definitions/Status"
+        },
+        "stale": {
+          "description": "List of stale information in the status",
+          "type": "object",
+          "additionalProperties": {
+            "description": "Timestamp when the probe was started",
+            "type": "string",
+            "format": "date-time"
+          }
+        },
+        "sysctl": {
+          "description": "Status of the applied sysctl hardening profile",
+          "$ref": "#/definitions/Status"
+        }
+      },
+      "example": {
+        "bpflock": {
+          "msg": "msg",
+          "state": "Ok"
+        }
+      }
+    }
+  },
+  "x-schemes": [
+    "unix"
+  ]
+}`))
+	FlatSwaggerJSON = json.RawMessage([]byte(`{
+  "consumes": [
+    "application/json"
+  ],
+  "produces": [
+    "application/json"
+  ],
+  "schemes": [
+    "http"
+  ],
+  "swagger": "2.0",
+  "info": {
+    "description": "bpflock",
+    "title": "bpflock API",
+    "license": {
+      "name": "Apache 2.0",
+      "url": "http://www.apache.org/licenses/LICENSE-2.0.html"
+    },
+    "version": "v1beta"
+  },
+  "basePath": "/v1",
+  "paths": {
+    "/config": {
+      "get": {
+        "description": "Returns the configuration of the bpflock daemon.",
+        "tags": [
+          "daemon"
+        ],
+        "summary": "Get configuration of bpflock daemon",
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "$ref": "#/definitions/DaemonConfiguration"
+            }
+          }
+        }
+      }
+    },
+    "/events/history": {
+      "get": {
+        "description": "Returns security events stored in the local audit log of the node, oldest first. Events are kept even when the node has no network access, up to the configured log size.",
+        "tags": [
+          "events"
+        ],
+        "summary": "Retrieve persisted security events",
+        "parameters": [
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "Only return events that happened at or after this time.",
+            "name": "since",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "Only return events that happened before this time.",
+            "name": "until",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return events reported by this bpf program.",
+            "name": "program",
+            "in": "query"
+          },
+          {
+            "enum": [
+              "allowed",
+              "denied"
+            ],
+            "type": "string",
+            "description": "Only return events with this access decision.",
+            "name": "decision",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return events of processes running inside this container. The container ID may be abbreviated.",
+            "name": "container",
+            "in": "query"
+          },
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "Only return events with a sequence number greater than cursor. Use the next value of a previous response to get the next page.",
+            "name": "cursor",
+            "in": "query"
+          },
+          {
+            "maximum": 1000,
+            "minimum": 1,
+            "type": "integer",
+            "format": "int64",
+            "default": 100,
+            "description": "Maximum number of events to return.",
+            "name": "limit",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "$ref": "#/definitions/EventHistory"
+            }
+          },
+          "500": {
+            "description": "Unable to read the event log",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/healthz": {
+      "get": {
+        "description": "Returns health and status information of the bpflock daemon.",
+        "tags": [
+          "daemon"
+        ],
+        "summary": "Get health of bpflock daemon",
+        "parameters": [
+          {
+            "type": "boolean",
+            "x-exportParamName": "Brief",
+            "x-optionalDataType": "Bool",
+            "description": "Brief will return a brief representation of the bpflock status.",
+            "name": "brief",
+            "in": "header"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "$ref": "#/definitions/StatusResponse"
+            }
+          },
+          "503": {
+            "description": "A required bpf program is not running",
+            "schema": {
+              "$ref": "#/definitions/StatusResponse"
+            }
+          }
+        }
+      }
+    },
+    "/policy": {
+      "put": {
+        "description": "Validates a complete bpf programs configuration with the same rules as the configuration directory, and applies all program changes as one transaction. If any bpf program fails to start, the previous configuration is restored. The restore is best-effort: bpf programs that changed are stopped before their new configuration starts, so they are not enforced until the previous one runs again, and the restore can fail too, see the restored field of the error. It is refused if the policy is sealed, if it would loosen the profile or the arguments of bpf programs running with the restricted profile, or if signed configurations are required.",
+        "tags": [
+          "policy"
+        ],
+        "summary": "Apply a new policy",
+        "parameters": [
+          {
+            "name": "bpfmeta",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/BpfMeta"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Policy applied",
+            "schema": {
+              "$ref": "#/definitions/PolicySnapshot"
+            }
+          },
+          "400": {
+            "description": "Invalid policy",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "403": {
+            "description": "The policy is sealed, the new policy would loosen bpf programs running with the restricted profile, or signed configurations are required",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Unable to apply the policy, the previous policy was restored unless restored is false",
+            "schema": {
+              "$ref": "#/definitions/PolicyApplyError"
+            }
+          }
+        }
+      }
+    },
+    "/policy/history": {
+      "get": {
+        "description": "Returns the snapshots of the bpf programs configurations that were applied, newest first. Snapshots are identified by the digest of their content.",
+        "tags": [
+          "policy"
+        ],
+        "summary": "Retrieve the history of applied policies",
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "$ref": "#/definitions/PolicyHistory"
+            }
+          },
+          "500": {
+            "description": "Unable to read the policy history",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/policy/rollback/{id}": {
+      "post": {
+        "description": "Applies again the bpf programs configuration of a previous snapshot. It is validated and applied like any new configuration, with the same best-effort restore on failure, and it is refused if the policy is sealed, if it would loosen bpf programs running with the restricted profile, or if signed configurations are required.",
+        "tags": [
+          "policy"
+        ],
+        "summary": "Roll back to a previous policy",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Identifier of the snapshot, or a unique prefix of it",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Policy applied",
+            "schema": {
+              "$ref": "#/definitions/PolicySnapshot"
+            }
+          },
+          "400": {
+            "description": "Invalid policy",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "403": {
+            "description": "The policy is sealed, the rollback would loosen bpf programs running with the restricted profile, or signed configurations are required",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "No such snapshot"
+          },
+          "500": {
+            "description": "Unable to apply the policy, the previous policy was restored",
+            "schema": {
+              "$ref": "#/definitions/PolicyApplyError"
+            }
           }
         }
-      },
-      "example": {
-        "bpflock": {
-          "msg": "msg",
-          "state": "Ok"
-        }
       }
-    }
-  },
-  "x-schemes": [
-    "unix"
-  ]
-}`))
-	FlatSwaggerJSON = json.RawMessage([]byte(`{
-  "consumes": [
-    "application/json"
-  ],
-  "produces": [
-    "application/json"
-  ],
-  "schemes": [
-    "http"
-  ],
-  "swagger": "2.0",
-  "info": {
-    "description": "bpflock",
-    "title": "bpflock API",
-    "license": {
-      "name": "Apache 2.0",
-      "url": "http://www.apache.org/licenses/LICENSE-2.0.html"
     },
-    "version": "v1beta"
-  },
-  "basePath": "/v1",
-  "paths": {
-    "/config": {
+    "/programs": {
       "get": {
-        "description": "Returns the configuration of the bpflock daemon.",
+        "description": "Returns the configured bpf programs in the order they are started, with their dependencies and status. Programs of the same stage are started concurrently.",
         "tags": [
-          "daemon"
+          "programs"
         ],
-        "summary": "Get configuration of bpflock daemon",
+        "summary": "List the configured bpf programs",
         "responses": {
           "200": {
             "description": "Success",
             "schema": {
-              "$ref": "#/definitions/DaemonConfiguration"
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/ProgramStatus"
+              }
             }
           }
         }
       }
     },
-    "/healthz": {
-      "get": {
-        "description": "Returns health and status information of the bpflock daemon.",
+    "/programs/{name}/exceptions": {
+      "post": {
+        "description": "Temporarily allows operations that the baseline profile of the bpf program denies, scoped by operation, cgroup or executable. The exception is revoked when it expires and when the bpflock agent restarts. Sealed programs and programs running with the restricted profile can not be loosened.",
         "tags": [
-          "daemon"
+          "programs"
         ],
-        "summary": "Get health of bpflock daemon",
+        "summary": "Grant a temporary exception to a bpf program",
         "parameters": [
           {
-            "type": "boolean",
-            "x-exportParamName": "Brief",
-            "x-optionalDataType": "Bool",
-            "description": "Brief will return a brief representation of the bpflock status.",
-            "name": "brief",
-            "in": "header"
+            "type": "string",
+            "description": "Name of the bpf program",
+            "name": "name",
+            "in": "path",
+            "required": true
+          },
+          {
+            "name": "exception",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/ExceptionRequest"
+            }
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "Exception granted",
+            "schema": {
+              "$ref": "#/definitions/Exception"
+            }
+          },
+          "400": {
+            "description": "Invalid exception",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "403": {
+            "description": "The bpf program is sealed or runs with the restricted profile",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "No such bpf program"
+          },
+          "409": {
+            "description": "Too many active exceptions",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Unable to load the exception",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
+        }
+      }
+    },
+    "/seal": {
+      "post": {
+        "description": "Freezes the current policy until the next reboot. The configuration maps of the bpf programs are frozen, active exceptions are revoked and the bpflock daemon refuses any change that could loosen the policy, even after a restart. Sealing again has no effect.",
+        "tags": [
+          "daemon"
         ],
+        "summary": "Seal the bpflock policy",
         "responses": {
           "200": {
-            "description": "Success",
+            "description": "Policy sealed",
             "schema": {
-              "$ref": "#/definitions/StatusResponse"
+              "$ref": "#/definitions/Status"
+            }
+          },
+          "412": {
+            "description": "The selflock bpf program is not running",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Unable to seal the policy",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           }
         }
@@ -368,6 +1307,13 @@ func init() {
     "BpfProgram": {
       "type": "object",
       "properties": {
+        "after": {
+          "description": "Bpf programs that must be started before this one, '*' for all the others",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
         "args": {
           "description": "Command line arguments passed to the bpf program launcher",
           "type": "array",
@@ -387,10 +1333,36 @@ func init() {
           "description": "Name of bpf program",
           "type": "string"
         },
+        "onFailure": {
+          "description": "What to do when the bpf program fails to start: exit the bpflock daemon, degrade and report a failure, or retry with backoff. Defaults to exit for required programs and degrade for the others",
+          "type": "string",
+          "enum": [
+            "exit",
+            "degrade",
+            "retry"
+          ]
+        },
         "priority": {
           "description": "Launch priority of the bpf program",
           "type": "integer",
           "format": "int32"
+        },
+        "required": {
+          "description": "The bpflock daemon is not healthy while a required bpf program is not running",
+          "type": "boolean"
+        },
+        "retries": {
+          "description": "Number of retries of the retry on-failure policy",
+          "type": "integer",
+          "format": "int32"
+        },
+        "retryBackoff": {
+          "description": "Delay before the first retry, doubled after each retry",
+          "type": "string"
+        },
+        "timeout": {
+          "description": "Time the bpf program launcher has to load and attach the bpf program",
+          "type": "string"
         }
       }
     },
@@ -468,6 +1440,268 @@ func init() {
         "daemonConfigurationMap": ""
       }
     },
+    "Error": {
+      "type": "string"
+    },
+    "Event": {
+      "description": "Security event reported by a bpf program",
+      "type": "object",
+      "properties": {
+        "comm": {
+          "description": "Command name of the task that triggered the event",
+          "type": "string"
+        },
+        "container": {
+          "description": "ID of the container of the task, empty for host tasks",
+          "type": "string"
+        },
+        "decision": {
+          "description": "Access decision",
+          "type": "string",
+          "enum": [
+            "allowed",
+            "denied"
+          ]
+        },
+        "operation": {
+          "description": "Operation that was checked",
+          "type": "string"
+        },
+        "pid": {
+          "description": "Process ID of the task that triggered the event",
+          "type": "integer",
+          "format": "int32"
+        },
+        "program": {
+          "description": "Name of the bpf program that reported the event",
+          "type": "string"
+        },
+        "reason": {
+          "description": "Profile that made the decision",
+          "type": "string"
+        },
+        "rule": {
+          "description": "User or group rule of the baseline trust that matched the task, empty if no rule matched",
+          "type": "string"
+        },
+        "seq": {
+          "description": "Sequence number of the event in the local audit log",
+          "type": "integer",
+          "format": "int64"
+        },
+        "time": {
+          "description": "Time when the event was received",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "EventHistory": {
+      "description": "Page of persisted security events",
+      "type": "object",
+      "properties": {
+        "events": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Event"
+          }
+        },
+        "next": {
+          "description": "Cursor to fetch the next page, zero if there are no more events",
+          "type": "integer",
+          "format": "int64"
+        }
+      }
+    },
+    "Exception": {
+      "description": "Active temporary exception of a bpf program",
+      "type": "object",
+      "properties": {
+        "cgroup": {
+          "description": "Path of the cgroup whose tasks are allowed, empty for all cgroups",
+          "type": "string"
+        },
+        "created": {
+          "description": "Time when the exception was granted",
+          "type": "string",
+          "format": "date-time"
+        },
+        "executable": {
+          "description": "Path of the executable that is allowed, empty for all executables",
+          "type": "string"
+        },
+        "expires": {
+          "description": "Time when the exception is revoked",
+          "type": "string",
+          "format": "date-time"
+        },
+        "id": {
+          "description": "Unique identifier of the exception",
+          "type": "string"
+        },
+        "operation": {
+          "description": "Operation that is allowed, empty for all operations",
+          "type": "string"
+        },
+        "program": {
+          "description": "Name of the bpf program",
+          "type": "string"
+        },
+        "reason": {
+          "description": "Why the exception was granted",
+          "type": "string"
+        }
+      }
+    },
+    "ExceptionRequest": {
+      "description": "Temporary exception to grant to a bpf program, at least one scope among operation, cgroup and executable must be set",
+      "type": "object",
+      "required": [
+        "ttl",
+        "reason"
+      ],
+      "properties": {
+        "cgroup": {
+          "description": "Path of the cgroup v2 whose tasks are allowed, relative to the cgroup filesystem root",
+          "type": "string"
+        },
+        "executable": {
+          "description": "Path of the executable that is allowed",
+          "type": "string"
+        },
+        "operation": {
+          "description": "Operation that is allowed, as named by the block option of the bpf program",
+          "type": "string"
+        },
+        "reason": {
+          "description": "Why the exception is needed, it is stored in the audit log",
+          "type": "string",
+          "minLength": 1
+        },
+        "ttl": {
+          "description": "Duration of the exception, like 30m",
+          "type": "string",
+          "minLength": 1
+        }
+      }
+    },
+    "PolicyApplyError": {
+      "description": "Failure to apply a policy",
+      "type": "object",
+      "properties": {
+        "msg": {
+          "description": "Human readable error message",
+          "type": "string"
+        },
+        "programs": {
+          "description": "Outcome of each bpf program of the policy",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ProgramResult"
+          }
+        },
+        "restored": {
+          "description": "True if the previous policy was restored",
+          "type": "boolean"
+        }
+      }
+    },
+    "PolicyHistory": {
+      "description": "Snapshots of applied bpf programs configurations, newest first",
+      "type": "object",
+      "properties": {
+        "snapshots": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/PolicySnapshot"
+          }
+        }
+      }
+    },
+    "PolicySnapshot": {
+      "description": "Applied bpf programs configuration",
+      "type": "object",
+      "properties": {
+        "bpfmeta": {
+          "$ref": "#/definitions/BpfMeta"
+        },
+        "current": {
+          "description": "True for the configuration that is currently applied",
+          "type": "boolean"
+        },
+        "id": {
+          "description": "Digest of the bpf programs configuration",
+          "type": "string"
+        },
+        "identity": {
+          "description": "Process that applied the configuration",
+          "type": "string"
+        },
+        "source": {
+          "description": "Where the configuration came from",
+          "type": "string"
+        },
+        "time": {
+          "description": "Time when the configuration was applied",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "ProgramResult": {
+      "description": "Outcome of starting a bpf program",
+      "type": "object",
+      "properties": {
+        "error": {
+          "description": "Why the bpf program failed or is disabled",
+          "type": "string"
+        },
+        "name": {
+          "description": "Name of the bpf program",
+          "type": "string"
+        },
+        "result": {
+          "description": "Outcome of the bpf program",
+          "type": "string",
+          "enum": [
+            "adopted",
+            "started",
+            "failed",
+            "skipped",
+            "disabled"
+          ]
+        }
+      }
+    },
+    "ProgramStatus": {
+      "description": "Status of a bpf program",
+      "type": "object",
+      "properties": {
+        "after": {
+          "description": "Configured bpf programs that are started before this one",
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "name": {
+          "description": "Name of the bpf program",
+          "type": "string"
+        },
+        "required": {
+          "description": "The bpflock daemon is not healthy while the bpf program is not running",
+          "type": "boolean"
+        },
+        "stage": {
+          "description": "Stage in which the bpf program is started, programs of the same stage are started concurrently",
+          "type": "integer",
+          "format": "int32"
+        },
+        "status": {
+          "$ref": "#/definitions/Status"
+        }
+      }
+    },
     "Status": {
       "description": "Status of an individual component",
       "type": "object",
@@ -499,6 +1733,28 @@ func init() {
         "bpflock": {
           "$ref": "#/definitions/Status"
         },
+        "exceptions": {
+          "description": "Active temporary exceptions of bpf programs",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Exception"
+          }
+        },
+        "integrity": {
+          "description": "Status of the integrity of pinned bpf programs, links and of the bpf filesystem",
+          "$ref": "#/definitions/Status"
+        },
+        "programs": {
+          "description": "Status of each configured bpf program",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ProgramStatus"
+          }
+        },
+        "seal": {
+          "description": "Status of the seal of the bpflock policy",
+          "$ref": "#/definitions/Status"
+        },
         "stale": {
           "description": "List of stale information in the status",
           "type": "object",
@@ -507,6 +1763,10 @@ func init() {
             "type": "string",
             "format": "date-time"
           }
+        },
+        "sysctl": {
+          "description": "Status of the applied sysctl hardening profile",
+          "$ref": "#/definitions/Status"
         }
       },
       "example": {
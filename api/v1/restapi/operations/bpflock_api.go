@@ -20,6 +20,9 @@ import (
 	"github.com/go-openapi/swag"
 
 	"github.com/linux-lock/bpflock/api/v1/restapi/operations/daemon"
+	"github.com/linux-lock/bpflock/api/v1/restapi/operations/events"
+	"github.com/linux-lock/bpflock/api/v1/restapi/operations/policy"
+	"github.com/linux-lock/bpflock/api/v1/restapi/operations/programs"
 )
 
 // NewBpflockAPI creates a new Bpflock instance
@@ -47,9 +50,30 @@ func NewBpflockAPI(spec *loads.Document) *BpflockAPI {
 		DaemonGetConfigHandler: daemon.GetConfigHandlerFunc(func(params daemon.GetConfigParams) middleware.Responder {
 			return middleware.NotImplemented("operation daemon.GetConfig has not yet been implemented")
 		}),
+		EventsGetEventsHistoryHandler: events.GetEventsHistoryHandlerFunc(func(params events.GetEventsHistoryParams) middleware.Responder {
+			return middleware.NotImplemented("operation events.GetEventsHistory has not yet been implemented")
+		}),
 		DaemonGetHealthzHandler: daemon.GetHealthzHandlerFunc(func(params daemon.GetHealthzParams) middleware.Responder {
 			return middleware.NotImplemented("operation daemon.GetHealthz has not yet been implemented")
 		}),
+		PolicyGetPolicyHistoryHandler: policy.GetPolicyHistoryHandlerFunc(func(params policy.GetPolicyHistoryParams) middleware.Responder {
+			return middleware.NotImplemented("operation policy.GetPolicyHistory has not yet been implemented")
+		}),
+		ProgramsGetProgramsHandler: programs.GetProgramsHandlerFunc(func(params programs.GetProgramsParams) middleware.Responder {
+			return middleware.NotImplemented("operation programs.GetPrograms has not yet been implemented")
+		}),
+		PolicyPostPolicyRollbackIDHandler: policy.PostPolicyRollbackIDHandlerFunc(func(params policy.PostPolicyRollbackIDParams) middleware.Responder {
+			return middleware.NotImplemented("operation policy.PostPolicyRollbackID has not yet been implemented")
+		}),
+		ProgramsPostProgramsNameExceptionsHandler: programs.PostProgramsNameExceptionsHandlerFunc(func(params programs.PostProgramsNameExceptionsParams) middleware.Responder {
+			return middleware.NotImplemented("operation programs.PostProgramsNameExceptions has not yet been implemented")
+		}),
+		DaemonPostSealHandler: daemon.PostSealHandlerFunc(func(params daemon.PostSealParams) middleware.Responder {
+			return middleware.NotImplemented("operation daemon.PostSeal has not yet been implemented")
+		}),
+		PolicyPutPolicyHandler: policy.PutPolicyHandlerFunc(func(params policy.PutPolicyParams) middleware.Responder {
+			return middleware.NotImplemented("operation policy.PutPolicy has not yet been implemented")
+		}),
 	}
 }
 
@@ -88,8 +112,22 @@ type BpflockAPI struct {
 
 	// DaemonGetConfigHandler sets the operation handler for the get config operation
 	DaemonGetConfigHandler daemon.GetConfigHandler
+	// EventsGetEventsHistoryHandler sets the operation handler for the get events history operation
+	EventsGetEventsHistoryHandler events.GetEventsHistoryHandler
 	// DaemonGetHealthzHandler sets the operation handler for the get healthz operation
 	DaemonGetHealthzHandler daemon.GetHealthzHandler
+	// PolicyGetPolicyHistoryHandler sets the operation handler for the get policy history operation
+	PolicyGetPolicyHistoryHandler policy.GetPolicyHistoryHandler
+	// ProgramsGetProgramsHandler sets the operation handler for the get programs operation
+	ProgramsGetProgramsHandler programs.GetProgramsHandler
+	// PolicyPostPolicyRollbackIDHandler sets the operation handler for the post policy rollback ID operation
+	PolicyPostPolicyRollbackIDHandler policy.PostPolicyRollbackIDHandler
+	// ProgramsPostProgramsNameExceptionsHandler sets the operation handler for the post programs name exceptions operation
+	ProgramsPostProgramsNameExceptionsHandler programs.PostProgramsNameExceptionsHandler
+	// DaemonPostSealHandler sets the operation handler for the post seal operation
+	DaemonPostSealHandler daemon.PostSealHandler
+	// PolicyPutPolicyHandler sets the operation handler for the put policy operation
+	PolicyPutPolicyHandler policy.PutPolicyHandler
 
 	// ServeError is called when an error is received, there is a default handler
 	// but you can set your own with this
@@ -170,9 +208,30 @@ func (o *BpflockAPI) Validate() error {
 	if o.DaemonGetConfigHandler == nil {
 		unregistered = append(unregistered, "daemon.GetConfigHandler")
 	}
+	if o.EventsGetEventsHistoryHandler == nil {
+		unregistered = append(unregistered, "events.GetEventsHistoryHandler")
+	}
 	if o.DaemonGetHealthzHandler == nil {
 		unregistered = append(unregistered, "daemon.GetHealthzHandler")
 	}
+	if o.PolicyGetPolicyHistoryHandler == nil {
+		unregistered = append(unregistered, "policy.GetPolicyHistoryHandler")
+	}
+	if o.ProgramsGetProgramsHandler == nil {
+		unregistered = append(unregistered, "programs.GetProgramsHandler")
+	}
+	if o.PolicyPostPolicyRollbackIDHandler == nil {
+		unregistered = append(unregistered, "policy.PostPolicyRollbackIDHandler")
+	}
+	if o.ProgramsPostProgramsNameExceptionsHandler == nil {
+		unregistered = append(unregistered, "programs.PostProgramsNameExceptionsHandler")
+	}
+	if o.DaemonPostSealHandler == nil {
+		unregistered = append(unregistered, "daemon.PostSealHandler")
+	}
+	if o.PolicyPutPolicyHandler == nil {
+		unregistered = append(unregistered, "policy.PutPolicyHandler")
+	}
 
 	if len(unregistered) > 0 {
 		return fmt.Errorf("missing registration: %s", strings.Join(unregistered, ", "))
@@ -268,7 +327,35 @@ func (o *BpflockAPI) initHandlerCache() {
 	if o.handlers["GET"] == nil {
 		o.handlers["GET"] = make(map[string]http.Handler)
 	}
+	o.handlers["GET"]["/events/history"] = events.NewGetEventsHistory(o.context, o.EventsGetEventsHistoryHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
 	o.handlers["GET"]["/healthz"] = daemon.NewGetHealthz(o.context, o.DaemonGetHealthzHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/policy/history"] = policy.NewGetPolicyHistory(o.context, o.PolicyGetPolicyHistoryHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/programs"] = programs.NewGetPrograms(o.context, o.ProgramsGetProgramsHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/policy/rollback/{id}"] = policy.NewPostPolicyRollbackID(o.context, o.PolicyPostPolicyRollbackIDHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/programs/{name}/exceptions"] = programs.NewPostProgramsNameExceptions(o.context, o.ProgramsPostProgramsNameExceptionsHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/seal"] = daemon.NewPostSeal(o.context, o.DaemonPostSealHandler)
+	if o.handlers["PUT"] == nil {
+		o.handlers["PUT"] = make(map[string]http.Handler)
+	}
+	o.handlers["PUT"]["/policy"] = policy.NewPutPolicy(o.context, o.PolicyPutPolicyHandler)
 }
 
 // Serve creates a http handler to serve the API over HTTP
@@ -56,3 +56,47 @@ func (o *GetHealthzOK) WriteResponse(rw http.ResponseWriter, producer runtime.Pr
 		}
 	}
 }
+
+// GetHealthzServiceUnavailableCode is the HTTP code returned for type GetHealthzServiceUnavailable
+const GetHealthzServiceUnavailableCode int = 503
+
+/*GetHealthzServiceUnavailable A required bpf program is not running
+
+swagger:response getHealthzServiceUnavailable
+*/
+type GetHealthzServiceUnavailable struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.StatusResponse `json:"body,omitempty"`
+}
+
+// NewGetHealthzServiceUnavailable creates GetHealthzServiceUnavailable with default headers values
+func NewGetHealthzServiceUnavailable() *GetHealthzServiceUnavailable {
+
+	return &GetHealthzServiceUnavailable{}
+}
+
+// WithPayload adds the payload to the get healthz service unavailable response
+func (o *GetHealthzServiceUnavailable) WithPayload(payload *models.StatusResponse) *GetHealthzServiceUnavailable {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get healthz service unavailable response
+func (o *GetHealthzServiceUnavailable) SetPayload(payload *models.StatusResponse) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetHealthzServiceUnavailable) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(503)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
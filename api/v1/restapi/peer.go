@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package restapi
+
+import (
+	"context"
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+type peerKey struct{}
+
+// peerContext stores the credentials of the process connected to the unix
+// socket of the API in the context of its requests.
+func peerContext(ctx context.Context, c net.Conn) context.Context {
+	uc, ok := c.(*net.UnixConn)
+	if !ok {
+		return ctx
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return ctx
+	}
+
+	var cred *unix.Ucred
+	raw.Control(func(fd uintptr) {
+		cred, err = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+	if err != nil || cred == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, peerKey{}, cred)
+}
+
+// PeerCredentials returns the credentials of the process that sent the
+// request of ctx, false if they are not known.
+func PeerCredentials(ctx context.Context) (*unix.Ucred, bool) {
+	cred, ok := ctx.Value(peerKey{}).(*unix.Ucred)
+	return cred, ok
+}
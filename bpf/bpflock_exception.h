@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: LGPL-2.1
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ */
+
+/* Temporary exceptions of bpf programs */
+
+#ifndef __BPFLOCK_EXCEPTION_H
+#define __BPFLOCK_EXCEPTION_H
+
+#include "bpflock_shared_defs.h"
+
+/*
+ * Declares the temporary exceptions map of a bpf program. Entries are set
+ * by the bpflock agent and expire on their own, even if the agent is gone.
+ */
+#define BPFLOCK_EXCEPTION_MAP(name)                             \
+struct {                                                        \
+        __uint(type, BPF_MAP_TYPE_ARRAY);                       \
+        __uint(max_entries, BPFLOCK_MAX_EXCEPTIONS);            \
+        __type(key, uint32_t);                                  \
+        __type(value, struct bl_exception);                     \
+} name SEC(".maps")
+
+/*
+ * Returns true if an active exception allows the operation op of current:
+ * its operations, cgroup and executable must all match.
+ */
+static __always_inline bool is_excepted(void *map, uint32_t op)
+{
+        struct task_struct *current;
+        struct bl_exception *e;
+        struct inode *inode;
+        uint64_t now, cgroup = 0, dev = 0, ino = 0;
+        uint32_t i, k;
+        bool exe = false;
+
+        now = bpf_ktime_get_boot_ns();
+
+        for (i = 0; i < BPFLOCK_MAX_EXCEPTIONS; i++) {
+                k = i;
+                e = bpf_map_lookup_elem(map, &k);
+                if (!e || e->expires <= now)
+                        continue;
+
+                if (e->ops && !(e->ops & op))
+                        continue;
+
+                if (e->cgroup) {
+                        if (!cgroup)
+                                cgroup = bpf_get_current_cgroup_id();
+                        if (cgroup != e->cgroup)
+                                continue;
+                }
+
+                if (e->st_ino) {
+                        if (!exe) {
+                                current = (struct task_struct *)bpf_get_current_task();
+                                inode = BPF_CORE_READ(current, mm, exe_file, f_inode);
+                                if (inode) {
+                                        dev = BPF_CORE_READ(inode, i_sb, s_dev);
+                                        ino = BPF_CORE_READ(inode, i_ino);
+                                }
+                                exe = true;
+                        }
+                        if (dev != e->st_dev || ino != e->st_ino)
+                                continue;
+                }
+
+                return true;
+        }
+
+        return false;
+}
+
+#endif /* __BPFLOCK_EXCEPTION_H */
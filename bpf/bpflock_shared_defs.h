@@ -15,4 +15,91 @@ enum bpflock_profile {
         BPFLOCK_P_RESTRICTED,
 };
 
+/*
+ * Namespace types of the baseline trust criteria, they must match
+ * pkg/trust. The criteria entry is keyed with the zero bl_trust_key and
+ * its value is a bitmask of (1 << type).
+ */
+enum bpflock_trust_ns {
+        BPFLOCK_TRUST_PIDNS     = 1,
+        BPFLOCK_TRUST_MNTNS,
+        BPFLOCK_TRUST_USERNS,
+        BPFLOCK_TRUST_CGROUPNS,
+};
+
+/* Maximum number of trusted namespace sets */
+#define BPFLOCK_TRUST_MAX_NS    256
+
+/*
+ * Namespaces of the host or of a trusted process or container. Only the
+ * types of the criteria are set, the others are zero, so a task is trusted
+ * only if all its namespaces belong to the same trusted source.
+ */
+struct bl_trust_key {
+        uint32_t pidns;
+        uint32_t mntns;
+        uint32_t userns;
+        uint32_t cgroupns;
+};
+
+/*
+ * User and group rules of the baseline trust, they must match pkg/trust.
+ * Deny rules make matching tasks untrusted even in trusted namespaces,
+ * if allow rules are set only matching tasks are trusted. Rules are
+ * stored in order in an array, the first rule with kind
+ * BPFLOCK_ID_NONE ends the list.
+ */
+enum bpflock_id_kind {
+        BPFLOCK_ID_NONE         = 0,
+        BPFLOCK_ID_ALLOW_UID,
+        BPFLOCK_ID_ALLOW_GID,
+        BPFLOCK_ID_DENY_UID,
+        BPFLOCK_ID_DENY_GID,
+};
+
+/* Maximum number of user and group rules */
+#define BPFLOCK_ID_MAX_RULES    32
+
+/* Maximum length of the rule description reported in events */
+#define BPFLOCK_ID_RULE_LEN     48
+
+struct bl_id_rule {
+        uint32_t kind;
+        uint32_t min;
+        uint32_t max;
+        char name[BPFLOCK_ID_RULE_LEN];
+};
+
+/*
+ * Temporary exceptions granted by the bpflock agent, they must match
+ * pkg/exception. A zero scope matches all, an entry is unused if it
+ * expired, expires is in CLOCK_BOOTTIME nanoseconds.
+ */
+#define BPFLOCK_MAX_EXCEPTIONS  16
+
+struct bl_exception {
+        uint32_t ops;
+        uint32_t pad;
+        uint64_t cgroup;
+        uint64_t st_dev;
+        uint64_t st_ino;
+        uint64_t expires;
+};
+
+/* Options of the trust criteria, they are loaded by the bpflock agent */
+#define BPFLOCK_TRUST_OPT       0x100
+#define BPFLOCK_TRUST_NS_OPT    0x101
+#define BPFLOCK_ALLOW_UID_OPT   0x102
+#define BPFLOCK_ALLOW_GID_OPT   0x103
+#define BPFLOCK_DENY_UID_OPT    0x104
+#define BPFLOCK_DENY_GID_OPT    0x105
+
+#define BPFLOCK_TRUST_ARGP_OPTIONS \
+        { "trust", BPFLOCK_TRUST_OPT, "NAMESPACES", 0, "Comma-separated list of namespace types that must be trusted in baseline profile, possible values: 'pid, mnt, user, cgroup'. Default value is: pid." }, \
+        { "trust-ns", BPFLOCK_TRUST_NS_OPT, "NAMES", 0, "Comma-separated list of 'process:PATH' or 'container:ID' whose namespaces are also trusted in baseline profile." }, \
+        { "allow-uid", BPFLOCK_ALLOW_UID_OPT, "USERS", 0, "Comma-separated list of user names, uids or uid ranges 'MIN-MAX', only their tasks are trusted in baseline profile." }, \
+        { "allow-gid", BPFLOCK_ALLOW_GID_OPT, "GROUPS", 0, "Comma-separated list of group names, gids or gid ranges 'MIN-MAX', only their tasks are trusted in baseline profile." }, \
+        { "deny-uid", BPFLOCK_DENY_UID_OPT, "USERS", 0, "Comma-separated list of user names, uids or uid ranges 'MIN-MAX' whose tasks are never trusted in baseline profile." }, \
+        { "deny-gid", BPFLOCK_DENY_GID_OPT, "GROUPS", 0, "Comma-separated list of group names, gids or gid ranges 'MIN-MAX' whose tasks are never trusted in baseline profile." }
+
 #endif /* __BPFLOCK_SHARED_DEFS_H */
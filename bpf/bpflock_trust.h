@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: LGPL-2.1
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ */
+
+/* Baseline trust criteria of bpf programs */
+
+#ifndef __BPFLOCK_TRUST_H
+#define __BPFLOCK_TRUST_H
+
+#include "bpflock_shared_defs.h"
+
+/*
+ * Declares the trusted namespaces map of a bpf program. Keys are set by the
+ * bpflock agent: the namespace sets of the host and of trusted processes or
+ * containers, and the trust criteria.
+ */
+#define BPFLOCK_TRUST_MAP(name)                                 \
+struct {                                                        \
+        __uint(type, BPF_MAP_TYPE_HASH);                        \
+        __uint(max_entries, BPFLOCK_TRUST_MAX_NS);              \
+        __type(key, struct bl_trust_key);                       \
+        __type(value, uint32_t);                                \
+} name SEC(".maps")
+
+/*
+ * Returns true if current is trusted as a task of the host under baseline:
+ * its namespaces of the criteria types must be one of the trusted sets.
+ * Until the agent has loaded the criteria only tasks of the initial pid
+ * namespace are trusted.
+ */
+static __always_inline bool is_trusted_ns(void *map)
+{
+        struct task_struct *current;
+        struct bl_trust_key key = {};
+        uint32_t *val, criteria, inum;
+
+        current = (struct task_struct *)bpf_get_current_task();
+        inum = BPF_CORE_READ(current, nsproxy, pid_ns_for_children, ns.inum);
+
+        /* The zero key holds the criteria */
+        val = bpf_map_lookup_elem(map, &key);
+        if (!val)
+                return inum == (uint32_t)PROC_PID_INIT_INO;
+
+        criteria = *val;
+        if (criteria & (1 << BPFLOCK_TRUST_PIDNS))
+                key.pidns = inum;
+
+        if (criteria & (1 << BPFLOCK_TRUST_MNTNS))
+                key.mntns = BPF_CORE_READ(current, nsproxy, mnt_ns, ns.inum);
+
+        if (criteria & (1 << BPFLOCK_TRUST_USERNS))
+                key.userns = BPF_CORE_READ(current, cred, user_ns, ns.inum);
+
+        if (criteria & (1 << BPFLOCK_TRUST_CGROUPNS))
+                key.cgroupns = BPF_CORE_READ(current, nsproxy, cgroup_ns, ns.inum);
+
+        return bpf_map_lookup_elem(map, &key) != NULL;
+}
+
+/*
+ * Declares the user and group rules map of a bpf program, rules are set in
+ * order by the bpflock agent.
+ */
+#define BPFLOCK_ID_RULES_MAP(name)                              \
+struct {                                                        \
+        __uint(type, BPF_MAP_TYPE_ARRAY);                       \
+        __uint(max_entries, BPFLOCK_ID_MAX_RULES);              \
+        __type(key, uint32_t);                                  \
+        __type(value, struct bl_id_rule);                       \
+} name SEC(".maps")
+
+static __always_inline bool id_in_rule(struct bl_id_rule *rule, uint32_t id)
+{
+        return id >= rule->min && id <= rule->max;
+}
+
+/*
+ * Reports the rule that made current untrusted before the decision message,
+ * it is attached to the event by the bpflock agent.
+ */
+static __always_inline void report_rule(const char *prog, const char *rule)
+{
+        uint32_t pid = bpf_get_current_pid_tgid() >> 32;
+
+        bpf_printk("bpflock bpf=%s pid=%lu rule=%s\n", prog, pid, rule);
+}
+
+/*
+ * Returns true if the user and group rules trust current. A deny rule
+ * matches if the real or effective id is in its range, an allow rule if
+ * both are, so setuid executables do not escape the rules. If allow rules
+ * are set current must match one of them.
+ */
+static __always_inline bool is_trusted_id(void *map, const char *prog)
+{
+        struct task_struct *current;
+        struct bl_id_rule *rule;
+        uint32_t uid, euid, gid, egid, i, k;
+        bool allowing = false, allowed = false;
+
+        current = (struct task_struct *)bpf_get_current_task();
+        uid = BPF_CORE_READ(current, cred, uid.val);
+        euid = BPF_CORE_READ(current, cred, euid.val);
+        gid = BPF_CORE_READ(current, cred, gid.val);
+        egid = BPF_CORE_READ(current, cred, egid.val);
+
+        for (i = 0; i < BPFLOCK_ID_MAX_RULES; i++) {
+                k = i;
+                rule = bpf_map_lookup_elem(map, &k);
+                if (!rule || rule->kind == BPFLOCK_ID_NONE)
+                        break;
+
+                switch (rule->kind) {
+                case BPFLOCK_ID_DENY_UID:
+                        if (id_in_rule(rule, uid) || id_in_rule(rule, euid)) {
+                                report_rule(prog, rule->name);
+                                return false;
+                        }
+                        break;
+                case BPFLOCK_ID_DENY_GID:
+                        if (id_in_rule(rule, gid) || id_in_rule(rule, egid)) {
+                                report_rule(prog, rule->name);
+                                return false;
+                        }
+                        break;
+                case BPFLOCK_ID_ALLOW_UID:
+                        allowing = true;
+                        if (id_in_rule(rule, uid) && id_in_rule(rule, euid))
+                                allowed = true;
+                        break;
+                case BPFLOCK_ID_ALLOW_GID:
+                        allowing = true;
+                        if (id_in_rule(rule, gid) && id_in_rule(rule, egid))
+                                allowed = true;
+                        break;
+                }
+        }
+
+        if (allowing && !allowed) {
+                report_rule(prog, "no allow rule");
+                return false;
+        }
+
+        return true;
+}
+
+/*
+ * Returns true if current is trusted under baseline: it must satisfy the
+ * namespace trust criteria and the user and group rules of the program.
+ */
+static __always_inline bool is_trusted(void *trust_map, void *ids_map,
+                                       const char *prog)
+{
+        return is_trusted_ns(trust_map) && is_trusted_id(ids_map, prog);
+}
+
+#endif /* __BPFLOCK_TRUST_H */
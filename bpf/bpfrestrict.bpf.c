@@ -11,6 +11,7 @@
 #include <errno.h>
 #include "bpflock_bpf_defs.h"
 #include "bpflock_shared_defs.h"
+#include "bpflock_trust.h"
 #include "bpfrestrict.h"
 
 #define DBPF_PROGRAMS 2
@@ -30,6 +31,18 @@ struct {
         __type(value, struct bl_stat);
 } bpfrestrict_ns_map SEC(".maps");
 
+/*
+ * Exempted executables that are trusted as if they were in the initial pid
+ * namespace under baseline. Keys are set by the bpflock agent, st_dev is
+ * the kernel encoded device number.
+ */
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, BPFLOCK_BPF_MAX_EXEMPT);
+        __type(key, struct bl_stat);
+        __type(value, uint32_t);
+} bpfrestrict_exempt_map SEC(".maps");
+
 struct {
         __uint(type, BPF_MAP_TYPE_RINGBUF);
         __uint(max_entries, 1 << 24);
@@ -37,16 +50,8 @@ struct {
 
 int pinned_bpf = 0;
 
-static __always_inline bool is_init_pid_ns(void)
-{
-        struct task_struct *current;
-        unsigned long id = 0;
-
-        current = (struct task_struct *)bpf_get_current_task();
-        id = BPF_CORE_READ(current, nsproxy, pid_ns_for_children, ns.inum);
-
-        return id == (unsigned long)PROC_PID_INIT_INO;
-}
+BPFLOCK_TRUST_MAP(bpfrestrict_trust_map);
+BPFLOCK_ID_RULES_MAP(bpfrestrict_ids_map);
 
 static __always_inline bool is_init_mnt_ns(void)
 {
@@ -73,6 +78,24 @@ static __always_inline bool is_init_mnt_ns(void)
         return ino == (unsigned long)PROC_DYNAMIC_FIRST && ino == st->st_ino;
 }
 
+/* Returns true if the executable of current is exempted */
+static __always_inline bool is_exempt(void)
+{
+        struct task_struct *current;
+        struct inode *inode;
+        struct bl_stat st = {};
+
+        current = (struct task_struct *)bpf_get_current_task();
+        inode = BPF_CORE_READ(current, mm, exe_file, f_inode);
+        if (!inode)
+                return false;
+
+        st.st_dev = BPF_CORE_READ(inode, i_sb, s_dev);
+        st.st_ino = BPF_CORE_READ(inode, i_ino);
+
+        return bpf_map_lookup_elem(&bpfrestrict_exempt_map, &st) != NULL;
+}
+
 static __always_inline int report(const char *op, const int ret, int reason)
 {
         uint64_t id;
@@ -113,8 +136,8 @@ int BPF_PROG(bpfrestrict, int cmd, union bpf_attr *attr,
                 if (blocked == BPFLOCK_P_ALLOW)
                         return report("bpf()", 0, reason_allow);
 
-                /* If baseline and not in init pid namespace deny access */
-                if (blocked == BPFLOCK_P_BASELINE && !is_init_pid_ns())
+                /* If baseline and not in init pid namespace deny access unless exempted */
+                if (blocked == BPFLOCK_P_BASELINE && !is_trusted(&bpfrestrict_trust_map, &bpfrestrict_ids_map, "bpfrestrict") && !is_exempt())
                         return report("bpf() from non init pid namespace", -EPERM, reason_baseline);
 
                 k = BPFLOCK_BPF_OP;
@@ -180,8 +203,8 @@ int BPF_PROG(bpfrestrict_bpf_write, enum lockdown_reason what, int ret)
         if (blocked == BPFLOCK_P_ALLOW)
                 return report("bpf() write user", 0, reason_allow);
 
-        /* If restrict and not in init pid namespace, then deny access */
-        if (blocked == BPFLOCK_P_BASELINE && !is_init_pid_ns())
+        /* If restrict and not in init pid namespace, then deny access unless exempted */
+        if (blocked == BPFLOCK_P_BASELINE && !is_trusted(&bpfrestrict_trust_map, &bpfrestrict_ids_map, "bpfrestrict") && !is_exempt())
                 return report("bpf() write user from non init pid namespace", -EPERM, reason_baseline);
 
         k = BPFLOCK_BPF_OP;
@@ -35,7 +35,7 @@ const char *argp_program_bug_address =
 const char argp_program_doc[] =
 "bpflock bpfrestrict - restrict access to BPF system call.\n"
 "\n"
-"USAGE: bpfrestrict [--help] [-p PROFILE] [-b CMD]\n"
+"USAGE: bpfrestrict [--help] [-p PROFILE] [-b CMD] [-e PATHS]\n"
 "\n"
 "EXAMPLES:\n"
 "  # Allow profile: BPF is allowed.\n"
@@ -46,12 +46,16 @@ const char argp_program_doc[] =
 "  # Baseline profile: restrict BPF to tasks in initial pid namespace and\n"
 "  # block the BPF load program command.\n"
 "  bpfrestrict --profile=baseline --block=prog_load\n\n"
+"  # Baseline profile: allow a monitoring agent in a container to use BPF.\n"
+"  bpfrestrict --profile=baseline --exempt=/usr/bin/monitoring-agent\n\n"
 "  # Restricted profile: deny BPF system call for all.\n"
 "  bpfrestrict --profile=restricted\n";
 
 static const struct argp_option opts[] = {
         { "profile", 'p', "PROFILE", 0, "Profile to apply, one of the following: allow, baseline or restricted. Default value is: allow." },
         { "block", 'b', "CMD", 0, "Block BPF commands, possible values: 'map_create, prog_load, btf_load, bpf_write' " },
+        { "exempt", 'e', "PATHS", 0, "Comma-separated list of executables that are trusted as tasks of the initial pid namespace in baseline profile, they are loaded by the bpflock agent." },
+        BPFLOCK_TRUST_ARGP_OPTIONS,
         { NULL, 'h', NULL, OPTION_HIDDEN, "Show the full help" },
         {},
 };
@@ -62,6 +66,9 @@ static error_t parse_arg(int key, char *arg, struct argp_state *state)
         case 'h':
                 argp_state_help(state, stderr, ARGP_HELP_STD_HELP);
                 break;
+        case 'e':
+                /* Exempted executables are resolved by the bpflock agent */
+                break;
         case 'b':
                 if (strlen(arg) + 1 > 128) {
                         fprintf(stderr, "invaild -b|--block argument: too long\n");
@@ -76,6 +83,14 @@ static error_t parse_arg(int key, char *arg, struct argp_state *state)
                 }
                 opt.perm = strndup(arg, strlen(arg));
                 break;
+        case BPFLOCK_TRUST_OPT:
+        case BPFLOCK_TRUST_NS_OPT:
+        case BPFLOCK_ALLOW_UID_OPT:
+        case BPFLOCK_ALLOW_GID_OPT:
+        case BPFLOCK_DENY_UID_OPT:
+        case BPFLOCK_DENY_GID_OPT:
+                /* Trust criteria are loaded by the bpflock agent */
+                break;
         default:
                 return ARGP_ERR_UNKNOWN;
         }
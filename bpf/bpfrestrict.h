@@ -19,6 +19,9 @@
 #define BPFLOCK_PROG_LOAD       (1 << 2)
 #define BPFLOCK_BPF_WRITE       (1 << 8)
 
+/* Maximum number of exempted executables */
+#define BPFLOCK_BPF_MAX_EXEMPT  256
+
 #define BPFRESTRICT_NS_MAP_PIN      "/sys/fs/bpf/bpflock/bpfrestrict_ns_map"
 
 struct bpflock_class_map bpf_security_map = {
@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ *
+ * Restricts execution of memory-backed and unlinked files.
+ */
+
+/*
+   To test it:
+        1. python3 -c 'import os; fd = os.memfd_create("x"); \
+                os.write(fd, open("/bin/true", "rb").read()); \
+                os.execv("/proc/self/fd/%d" % fd, ["x"])'
+                PermissionError: [Errno 1] Operation not permitted
+*/
+
+#include <vmlinux.h>
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_tracing.h>
+#include <bpf/bpf_core_read.h>
+#include <errno.h>
+#include "bpflock_bpf_defs.h"
+#include "bpflock_shared_defs.h"
+#include "bpflock_trust.h"
+#include "execlock.h"
+
+#define MEMFD_PREFIX    "memfd:"
+
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, 4);
+        __type(key, uint32_t);
+        __type(value, uint32_t);
+} execlock_map SEC(".maps");
+
+/*
+ * Exempted executables that may execute memory-backed files, like
+ * container runtimes that execute a sealed memfd copy of themselves.
+ * Keys are set by the bpflock agent, st_dev is the kernel encoded device
+ * number.
+ */
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, BPFLOCK_EL_MAX_EXEMPT);
+        __type(key, struct bl_stat);
+        __type(value, uint32_t);
+} execlock_exempt_map SEC(".maps");
+
+BPFLOCK_TRUST_MAP(execlock_trust_map);
+BPFLOCK_ID_RULES_MAP(execlock_ids_map);
+
+static __always_inline int report(const char *op, const int ret, int reason)
+{
+        uint64_t id;
+        static struct event info;
+
+        id = bpf_get_current_pid_tgid();
+        info.pid = id >> 32;
+
+        bpf_get_current_comm(&info.comm, sizeof(info.comm));
+
+        bpf_printk("bpflock bpf=execlock pid=%lu comm=%s event=%s\n",
+                   info.pid, info.comm, op);
+        bpf_printk("bpflock bpf=execlock pid=%lu event=%s status=%s\n",
+                   info.pid, op, get_reason_str(ret, reason));
+
+        return ret;
+}
+
+/* Returns true if the executable of current is exempted */
+static __always_inline bool is_exempt(void)
+{
+        struct task_struct *current;
+        struct inode *inode;
+        struct bl_stat st = {};
+
+        current = (struct task_struct *)bpf_get_current_task();
+        inode = BPF_CORE_READ(current, mm, exe_file, f_inode);
+        if (!inode)
+                return false;
+
+        st.st_dev = BPF_CORE_READ(inode, i_sb, s_dev);
+        st.st_ino = BPF_CORE_READ(inode, i_ino);
+
+        return bpf_map_lookup_elem(&execlock_exempt_map, &st) != NULL;
+}
+
+static __always_inline bool is_memfd(struct file *file)
+{
+        const unsigned char *dname;
+        char name[sizeof(MEMFD_PREFIX)] = {};
+        int i;
+
+        dname = BPF_CORE_READ(file, f_path.dentry, d_name.name);
+        bpf_probe_read_kernel_str(name, sizeof(name), dname);
+
+        for (i = 0; i < sizeof(MEMFD_PREFIX) - 1; i++) {
+                if (name[i] != MEMFD_PREFIX[i])
+                        return false;
+        }
+
+        return true;
+}
+
+SEC("lsm/bprm_check_security")
+int BPF_PROG(execlock_bprm_check, struct linux_binprm *bprm, int ret)
+{
+        struct file *file;
+        const char *op;
+        uint32_t perm, k = BPFLOCK_EL_PERM, *val;
+
+        if (ret != 0)
+                return ret;
+
+        /* Only files without a name on any filesystem are checked */
+        file = BPF_CORE_READ(bprm, file);
+        if (!file || BPF_CORE_READ(file, f_inode, i_nlink) != 0)
+                return 0;
+
+        op = is_memfd(file) ? "exec of memfd file" : "exec of unlinked file";
+
+        val = bpf_map_lookup_elem(&execlock_map, &k);
+        perm = val ? *val : 0;
+        if (perm == 0 || perm == BPFLOCK_P_ALLOW)
+                return report(op, 0, reason_allow);
+
+        if (is_exempt())
+                return report(op, 0, reason_baseline_allowed);
+
+        if (perm == BPFLOCK_P_RESTRICTED)
+                return report(op, -EPERM, reason_restricted);
+
+        /* Baseline: the host namespace is exempt */
+        if (!is_trusted(&execlock_trust_map, &execlock_ids_map, LOG_EXECLOCK))
+                return report(op, -EPERM, reason_baseline);
+
+        return report(op, 0, reason_baseline);
+}
+
+char _license[] SEC("license") = "GPL";
@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ */
+
+#ifndef __BPFLOCK_EXECLOCK_H
+#define __BPFLOCK_EXECLOCK_H
+
+#include "bpflock_security_class.h"
+
+/* execlock security class */
+
+#define LOG_EXECLOCK "execlock"
+
+#define BPFLOCK_EL_PERM         1
+
+/* Maximum number of exempted executables */
+#define BPFLOCK_EL_MAX_EXEMPT   256
+
+struct bpflock_class_map exec_security_map = {
+        "exec",
+        "/sys/fs/bpf/bpflock/execlock",
+        { NULL },
+        { 0 }
+};
+
+struct bpflock_class_prog_link exec_prog_links[] = {
+        {
+                "bpflock_execlock_bprm_check",
+                "/sys/fs/bpf/bpflock/execlock/execlock_bprm_check_link",
+        },
+};
+
+/* End of execlock security class */
+
+#endif /* __BPFLOCK_EXECLOCK_H */
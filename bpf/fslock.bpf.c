@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ *
+ * Restricts opening files to allowed filesystems.
+ *
+ * Reference work: https://github.com/systemd/systemd/pull/18145
+ */
+
+#include <vmlinux.h>
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_tracing.h>
+#include <bpf/bpf_core_read.h>
+#include <errno.h>
+#include "bpflock_bpf_defs.h"
+#include "bpflock_shared_defs.h"
+#include "bpflock_trust.h"
+#include "fslock.h"
+
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, 4);
+        __type(key, uint32_t);
+        __type(value, uint32_t);
+} fslock_map SEC(".maps");
+
+/*
+ * Allowed filesystems: keys are superblock magics, they are set by the
+ * bpflock agent from the configured filesystem names.
+ */
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, BPFLOCK_FS_MAX_MAGICS);
+        __type(key, uint64_t);
+        __type(value, uint32_t);
+} fslock_magics_map SEC(".maps");
+
+BPFLOCK_TRUST_MAP(fslock_trust_map);
+BPFLOCK_ID_RULES_MAP(fslock_ids_map);
+
+static __always_inline int report(uint64_t magic, const int ret, int reason)
+{
+        uint64_t id;
+        static struct event info;
+
+        id = bpf_get_current_pid_tgid();
+        info.pid = id >> 32;
+
+        bpf_get_current_comm(&info.comm, sizeof(info.comm));
+
+        bpf_printk("bpflock bpf=fslock pid=%lu comm=%s event=file open magic=%lx\n",
+                   info.pid, info.comm, magic);
+        bpf_printk("bpflock bpf=fslock pid=%lu event=file open status=%s\n",
+                   info.pid, get_reason_str(ret, reason));
+
+        return ret;
+}
+
+static __always_inline uint32_t lookup_key(uint32_t k)
+{
+        uint32_t *val;
+
+        val = bpf_map_lookup_elem(&fslock_map, &k);
+        if (!val)
+                return 0;
+
+        return *val;
+}
+
+SEC("lsm/file_open")
+int BPF_PROG(fslock_file_open, struct file *file, int ret)
+{
+        uint64_t magic = 0;
+        uint32_t perm;
+
+        if (ret != 0)
+                return ret;
+
+        /*
+         * file_open is a hot path: opens that are allowed are not
+         * reported, only denials are.
+         */
+        perm = lookup_key(BPFLOCK_FS_PERM);
+        if (perm == 0 || perm == BPFLOCK_P_ALLOW)
+                return 0;
+
+        /* Do not restrict anything until allowed filesystems are loaded */
+        if (!lookup_key(BPFLOCK_FS_READY))
+                return 0;
+
+        magic = BPF_CORE_READ(file, f_inode, i_sb, s_magic);
+        if (bpf_map_lookup_elem(&fslock_magics_map, &magic))
+                return 0;
+
+        if (perm == BPFLOCK_P_RESTRICTED)
+                return report(magic, -EPERM, reason_restricted);
+
+        /* Baseline: the host namespace is exempt */
+        if (is_trusted(&fslock_trust_map, &fslock_ids_map, LOG_FSLOCK))
+                return 0;
+
+        return report(magic, -EPERM, reason_baseline);
+}
+
+char _license[] SEC("license") = "GPL";
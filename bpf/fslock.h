@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ */
+
+#ifndef __BPFLOCK_FSLOCK_H
+#define __BPFLOCK_FSLOCK_H
+
+#include "bpflock_security_class.h"
+
+/* fslock security class */
+
+#define LOG_FSLOCK "fslock"
+
+#define BPFLOCK_FS_PERM         1
+/* Set by the bpflock agent once allowed filesystems are loaded */
+#define BPFLOCK_FS_READY        2
+
+/* Maximum number of allowed filesystem magics */
+#define BPFLOCK_FS_MAX_MAGICS   64
+
+struct bpflock_class_map fs_security_map = {
+        "fs",
+        "/sys/fs/bpf/bpflock/fslock",
+        { NULL },
+        { 0 }
+};
+
+struct bpflock_class_prog_link fs_prog_links[] = {
+        {
+                "bpflock_fslock_file_open",
+                "/sys/fs/bpf/bpflock/fslock/fslock_file_open_link",
+        },
+};
+
+/* End of fslock security class */
+
+#endif /* __BPFLOCK_FSLOCK_H */
@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2021 Djalal Harouni
+ */
+
+#include <vmlinux.h>
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_tracing.h>
+#include <bpf/bpf_core_read.h>
+#include <errno.h>
+#include "bpflock_bpf_defs.h"
+#include "bpflock_shared_defs.h"
+#include "bpflock_trust.h"
+#include "kimglock.h"
+
+/*
+ * The profile and the operations allowed under baseline, keys of allowed
+ * operations are BPFLOCK_KI_ALLOW_OP + the lockdown reason.
+ */
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, 64);
+        __type(key, uint32_t);
+        __type(value, uint32_t);
+} kimglock_map SEC(".maps");
+
+/*
+ * Exempted executables that are trusted as if they were in the initial pid
+ * namespace under baseline. Keys are set by the bpflock agent, st_dev is
+ * the kernel encoded device number.
+ */
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, BPFLOCK_KI_MAX_EXEMPT);
+        __type(key, struct bl_stat);
+        __type(value, uint32_t);
+} kimglock_exempt_map SEC(".maps");
+
+BPFLOCK_TRUST_MAP(kimglock_trust_map);
+BPFLOCK_ID_RULES_MAP(kimglock_ids_map);
+
+/* Returns true if the executable of current is exempted */
+static __always_inline bool is_exempt(void)
+{
+        struct task_struct *current;
+        struct inode *inode;
+        struct bl_stat st = {};
+
+        current = (struct task_struct *)bpf_get_current_task();
+        inode = BPF_CORE_READ(current, mm, exe_file, f_inode);
+        if (!inode)
+                return false;
+
+        st.st_dev = BPF_CORE_READ(inode, i_sb, s_dev);
+        st.st_ino = BPF_CORE_READ(inode, i_ino);
+
+        return bpf_map_lookup_elem(&kimglock_exempt_map, &st) != NULL;
+}
+
+static __always_inline int report(const char *op, const int ret, int reason)
+{
+        uint64_t id;
+        static struct event info;
+
+        id = bpf_get_current_pid_tgid();
+        info.pid = id >> 32;
+
+        bpf_get_current_comm(&info.comm, sizeof(info.comm));
+
+        bpf_printk("bpflock bpf=kimglock pid=%lu comm=%s event=%s\n",
+                   info.pid, info.comm, op);
+        bpf_printk("bpflock bpf=kimglock pid=%lu event=%s status=%s\n",
+                   info.pid, op, get_reason_str(ret, reason));
+
+        return ret;
+}
+
+static __always_inline bool is_allowed_op(uint32_t what)
+{
+        uint32_t k = BPFLOCK_KI_ALLOW_OP + what;
+        uint32_t *val;
+
+        val = bpf_map_lookup_elem(&kimglock_map, &k);
+
+        return val && *val > 0;
+}
+
+/* Checks an operation that modifies the running kernel image */
+static __always_inline int kimg_check(const char *op, uint32_t what)
+{
+        uint32_t *val, blocked = 0;
+        uint32_t k = BPFLOCK_KI_PERM;
+
+        val = bpf_map_lookup_elem(&kimglock_map, &k);
+        if (!val)
+                return 0;
+
+        blocked = *val;
+        if (blocked == BPFLOCK_P_RESTRICTED)
+                return report(op, -EPERM, reason_restricted);
+
+        if (blocked == BPFLOCK_P_ALLOW)
+                return report(op, 0, reason_allow);
+
+        if (is_trusted(&kimglock_trust_map, &kimglock_ids_map, LOG_KIMGLOCK))
+                return report(op, 0, reason_baseline);
+
+        if (is_allowed_op(what))
+                return report(op, 0, reason_baseline_allowed);
+
+        if (is_exempt())
+                return report(op, 0, reason_baseline_allowed);
+
+        /* If baseline and not in init pid namespace deny access unless exempted */
+        return report(op, -EPERM, reason_baseline_restricted);
+}
+
+SEC("lsm/locked_down")
+int BPF_PROG(kimglock_lock, enum lockdown_reason what, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        /* Only the integrity of the kernel image is protected */
+        if (what == LOCKDOWN_NONE || what >= LOCKDOWN_INTEGRITY_MAX)
+                return 0;
+
+        return kimg_check("kernel image lockdown", what);
+}
+
+SEC("lsm/bpf")
+int BPF_PROG(kimglock_bpf, int cmd, union bpf_attr *attr,
+             unsigned int size, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        if (cmd != BPF_BTF_LOAD)
+                return 0;
+
+        return kimg_check("kernel image btf load", LOCK_KIMG_BTF_LOAD);
+}
+
+static const char _license[] SEC("license") = "GPL";
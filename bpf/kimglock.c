@@ -0,0 +1,294 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2021 Djalal Harouni
+ */
+
+/*
+ * Implements restrictions on modifications of the running kernel image.
+ */
+
+#include <argp.h>
+#include <bpf/bpf.h>
+#include <errno.h>
+#include <string.h>
+#include <time.h>
+#include <sys/types.h>
+#include <sys/stat.h>
+#include <unistd.h>
+#include "bpflock_shared_defs.h"
+#include "trace_helpers.h"
+#include "bpflock_utils.h"
+#include "kimglock.h"
+#include "kimglock.skel.h"
+
+static struct options {
+        int perm_int;
+        char *perm;
+        char *allow_op;
+} opt = {};
+
+/* Operations that can be allowed under baseline */
+static const struct {
+        const char *name;
+        uint32_t what;
+} kimg_ops[] = {
+        { "unsigned_module", LOCK_KIMG_MODULE_SIGNATURE },
+        { "unsafe_module_parameters", LOCK_KIMG_MODULE_PARAMETERS },
+        { "dev_mem", LOCK_KIMG_DEV_MEM },
+        { "kexec", LOCK_KIMG_KEXEC },
+        { "hibernation", LOCK_KIMG_HIBERNATION },
+        { "pci_access", LOCK_KIMG_PCI_ACCESS },
+        { "ioport", LOCK_KIMG_IOPORT },
+        { "msr", LOCK_KIMG_MSR },
+        { "mmiotrace", LOCK_KIMG_MMIOTRACE },
+        { "debugfs", LOCK_KIMG_DEBUGFS },
+        { "xmon_rw", LOCK_KIMG_XMON_WR },
+        { "bpf_write", LOCK_KIMG_BPF_WRITE_USER },
+        { "btf_load", LOCK_KIMG_BTF_LOAD },
+};
+
+const char *argp_program_version = "kimglock 0.1";
+const char *argp_program_bug_address =
+        "https://github.com/linux-lock/bpflock";
+const char argp_program_doc[] =
+"bpflock kimglock - restrict modifications of the running kernel image.\n"
+"\n"
+"USAGE: kimglock [--help] [-p PROFILE] [-a OPS] [-e PATHS]\n"
+"\n"
+"EXAMPLES:\n"
+"  # Allow profile: kernel image access is allowed.\n"
+"  kimglock --profile=allow\n\n"
+"  # Baseline profile: kernel image access is allowed only for tasks in initial pid namespace.\n"
+"  kimglock --profile=baseline\n\n"
+"  # Baseline profile: allow all tasks to access debugfs and raw I/O ports.\n"
+"  kimglock --profile=baseline --allow=debugfs,ioport\n\n"
+"  # Baseline profile: allow a kexec helper of containers to load kernel images.\n"
+"  kimglock --profile=baseline --exempt=/usr/libexec/helper-kexec\n\n"
+"  # Restricted profile: deny kernel image access for all.\n"
+"  kimglock --profile=restricted\n";
+
+static const struct argp_option opts[] = {
+        { "profile", 'p', "PROFILE", 0, "Profile to apply, one of the following: allow, baseline or restricted. Default value is: allow." },
+        { "allow", 'a', "OPS", 0, "Allow operations in baseline profile, possible values: 'unsigned_module, unsafe_module_parameters, dev_mem, kexec, hibernation, pci_access, ioport, msr, mmiotrace, debugfs, xmon_rw, bpf_write, btf_load' " },
+        { "exempt", 'e', "PATHS", 0, "Comma-separated list of executables that are trusted as tasks of the initial pid namespace in baseline profile, they are loaded by the bpflock agent." },
+        BPFLOCK_TRUST_ARGP_OPTIONS,
+        { NULL, 'h', NULL, OPTION_HIDDEN, "Show the full help" },
+        {},
+};
+
+static error_t parse_arg(int key, char *arg, struct argp_state *state)
+{
+        switch (key) {
+        case 'h':
+                argp_state_help(state, stderr, ARGP_HELP_STD_HELP);
+                break;
+        case 'e':
+                /* Exempted executables are resolved by the bpflock agent */
+                break;
+        case 'a':
+                if (strlen(arg) + 1 > 256) {
+                        fprintf(stderr, "invaild -a|--allow argument: too long\n");
+                        argp_usage(state);
+                }
+                opt.allow_op = strndup(arg, strlen(arg));
+                break;
+        case 'p':
+                if (strlen(arg) + 1 > 64) {
+                        fprintf(stderr, "invaild -p|--profile argument: too long\n");
+                        argp_usage(state);
+                }
+                opt.perm = strndup(arg, strlen(arg));
+                break;
+        case BPFLOCK_TRUST_OPT:
+        case BPFLOCK_TRUST_NS_OPT:
+        case BPFLOCK_ALLOW_UID_OPT:
+        case BPFLOCK_ALLOW_GID_OPT:
+        case BPFLOCK_DENY_UID_OPT:
+        case BPFLOCK_DENY_GID_OPT:
+                /* Trust criteria are loaded by the bpflock agent */
+                break;
+        default:
+                return ARGP_ERR_UNKNOWN;
+        }
+
+        return 0;
+}
+
+/* Setup bpf map options */
+static int setup_kimg_opt_map(struct kimglock_bpf *skel, int *fd)
+{
+        uint32_t perm_k = BPFLOCK_KI_PERM;
+        uint32_t allowed = 1, k;
+        int f, i;
+
+        opt.perm_int = 0;
+
+        f = bpf_map__fd(skel->maps.kimglock_map);
+        if (f < 0) {
+                fprintf(stderr, "%s: error: failed to get bpf map fd: %d\n",
+                        LOG_BPFLOCK, f);
+                return f;
+        }
+
+        if (!opt.perm) {
+                opt.perm_int = BPFLOCK_P_ALLOW;
+        } else {
+                if (strncmp(opt.perm, "restricted", 10) == 0) {
+                        opt.perm_int = BPFLOCK_P_RESTRICTED;
+                } else if (strncmp(opt.perm, "baseline", 8) == 0) {
+                        opt.perm_int = BPFLOCK_P_BASELINE;
+                } else {
+                        opt.perm_int = BPFLOCK_P_ALLOW;
+                }
+        }
+
+        *fd = f;
+
+        bpf_map_update_elem(f, &perm_k, &opt.perm_int, BPF_ANY);
+
+        if (!opt.allow_op)
+                return 0;
+
+        for (i = 0; i < sizeof(kimg_ops) / sizeof(kimg_ops[0]); i++) {
+                if (strstr(opt.allow_op, kimg_ops[i].name) == NULL)
+                        continue;
+
+                k = BPFLOCK_KI_ALLOW_OP + kimg_ops[i].what;
+                bpf_map_update_elem(f, &k, &allowed, BPF_ANY);
+        }
+
+        return 0;
+}
+
+int main(int argc, char **argv)
+{
+        static const struct argp argp = {
+                .options = opts,
+                .parser = parse_arg,
+                .doc = argp_program_doc,
+        };
+
+        struct kimglock_bpf *skel = NULL;
+        struct bpf_link *link = NULL;
+        struct bpf_program *prog = NULL;
+        int kimglock_map_fd = -1;
+        struct stat st;
+        char *buf = NULL;
+        int err, i, buflen = 512;
+
+        err = argp_parse(&argp, argc, argv, 0, NULL, NULL);
+        if (err)
+                return err;
+
+        err = is_lsmbpf_supported();
+        if (err) {
+                fprintf(stderr, "%s: error: failed to check LSM BPF support\n",
+                        LOG_BPFLOCK);
+                return err;
+        }
+
+        err = bump_memlock_rlimit();
+        if (err) {
+                fprintf(stderr, "%s: error: failed to increase rlimit: %s\n",
+                        LOG_BPFLOCK, strerror(errno));
+                return err;
+        }
+
+        err = stat(kimg_security_map.pin_path, &st);
+        if (err == 0) {
+                fprintf(stdout, "%s: %s already loaded nothing todo, please delete pinned directory '%s' "
+                        "to be able to run it again.\n",
+                        LOG_BPFLOCK, argv[0], kimg_security_map.pin_path);
+                return -EALREADY;
+        }
+
+        buf = malloc(buflen);
+        if (!buf) {
+                fprintf(stderr, "%s: error: failed to allocate memory\n",
+                        LOG_BPFLOCK);
+                return -ENOMEM;
+        }
+
+        memset(buf, 0, buflen);
+
+        skel = kimglock_bpf__open();
+        if (!skel) {
+                fprintf(stderr, "%s: error: failed to open BPF skelect\n",
+                        LOG_BPFLOCK);
+                err = -EINVAL;
+                goto cleanup;
+        }
+
+        err = kimglock_bpf__load(skel);
+        if (err) {
+                fprintf(stderr, "%s: error: failed to load BPF skelect: %d\n",
+                        LOG_BPFLOCK, err);
+                goto cleanup;
+        }
+
+        err = setup_kimg_opt_map(skel, &kimglock_map_fd);
+        if (err < 0) {
+                fprintf(stderr, "%s: error: failed to setup bpf opt map: %d\n",
+                        LOG_BPFLOCK, err);
+                goto cleanup;
+        }
+
+        mkdir(BPFLOCK_PIN_PATH, 0700);
+        mkdir(kimg_security_map.pin_path, 0700);
+
+        err = bpf_object__pin(skel->obj, kimg_security_map.pin_path);
+        if (err) {
+                libbpf_strerror(err, buf, buflen);
+                fprintf(stderr, "%s: %s: error: failed to pin obj into '%s': %s\n",
+                        LOG_BPFLOCK, LOG_KIMGLOCK, kimg_security_map.pin_path, buf);
+                goto cleanup;
+        }
+
+        i = 0;
+        bpf_object__for_each_program(prog, skel->obj) {
+                if (i >= sizeof(bpf_prog_links) / sizeof(bpflock_class_prog_link_t))
+                        break;
+
+                link = bpf_program__attach(prog);
+                err = libbpf_get_error(link);
+                if (err) {
+                        libbpf_strerror(err, buf, buflen);
+                        fprintf(stderr, "%s: %s: error: failed to attach BPF programs: %s\n",
+                                LOG_BPFLOCK, LOG_KIMGLOCK, strerror(-err));
+                        goto cleanup;
+                }
+
+                err = bpf_link__pin(link, bpf_prog_links[i].link);
+                if (err) {
+                        libbpf_strerror(err, buf, buflen);
+                        fprintf(stderr, "%s: %s: error: failed to pin bpf obj into link '%s': %s\n",
+                                LOG_BPFLOCK, LOG_KIMGLOCK, bpf_prog_links[i].link, buf);
+                        goto cleanup;
+                }
+
+                i++;
+        }
+
+        if (opt.perm_int == BPFLOCK_P_RESTRICTED) {
+                printf("%s: success: profile: restricted - kernel image modifications are now disabled - delete pinned directory '%s' to re-enable\n",
+                        LOG_BPFLOCK, kimg_security_map.pin_path);
+        } else if (opt.perm_int == BPFLOCK_P_BASELINE) {
+                printf("%s: success: profile: baseline - kernel image modifications are now restricted only to initial pid namespace - delete pinned directory '%s' to re-enable\n",
+                        LOG_BPFLOCK, kimg_security_map.pin_path);
+        } else {
+                printf("%s: success: profile: allow - kernel image modifications are allowed - delete pinned directory '%s' to disable access logging\n",
+                        LOG_BPFLOCK, kimg_security_map.pin_path);
+        }
+
+cleanup:
+        if (link)
+                bpf_link__destroy(link);
+
+        if (skel)
+                kimglock_bpf__destroy(skel);
+
+        free(buf);
+
+        return err != 0;
+}
@@ -17,6 +17,9 @@
 #define BPFLOCK_KI_PERM        1
 #define BPFLOCK_KI_ALLOW_OP    256
 
+/* Maximum number of exempted executables */
+#define BPFLOCK_KI_MAX_EXEMPT  256
+
 #define FOREACH_KIMGREASON(KIMGREASON) \
         KIMGREASON(LOCK_KIMG_NONE)      \
         KIMGREASON(LOCK_KIMG_MODULE_SIGNATURE)  \
@@ -21,6 +21,8 @@
 #include <errno.h>
 #include "bpflock_bpf_defs.h"
 #include "bpflock_shared_defs.h"
+#include "bpflock_trust.h"
+#include "bpflock_exception.h"
 #include "kmodlock.h"
 
 struct {
@@ -44,16 +46,32 @@ struct {
         __type(value, struct sb_elem);
 } disablemods_sb_map SEC(".maps");
 
-static __always_inline bool is_init_pid_ns(void)
-{
-        struct task_struct *current;
-        unsigned long id = 0;
-        
-        current = (struct task_struct *)bpf_get_current_task();
-        id = BPF_CORE_READ(current, nsproxy, pid_ns_for_children, ns.inum);
+/*
+ * Module names and autoload requests, keys are set by the bpflock agent
+ * and values are BPFLOCK_KM_NAME_ALLOW or BPFLOCK_KM_NAME_DENY.
+ */
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, BPFLOCK_KM_MAX_NAMES);
+        __type(key, char[BPFLOCK_KM_NAME_LEN]);
+        __type(value, uint32_t);
+} kmodlock_names_map SEC(".maps");
 
-        return id == (unsigned long)PROC_PID_INIT_INO;
-}
+/*
+ * Exempted executables that are trusted as if they were in the initial pid
+ * namespace under baseline. Keys are set by the bpflock agent, st_dev is
+ * the kernel encoded device number.
+ */
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, BPFLOCK_KM_MAX_EXEMPT);
+        __type(key, struct bl_stat);
+        __type(value, uint32_t);
+} kmodlock_exempt_map SEC(".maps");
+
+BPFLOCK_TRUST_MAP(kmodlock_trust_map);
+BPFLOCK_ID_RULES_MAP(kmodlock_ids_map);
+BPFLOCK_EXCEPTION_MAP(kmodlock_exceptions_map);
 
 static __always_inline bool is_init_mnt_ns(void)
 {
@@ -80,6 +98,24 @@ static __always_inline bool is_init_mnt_ns(void)
         return ino == (unsigned long)PROC_DYNAMIC_FIRST && ino == st->st_ino;
 }
 
+/* Returns true if the executable of current is exempted */
+static __always_inline bool is_exempt(void)
+{
+        struct task_struct *current;
+        struct inode *inode;
+        struct bl_stat st = {};
+
+        current = (struct task_struct *)bpf_get_current_task();
+        inode = BPF_CORE_READ(current, mm, exe_file, f_inode);
+        if (!inode)
+                return false;
+
+        st.st_dev = BPF_CORE_READ(inode, i_sb, s_dev);
+        st.st_ino = BPF_CORE_READ(inode, i_ino);
+
+        return bpf_map_lookup_elem(&kmodlock_exempt_map, &st) != NULL;
+}
+
 static __always_inline int report(const char *op, const int ret, int reason)
 {
         uint64_t id;
@@ -157,9 +193,51 @@ static __always_inline int module_rootfs_check(struct file *file,
         return 0;
 }
 
-static __always_inline int module_load_check(int blocked_op)
+static __always_inline uint32_t lookup_name(const char *name)
+{
+        uint32_t *val;
+
+        if (!name)
+                return 0;
+
+        val = bpf_map_lookup_elem(&kmodlock_names_map, name);
+        if (!val)
+                return 0;
+
+        return *val;
+}
+
+/*
+ * Sets name to the module name of a module file: the file name without
+ * extensions and with dashes replaced by underscores, as modprobe does.
+ * name must be zeroed.
+ */
+static __always_inline void module_file_name(struct file *file, char *name)
 {
-        uint32_t *val, blocked = 0;
+        const unsigned char *dname;
+        bool cut = false;
+        int i;
+
+        dname = BPF_CORE_READ(file, f_path.dentry, d_name.name);
+        bpf_probe_read_kernel_str(name, BPFLOCK_KM_NAME_LEN, dname);
+
+        for (i = 0; i < BPFLOCK_KM_NAME_LEN; i++) {
+                if (cut || name[i] == '.') {
+                        cut = true;
+                        name[i] = 0;
+                } else if (name[i] == '-') {
+                        name[i] = '_';
+                }
+        }
+}
+
+/*
+ * Checks a module operation, name is the module name or the autoload
+ * request, NULL if unknown.
+ */
+static __always_inline int module_load_check(const char *name, int blocked_op)
+{
+        uint32_t *val, blocked = 0, rule;
         uint32_t k = BPFLOCK_KM_PERM;
 
         val = bpf_map_lookup_elem(&disablemods_map, &k);
@@ -173,9 +251,20 @@ static __always_inline int module_load_check(int blocked_op)
         if (blocked == BPFLOCK_P_ALLOW)
                 return report("module load", 0, reason_allow);
 
-        /* If restrict and not in init pid namespace deny access */
-        if (blocked == BPFLOCK_P_BASELINE && !is_init_pid_ns())
+        rule = lookup_name(name);
+        if (blocked == BPFLOCK_P_BASELINE && rule == BPFLOCK_KM_NAME_DENY)
+                return report("module load of denied module", -EPERM, reason_baseline_restricted);
+
+        /* If restrict and not in init pid namespace deny access unless allowed */
+        if (blocked == BPFLOCK_P_BASELINE && !is_trusted(&kmodlock_trust_map, &kmodlock_ids_map, LOG_KMODLOCK)) {
+                if (rule == BPFLOCK_KM_NAME_ALLOW)
+                        return report("module load of allowed module from non init pid namespace", 0, reason_baseline_allowed);
+                if (is_exempt())
+                        return report("module load from exempted executable", 0, reason_baseline_allowed);
+                if (is_excepted(&kmodlock_exceptions_map, blocked_op))
+                        return report("module load under temporary exception", 0, reason_baseline_allowed);
                 return report("module load from non init pid namespace", -EPERM, reason_baseline);
+        }
 
         k = BPFLOCK_KM_OP;
         val = bpf_map_lookup_elem(&disablemods_map, &k);
@@ -183,8 +272,11 @@ static __always_inline int module_load_check(int blocked_op)
                 return report("module load", 0, reason_baseline_allowed);
 
         blocked = *val;
-        if (blocked & blocked_op)
+        if (blocked & blocked_op) {
+                if (is_excepted(&kmodlock_exceptions_map, blocked_op))
+                        return report("module load under temporary exception", 0, reason_baseline_allowed);
                 return report("module load", -EPERM, reason_baseline_restricted);
+        }
 
         return report("module load", 0, reason_baseline);
 }
@@ -225,21 +317,26 @@ int BPF_PROG(km_locked_down, enum lockdown_reason what, int ret)
         else
                 return 0;
 
-        return module_load_check(blocked_op);
+        return module_load_check(NULL, blocked_op);
 }
 
 SEC("lsm/kernel_module_request")
 int BPF_PROG(km_autoload, char *kmod_name, int ret)
 {
+        char name[BPFLOCK_KM_NAME_LEN] = {};
+
         if (ret != 0)
                 return ret;
 
-        return module_load_check(BPFLOCK_KM_AUTOLOAD);
+        bpf_probe_read_kernel_str(name, sizeof(name), kmod_name);
+
+        return module_load_check(name, BPFLOCK_KM_AUTOLOAD);
 }
 
 static int kmod_from_file(struct file *file,
                 enum kernel_read_file_id id, bool contents)
 {
+        char name[BPFLOCK_KM_NAME_LEN] = {};
         struct super_block *sb;
         uint32_t key = BPFLOCK_KM_SB;
         unsigned long sdev;
@@ -247,7 +344,10 @@ static int kmod_from_file(struct file *file,
 
         prepare_sb_elem();
 
-        ret = module_load_check(BPFLOCK_KM_LOAD);
+        if (file && id == READING_MODULE)
+                module_file_name(file, name);
+
+        ret = module_load_check(name[0] ? name : NULL, BPFLOCK_KM_LOAD);
         if (ret < 0)
                 return ret;
 
@@ -34,7 +34,7 @@ const char *argp_program_bug_address =
 const char argp_program_doc[] =
 "bpflock kmodlock - restrict kernel module load operations.\n"
 "\n"
-"USAGE: kmodlock [--help] [-p PROFILE] [-b CMD] [--rootfs] [--ro] [--ro-dev]\n"
+"USAGE: kmodlock [--help] [-p PROFILE] [-b CMD] [-a MODULES] [-x MODULES] [-e PATHS] [--rootfs] [--ro] [--ro-dev]\n"
 "\n"
 "EXAMPLES:\n"
 "  # Allow profile: kernel module operations are allowed.\n"
@@ -44,15 +44,23 @@ const char argp_program_doc[] =
 "  # Baseline profile: restrict kernel module operations to tasks in initial pid namespace and\n"
 "  # block loading of unsigned modules and other automatic module operations.\n"
 "  kmodlock --profile=baseline --block=autoload_module,unsigned_module\n\n"
+"  # Baseline profile: allow containers to load the ip_tables module and deny the ipip module for all.\n"
+"  kmodlock --profile=baseline --allow=ip_tables --deny=ipip\n\n"
+"  # Baseline profile: allow a modprobe helper of containers to load modules.\n"
+"  kmodlock --profile=baseline --exempt=/usr/libexec/helper-modprobe\n\n"
 "  # Restricted profile: deny loading kernel modules for all.\n"
 "  kmodlock ---profile=restricted\n";
 
 static const struct argp_option opts[] = {
         { "profile", 'p', "PROFILE", 0, "Profile to apply, one of the following: allow, baseline or restricted. Default value is: allow." },
         { "block", 'b', "CMD", 0, "Block module operations, possible values: 'load_module, unload_module, autoload_module, unsigned_module, unsafe_module_parameters' " },
+        { "allow", 'a', "MODULES", 0, "Comma-separated list of module names or autoload modalias patterns allowed in baseline profile for tasks that are not in the initial pid namespace, they are loaded by the bpflock agent." },
+        { "deny", 'x', "MODULES", 0, "Comma-separated list of module names or autoload modalias patterns denied in baseline profile, they are loaded by the bpflock agent." },
+        { "exempt", 'e', "PATHS", 0, "Comma-separated list of executables that are trusted as tasks of the initial pid namespace in baseline profile, they are loaded by the bpflock agent." },
         { "rootfs", 'f', NULL, 0, "Allow module operations only if the modules originate from the root filesystem."},
         { "ro", 'r', NULL, 0, "Allow module operations only if the root filesystem is mounted read-only"},
         { "ro-dev", 'd', NULL, 0, "Allow module operations only if the filesystem is backed by a read-only device."},
+        BPFLOCK_TRUST_ARGP_OPTIONS,
         { NULL, 'h', NULL, OPTION_HIDDEN, "Show the full help" },
         {},
 };
@@ -63,6 +71,13 @@ static error_t parse_arg(int key, char *arg, struct argp_state *state)
         case 'h':
                 argp_state_help(state, stderr, ARGP_HELP_STD_HELP);
                 break;
+        case 'a':
+        case 'x':
+                /* Module names are validated and loaded by the bpflock agent */
+                break;
+        case 'e':
+                /* Exempted executables are resolved by the bpflock agent */
+                break;
         case 'b':
                 if (strlen(arg) + 1 > 128) {
                         fprintf(stderr, "invaild -b|--block argument: too long\n");
@@ -77,6 +92,14 @@ static error_t parse_arg(int key, char *arg, struct argp_state *state)
                 }
                 opt.perm = strndup(arg, strlen(arg));
                 break;
+        case BPFLOCK_TRUST_OPT:
+        case BPFLOCK_TRUST_NS_OPT:
+        case BPFLOCK_ALLOW_UID_OPT:
+        case BPFLOCK_ALLOW_GID_OPT:
+        case BPFLOCK_DENY_UID_OPT:
+        case BPFLOCK_DENY_GID_OPT:
+                /* Trust criteria are loaded by the bpflock agent */
+                break;
         default:
                 return ARGP_ERR_UNKNOWN;
         }
@@ -23,6 +23,16 @@
 #define BPFLOCK_KM_UNSIGNED     (1 << 3)
 #define BPFLOCK_KM_UNSAFEMOD    (1 << 4)
 
+/* Module names and autoload requests allowed or denied under baseline */
+#define BPFLOCK_KM_NAME_LEN     64
+#define BPFLOCK_KM_MAX_NAMES    4096
+
+#define BPFLOCK_KM_NAME_ALLOW   1
+#define BPFLOCK_KM_NAME_DENY    2
+
+/* Maximum number of exempted executables */
+#define BPFLOCK_KM_MAX_EXEMPT   256
+
 enum dm_env {
         BPFLOCK_KM_NS           = BPFLOCK_NS_KEY,
         BPFLOCK_KM_SB,
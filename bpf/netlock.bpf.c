@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ *
+ * Restricts creation of sockets of selected address families.
+ */
+
+/*
+   To test it:
+        1. python3 -c 'import socket; socket.socket(socket.AF_PACKET, socket.SOCK_RAW)'
+                PermissionError: [Errno 1] Operation not permitted
+*/
+
+#include <vmlinux.h>
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_tracing.h>
+#include <bpf/bpf_core_read.h>
+#include <errno.h>
+#include "bpflock_bpf_defs.h"
+#include "bpflock_shared_defs.h"
+#include "bpflock_trust.h"
+#include "netlock.h"
+
+#ifndef AF_INET
+#define AF_INET         2
+#endif
+#ifndef AF_INET6
+#define AF_INET6        10
+#endif
+#ifndef AF_PACKET
+#define AF_PACKET       17
+#endif
+#ifndef AF_CAN
+#define AF_CAN          29
+#endif
+#ifndef AF_BLUETOOTH
+#define AF_BLUETOOTH    31
+#endif
+#ifndef AF_VSOCK
+#define AF_VSOCK        40
+#endif
+
+#define SOCK_TYPE_MASK  0xf
+
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, 4);
+        __type(key, uint32_t);
+        __type(value, uint32_t);
+} netlock_map SEC(".maps");
+
+/*
+ * Restricted socket families, they are set by the bpflock agent from the
+ * configured family names.
+ */
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, BPFLOCK_NET_MAX_FAMILIES);
+        __type(key, struct bl_socket_family);
+        __type(value, uint32_t);
+} netlock_families_map SEC(".maps");
+
+BPFLOCK_TRUST_MAP(netlock_trust_map);
+BPFLOCK_ID_RULES_MAP(netlock_ids_map);
+
+static __always_inline const char *op_name(int family, int type)
+{
+        switch (family) {
+        case AF_PACKET:
+                return "packet socket creation";
+        case AF_CAN:
+                return "can socket creation";
+        case AF_BLUETOOTH:
+                return "bluetooth socket creation";
+        case AF_VSOCK:
+                return "vsock socket creation";
+        }
+
+        if ((family == AF_INET || family == AF_INET6) && type == SOCK_RAW)
+                return "raw socket creation";
+
+        return "socket creation";
+}
+
+static __always_inline int report(int family, int type, const int ret, int reason)
+{
+        uint64_t id;
+        static struct event info;
+        const char *op = op_name(family, type);
+
+        id = bpf_get_current_pid_tgid();
+        info.pid = id >> 32;
+
+        bpf_get_current_comm(&info.comm, sizeof(info.comm));
+
+        bpf_printk("bpflock bpf=netlock pid=%lu comm=%s event=%s\n",
+                   info.pid, info.comm, op);
+        bpf_printk("bpflock bpf=netlock pid=%lu event=%s status=%s\n",
+                   info.pid, op, get_reason_str(ret, reason));
+
+        return ret;
+}
+
+static __always_inline uint32_t lookup_key(uint32_t k)
+{
+        uint32_t *val;
+
+        val = bpf_map_lookup_elem(&netlock_map, &k);
+        if (!val)
+                return 0;
+
+        return *val;
+}
+
+/* Returns true if sockets of family and type are restricted */
+static __always_inline bool is_restricted(int family, int type)
+{
+        struct bl_socket_family key = {};
+
+        key.family = family;
+        if (bpf_map_lookup_elem(&netlock_families_map, &key))
+                return true;
+
+        key.type = type;
+        return bpf_map_lookup_elem(&netlock_families_map, &key) != NULL;
+}
+
+SEC("lsm/socket_create")
+int BPF_PROG(netlock_socket_create, int family, int type, int protocol,
+             int kern, int ret)
+{
+        uint32_t perm;
+
+        if (ret != 0)
+                return ret;
+
+        /* Kernel sockets are not restricted */
+        if (kern)
+                return 0;
+
+        /* Nothing is reported nor restricted until families are loaded */
+        if (!lookup_key(BPFLOCK_NET_READY))
+                return 0;
+
+        type &= SOCK_TYPE_MASK;
+        if (!is_restricted(family, type))
+                return 0;
+
+        perm = lookup_key(BPFLOCK_NET_PERM);
+        if (perm == 0 || perm == BPFLOCK_P_ALLOW)
+                return report(family, type, 0, reason_allow);
+
+        if (perm == BPFLOCK_P_RESTRICTED)
+                return report(family, type, -EPERM, reason_restricted);
+
+        if (is_trusted(&netlock_trust_map, &netlock_ids_map, LOG_NETLOCK))
+                return report(family, type, 0, reason_baseline);
+
+        return report(family, type, -EPERM, reason_baseline);
+}
+
+char _license[] SEC("license") = "GPL";
@@ -0,0 +1,241 @@
+/* SPDX-License-Identifier: GPL-2.0 */
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ *
+ * Implements restrictions on creation of sockets of selected address families.
+ */
+
+#include <argp.h>
+#include <bpf/bpf.h>
+#include <errno.h>
+#include <stdlib.h>
+#include <string.h>
+#include <time.h>
+#include <sys/types.h>
+#include <sys/stat.h>
+#include <unistd.h>
+#include "bpflock_security_class.h"
+#include "bpflock_shared_defs.h"
+#include "trace_helpers.h"
+#include "bpflock_utils.h"
+#include "netlock.h"
+#include "netlock.skel.h"
+
+static struct options {
+        int perm_int;
+        char *perm;
+} opt = {};
+
+const char *argp_program_version = "netlock 0.1";
+const char *argp_program_bug_address =
+        "https://github.com/linux-lock/bpflock";
+const char argp_program_doc[] =
+"bpflock netlock - restrict creation of sockets of selected address families.\n"
+"\n"
+"USAGE: netlock [--help] [-p PROFILE] [-a FAMILIES]\n"
+"\n"
+"Restricted families are: packet, raw, bluetooth, can and vsock. They are\n"
+"loaded by the bpflock agent.\n"
+"\n"
+"EXAMPLES:\n"
+"  # Allow profile: creation of restricted sockets is allowed and reported.\n"
+"  netlock --profile=allow\n\n"
+"  # Baseline profile: containers can not create restricted sockets.\n"
+"  netlock --profile=baseline\n\n"
+"  # Restricted profile: only can sockets can be created.\n"
+"  netlock --profile=restricted --allow=can\n";
+
+static const struct argp_option opts[] = {
+        { "profile", 'p', "PROFILE", 0, "Profile to apply, one of the following: allow, baseline or restricted. Default value is: allow." },
+        { "allow", 'a', "FAMILIES", 0, "Comma-separated list of allowed socket families, possible values: 'packet, raw, bluetooth, can, vsock'. They are loaded by the bpflock agent." },
+        BPFLOCK_TRUST_ARGP_OPTIONS,
+        { NULL, 'h', NULL, OPTION_HIDDEN, "Show the full help" },
+        {},
+};
+
+static error_t parse_arg(int key, char *arg, struct argp_state *state)
+{
+        switch (key) {
+        case 'h':
+                argp_state_help(state, stderr, ARGP_HELP_STD_HELP);
+                break;
+        case 'a':
+                /* Restricted families are loaded by the bpflock agent */
+                break;
+        case 'p':
+                if (strlen(arg) + 1 > 64) {
+                        fprintf(stderr, "invaild -p|--profile argument: too long\n");
+                        argp_usage(state);
+                }
+                opt.perm = strndup(arg, strlen(arg));
+                break;
+        case BPFLOCK_TRUST_OPT:
+        case BPFLOCK_TRUST_NS_OPT:
+        case BPFLOCK_ALLOW_UID_OPT:
+        case BPFLOCK_ALLOW_GID_OPT:
+        case BPFLOCK_DENY_UID_OPT:
+        case BPFLOCK_DENY_GID_OPT:
+                /* Trust criteria are loaded by the bpflock agent */
+                break;
+        default:
+                return ARGP_ERR_UNKNOWN;
+        }
+
+        return 0;
+}
+
+/* Setup bpf map options */
+static int setup_net_opt_map(struct netlock_bpf *skel)
+{
+        uint32_t perm_k = BPFLOCK_NET_PERM;
+        int f;
+
+        f = bpf_map__fd(skel->maps.netlock_map);
+        if (f < 0) {
+                fprintf(stderr, "%s: error: failed to get bpf map fd: %d\n",
+                        LOG_BPFLOCK, f);
+                return f;
+        }
+
+        opt.perm_int = BPFLOCK_P_ALLOW;
+        if (opt.perm) {
+                if (strncmp(opt.perm, "restricted", 10) == 0)
+                        opt.perm_int = BPFLOCK_P_RESTRICTED;
+                else if (strncmp(opt.perm, "baseline", 8) == 0)
+                        opt.perm_int = BPFLOCK_P_BASELINE;
+        }
+
+        return bpf_map_update_elem(f, &perm_k, &opt.perm_int, BPF_ANY);
+}
+
+int main(int argc, char **argv)
+{
+        static const struct argp argp = {
+                .options = opts,
+                .parser = parse_arg,
+                .doc = argp_program_doc,
+        };
+
+        struct netlock_bpf *skel = NULL;
+        struct bpf_link *link = NULL;
+        struct bpf_program *prog = NULL;
+        struct stat st;
+        char *buf = NULL;
+        int err, i, buflen = 512;
+
+        err = argp_parse(&argp, argc, argv, 0, NULL, NULL);
+        if (err)
+                return err;
+
+        err = is_lsmbpf_supported();
+        if (err) {
+                fprintf(stderr, "%s: error: failed to check LSM BPF support\n",
+                        LOG_BPFLOCK);
+                return err;
+        }
+
+        err = bump_memlock_rlimit();
+        if (err) {
+                fprintf(stderr, "%s: error: failed to increase rlimit: %s\n",
+                        LOG_BPFLOCK, strerror(errno));
+                return err;
+        }
+
+        err = stat(net_security_map.pin_path, &st);
+        if (err == 0) {
+                fprintf(stdout, "%s: %s already loaded nothing todo, please delete pinned file '%s' "
+                        "to be able to run it again.\n",
+                        LOG_BPFLOCK, argv[0], net_security_map.pin_path);
+                return -EALREADY;
+        }
+
+        buf = malloc(buflen);
+        if (!buf) {
+                fprintf(stderr, "%s: error: failed to allocate memory\n",
+                        LOG_BPFLOCK);
+                return -ENOMEM;
+        }
+
+        memset(buf, 0, buflen);
+
+        skel = netlock_bpf__open();
+        if (!skel) {
+                fprintf(stderr, "%s: error: failed to open BPF skelect\n",
+                        LOG_BPFLOCK);
+                err = -EINVAL;
+                goto cleanup;
+        }
+
+        err = netlock_bpf__load(skel);
+        if (err) {
+                fprintf(stderr, "%s: error: failed to load BPF skelect: %d\n",
+                        LOG_BPFLOCK, err);
+                goto cleanup;
+        }
+
+        err = setup_net_opt_map(skel);
+        if (err < 0) {
+                fprintf(stderr, "%s: error: failed to setup bpf opt map: %d\n",
+                        LOG_BPFLOCK, err);
+                goto cleanup;
+        }
+
+        mkdir(BPFLOCK_PIN_PATH, 0700);
+        mkdir(net_security_map.pin_path, 0700);
+
+        err = bpf_object__pin(skel->obj, net_security_map.pin_path);
+        if (err) {
+                libbpf_strerror(err, buf, buflen);
+                fprintf(stderr, "%s: %s: error: failed to pin obj into link '%s': %s\n",
+                        LOG_BPFLOCK, LOG_NETLOCK, net_security_map.pin_path, buf);
+                goto cleanup;
+        }
+
+        i = 0;
+        bpf_object__for_each_program(prog, skel->obj) {
+                if (i >= sizeof(net_prog_links) / sizeof(bpflock_class_prog_link_t))
+                        break;
+
+                link = bpf_program__attach(prog);
+                err = libbpf_get_error(link);
+                if (err) {
+                        libbpf_strerror(err, buf, buflen);
+                        fprintf(stderr, "%s: %s: error: failed to attach BPF programs: %s\n",
+                                LOG_BPFLOCK, LOG_NETLOCK, strerror(-err));
+                        goto cleanup;
+                }
+
+                err = bpf_link__pin(link, net_prog_links[i].link);
+                if (err) {
+                        libbpf_strerror(err, buf, buflen);
+                        fprintf(stderr, "%s: %s: error: failed to pin bpf obj into link '%s': %s\n",
+                                LOG_BPFLOCK, LOG_NETLOCK, net_prog_links[i].link, buf);
+                        goto cleanup;
+                }
+
+                i++;
+        }
+
+        if (opt.perm_int == BPFLOCK_P_RESTRICTED) {
+                printf("%s: success: profile: restricted - creation of restricted sockets is now blocked - delete pinned file '%s' to re-enable\n",
+                        LOG_BPFLOCK, net_security_map.pin_path);
+        } else if (opt.perm_int == BPFLOCK_P_BASELINE) {
+                printf("%s: success: profile: baseline - creation of restricted sockets is now blocked in containers - delete pinned file '%s' to re-enable\n",
+                        LOG_BPFLOCK, net_security_map.pin_path);
+        } else {
+                printf("%s: success: profile : allow - creation of restricted sockets is allowed - delete pinned file '%s' to disable access logging\n",
+                        LOG_BPFLOCK, net_security_map.pin_path);
+        }
+
+cleanup:
+        if (link)
+                bpf_link__destroy(link);
+
+        if (skel)
+                netlock_bpf__destroy(skel);
+
+        free(buf);
+
+        return err != 0;
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ */
+
+#ifndef __BPFLOCK_NETLOCK_H
+#define __BPFLOCK_NETLOCK_H
+
+#include "bpflock_security_class.h"
+
+/* netlock security class */
+
+#define LOG_NETLOCK "netlock"
+
+#define BPFLOCK_NET_PERM        1
+/* Set by the bpflock agent once restricted families are loaded */
+#define BPFLOCK_NET_READY       2
+
+/* Maximum number of restricted socket families */
+#define BPFLOCK_NET_MAX_FAMILIES 64
+
+/*
+ * Restricted socket family, type zero restricts all types of the family.
+ * Must match the Family of pkg/netlock.
+ */
+struct bl_socket_family {
+        uint32_t family;
+        uint32_t type;
+};
+
+struct bpflock_class_map net_security_map = {
+        "net",
+        "/sys/fs/bpf/bpflock/netlock",
+        { NULL },
+        { 0 }
+};
+
+struct bpflock_class_prog_link net_prog_links[] = {
+        {
+                "bpflock_netlock_socket_create",
+                "/sys/fs/bpf/bpflock/netlock/netlock_socket_create_link",
+        },
+};
+
+/* End of netlock security class */
+
+#endif /* __BPFLOCK_NETLOCK_H */
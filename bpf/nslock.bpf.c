@@ -0,0 +1,232 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ *
+ * Restricts creation of user, network and mount namespaces.
+ */
+
+/*
+   To test it:
+        1. unshare --user --map-root-user true
+                unshare: unshare failed: Operation not permitted
+*/
+
+#include <vmlinux.h>
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_tracing.h>
+#include <bpf/bpf_core_read.h>
+#include <errno.h>
+#include "bpflock_bpf_defs.h"
+#include "bpflock_shared_defs.h"
+#include "bpflock_trust.h"
+#include "nslock.h"
+
+#ifndef CLONE_NEWNS
+#define CLONE_NEWNS     0x00020000
+#endif
+#ifndef CLONE_NEWUSER
+#define CLONE_NEWUSER   0x10000000
+#endif
+#ifndef CLONE_NEWNET
+#define CLONE_NEWNET    0x40000000
+#endif
+#ifndef NSFS_MAGIC
+#define NSFS_MAGIC      0x6e736673
+#endif
+
+/*
+ * unshare() and setns() have no LSM hooks, their syscall wrappers are
+ * modified instead. They take a struct pt_regs with the syscall arguments.
+ * On amd64 the 32-bit compat entry points are modified too, their
+ * arguments are passed in bx and cx.
+ */
+#if defined(__TARGET_ARCH_amd64)
+#define SYS_PREFIX              "__x64_"
+#define SYSCALL_ARG1(regs)      BPF_CORE_READ(regs, di)
+#define SYSCALL_ARG2(regs)      BPF_CORE_READ(regs, si)
+#define IA32_SYS_PREFIX         "__ia32_"
+#define IA32_SYSCALL_ARG1(regs) BPF_CORE_READ(regs, bx)
+#define IA32_SYSCALL_ARG2(regs) BPF_CORE_READ(regs, cx)
+#elif defined(__TARGET_ARCH_arm64)
+#define SYS_PREFIX              "__arm64_"
+#define SYSCALL_ARG1(regs)      BPF_CORE_READ(regs, regs[0])
+#define SYSCALL_ARG2(regs)      BPF_CORE_READ(regs, regs[1])
+#endif
+
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, 4);
+        __type(key, uint32_t);
+        __type(value, uint32_t);
+} nslock_map SEC(".maps");
+
+BPFLOCK_TRUST_MAP(nslock_trust_map);
+BPFLOCK_ID_RULES_MAP(nslock_ids_map);
+
+static __always_inline int report(const char *op, const int ret, int reason)
+{
+        uint64_t id;
+        static struct event info;
+
+        id = bpf_get_current_pid_tgid();
+        info.pid = id >> 32;
+
+        bpf_get_current_comm(&info.comm, sizeof(info.comm));
+
+        bpf_printk("bpflock bpf=nslock pid=%lu comm=%s event=%s\n",
+                   info.pid, info.comm, op);
+        bpf_printk("bpflock bpf=nslock pid=%lu event=%s status=%s\n",
+                   info.pid, op, get_reason_str(ret, reason));
+
+        return ret;
+}
+
+static __always_inline uint32_t lookup_key(uint32_t k)
+{
+        uint32_t *val;
+
+        val = bpf_map_lookup_elem(&nslock_map, &k);
+        if (!val)
+                return 0;
+
+        return *val;
+}
+
+static __always_inline const char *op_name(uint32_t ops, bool join)
+{
+        if (ops & BPFLOCK_NL_USERNS)
+                return join ? "setns to user namespace" : "user namespace creation";
+        if (ops & BPFLOCK_NL_NETNS)
+                return join ? "setns to network namespace" : "network namespace creation";
+
+        return join ? "setns to mount namespace" : "mount namespace creation";
+}
+
+/*
+ * Returns the namespace type of a setns() file descriptor of current, or 0
+ * if it is not a namespace file. Pidfds are not namespace files, the kernel
+ * refuses them when no namespace type is specified.
+ */
+static __always_inline uint64_t fd_ns_type(uint64_t fd)
+{
+        struct task_struct *current;
+        struct fdtable *fdt;
+        struct file **fds;
+        struct file *file = NULL;
+        struct inode *inode;
+        struct ns_common *ns;
+
+        current = (struct task_struct *)bpf_get_current_task();
+        fdt = BPF_CORE_READ(current, files, fdt);
+        if (!fdt || fd >= BPF_CORE_READ(fdt, max_fds))
+                return 0;
+
+        fds = BPF_CORE_READ(fdt, fd);
+        bpf_probe_read_kernel(&file, sizeof(file), &fds[fd]);
+        if (!file)
+                return 0;
+
+        inode = BPF_CORE_READ(file, f_inode);
+        if (!inode || BPF_CORE_READ(inode, i_sb, s_magic) != NSFS_MAGIC)
+                return 0;
+
+        ns = BPF_CORE_READ(inode, i_private);
+        if (!ns)
+                return 0;
+
+        return BPF_CORE_READ(ns, ops, type);
+}
+
+/*
+ * Checks the namespace types of clone flags, for setns() the type of fd
+ * is used when no namespace type is specified.
+ */
+static __always_inline int check_ns(uint64_t flags, uint64_t fd, bool join)
+{
+        uint32_t perm, blocked, ops = 0;
+
+        if (join && flags == 0)
+                flags = fd_ns_type(fd);
+        if (flags & CLONE_NEWUSER)
+                ops |= BPFLOCK_NL_USERNS;
+        if (flags & CLONE_NEWNET)
+                ops |= BPFLOCK_NL_NETNS;
+        if (flags & CLONE_NEWNS)
+                ops |= BPFLOCK_NL_MNTNS;
+
+        if (ops == 0)
+                return 0;
+
+        perm = lookup_key(BPFLOCK_NL_PERM);
+        if (perm == 0 || perm == BPFLOCK_P_ALLOW)
+                return report(op_name(ops, join), 0, reason_allow);
+
+        blocked = lookup_key(BPFLOCK_NL_OP);
+        if (blocked == 0)
+                blocked = BPFLOCK_NL_ALL_OPS;
+
+        /* Allowed namespace types are never blocked */
+        blocked &= ~lookup_key(BPFLOCK_NL_ALLOW);
+
+        if (!(ops & blocked))
+                return report(op_name(ops, join), 0, reason_baseline_allowed);
+
+        if (perm == BPFLOCK_P_RESTRICTED)
+                return report(op_name(ops & blocked, join), -EPERM, reason_restricted);
+
+        if (!is_trusted(&nslock_trust_map, &nslock_ids_map, LOG_NSLOCK))
+                return report(op_name(ops & blocked, join), -EPERM, reason_baseline);
+
+        return report(op_name(ops & blocked, join), 0, reason_baseline);
+}
+
+SEC("lsm/task_alloc")
+int BPF_PROG(nslock_task_alloc, struct task_struct *task,
+             unsigned long clone_flags, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        return check_ns(clone_flags, 0, false);
+}
+
+SEC("fmod_ret/" SYS_PREFIX "sys_unshare")
+int BPF_PROG(nslock_unshare, struct pt_regs *regs, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        return check_ns(SYSCALL_ARG1(regs), 0, false);
+}
+
+SEC("fmod_ret/" SYS_PREFIX "sys_setns")
+int BPF_PROG(nslock_setns, struct pt_regs *regs, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        return check_ns(SYSCALL_ARG2(regs), SYSCALL_ARG1(regs), true);
+}
+
+#ifdef IA32_SYS_PREFIX
+SEC("fmod_ret/" IA32_SYS_PREFIX "sys_unshare")
+int BPF_PROG(nslock_ia32_unshare, struct pt_regs *regs, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        return check_ns(IA32_SYSCALL_ARG1(regs), 0, false);
+}
+
+SEC("fmod_ret/" IA32_SYS_PREFIX "sys_setns")
+int BPF_PROG(nslock_ia32_setns, struct pt_regs *regs, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        return check_ns(IA32_SYSCALL_ARG2(regs), IA32_SYSCALL_ARG1(regs), true);
+}
+#endif
+
+char _license[] SEC("license") = "GPL";
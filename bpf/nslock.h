@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ */
+
+#ifndef __BPFLOCK_NSLOCK_H
+#define __BPFLOCK_NSLOCK_H
+
+#include "bpflock_security_class.h"
+
+/* nslock security class */
+
+#define LOG_NSLOCK "nslock"
+
+#define BPFLOCK_NL_PERM         1
+#define BPFLOCK_NL_OP           2
+#define BPFLOCK_NL_ALLOW        3
+
+#define BPFLOCK_NL_USERNS       (1 << 0)
+#define BPFLOCK_NL_NETNS        (1 << 1)
+#define BPFLOCK_NL_MNTNS        (1 << 2)
+
+#define BPFLOCK_NL_ALL_OPS      (BPFLOCK_NL_USERNS | BPFLOCK_NL_NETNS | \
+                                 BPFLOCK_NL_MNTNS)
+
+struct bpflock_class_map ns_security_map = {
+        "ns",
+        "/sys/fs/bpf/bpflock/nslock",
+        { NULL },
+        { 0 }
+};
+
+struct bpflock_class_prog_link ns_prog_links[] = {
+        {
+                "bpflock_nslock_task_alloc",
+                "/sys/fs/bpf/bpflock/nslock/nslock_task_alloc_link",
+        },
+        {
+                "bpflock_nslock_unshare",
+                "/sys/fs/bpf/bpflock/nslock/nslock_unshare_link",
+        },
+        {
+                "bpflock_nslock_setns",
+                "/sys/fs/bpf/bpflock/nslock/nslock_setns_link",
+        },
+        /* 32-bit compat syscalls, only loaded if the kernel has them */
+        {
+                "bpflock_nslock_ia32_unshare",
+                "/sys/fs/bpf/bpflock/nslock/nslock_ia32_unshare_link",
+        },
+        {
+                "bpflock_nslock_ia32_setns",
+                "/sys/fs/bpf/bpflock/nslock/nslock_ia32_setns_link",
+        },
+};
+
+/* End of nslock security class */
+
+#endif /* __BPFLOCK_NSLOCK_H */
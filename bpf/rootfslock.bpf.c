@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ *
+ * Restricts writes to the root filesystem and to sysfs.
+ */
+
+#include <vmlinux.h>
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_tracing.h>
+#include <bpf/bpf_core_read.h>
+#include <errno.h>
+#include "bpflock_bpf_defs.h"
+#include "bpflock_shared_defs.h"
+#include "bpflock_trust.h"
+#include "rootfslock.h"
+
+#define FMODE_WRITE     0x2
+
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, 4);
+        __type(key, uint32_t);
+        __type(value, uint32_t);
+} rootfslock_map SEC(".maps");
+
+/*
+ * Allowed directories: writes below them are allowed. Keys are set by the
+ * bpflock agent, st_dev is the kernel encoded device number.
+ */
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, BPFLOCK_RF_MAX_ALLOW);
+        __type(key, struct bl_stat);
+        __type(value, uint32_t);
+} rootfslock_allow_map SEC(".maps");
+
+BPFLOCK_TRUST_MAP(rootfslock_trust_map);
+BPFLOCK_ID_RULES_MAP(rootfslock_ids_map);
+
+static __always_inline int report(const char *op, const int ret, int reason)
+{
+        uint64_t id;
+        static struct event info;
+
+        id = bpf_get_current_pid_tgid();
+        info.pid = id >> 32;
+
+        bpf_get_current_comm(&info.comm, sizeof(info.comm));
+
+        bpf_printk("bpflock bpf=rootfslock pid=%lu comm=%s event=%s\n",
+                   info.pid, info.comm, op);
+        bpf_printk("bpflock bpf=rootfslock pid=%lu event=%s status=%s\n",
+                   info.pid, op, get_reason_str(ret, reason));
+
+        return ret;
+}
+
+static __always_inline uint32_t lookup_key(uint32_t k)
+{
+        uint32_t *val;
+
+        val = bpf_map_lookup_elem(&rootfslock_map, &k);
+        if (!val)
+                return 0;
+
+        return *val;
+}
+
+/* Returns true if dentry or one of its parents is an allowed directory */
+static __always_inline bool is_allowed(struct dentry *dentry, unsigned long dev)
+{
+        struct bl_stat st = { .st_dev = dev };
+        struct dentry *parent;
+        int i;
+
+        for (i = 0; i < BPFLOCK_RF_MAX_DEPTH; i++) {
+                if (!dentry)
+                        return false;
+
+                st.st_ino = BPF_CORE_READ(dentry, d_inode, i_ino);
+                if (st.st_ino && bpf_map_lookup_elem(&rootfslock_allow_map, &st))
+                        return true;
+
+                parent = BPF_CORE_READ(dentry, d_parent);
+                if (parent == dentry)
+                        return false;
+                dentry = parent;
+        }
+
+        return false;
+}
+
+/*
+ * Checks a write to dentry, rootfs_op and sysfs_op are the reported
+ * operations on the root filesystem and on sysfs.
+ */
+static __always_inline int check_write(struct dentry *dentry,
+                                       const char *rootfs_op, const char *sysfs_op)
+{
+        struct super_block *sb;
+        const char *op;
+        uint32_t perm, dev;
+
+        perm = lookup_key(BPFLOCK_RF_PERM);
+        if (perm == 0 || perm == BPFLOCK_P_ALLOW)
+                return 0;
+
+        /* Do not restrict anything until allowed directories are loaded */
+        if (!lookup_key(BPFLOCK_RF_READY))
+                return 0;
+
+        if (!dentry)
+                return 0;
+
+        sb = BPF_CORE_READ(dentry, d_sb);
+        dev = BPF_CORE_READ(sb, s_dev);
+        if (BPF_CORE_READ(sb, s_magic) == SYSFS_MAGIC)
+                op = sysfs_op;
+        else if (dev == lookup_key(BPFLOCK_RF_ROOT_DEV))
+                op = rootfs_op;
+        else
+                return 0;
+
+        if (is_allowed(dentry, dev))
+                return 0;
+
+        if (perm == BPFLOCK_P_RESTRICTED)
+                return report(op, -EPERM, reason_restricted);
+
+        /* Baseline: the host namespace is exempt */
+        if (is_trusted(&rootfslock_trust_map, &rootfslock_ids_map, LOG_ROOTFSLOCK))
+                return 0;
+
+        return report(op, -EPERM, reason_baseline);
+}
+
+SEC("lsm/file_open")
+int BPF_PROG(rootfslock_file_open, struct file *file, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        if (!(BPF_CORE_READ(file, f_mode) & FMODE_WRITE))
+                return 0;
+
+        return check_write(BPF_CORE_READ(file, f_path.dentry),
+                           "rootfs open for write", "sysfs open for write");
+}
+
+SEC("lsm/inode_create")
+int BPF_PROG(rootfslock_create, struct inode *dir, struct dentry *dentry,
+             umode_t mode, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        return check_write(BPF_CORE_READ(dentry, d_parent),
+                           "rootfs create", "sysfs create");
+}
+
+SEC("lsm/inode_mkdir")
+int BPF_PROG(rootfslock_mkdir, struct inode *dir, struct dentry *dentry,
+             umode_t mode, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        return check_write(BPF_CORE_READ(dentry, d_parent),
+                           "rootfs mkdir", "sysfs mkdir");
+}
+
+SEC("lsm/inode_unlink")
+int BPF_PROG(rootfslock_unlink, struct inode *dir, struct dentry *dentry, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        return check_write(dentry, "rootfs unlink", "sysfs unlink");
+}
+
+SEC("lsm/inode_rmdir")
+int BPF_PROG(rootfslock_rmdir, struct inode *dir, struct dentry *dentry, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        return check_write(dentry, "rootfs rmdir", "sysfs rmdir");
+}
+
+SEC("lsm/inode_rename")
+int BPF_PROG(rootfslock_rename, struct inode *old_dir, struct dentry *old_dentry,
+             struct inode *new_dir, struct dentry *new_dentry, unsigned int flags,
+             int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        ret = check_write(old_dentry, "rootfs rename", "sysfs rename");
+        if (ret != 0)
+                return ret;
+
+        return check_write(BPF_CORE_READ(new_dentry, d_parent),
+                           "rootfs rename", "sysfs rename");
+}
+
+char _license[] SEC("license") = "GPL";
@@ -0,0 +1,257 @@
+/* SPDX-License-Identifier: GPL-2.0 */
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ *
+ * Implements write restrictions on the root filesystem and sysfs.
+ */
+
+#include <argp.h>
+#include <bpf/bpf.h>
+#include <errno.h>
+#include <stdlib.h>
+#include <string.h>
+#include <time.h>
+#include <sys/types.h>
+#include <sys/stat.h>
+#include <sys/sysmacros.h>
+#include <unistd.h>
+#include "bpflock_security_class.h"
+#include "bpflock_shared_defs.h"
+#include "trace_helpers.h"
+#include "bpflock_utils.h"
+#include "rootfslock.h"
+#include "rootfslock.skel.h"
+
+static struct options {
+        int perm_int;
+        char *perm;
+} opt = {};
+
+const char *argp_program_version = "rootfslock 0.1";
+const char *argp_program_bug_address =
+        "https://github.com/linux-lock/bpflock";
+const char argp_program_doc[] =
+"bpflock rootfslock - restrict writes to the root filesystem and sysfs.\n"
+"\n"
+"USAGE: rootfslock [--help] [-p PROFILE] [-a PATHS]\n"
+"\n"
+"The allowed directories are loaded by the bpflock agent, until then\n"
+"nothing is restricted.\n"
+"\n"
+"EXAMPLES:\n"
+"  # Allow profile: writes are allowed.\n"
+"  rootfslock --profile=allow\n\n"
+"  # Baseline profile: containers can not write to the root filesystem and sysfs.\n"
+"  rootfslock --profile=baseline\n\n"
+"  # Restricted profile: only writes below /var and /tmp are allowed.\n"
+"  rootfslock --profile=restricted --allow=/var,/tmp\n";
+
+static const struct argp_option opts[] = {
+        { "profile", 'p', "PROFILE", 0, "Profile to apply, one of the following: allow, baseline or restricted. Default value is: allow." },
+        { "allow", 'a', "PATHS", 0, "Comma-separated list of directories where writes are allowed, they are loaded by the bpflock agent." },
+        BPFLOCK_TRUST_ARGP_OPTIONS,
+        { NULL, 'h', NULL, OPTION_HIDDEN, "Show the full help" },
+        {},
+};
+
+static error_t parse_arg(int key, char *arg, struct argp_state *state)
+{
+        switch (key) {
+        case 'h':
+                argp_state_help(state, stderr, ARGP_HELP_STD_HELP);
+                break;
+        case 'a':
+                /* Allowed directories are resolved by the bpflock agent */
+                break;
+        case 'p':
+                if (strlen(arg) + 1 > 64) {
+                        fprintf(stderr, "invaild -p|--profile argument: too long\n");
+                        argp_usage(state);
+                }
+                opt.perm = strndup(arg, strlen(arg));
+                break;
+        case BPFLOCK_TRUST_OPT:
+        case BPFLOCK_TRUST_NS_OPT:
+        case BPFLOCK_ALLOW_UID_OPT:
+        case BPFLOCK_ALLOW_GID_OPT:
+        case BPFLOCK_DENY_UID_OPT:
+        case BPFLOCK_DENY_GID_OPT:
+                /* Trust criteria are loaded by the bpflock agent */
+                break;
+        default:
+                return ARGP_ERR_UNKNOWN;
+        }
+
+        return 0;
+}
+
+/* Setup bpf map options */
+static int setup_rootfs_opt_map(struct rootfslock_bpf *skel)
+{
+        uint32_t perm_k = BPFLOCK_RF_PERM, dev_k = BPFLOCK_RF_ROOT_DEV;
+        uint32_t dev;
+        struct stat st;
+        int f, err;
+
+        f = bpf_map__fd(skel->maps.rootfslock_map);
+        if (f < 0) {
+                fprintf(stderr, "%s: error: failed to get bpf map fd: %d\n",
+                        LOG_BPFLOCK, f);
+                return f;
+        }
+
+        opt.perm_int = BPFLOCK_P_ALLOW;
+        if (opt.perm) {
+                if (strncmp(opt.perm, "restricted", 10) == 0)
+                        opt.perm_int = BPFLOCK_P_RESTRICTED;
+                else if (strncmp(opt.perm, "baseline", 8) == 0)
+                        opt.perm_int = BPFLOCK_P_BASELINE;
+        }
+
+        err = stat("/", &st);
+        if (err < 0) {
+                fprintf(stderr, "%s: error: failed to stat root filesystem: %s\n",
+                        LOG_BPFLOCK, strerror(errno));
+                return -errno;
+        }
+
+        /* Kernel internal encoding of the device number */
+        dev = major(st.st_dev) << 20 | minor(st.st_dev);
+        err = bpf_map_update_elem(f, &dev_k, &dev, BPF_ANY);
+        if (err < 0)
+                return err;
+
+        return bpf_map_update_elem(f, &perm_k, &opt.perm_int, BPF_ANY);
+}
+
+int main(int argc, char **argv)
+{
+        static const struct argp argp = {
+                .options = opts,
+                .parser = parse_arg,
+                .doc = argp_program_doc,
+        };
+
+        struct rootfslock_bpf *skel = NULL;
+        struct bpf_link *link = NULL;
+        struct bpf_program *prog = NULL;
+        struct stat st;
+        char *buf = NULL;
+        int err, i, buflen = 512;
+
+        err = argp_parse(&argp, argc, argv, 0, NULL, NULL);
+        if (err)
+                return err;
+
+        err = is_lsmbpf_supported();
+        if (err) {
+                fprintf(stderr, "%s: error: failed to check LSM BPF support\n",
+                        LOG_BPFLOCK);
+                return err;
+        }
+
+        err = bump_memlock_rlimit();
+        if (err) {
+                fprintf(stderr, "%s: error: failed to increase rlimit: %s\n",
+                        LOG_BPFLOCK, strerror(errno));
+                return err;
+        }
+
+        err = stat(rootfs_security_map.pin_path, &st);
+        if (err == 0) {
+                fprintf(stdout, "%s: %s already loaded nothing todo, please delete pinned file '%s' "
+                        "to be able to run it again.\n",
+                        LOG_BPFLOCK, argv[0], rootfs_security_map.pin_path);
+                return -EALREADY;
+        }
+
+        buf = malloc(buflen);
+        if (!buf) {
+                fprintf(stderr, "%s: error: failed to allocate memory\n",
+                        LOG_BPFLOCK);
+                return -ENOMEM;
+        }
+
+        memset(buf, 0, buflen);
+
+        skel = rootfslock_bpf__open();
+        if (!skel) {
+                fprintf(stderr, "%s: error: failed to open BPF skelect\n",
+                        LOG_BPFLOCK);
+                err = -EINVAL;
+                goto cleanup;
+        }
+
+        err = rootfslock_bpf__load(skel);
+        if (err) {
+                fprintf(stderr, "%s: error: failed to load BPF skelect: %d\n",
+                        LOG_BPFLOCK, err);
+                goto cleanup;
+        }
+
+        err = setup_rootfs_opt_map(skel);
+        if (err < 0) {
+                fprintf(stderr, "%s: error: failed to setup bpf opt map: %d\n",
+                        LOG_BPFLOCK, err);
+                goto cleanup;
+        }
+
+        mkdir(BPFLOCK_PIN_PATH, 0700);
+        mkdir(rootfs_security_map.pin_path, 0700);
+
+        err = bpf_object__pin(skel->obj, rootfs_security_map.pin_path);
+        if (err) {
+                libbpf_strerror(err, buf, buflen);
+                fprintf(stderr, "%s: %s: error: failed to pin obj into link '%s': %s\n",
+                        LOG_BPFLOCK, LOG_ROOTFSLOCK, rootfs_security_map.pin_path, buf);
+                goto cleanup;
+        }
+
+        i = 0;
+        bpf_object__for_each_program(prog, skel->obj) {
+                if (i >= sizeof(rootfs_prog_links) / sizeof(bpflock_class_prog_link_t))
+                        break;
+
+                link = bpf_program__attach(prog);
+                err = libbpf_get_error(link);
+                if (err) {
+                        libbpf_strerror(err, buf, buflen);
+                        fprintf(stderr, "%s: %s: error: failed to attach BPF programs: %s\n",
+                                LOG_BPFLOCK, LOG_ROOTFSLOCK, strerror(-err));
+                        goto cleanup;
+                }
+
+                err = bpf_link__pin(link, rootfs_prog_links[i].link);
+                if (err) {
+                        libbpf_strerror(err, buf, buflen);
+                        fprintf(stderr, "%s: %s: error: failed to pin bpf obj into link '%s': %s\n",
+                                LOG_BPFLOCK, LOG_ROOTFSLOCK, rootfs_prog_links[i].link, buf);
+                        goto cleanup;
+                }
+
+                i++;
+        }
+
+        if (opt.perm_int == BPFLOCK_P_RESTRICTED) {
+                printf("%s: success: profile: restricted - writes to the root filesystem and sysfs are now restricted - delete pinned file '%s' to re-enable\n",
+                        LOG_BPFLOCK, rootfs_security_map.pin_path);
+        } else if (opt.perm_int == BPFLOCK_P_BASELINE) {
+                printf("%s: success: profile: baseline - writes to the root filesystem and sysfs are now restricted to initial pid namespace - delete pinned file '%s' to re-enable\n",
+                        LOG_BPFLOCK, rootfs_security_map.pin_path);
+        } else {
+                printf("%s: success: profile : allow - writes to the root filesystem and sysfs are allowed - delete pinned file '%s' to disable\n",
+                        LOG_BPFLOCK, rootfs_security_map.pin_path);
+        }
+
+cleanup:
+        if (link)
+                bpf_link__destroy(link);
+
+        if (skel)
+                rootfslock_bpf__destroy(skel);
+
+        free(buf);
+
+        return err != 0;
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ */
+
+#ifndef __BPFLOCK_ROOTFSLOCK_H
+#define __BPFLOCK_ROOTFSLOCK_H
+
+#include "bpflock_security_class.h"
+
+/* rootfslock security class */
+
+#define LOG_ROOTFSLOCK "rootfslock"
+
+#define BPFLOCK_RF_PERM         1
+/* Kernel encoded device number of the root filesystem */
+#define BPFLOCK_RF_ROOT_DEV     2
+/* Set by the bpflock agent once allowed directories are loaded */
+#define BPFLOCK_RF_READY        3
+
+/* Maximum number of allowed directories */
+#define BPFLOCK_RF_MAX_ALLOW    256
+
+/* Maximum depth of parents that are checked against allowed directories */
+#define BPFLOCK_RF_MAX_DEPTH    16
+
+#define SYSFS_MAGIC             0x62656572
+
+struct bpflock_class_map rootfs_security_map = {
+        "rootfs",
+        "/sys/fs/bpf/bpflock/rootfslock",
+        { NULL },
+        { 0 }
+};
+
+struct bpflock_class_prog_link rootfs_prog_links[] = {
+        {
+                "bpflock_rootfslock_file_open",
+                "/sys/fs/bpf/bpflock/rootfslock/rootfslock_file_open_link",
+        },
+        {
+                "bpflock_rootfslock_create",
+                "/sys/fs/bpf/bpflock/rootfslock/rootfslock_create_link",
+        },
+        {
+                "bpflock_rootfslock_mkdir",
+                "/sys/fs/bpf/bpflock/rootfslock/rootfslock_mkdir_link",
+        },
+        {
+                "bpflock_rootfslock_unlink",
+                "/sys/fs/bpf/bpflock/rootfslock/rootfslock_unlink_link",
+        },
+        {
+                "bpflock_rootfslock_rmdir",
+                "/sys/fs/bpf/bpflock/rootfslock/rootfslock_rmdir_link",
+        },
+        {
+                "bpflock_rootfslock_rename",
+                "/sys/fs/bpf/bpflock/rootfslock/rootfslock_rename_link",
+        },
+};
+
+/* End of rootfslock security class */
+
+#endif /* __BPFLOCK_ROOTFSLOCK_H */
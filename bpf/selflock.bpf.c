@@ -0,0 +1,282 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ */
+
+#include <vmlinux.h>
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_tracing.h>
+#include <bpf/bpf_core_read.h>
+#include <errno.h>
+#include "bpflock_bpf_defs.h"
+#include "bpflock_shared_defs.h"
+#include "bpflock_trust.h"
+#include "selflock.h"
+
+#define MINORBITS       20
+#define FMODE_WRITE     0x2
+
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, 8);
+        __type(key, uint32_t);
+        __type(value, uint32_t);
+} selflock_map SEC(".maps");
+
+/*
+ * Protected inodes: pins of bpflock and its configuration. Keys are set
+ * by the bpflock agent, st_dev is the kernel encoded device number.
+ */
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, BPFLOCK_SL_MAX_INODES);
+        __type(key, struct bl_stat);
+        __type(value, uint32_t);
+} selflock_inodes_map SEC(".maps");
+
+/*
+ * Identity of the bpflock agent, it is set by the agent and cleared when it
+ * stops or exits. It is not one of the options so it is not frozen when the
+ * policy is sealed, a restarted agent registers itself again. Only the
+ * agent can write the selflock maps while it runs.
+ */
+struct {
+        __uint(type, BPF_MAP_TYPE_ARRAY);
+        __uint(max_entries, 1);
+        __type(key, uint32_t);
+        __type(value, struct bl_sl_agent);
+} selflock_agent_map SEC(".maps");
+
+BPFLOCK_TRUST_MAP(selflock_trust_map);
+BPFLOCK_ID_RULES_MAP(selflock_ids_map);
+
+static __always_inline int report(const char *op, const int ret, int reason)
+{
+        uint64_t id;
+        static struct event info;
+
+        id = bpf_get_current_pid_tgid();
+        info.pid = id >> 32;
+
+        bpf_get_current_comm(&info.comm, sizeof(info.comm));
+
+        bpf_printk("bpflock bpf=selflock pid=%lu comm=%s event=%s\n",
+                   info.pid, info.comm, op);
+        bpf_printk("bpflock bpf=selflock pid=%lu event=%s status=%s\n",
+                   info.pid, op, get_reason_str(ret, reason));
+
+        return ret;
+}
+
+static __always_inline uint32_t lookup_key(uint32_t k)
+{
+        uint32_t *val;
+
+        val = bpf_map_lookup_elem(&selflock_map, &k);
+        if (!val)
+                return 0;
+
+        return *val;
+}
+
+/* Returns true if p is a thread of the running bpflock agent */
+static __always_inline bool is_bpflock_task(struct task_struct *p)
+{
+        struct bl_sl_agent *agent;
+        uint64_t start;
+        uint32_t k = 0;
+
+        agent = bpf_map_lookup_elem(&selflock_agent_map, &k);
+        if (!agent || agent->pid == 0)
+                return false;
+
+        if (BPF_CORE_READ(p, tgid) != agent->pid)
+                return false;
+
+        /* A recycled pid does not have the start time of the agent */
+        start = BPF_CORE_READ(p, group_leader, start_boottime);
+
+        return start / (1000000000ULL / BPFLOCK_SL_USER_HZ) == agent->start_time;
+}
+
+/* Returns true if current is the bpflock agent or one of its launchers */
+static __always_inline bool is_bpflock(void)
+{
+        struct task_struct *current;
+
+        current = (struct task_struct *)bpf_get_current_task();
+
+        return is_bpflock_task(current) ||
+               is_bpflock_task(BPF_CORE_READ(current, real_parent));
+}
+
+static __always_inline bool is_protected_inode(struct inode *inode)
+{
+        struct bl_stat st = {};
+        uint32_t *val;
+
+        if (!inode)
+                return false;
+
+        st.st_dev = BPF_CORE_READ(inode, i_sb, s_dev);
+        st.st_ino = BPF_CORE_READ(inode, i_ino);
+
+        val = bpf_map_lookup_elem(&selflock_inodes_map, &st);
+
+        return val != NULL;
+}
+
+/*
+ * Checks access to a protected operation, op_mask is the operation in
+ * the list of blocked operations.
+ */
+static __always_inline int check_access(const char *op, uint32_t op_mask)
+{
+        uint32_t perm, blocked;
+
+        if (is_bpflock())
+                return 0;
+
+        perm = lookup_key(BPFLOCK_SL_PERM);
+        if (perm == 0 || perm == BPFLOCK_P_ALLOW)
+                return report(op, 0, reason_allow);
+
+        blocked = lookup_key(BPFLOCK_SL_OP);
+        if (blocked == 0)
+                blocked = BPFLOCK_SL_ALL_OPS;
+
+        if (!(blocked & op_mask))
+                return report(op, 0, reason_baseline_allowed);
+
+        /* Once sealed, the restricted profile applies to all */
+        if (perm == BPFLOCK_P_RESTRICTED || lookup_key(BPFLOCK_SL_SEALED))
+                return report(op, -EPERM, reason_restricted);
+
+        if (!is_trusted(&selflock_trust_map, &selflock_ids_map, LOG_SELFLOCK))
+                return report(op, -EPERM, reason_baseline);
+
+        return report(op, 0, reason_baseline);
+}
+
+SEC("lsm/task_kill")
+int BPF_PROG(selflock_kill, struct task_struct *p, struct kernel_siginfo *info,
+             int sig, const struct cred *cred, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        /* Signal 0 only checks for existence of the process */
+        if (sig == 0 || !is_bpflock_task(p))
+                return ret;
+
+        return check_access("kill of bpflock", BPFLOCK_SL_KILL);
+}
+
+SEC("lsm/ptrace_access_check")
+int BPF_PROG(selflock_ptrace, struct task_struct *child, unsigned int mode, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        if (!is_bpflock_task(child))
+                return ret;
+
+        return check_access("ptrace of bpflock", BPFLOCK_SL_PTRACE);
+}
+
+SEC("lsm/inode_unlink")
+int BPF_PROG(selflock_unlink, struct inode *dir, struct dentry *dentry, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        if (!is_protected_inode(BPF_CORE_READ(dentry, d_inode)))
+                return ret;
+
+        return check_access("unlink of bpflock file", BPFLOCK_SL_UNLINK);
+}
+
+SEC("lsm/inode_rmdir")
+int BPF_PROG(selflock_rmdir, struct inode *dir, struct dentry *dentry, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        if (!is_protected_inode(BPF_CORE_READ(dentry, d_inode)))
+                return ret;
+
+        return check_access("rmdir of bpflock directory", BPFLOCK_SL_UNLINK);
+}
+
+SEC("lsm/inode_rename")
+int BPF_PROG(selflock_rename, struct inode *old_dir, struct dentry *old_dentry,
+             struct inode *new_dir, struct dentry *new_dentry, int ret)
+{
+        if (ret != 0)
+                return ret;
+
+        if (!is_protected_inode(BPF_CORE_READ(old_dentry, d_inode)) &&
+            !is_protected_inode(BPF_CORE_READ(new_dentry, d_inode)))
+                return ret;
+
+        return check_access("rename of bpflock file", BPFLOCK_SL_RENAME);
+}
+
+/* Clears the identity of the bpflock agent when it exits, even if it crashed */
+SEC("lsm/task_free")
+int BPF_PROG(selflock_task_free, struct task_struct *task)
+{
+        struct bl_sl_agent none = {};
+        uint32_t k = 0;
+
+        if (BPF_CORE_READ(task, pid) != BPF_CORE_READ(task, tgid))
+                return 0;
+
+        if (is_bpflock_task(task))
+                bpf_map_update_elem(&selflock_agent_map, &k, &none, BPF_ANY);
+
+        return 0;
+}
+
+static __always_inline bool is_selflock_map(struct bpf_map *map)
+{
+        return map == (struct bpf_map *)&selflock_map ||
+               map == (struct bpf_map *)&selflock_inodes_map ||
+               map == (struct bpf_map *)&selflock_agent_map ||
+               map == (struct bpf_map *)&selflock_trust_map ||
+               map == (struct bpf_map *)&selflock_ids_map;
+}
+
+/*
+ * Write access to the selflock maps is limited to the running bpflock
+ * agent, otherwise any process could register itself as the agent. A
+ * new agent can register once the previous one is gone.
+ */
+SEC("lsm/bpf_map")
+int BPF_PROG(selflock_bpf_map, struct bpf_map *map, fmode_t fmode, int ret)
+{
+        struct bl_sl_agent *agent;
+        uint32_t perm, k = 0;
+
+        if (ret != 0)
+                return ret;
+
+        if (!(fmode & FMODE_WRITE) || !is_selflock_map(map))
+                return ret;
+
+        if (is_bpflock())
+                return ret;
+
+        perm = lookup_key(BPFLOCK_SL_PERM);
+        if ((perm == 0 || perm == BPFLOCK_P_ALLOW) && !lookup_key(BPFLOCK_SL_SEALED))
+                return report("write access to selflock maps", 0, reason_allow);
+
+        agent = bpf_map_lookup_elem(&selflock_agent_map, &k);
+        if (!agent || agent->pid == 0)
+                return report("write access to selflock maps", 0, reason_baseline_allowed);
+
+        return report("write access to selflock maps", -EPERM, reason_restricted);
+}
+
+static const char _license[] SEC("license") = "GPL";
@@ -0,0 +1,352 @@
+/* SPDX-License-Identifier: GPL-2.0 */
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ *
+ * Protects the bpflock agent, its pinned bpf objects and its configuration.
+ */
+
+#include <argp.h>
+#include <bpf/bpf.h>
+#include <errno.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <time.h>
+#include <sys/types.h>
+#include <sys/stat.h>
+#include <unistd.h>
+#include "bpflock_security_class.h"
+#include "bpflock_shared_defs.h"
+#include "trace_helpers.h"
+#include "bpflock_utils.h"
+#include "selflock.h"
+#include "selflock.skel.h"
+
+static struct options {
+        int perm_int;
+        int block_op_int;
+        int pid;
+        char *perm;
+        char *block_op;
+} opt = {};
+
+const char *argp_program_version = "selflock 0.1";
+const char *argp_program_bug_address =
+        "https://github.com/linux-lock/bpflock";
+const char argp_program_doc[] =
+"bpflock selflock - protect bpflock agent, its bpf pins and configuration.\n"
+"\n"
+"USAGE: selflock [--help] [-p PROFILE] [-b CMD] [--pid PID]\n"
+"\n"
+"EXAMPLES:\n"
+"  # Allow profile: operations on bpflock are allowed.\n"
+"  selflock --profile=allow\n\n"
+"  # Baseline profile: killing or tracing bpflock and removing its pins or\n"
+"  # configuration is allowed only for tasks in initial pid namespace.\n"
+"  selflock --profile=baseline\n\n"
+"  # Restricted profile: deny killing or tracing bpflock and removing its\n"
+"  # pins or configuration for all except bpflock itself.\n"
+"  selflock --profile=restricted --pid=1234\n";
+
+static const struct argp_option opts[] = {
+        { "profile", 'p', "PROFILE", 0, "Profile to apply, one of the following: allow, baseline or restricted. Default value is: allow." },
+        { "block", 'b', "CMD", 0, "Block operations, possible values: 'kill, ptrace, unlink, rename'. Default: all operations." },
+        { "pid", 'P', "PID", 0, "Process ID of the bpflock agent. Default value is the parent process." },
+        BPFLOCK_TRUST_ARGP_OPTIONS,
+        { NULL, 'h', NULL, OPTION_HIDDEN, "Show the full help" },
+        {},
+};
+
+static error_t parse_arg(int key, char *arg, struct argp_state *state)
+{
+        switch (key) {
+        case 'h':
+                argp_state_help(state, stderr, ARGP_HELP_STD_HELP);
+                break;
+        case 'b':
+                if (strlen(arg) + 1 > 128) {
+                        fprintf(stderr, "invaild -b|--block argument: too long\n");
+                        argp_usage(state);
+                }
+                opt.block_op = strndup(arg, strlen(arg));
+                break;
+        case 'p':
+                if (strlen(arg) + 1 > 64) {
+                        fprintf(stderr, "invaild -p|--profile argument: too long\n");
+                        argp_usage(state);
+                }
+                opt.perm = strndup(arg, strlen(arg));
+                break;
+        case 'P':
+                errno = 0;
+                opt.pid = strtol(arg, NULL, 10);
+                if (errno || opt.pid <= 0) {
+                        fprintf(stderr, "invalid -P|--pid argument\n");
+                        argp_usage(state);
+                }
+                break;
+        case BPFLOCK_TRUST_OPT:
+        case BPFLOCK_TRUST_NS_OPT:
+        case BPFLOCK_ALLOW_UID_OPT:
+        case BPFLOCK_ALLOW_GID_OPT:
+        case BPFLOCK_DENY_UID_OPT:
+        case BPFLOCK_DENY_GID_OPT:
+                /* Trust criteria are loaded by the bpflock agent */
+                break;
+        default:
+                return ARGP_ERR_UNKNOWN;
+        }
+
+        return 0;
+}
+
+/* Reads the start time of pid in clock ticks since boot */
+static int read_start_time(int pid, uint64_t *start)
+{
+        char path[64], buf[1024], *p;
+        unsigned long long v;
+        FILE *f;
+        size_t n;
+        int i;
+
+        snprintf(path, sizeof(path), "/proc/%d/stat", pid);
+        f = fopen(path, "r");
+        if (!f)
+                return -errno;
+
+        n = fread(buf, 1, sizeof(buf) - 1, f);
+        fclose(f);
+        buf[n] = '\0';
+
+        /* The command may contain spaces, fields start after its last ')' */
+        p = strrchr(buf, ')');
+        if (!p)
+                return -EINVAL;
+
+        /* starttime is the 22nd field, the 20th after the command */
+        for (i = 0; i < 20 && p; i++)
+                p = strchr(p + 1, ' ');
+        if (!p || sscanf(p + 1, "%llu", &v) != 1)
+                return -EINVAL;
+
+        *start = v;
+        return 0;
+}
+
+/* Setup the identity of the bpflock agent */
+static int setup_sl_agent_map(struct selflock_bpf *skel)
+{
+        struct bl_sl_agent agent = {};
+        uint32_t k = 0;
+        int f, err;
+
+        f = bpf_map__fd(skel->maps.selflock_agent_map);
+        if (f < 0) {
+                fprintf(stderr, "%s: error: failed to get bpf map fd: %d\n",
+                        LOG_BPFLOCK, f);
+                return f;
+        }
+
+        err = read_start_time(opt.pid, &agent.start_time);
+        if (err < 0) {
+                fprintf(stderr, "%s: error: failed to read start time of pid %d: %d\n",
+                        LOG_BPFLOCK, opt.pid, err);
+                return err;
+        }
+        agent.pid = opt.pid;
+
+        return bpf_map_update_elem(f, &k, &agent, BPF_ANY);
+}
+
+/* Setup bpf map options */
+static int setup_sl_opt_map(struct selflock_bpf *skel, int *fd)
+{
+        uint32_t perm_k = BPFLOCK_SL_PERM;
+        uint32_t op_k = BPFLOCK_SL_OP;
+        int f;
+
+        opt.perm_int = 0;
+        opt.block_op_int = 0;
+
+        f = bpf_map__fd(skel->maps.selflock_map);
+        if (f < 0) {
+                fprintf(stderr, "%s: error: failed to get bpf map fd: %d\n",
+                        LOG_BPFLOCK, f);
+                return f;
+        }
+
+        if (!opt.perm) {
+                opt.perm_int = BPFLOCK_P_ALLOW;
+        } else {
+                if (strncmp(opt.perm, "restricted", 10) == 0) {
+                        opt.perm_int = BPFLOCK_P_RESTRICTED;
+                } else if (strncmp(opt.perm, "baseline", 8) == 0) {
+                        opt.perm_int = BPFLOCK_P_BASELINE;
+                } else {
+                        opt.perm_int = BPFLOCK_P_ALLOW;
+                }
+        }
+
+        if (opt.block_op) {
+                if (strstr(opt.block_op, "kill") != NULL)
+                        opt.block_op_int |= BPFLOCK_SL_KILL;
+                if (strstr(opt.block_op, "ptrace") != NULL)
+                        opt.block_op_int |= BPFLOCK_SL_PTRACE;
+                if (strstr(opt.block_op, "unlink") != NULL)
+                        opt.block_op_int |= BPFLOCK_SL_UNLINK;
+                if (strstr(opt.block_op, "rename") != NULL)
+                        opt.block_op_int |= BPFLOCK_SL_RENAME;
+        }
+
+        /* Launched by the bpflock agent, protect our parent */
+        if (opt.pid <= 0)
+                opt.pid = getppid();
+
+        *fd = f;
+
+        bpf_map_update_elem(f, &perm_k, &opt.perm_int, BPF_ANY);
+        if (opt.block_op_int > 0)
+                bpf_map_update_elem(f, &op_k, &opt.block_op_int, BPF_ANY);
+
+        return 0;
+}
+
+int main(int argc, char **argv)
+{
+        static const struct argp argp = {
+                .options = opts,
+                .parser = parse_arg,
+                .doc = argp_program_doc,
+        };
+
+        struct selflock_bpf *skel = NULL;
+        struct bpf_link *link = NULL;
+        struct bpf_program *prog = NULL;
+        int selflock_map_fd = -1;
+        struct stat st;
+        char *buf = NULL;
+        int err, i, buflen = 512;
+
+        err = argp_parse(&argp, argc, argv, 0, NULL, NULL);
+        if (err)
+                return err;
+
+        err = is_lsmbpf_supported();
+        if (err) {
+                fprintf(stderr, "%s: error: failed to check LSM BPF support\n",
+                        LOG_BPFLOCK);
+                return err;
+        }
+
+        err = bump_memlock_rlimit();
+        if (err) {
+                fprintf(stderr, "%s: error: failed to increase rlimit: %s\n",
+                        LOG_BPFLOCK, strerror(errno));
+                return err;
+        }
+
+        err = stat(selflock_security_map.pin_path, &st);
+        if (err == 0) {
+                fprintf(stdout, "%s: %s already loaded nothing todo, please delete pinned file '%s' "
+                        "to be able to run it again.\n",
+                        LOG_BPFLOCK, argv[0], selflock_security_map.pin_path);
+                return -EALREADY;
+        }
+
+        buf = malloc(buflen);
+        if (!buf) {
+                fprintf(stderr, "%s: error: failed to allocate memory\n",
+                        LOG_BPFLOCK);
+                return -ENOMEM;
+        }
+
+        memset(buf, 0, buflen);
+
+        skel = selflock_bpf__open();
+        if (!skel) {
+                fprintf(stderr, "%s: error: failed to open BPF skelect\n",
+                        LOG_BPFLOCK);
+                err = -EINVAL;
+                goto cleanup;
+        }
+
+        err = selflock_bpf__load(skel);
+        if (err) {
+                fprintf(stderr, "%s: error: failed to load BPF skelect: %d\n",
+                        LOG_BPFLOCK, err);
+                goto cleanup;
+        }
+
+        err = setup_sl_opt_map(skel, &selflock_map_fd);
+        if (err < 0) {
+                fprintf(stderr, "%s: error: failed to setup bpf opt map: %d\n",
+                        LOG_BPFLOCK, err);
+                goto cleanup;
+        }
+
+        err = setup_sl_agent_map(skel);
+        if (err < 0) {
+                fprintf(stderr, "%s: error: failed to setup bpf agent map: %d\n",
+                        LOG_BPFLOCK, err);
+                goto cleanup;
+        }
+
+        mkdir(BPFLOCK_PIN_PATH, 0700);
+        mkdir(selflock_security_map.pin_path, 0700);
+
+        err = bpf_object__pin(skel->obj, selflock_security_map.pin_path);
+        if (err) {
+                libbpf_strerror(err, buf, buflen);
+                fprintf(stderr, "%s: %s: error: failed to pin obj into link '%s': %s\n",
+                        LOG_BPFLOCK, LOG_SELFLOCK, selflock_security_map.pin_path, buf);
+                goto cleanup;
+        }
+
+        i = 0;
+        bpf_object__for_each_program(prog, skel->obj) {
+                if (i >= sizeof(selflock_prog_links) / sizeof(bpflock_class_prog_link_t))
+                        break;
+
+                link = bpf_program__attach(prog);
+                err = libbpf_get_error(link);
+                if (err) {
+                        libbpf_strerror(err, buf, buflen);
+                        fprintf(stderr, "%s: %s: error: failed to attach BPF programs: %s\n",
+                                LOG_BPFLOCK, LOG_SELFLOCK, strerror(-err));
+                        goto cleanup;
+                }
+
+                err = bpf_link__pin(link, selflock_prog_links[i].link);
+                if (err) {
+                        libbpf_strerror(err, buf, buflen);
+                        fprintf(stderr, "%s: %s: error: failed to pin bpf obj into link '%s': %s\n",
+                                LOG_BPFLOCK, LOG_SELFLOCK, selflock_prog_links[i].link, buf);
+                        goto cleanup;
+                }
+
+                i++;
+        }
+
+        if (opt.perm_int == BPFLOCK_P_RESTRICTED) {
+                printf("%s: success: profile: restricted - bpflock pid %d, pins and configuration are now protected from all\n",
+                        LOG_BPFLOCK, opt.pid);
+        } else if (opt.perm_int == BPFLOCK_P_BASELINE) {
+                printf("%s: success: profile: baseline - bpflock pid %d, pins and configuration are now protected from tasks outside initial pid namespace\n",
+                        LOG_BPFLOCK, opt.pid);
+        } else {
+                printf("%s: success: profile : allow - operations on bpflock are allowed - delete pinned file '%s' to disable access logging\n",
+                        LOG_BPFLOCK, selflock_security_map.pin_path);
+        }
+
+cleanup:
+        if (link)
+                bpf_link__destroy(link);
+
+        if (skel)
+                selflock_bpf__destroy(skel);
+
+        free(buf);
+
+        return err != 0;
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ */
+
+#ifndef __BPFLOCK_SELFLOCK_H
+#define __BPFLOCK_SELFLOCK_H
+
+#include "bpflock_security_class.h"
+
+/* selflock security class */
+
+#define LOG_SELFLOCK "selflock"
+
+#define BPFLOCK_SL_PERM         1
+#define BPFLOCK_SL_OP           2
+#define BPFLOCK_SL_SEALED       4
+
+#define BPFLOCK_SL_KILL         (1 << 0)
+#define BPFLOCK_SL_PTRACE       (1 << 1)
+#define BPFLOCK_SL_UNLINK       (1 << 2)
+#define BPFLOCK_SL_RENAME       (1 << 3)
+
+#define BPFLOCK_SL_ALL_OPS      (BPFLOCK_SL_KILL | BPFLOCK_SL_PTRACE | \
+                                 BPFLOCK_SL_UNLINK | BPFLOCK_SL_RENAME)
+
+/* Maximum number of protected inodes */
+#define BPFLOCK_SL_MAX_INODES   4096
+
+/* Clock ticks of the start time in /proc/<pid>/stat */
+#define BPFLOCK_SL_USER_HZ      100
+
+/*
+ * Identity of the bpflock agent, it must match pkg/selflock. Pids are
+ * recycled so the agent is also identified by its start time in clock
+ * ticks since boot. A zero pid means no agent is running.
+ */
+struct bl_sl_agent {
+        uint32_t pid;
+        uint32_t pad;
+        uint64_t start_time;
+};
+
+struct bpflock_class_map selflock_security_map = {
+        "selflock",
+        "/sys/fs/bpf/bpflock/selflock",
+        { NULL },
+        { 0 }
+};
+
+struct bpflock_class_prog_link selflock_prog_links[] = {
+        {
+                "bpflock_selflock_kill",
+                "/sys/fs/bpf/bpflock/selflock/selflock_kill_link",
+        },
+        {
+                "bpflock_selflock_ptrace",
+                "/sys/fs/bpf/bpflock/selflock/selflock_ptrace_link",
+        },
+        {
+                "bpflock_selflock_unlink",
+                "/sys/fs/bpf/bpflock/selflock/selflock_unlink_link",
+        },
+        {
+                "bpflock_selflock_rmdir",
+                "/sys/fs/bpf/bpflock/selflock/selflock_rmdir_link",
+        },
+        {
+                "bpflock_selflock_rename",
+                "/sys/fs/bpf/bpflock/selflock/selflock_rename_link",
+        },
+        {
+                "bpflock_selflock_task_free",
+                "/sys/fs/bpf/bpflock/selflock/selflock_task_free_link",
+        },
+        {
+                "bpflock_selflock_bpf_map",
+                "/sys/fs/bpf/bpflock/selflock/selflock_bpf_map_link",
+        },
+};
+
+/* End of selflock security class */
+
+#endif /* __BPFLOCK_SELFLOCK_H */
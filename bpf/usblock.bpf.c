@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ */
+
+#include <vmlinux.h>
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_tracing.h>
+#include <bpf/bpf_core_read.h>
+#include <errno.h>
+#include "bpflock_bpf_defs.h"
+#include "bpflock_shared_defs.h"
+#include "usblock.h"
+
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, 4);
+        __type(key, uint32_t);
+        __type(value, uint32_t);
+} usblock_map SEC(".maps");
+
+/* Allowed devices, key is vendor << 16 | product */
+struct {
+        __uint(type, BPF_MAP_TYPE_HASH);
+        __uint(max_entries, BPFLOCK_USB_MAX_IDS);
+        __type(key, uint32_t);
+        __type(value, uint32_t);
+} usblock_ids_map SEC(".maps");
+
+/* Allowed device classes, indexed by class code */
+struct {
+        __uint(type, BPF_MAP_TYPE_ARRAY);
+        __uint(max_entries, USB_CLASS_MAX);
+        __type(key, uint32_t);
+        __type(value, uint32_t);
+} usblock_class_map SEC(".maps");
+
+static __always_inline int report(struct usb_device *udev, const int ret, int reason)
+{
+        uint64_t id;
+        uint32_t devid, class;
+        static struct event info;
+
+        id = bpf_get_current_pid_tgid();
+        info.pid = id >> 32;
+
+        bpf_get_current_comm(&info.comm, sizeof(info.comm));
+
+        /* vendor:product as one hex number, bpf_printk() takes three arguments */
+        devid = (uint32_t)BPF_CORE_READ(udev, descriptor.idVendor) << 16 |
+                BPF_CORE_READ(udev, descriptor.idProduct);
+        class = BPF_CORE_READ(udev, descriptor.bDeviceClass);
+
+        bpf_printk("bpflock bpf=usblock pid=%lu comm=%s event=usb device addition class=%02x\n",
+                   info.pid, info.comm, class);
+        bpf_printk("bpflock bpf=usblock pid=%lu event=usb device %08x addition status=%s\n",
+                   info.pid, devid, get_reason_str(ret, reason));
+
+        return ret;
+}
+
+static __always_inline bool is_class_allowed(uint32_t class)
+{
+        uint32_t *val;
+
+        val = bpf_map_lookup_elem(&usblock_class_map, &class);
+
+        return val && *val > 0;
+}
+
+/*
+ * Most devices report the device class 0 and declare their class per
+ * interface, they are allowed if the classes of all the interfaces of
+ * their first configuration are allowed.
+ */
+static __always_inline bool is_interfaces_allowed(struct usb_device *udev)
+{
+        struct usb_host_config *config;
+        struct usb_interface_cache *cache;
+        uint32_t class;
+        int i, n;
+
+        config = BPF_CORE_READ(udev, config);
+        if (!config)
+                return false;
+
+        n = BPF_CORE_READ(config, desc.bNumInterfaces);
+        if (n == 0 || n > USBLOCK_MAX_INTERFACES)
+                return false;
+
+        for (i = 0; i < USBLOCK_MAX_INTERFACES; i++) {
+                if (i >= n)
+                        break;
+                cache = BPF_CORE_READ(config, intf_cache[i]);
+                if (!cache)
+                        return false;
+                class = BPF_CORE_READ(cache, altsetting[0].desc.bInterfaceClass);
+                if (!is_class_allowed(class))
+                        return false;
+        }
+
+        return true;
+}
+
+static __always_inline bool is_allowed(struct usb_device *udev)
+{
+        uint32_t id, class;
+        uint32_t *val;
+
+        id = (uint32_t)BPF_CORE_READ(udev, descriptor.idVendor) << 16 |
+             BPF_CORE_READ(udev, descriptor.idProduct);
+        val = bpf_map_lookup_elem(&usblock_ids_map, &id);
+        if (val && *val > 0)
+                return true;
+
+        class = BPF_CORE_READ(udev, descriptor.bDeviceClass);
+        if (is_class_allowed(class))
+                return true;
+
+        return is_interfaces_allowed(udev);
+}
+
+/*
+ * New devices are not authorized by the kernel as the launcher disabled
+ * authorized_default on all buses. The decision is reported and allowed
+ * devices are authorized by the bpflock agent. The decision is taken on
+ * exit, once the configurations of the device have been read.
+ */
+SEC("fexit/usb_new_device")
+int BPF_PROG(usblock_new_device, struct usb_device *udev, int err)
+{
+        uint32_t k = BPFLOCK_USB_PERM;
+        uint32_t *val;
+
+        /* Root hubs are not affected by authorized_default */
+        if (!BPF_CORE_READ(udev, parent))
+                return 0;
+
+        val = bpf_map_lookup_elem(&usblock_map, &k);
+        if (!val || *val == 0 || *val == BPFLOCK_P_ALLOW)
+                return report(udev, 0, reason_allow);
+
+        if (*val == BPFLOCK_P_RESTRICTED)
+                return report(udev, -EPERM, reason_restricted);
+
+        if (is_allowed(udev))
+                return report(udev, 0, reason_baseline_allowed);
+
+        return report(udev, -EPERM, reason_baseline);
+}
+
+static const char _license[] SEC("license") = "GPL";
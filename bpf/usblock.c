@@ -0,0 +1,354 @@
+/* SPDX-License-Identifier: GPL-2.0 */
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ *
+ * Implements access restrictions on USB device additions.
+ */
+
+#include <argp.h>
+#include <bpf/bpf.h>
+#include <dirent.h>
+#include <errno.h>
+#include <fcntl.h>
+#include <stdlib.h>
+#include <string.h>
+#include <time.h>
+#include <sys/types.h>
+#include <sys/stat.h>
+#include <unistd.h>
+#include "bpflock_security_class.h"
+#include "bpflock_shared_defs.h"
+#include "trace_helpers.h"
+#include "bpflock_utils.h"
+#include "usblock.h"
+#include "usblock.skel.h"
+
+static struct options {
+        int perm_int;
+        char *perm;
+        char *allow;
+        char *allow_class;
+} opt = {};
+
+static const struct {
+        const char *name;
+        uint32_t class;
+} usb_classes[] = {
+        { "audio",      0x01 },
+        { "comm",       0x02 },
+        { "hid",        0x03 },
+        { "printer",    0x07 },
+        { "storage",    0x08 },
+        { "hub",        0x09 },
+        { "cdc-data",   0x0a },
+        { "video",      0x0e },
+        { "wireless",   0xe0 },
+        { "misc",       0xef },
+        { "vendor",     0xff },
+};
+
+const char *argp_program_version = "usblock 0.1";
+const char *argp_program_bug_address =
+        "https://github.com/linux-lock/bpflock";
+const char argp_program_doc[] =
+"bpflock usblock - restrict USB device additions.\n"
+"\n"
+"USAGE: usblock [--help] [-p PROFILE] [-a VENDOR:PRODUCT] [-c CLASS]\n"
+"\n"
+"EXAMPLES:\n"
+"  # Allow profile: new USB devices are allowed and reported.\n"
+"  usblock --profile=allow\n\n"
+"  # Baseline profile: only new USB hubs and keyboards of vendor 046d are allowed.\n"
+"  usblock --profile=baseline --allow=046d:c31c --allow-class=hub\n\n"
+"  # Restricted profile: deny all new USB devices.\n"
+"  usblock --profile=restricted\n";
+
+static const struct argp_option opts[] = {
+        { "profile", 'p', "PROFILE", 0, "Profile to apply, one of the following: allow, baseline or restricted. Default value is: allow." },
+        { "allow", 'a', "VENDOR:PRODUCT", 0, "Comma-separated list of allowed devices in baseline profile, as hexadecimal vendor:product IDs." },
+        { "allow-class", 'c', "CLASS", 0, "Comma-separated list of allowed device classes in baseline profile: 'audio, comm, hid, printer, storage, hub, cdc-data, video, wireless, misc, vendor' or hexadecimal class codes." },
+        { NULL, 'h', NULL, OPTION_HIDDEN, "Show the full help" },
+        {},
+};
+
+static error_t parse_arg(int key, char *arg, struct argp_state *state)
+{
+        switch (key) {
+        case 'h':
+                argp_state_help(state, stderr, ARGP_HELP_STD_HELP);
+                break;
+        case 'a':
+                if (strlen(arg) + 1 > 4096) {
+                        fprintf(stderr, "invaild -a|--allow argument: too long\n");
+                        argp_usage(state);
+                }
+                opt.allow = strndup(arg, strlen(arg));
+                break;
+        case 'c':
+                if (strlen(arg) + 1 > 512) {
+                        fprintf(stderr, "invaild -c|--allow-class argument: too long\n");
+                        argp_usage(state);
+                }
+                opt.allow_class = strndup(arg, strlen(arg));
+                break;
+        case 'p':
+                if (strlen(arg) + 1 > 64) {
+                        fprintf(stderr, "invaild -p|--profile argument: too long\n");
+                        argp_usage(state);
+                }
+                opt.perm = strndup(arg, strlen(arg));
+                break;
+        default:
+                return ARGP_ERR_UNKNOWN;
+        }
+
+        return 0;
+}
+
+static int parse_usb_class(const char *s, uint32_t *class)
+{
+        unsigned long v;
+        char *end;
+        int i;
+
+        for (i = 0; i < sizeof(usb_classes) / sizeof(usb_classes[0]); i++) {
+                if (strcmp(s, usb_classes[i].name) == 0) {
+                        *class = usb_classes[i].class;
+                        return 0;
+                }
+        }
+
+        errno = 0;
+        v = strtoul(s, &end, 16);
+        if (errno || *end != '\0' || v >= USB_CLASS_MAX)
+                return -EINVAL;
+
+        *class = v;
+        return 0;
+}
+
+/* Setup bpf map options */
+static int setup_usb_opt_map(struct usblock_bpf *skel)
+{
+        uint32_t perm_k = BPFLOCK_USB_PERM;
+        uint32_t allowed = 1, id, class;
+        unsigned int vendor, product;
+        char *tok, *saveptr = NULL;
+        int f, ids_fd, class_fd;
+
+        f = bpf_map__fd(skel->maps.usblock_map);
+        ids_fd = bpf_map__fd(skel->maps.usblock_ids_map);
+        class_fd = bpf_map__fd(skel->maps.usblock_class_map);
+        if (f < 0 || ids_fd < 0 || class_fd < 0) {
+                fprintf(stderr, "%s: error: failed to get bpf map fd\n",
+                        LOG_BPFLOCK);
+                return -EINVAL;
+        }
+
+        opt.perm_int = BPFLOCK_P_ALLOW;
+        if (opt.perm) {
+                if (strncmp(opt.perm, "restricted", 10) == 0)
+                        opt.perm_int = BPFLOCK_P_RESTRICTED;
+                else if (strncmp(opt.perm, "baseline", 8) == 0)
+                        opt.perm_int = BPFLOCK_P_BASELINE;
+        }
+
+        if (opt.allow) {
+                for (tok = strtok_r(opt.allow, ",", &saveptr); tok;
+                     tok = strtok_r(NULL, ",", &saveptr)) {
+                        if (sscanf(tok, "%4x:%4x", &vendor, &product) != 2) {
+                                fprintf(stderr, "%s: %s: error: invalid device '%s'\n",
+                                        LOG_BPFLOCK, LOG_USBLOCK, tok);
+                                return -EINVAL;
+                        }
+                        id = vendor << 16 | product;
+                        bpf_map_update_elem(ids_fd, &id, &allowed, BPF_ANY);
+                }
+        }
+
+        if (opt.allow_class) {
+                saveptr = NULL;
+                for (tok = strtok_r(opt.allow_class, ",", &saveptr); tok;
+                     tok = strtok_r(NULL, ",", &saveptr)) {
+                        if (parse_usb_class(tok, &class) < 0) {
+                                fprintf(stderr, "%s: %s: error: invalid device class '%s'\n",
+                                        LOG_BPFLOCK, LOG_USBLOCK, tok);
+                                return -EINVAL;
+                        }
+                        bpf_map_update_elem(class_fd, &class, &allowed, BPF_ANY);
+                }
+        }
+
+        bpf_map_update_elem(f, &perm_k, &opt.perm_int, BPF_ANY);
+
+        return 0;
+}
+
+/*
+ * Stop the kernel from authorizing new devices on all USB buses. Allowed
+ * devices are authorized by the bpflock agent.
+ */
+static int disable_authorized_default(void)
+{
+        char path[PATH_MAX];
+        struct dirent *d;
+        DIR *dir;
+        int fd, err = 0;
+
+        dir = opendir(USBLOCK_BUS_DEVICES);
+        if (!dir)
+                return -errno;
+
+        while ((d = readdir(dir)) != NULL) {
+                if (strncmp(d->d_name, "usb", 3) != 0)
+                        continue;
+
+                snprintf(path, sizeof(path), "%s/%s/authorized_default",
+                         USBLOCK_BUS_DEVICES, d->d_name);
+                fd = open(path, O_WRONLY | O_CLOEXEC);
+                if (fd < 0) {
+                        err = -errno;
+                        continue;
+                }
+                if (write(fd, "0", 1) != 1)
+                        err = -errno;
+                close(fd);
+        }
+
+        closedir(dir);
+        return err;
+}
+
+int main(int argc, char **argv)
+{
+        static const struct argp argp = {
+                .options = opts,
+                .parser = parse_arg,
+                .doc = argp_program_doc,
+        };
+
+        struct usblock_bpf *skel = NULL;
+        struct bpf_link *link = NULL;
+        struct bpf_program *prog = NULL;
+        struct stat st;
+        char *buf = NULL;
+        int err, i, buflen = 512;
+
+        err = argp_parse(&argp, argc, argv, 0, NULL, NULL);
+        if (err)
+                return err;
+
+        err = bump_memlock_rlimit();
+        if (err) {
+                fprintf(stderr, "%s: error: failed to increase rlimit: %s\n",
+                        LOG_BPFLOCK, strerror(errno));
+                return err;
+        }
+
+        err = stat(usb_security_map.pin_path, &st);
+        if (err == 0) {
+                fprintf(stdout, "%s: %s already loaded nothing todo, please delete pinned file '%s' "
+                        "to be able to run it again.\n",
+                        LOG_BPFLOCK, argv[0], usb_security_map.pin_path);
+                return -EALREADY;
+        }
+
+        buf = malloc(buflen);
+        if (!buf) {
+                fprintf(stderr, "%s: error: failed to allocate memory\n",
+                        LOG_BPFLOCK);
+                return -ENOMEM;
+        }
+
+        memset(buf, 0, buflen);
+
+        skel = usblock_bpf__open();
+        if (!skel) {
+                fprintf(stderr, "%s: error: failed to open BPF skelect\n",
+                        LOG_BPFLOCK);
+                err = -EINVAL;
+                goto cleanup;
+        }
+
+        err = usblock_bpf__load(skel);
+        if (err) {
+                fprintf(stderr, "%s: error: failed to load BPF skelect: %d\n",
+                        LOG_BPFLOCK, err);
+                goto cleanup;
+        }
+
+        err = setup_usb_opt_map(skel);
+        if (err < 0) {
+                fprintf(stderr, "%s: error: failed to setup bpf opt map: %d\n",
+                        LOG_BPFLOCK, err);
+                goto cleanup;
+        }
+
+        mkdir(BPFLOCK_PIN_PATH, 0700);
+        mkdir(usb_security_map.pin_path, 0700);
+
+        err = bpf_object__pin(skel->obj, usb_security_map.pin_path);
+        if (err) {
+                libbpf_strerror(err, buf, buflen);
+                fprintf(stderr, "%s: %s: error: failed to pin obj into link '%s': %s\n",
+                        LOG_BPFLOCK, LOG_USBLOCK, usb_security_map.pin_path, buf);
+                goto cleanup;
+        }
+
+        i = 0;
+        bpf_object__for_each_program(prog, skel->obj) {
+                if (i >= sizeof(usb_prog_links) / sizeof(bpflock_class_prog_link_t))
+                        break;
+
+                link = bpf_program__attach(prog);
+                err = libbpf_get_error(link);
+                if (err) {
+                        libbpf_strerror(err, buf, buflen);
+                        fprintf(stderr, "%s: %s: error: failed to attach BPF programs: %s\n",
+                                LOG_BPFLOCK, LOG_USBLOCK, strerror(-err));
+                        goto cleanup;
+                }
+
+                err = bpf_link__pin(link, usb_prog_links[i].link);
+                if (err) {
+                        libbpf_strerror(err, buf, buflen);
+                        fprintf(stderr, "%s: %s: error: failed to pin bpf obj into link '%s': %s\n",
+                                LOG_BPFLOCK, LOG_USBLOCK, usb_prog_links[i].link, buf);
+                        goto cleanup;
+                }
+
+                i++;
+        }
+
+        if (opt.perm_int != BPFLOCK_P_ALLOW) {
+                err = disable_authorized_default();
+                if (err < 0) {
+                        fprintf(stderr, "%s: %s: error: failed to disable authorized_default of USB buses: %s\n",
+                                LOG_BPFLOCK, LOG_USBLOCK, strerror(-err));
+                        goto cleanup;
+                }
+        }
+
+        if (opt.perm_int == BPFLOCK_P_RESTRICTED) {
+                printf("%s: success: profile: restricted - new USB devices are now blocked - delete pinned file '%s' to re-enable\n",
+                        LOG_BPFLOCK, usb_security_map.pin_path);
+        } else if (opt.perm_int == BPFLOCK_P_BASELINE) {
+                printf("%s: success: profile: baseline - only allowed new USB devices are authorized - delete pinned file '%s' to re-enable\n",
+                        LOG_BPFLOCK, usb_security_map.pin_path);
+        } else {
+                printf("%s: success: profile : allow - new USB devices are allowed - delete pinned file '%s' to disable access logging\n",
+                        LOG_BPFLOCK, usb_security_map.pin_path);
+        }
+
+cleanup:
+        if (link)
+                bpf_link__destroy(link);
+
+        if (skel)
+                usblock_bpf__destroy(skel);
+
+        free(buf);
+
+        return err != 0;
+}
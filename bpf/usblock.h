@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: GPL-2.0
+
+/*
+ * Copyright (C) 2022 Djalal Harouni
+ */
+
+#ifndef __BPFLOCK_USBLOCK_H
+#define __BPFLOCK_USBLOCK_H
+
+#include "bpflock_security_class.h"
+
+/* usb security class */
+
+#define LOG_USBLOCK "usblock"
+
+#define BPFLOCK_USB_PERM        1
+
+/* Maximum number of allowed vendor:product pairs */
+#define BPFLOCK_USB_MAX_IDS     256
+
+#define USB_CLASS_MAX           256
+
+/* Maximum number of interfaces of allowed devices */
+#define USBLOCK_MAX_INTERFACES  32
+
+#define USBLOCK_BUS_DEVICES     "/sys/bus/usb/devices"
+
+struct bpflock_class_map usb_security_map = {
+        "usb",
+        "/sys/fs/bpf/bpflock/usblock",
+        { NULL },
+        { 0 }
+};
+
+struct bpflock_class_prog_link usb_prog_links[] = {
+        {
+                "bpflock_usblock_new_device",
+                "/sys/fs/bpf/bpflock/usblock/usblock_new_device_link",
+        },
+};
+
+/* End of usb security class */
+
+#endif /* __BPFLOCK_USBLOCK_H */
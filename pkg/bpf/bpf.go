@@ -10,12 +10,16 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/linux-lock/bpflock/api/v1/models"
 	"github.com/linux-lock/bpflock/pkg/command/exec"
 	"github.com/linux-lock/bpflock/pkg/defaults"
 	"github.com/linux-lock/bpflock/pkg/logging"
 	"github.com/linux-lock/bpflock/pkg/logging/logfields"
 	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/spanstat"
 
 	"github.com/sirupsen/logrus"
 )
@@ -57,34 +61,316 @@ func bpftoolGetProgID(progName string) (string, error) {
 	return progID[0], nil
 }
 
+// ApplyError is returned when a bpf program of a configuration fails to
+// start, Programs holds the outcome of each bpf program.
+type ApplyError struct {
+	Program  string
+	Err      error
+	Programs []*models.ProgramResult
+}
+
+func (e *ApplyError) Error() string {
+	return fmt.Sprintf("bpf program %s failed to start: %v", e.Program, e.Err)
+}
+
+func (e *ApplyError) Unwrap() error {
+	return e.Err
+}
+
 // BpfLsmEnable will execute all programs according to configuration
 // and corresponding bpf programs will be pinned automatically
 func BpfLsmEnable() error {
-	spec := option.Config.BpfMeta.Bpfspec
+	return startPrograms(nil, nil, false)
+}
 
-	i := 0
-	for _, p := range spec.Programs {
-		launcher := filepath.Join(option.Config.BpfDir, p.Command)
-		_, err := os.Stat(launcher)
+// BpfLsmRestore adopts the pinned bpf programs of a previous bpflock
+// instance that match the configuration, and replaces only the others.
+// Protections that did not change are never removed.
+func BpfLsmRestore() error {
+	return restorePrograms(false)
+}
+
+// BpfLsmApply replaces the running bpf programs whose configuration
+// changed like BpfLsmRestore, but stops at the first bpf program that
+// fails to start and returns an *ApplyError.
+func BpfLsmApply() error {
+	return restorePrograms(true)
+}
+
+func restorePrograms(strict bool) error {
+	spec := option.Config.BpfPrograms()
+
+	prev, err := ReadState(stateFile())
+	if err != nil {
+		log.WithError(err).Warn("Unable to read state of bpf programs, replacing all of them")
+		prev = &State{Programs: make(map[string]*ProgramState)}
+	}
+
+	digests := make(map[string]string, len(spec))
+	for _, p := range spec {
+		digests[p.Name] = ProgramDigest(p, filepath.Join(option.Config.BpfDir, p.Command))
+	}
+
+	current := make(map[string]map[string]uint32)
+	pinned := make(map[string]string)
+	files, _ := ioutil.ReadDir(MapPrefixPath())
+	for _, f := range files {
+		if !f.IsDir() || strings.HasPrefix(f.Name(), "..") {
+			continue
+		}
+		dir := filepath.Join(MapPrefixPath(), f.Name())
+		pins, err := readPins(dir)
 		if err != nil {
-			log.WithError(err).Warnf("run bpf program '%s' failed: unable to find command launcher '%q'", p.Name, launcher)
+			log.WithError(err).Warnf("Unable to read pins of bpf-program=%s", f.Name())
+		}
+		current[f.Name()] = pins
+		if _, ok := pins[DigestPin]; ok {
+			if digest, err := readDigest(dir); err == nil {
+				pinned[f.Name()] = digest
+			} else {
+				log.WithError(err).Warnf("Unable to read digest of bpf-program=%s", f.Name())
+			}
+		}
+	}
+
+	adopt, remove := planRestore(spec, digests, pinned, prev, current)
+	for _, name := range remove {
+		bpftoolUnload(name)
+	}
+
+	return startPrograms(adopt, prev, strict)
+}
+
+// BpfLsmAdopt adopts the pinned bpf programs in adopt as they are, even if
+// their configuration changed, and starts the other ones. It is used once
+// the policy is sealed, when running programs can not be replaced.
+func BpfLsmAdopt(adopt map[string]bool) error {
+	prev, err := ReadState(stateFile())
+	if err != nil {
+		log.WithError(err).Warn("Unable to read state of bpf programs")
+		prev = &State{Programs: make(map[string]*ProgramState)}
+	}
+
+	for _, p := range option.Config.BpfPrograms() {
+		if !adopt[p.Name] || prev.Programs[p.Name] != nil {
 			continue
 		}
-		_, err = exec.WithTimeout(defaults.ShortExecTimeout, launcher, p.Args...).CombinedOutput(log, true)
+		pins, err := readPins(filepath.Join(MapPrefixPath(), p.Name))
 		if err != nil {
-			// Let's not fail execution but report it
-			log.WithError(err).Warnf("run bpf program '%s' with '%q' failed: %v", p.Name, launcher, err)
+			log.WithError(err).Warnf("Unable to read pins of bpf-program=%s", p.Name)
+		}
+		prev.Programs[p.Name] = &ProgramState{
+			Name:   p.Name,
+			Digest: ProgramDigest(p, filepath.Join(option.Config.BpfDir, p.Command)),
+			Pins:   pins,
+		}
+	}
+
+	return startPrograms(adopt, prev, false)
+}
+
+// exitOnFailure returns true if the bpflock daemon must not run without p.
+func exitOnFailure(p *models.BpfProgram) bool {
+	switch option.ProgramOnFailure(p) {
+	case models.BpfProgramOnFailureExit:
+		return true
+	case models.BpfProgramOnFailureRetry:
+		return p.Required
+	}
+	return false
+}
+
+// programOutcome is the outcome of starting a bpf program.
+type programOutcome struct {
+	result *models.ProgramResult
+	state  *ProgramState
+
+	// err is set if the programs that follow must not be started
+	err error
+}
+
+// startProgram executes the launcher of p unless it is adopted or
+// disabled. If strict, any failure is an error.
+func startProgram(p *models.BpfProgram, disabled map[string]string, adopted map[string]bool, prev *State, strict bool) programOutcome {
+	launcher := filepath.Join(option.Config.BpfDir, p.Command)
+	if reason, ok := disabled[p.Name]; ok {
+		log.Warnf("Not starting bpf program %s: %s", p.Name, reason)
+		o := programOutcome{result: &models.ProgramResult{
+			Name:   p.Name,
+			Result: models.ProgramResultResultDisabled,
+			Error:  reason,
+		}}
+		if p.Required && (strict || exitOnFailure(p)) {
+			o.err = fmt.Errorf("required program is disabled: %s", reason)
+		}
+		return o
+	}
+	if adopted[p.Name] {
+		log.Infof("Adopted pinned bpf program %s: %s", p.Name, p.Description)
+		return programOutcome{
+			result: &models.ProgramResult{Name: p.Name, Result: models.ProgramResultResultAdopted},
+			state:  prev.Programs[p.Name],
+		}
+	}
+
+	fail := func(err error) programOutcome {
+		o := programOutcome{result: &models.ProgramResult{
+			Name:   p.Name,
+			Result: models.ProgramResultResultFailed,
+			Error:  err.Error(),
+		}}
+		if strict || exitOnFailure(p) {
+			o.err = err
+		}
+		return o
+	}
+
+	_, err := os.Stat(launcher)
+	if err != nil {
+		log.WithError(err).Warnf("run bpf program '%s' failed: unable to find command launcher '%q'", p.Name, launcher)
+		return fail(fmt.Errorf("unable to find command launcher %q", launcher))
+	}
+	_, err = exec.WithTimeout(option.ProgramTimeout(p), launcher, p.Args...).CombinedOutput(log, true)
+	if err != nil {
+		// Let's not fail execution but report it
+		log.WithError(err).Warnf("run bpf program '%s' with '%q' failed: %v", p.Name, launcher, err)
+		return fail(err)
+	}
+
+	dir := filepath.Join(MapPrefixPath(), p.Name)
+	digest := ProgramDigest(p, launcher)
+	if err := pinDigest(dir, digest); err != nil {
+		// The recorded state still holds it
+		log.WithError(err).Warnf("Unable to pin digest of bpf-program=%s", p.Name)
+	}
+
+	pins, err := readPins(dir)
+	if err != nil && (strict || exitOnFailure(p)) {
+		// Nothing was pinned, the program is not attached
+		log.WithError(err).Warnf("run bpf program '%s' failed: unable to read pins", p.Name)
+		return fail(fmt.Errorf("unable to read pins: %w", err))
+	}
+
+	log.WithFields(logrus.Fields{
+		"launcher": launcher,
+		"args":     p.Args,
+	}).Infof("Started bpf program %s: %s", p.Name, p.Description)
+	o := programOutcome{result: &models.ProgramResult{Name: p.Name, Result: models.ProgramResultResultStarted}}
+
+	if err != nil {
+		log.WithError(err).Warnf("Unable to read pins of bpf-program=%s", p.Name)
+		return o
+	}
+	o.state = &ProgramState{
+		Name:   p.Name,
+		Digest: digest,
+		Pins:   pins,
+	}
+	return o
+}
+
+// retryProgram starts p again with backoff according to its on-failure
+// policy, as long as it fails to start. o is the outcome of the first try.
+func retryProgram(p *models.BpfProgram, o programOutcome, disabled map[string]string, adopted map[string]bool, prev *State, strict bool) programOutcome {
+	retries := option.ProgramRetries(p)
+	backoff := option.ProgramRetryBackoff(p)
+	for i := 0; i < retries && o.result.Result == models.ProgramResultResultFailed; i++ {
+		log.Warnf("run bpf program '%s' failed, retrying in %s (%d/%d)", p.Name, backoff, i+1, retries)
+		bpftoolUnload(p.Name)
+		time.Sleep(backoff)
+		backoff *= 2
+		o = startProgram(p, disabled, adopted, prev, strict)
+	}
+	return o
+}
+
+// startPrograms executes the launchers of all programs that are not in
+// adopted, and records the state of all running programs. Programs are
+// started by stages of their dependencies, the programs of a stage
+// concurrently, failed programs are retried one by one once their stage
+// is done. If strict, it stops at the first stage where a program
+// fails to start, otherwise only at the first one that the bpflock daemon
+// must not run without.
+func startPrograms(adopted map[string]bool, prev *State, strict bool) error {
+	meta, disabled := option.Config.GetBpfPrograms()
+	spec := meta.Bpfspec
+	stages, err := option.ProgramStages(spec.Programs)
+	if err != nil {
+		return err
+	}
+
+	state := &State{Programs: make(map[string]*ProgramState)}
+	results := make([]*models.ProgramResult, 0, len(spec.Programs))
+	var failed *ApplyError
+
+	i := 0
+	total := spanstat.Start()
+	for n, stage := range stages {
+		if failed != nil {
+			for _, p := range stage {
+				results = append(results, &models.ProgramResult{Name: p.Name, Result: models.ProgramResultResultSkipped})
+			}
 			continue
 		}
 
+		span := spanstat.Start()
+		outcomes := make([]programOutcome, len(stage))
+		var wg sync.WaitGroup
+		for j, p := range stage {
+			wg.Add(1)
+			go func(j int, p *models.BpfProgram) {
+				defer wg.Done()
+				outcomes[j] = startProgram(p, disabled, adopted, prev, strict)
+			}(j, p)
+		}
+		wg.Wait()
+
+		for j, p := range stage {
+			outcomes[j] = retryProgram(p, outcomes[j], disabled, adopted, prev, strict)
+		}
+
+		names := make([]string, 0, len(stage))
+		success := true
+		for j, o := range outcomes {
+			p := stage[j]
+			names = append(names, p.Name)
+			results = append(results, o.result)
+			switch o.result.Result {
+			case models.ProgramResultResultAdopted, models.ProgramResultResultStarted:
+				i++
+			case models.ProgramResultResultFailed:
+				success = false
+			}
+			if o.state != nil {
+				state.Programs[p.Name] = o.state
+			}
+			if o.err != nil && failed == nil {
+				failed = &ApplyError{Program: p.Name, Err: o.err}
+			}
+		}
+		span.End(success)
 		log.WithFields(logrus.Fields{
-			"launcher": launcher,
-			"args":     p.Args,
-		}).Infof("Started bpf program %s: %s", p.Name, p.Description)
-		i++
+			"stage":    n,
+			"programs": strings.Join(names, ","),
+			"duration": span.Total(),
+		}).Info("Started bpf programs stage")
+	}
+	total.End(failed == nil)
+	log.WithField("duration", total.Total()).Infof("Started bpf programs in %d stages", len(stages))
+
+	// The state of a partial start is not stored, the programs that
+	// were not reached could not be adopted anymore
+	if failed != nil {
+		log.Warn("Not storing state of bpf programs, they failed to start")
+		failed.Programs = results
+		return failed
+	}
+
+	if err := WriteState(stateFile(), state); err != nil {
+		log.WithError(err).Warn("Unable to store state of bpf programs")
 	}
 
-	if i == 0 {
+	if i == 0 && len(spec.Programs) > len(disabled) {
 		return fmt.Errorf("unable to start bpf programs: all failed")
 	}
 
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package bpf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/option"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *StateSuite) TestStartProgramsStrict(c *C) {
+	dir := c.MkDir()
+	c.Assert(os.WriteFile(filepath.Join(dir, "kmodlock"), []byte("#!/bin/sh\necho failed to attach >&2\nexit 1\n"), 0700), IsNil)
+
+	oldMeta, oldDisabled := option.Config.BpfMeta, option.Config.DisabledBpfProgs
+	oldBpfDir, oldStateDir := option.Config.BpfDir, option.Config.StateDir
+	defer func() {
+		option.Config.BpfMeta, option.Config.DisabledBpfProgs = oldMeta, oldDisabled
+		option.Config.BpfDir, option.Config.StateDir = oldBpfDir, oldStateDir
+	}()
+
+	option.Config.BpfDir = dir
+	option.Config.StateDir = c.MkDir()
+	option.Config.DisabledBpfProgs = map[string]string{"usblock": "not supported"}
+	option.Config.BpfMeta = &models.BpfMeta{
+		Bpfspec: &models.BpfSpec{
+			Programs: []*models.BpfProgram{
+				{Name: "usblock", Command: "usblock"},
+				{Name: "kmodlock", Command: "kmodlock"},
+				{Name: "bpfrestrict", Command: "bpfrestrict", After: []string{option.AllPrograms}},
+			},
+		},
+	}
+
+	err := startPrograms(nil, nil, true)
+	var ae *ApplyError
+	c.Assert(errors.As(err, &ae), Equals, true)
+	c.Assert(ae.Program, Equals, "kmodlock")
+	c.Assert(ae.Programs, HasLen, 3)
+	c.Assert(ae.Programs[0].Result, Equals, models.ProgramResultResultDisabled)
+	c.Assert(ae.Programs[0].Error, Equals, "not supported")
+	c.Assert(ae.Programs[1].Result, Equals, models.ProgramResultResultFailed)
+	c.Assert(ae.Programs[1].Error, Not(Equals), "")
+	c.Assert(ae.Programs[2].Result, Equals, models.ProgramResultResultSkipped)
+
+	// Failures are only reported once all programs failed
+	err = startPrograms(nil, nil, false)
+	c.Assert(err, NotNil)
+	c.Assert(errors.As(err, &ae), Equals, false)
+
+	// Unless the bpflock daemon must not run without them
+	option.Config.BpfMeta.Bpfspec.Programs[0].Required = true
+	err = startPrograms(nil, nil, false)
+	c.Assert(errors.As(err, &ae), Equals, true)
+	c.Assert(ae.Program, Equals, "usblock")
+	option.Config.BpfMeta.Bpfspec.Programs[0].Required = false
+	option.Config.BpfMeta.Bpfspec.Programs[0].OnFailure = models.BpfProgramOnFailureDegrade
+	c.Assert(errors.As(startPrograms(nil, nil, false), &ae), Equals, false)
+}
+
+func (s *StateSuite) TestStartProgramsRetry(c *C) {
+	dir := c.MkDir()
+	count := filepath.Join(dir, "count")
+	launcher := "#!/bin/sh\necho run >> " + count + "\nexit 1\n"
+	c.Assert(os.WriteFile(filepath.Join(dir, "kmodlock"), []byte(launcher), 0700), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dir, "kimglock"), []byte("#!/bin/sh\nexit 1\n"), 0700), IsNil)
+
+	oldMeta, oldDisabled := option.Config.BpfMeta, option.Config.DisabledBpfProgs
+	oldBpfDir, oldStateDir, oldMapRoot := option.Config.BpfDir, option.Config.StateDir, mapRoot
+	defer func() {
+		option.Config.BpfMeta, option.Config.DisabledBpfProgs = oldMeta, oldDisabled
+		option.Config.BpfDir, option.Config.StateDir, mapRoot = oldBpfDir, oldStateDir, oldMapRoot
+	}()
+
+	option.Config.BpfDir = dir
+	option.Config.StateDir = c.MkDir()
+	option.Config.DisabledBpfProgs = nil
+	mapRoot = c.MkDir()
+	option.Config.BpfMeta = &models.BpfMeta{
+		Bpfspec: &models.BpfSpec{
+			Programs: []*models.BpfProgram{
+				{Name: "kimglock", Command: "kimglock"},
+				{
+					Name:         "kmodlock",
+					Command:      "kmodlock",
+					Required:     true,
+					OnFailure:    models.BpfProgramOnFailureRetry,
+					Retries:      2,
+					RetryBackoff: "1ms",
+					Timeout:      "5s",
+				},
+			},
+		},
+	}
+
+	err := startPrograms(nil, nil, false)
+	var ae *ApplyError
+	c.Assert(errors.As(err, &ae), Equals, true)
+	c.Assert(ae.Program, Equals, "kmodlock")
+	c.Assert(ae.Programs[0].Result, Equals, models.ProgramResultResultFailed)
+	c.Assert(ae.Programs[1].Result, Equals, models.ProgramResultResultFailed)
+
+	runs, err := os.ReadFile(count)
+	c.Assert(err, IsNil)
+	c.Assert(string(runs), Equals, "run\nrun\nrun\n")
+}
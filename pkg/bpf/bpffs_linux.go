@@ -252,3 +252,9 @@ func CheckOrMountFS() {
 		}
 	})
 }
+
+// RecoverFS checks the BPF filesystem again and mounts it if it was
+// unmounted since CheckOrMountFS.
+func RecoverFS() error {
+	return checkOrMountFS()
+}
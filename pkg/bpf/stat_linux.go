@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build linux
+// +build linux
+
+package bpf
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// minorBits is the number of bits of the minor in kernel dev_t
+const minorBits = 20
+
+// Stat identifies a file by the device and inode numbers that bpf programs
+// read from kernel inodes, it matches struct bl_stat of
+// bpf/bpflock_bpf_defs.h where Dev is the device number as encoded inside
+// the kernel.
+type Stat struct {
+	Dev uint64
+	Ino uint64
+}
+
+// KernelDev converts a device number as returned by stat(2) into the
+// kernel internal encoding.
+func KernelDev(dev uint64) uint64 {
+	return uint64(unix.Major(dev))<<minorBits | uint64(unix.Minor(dev))
+}
+
+// NewStat returns the Stat of the file of st.
+func NewStat(st *syscall.Stat_t) Stat {
+	return Stat{Dev: KernelDev(uint64(st.Dev)), Ino: st.Ino}
+}
@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package bpf
+
+import (
+	"golang.org/x/sys/unix"
+	. "gopkg.in/check.v1"
+)
+
+type StatSuite struct{}
+
+var _ = Suite(&StatSuite{})
+
+func (s *StatSuite) TestKernelDev(c *C) {
+	c.Assert(KernelDev(unix.Mkdev(8, 1)), Equals, uint64(8<<20|1))
+	c.Assert(KernelDev(unix.Mkdev(259, 300)), Equals, uint64(259<<20|300))
+	c.Assert(KernelDev(0), Equals, uint64(0))
+}
@@ -0,0 +1,250 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package bpf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/option"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// StateFile is the file inside the state directory that records the
+	// started bpf programs, the digest of their configuration and their pins.
+	StateFile = "bpf-programs.json"
+
+	// DigestPin is the frozen map pinned inside the pin directory of a
+	// started bpf program that holds the digest of its configuration.
+	DigestPin = "bpflock_digest"
+)
+
+// replaced in tests
+var getObjInfo = GetObjInfo
+
+// ProgramState is the recorded state of a started bpf program.
+type ProgramState struct {
+	Name string `json:"name"`
+
+	// Digest of the configuration and of the launcher of the program
+	Digest string `json:"digest"`
+
+	// Pins maps the pinned objects relative to the program pin directory
+	// to their IDs, to detect pins that were replaced.
+	Pins map[string]uint32 `json:"pins"`
+}
+
+// State is the recorded state of all bpf programs started by bpflock.
+type State struct {
+	Programs map[string]*ProgramState `json:"programs"`
+}
+
+func stateFile() string {
+	return filepath.Join(option.Config.StateDir, StateFile)
+}
+
+// ReadState reads the state of bpf programs from path. A missing file
+// returns an empty state.
+func ReadState(path string) (*State, error) {
+	s := &State{Programs: make(map[string]*ProgramState)}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	if s.Programs == nil {
+		s.Programs = make(map[string]*ProgramState)
+	}
+	return s, nil
+}
+
+// WriteState atomically writes the state of bpf programs to path.
+func WriteState(path string, s *State) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ProgramDigest returns the digest of the configuration of the bpf program
+// p and of its launcher, so an upgraded launcher replaces the program.
+func ProgramDigest(p *models.BpfProgram, launcher string) string {
+	h := sha256.New()
+	cfg, _ := json.Marshal(struct {
+		Name    string   `json:"name"`
+		Command string   `json:"command"`
+		Args    []string `json:"args"`
+	}{p.Name, p.Command, p.Args})
+	h.Write(cfg)
+
+	if f, err := os.Open(launcher); err == nil {
+		io.Copy(h, f)
+		f.Close()
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// pinDigest pins the digest of a started bpf program inside its pin
+// directory dir, so it stays with the pins it describes.
+func pinDigest(dir string, digest string) error {
+	value, err := hex.DecodeString(digest)
+	if err != nil {
+		return err
+	}
+	fd, err := MapCreate(DigestPin, unix.BPF_MAP_TYPE_ARRAY, 4, uint32(len(value)), 1)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	key := uint32(0)
+	if err := MapUpdateElem(fd, unsafe.Pointer(&key), unsafe.Pointer(&value[0]), unix.BPF_ANY); err != nil {
+		return fmt.Errorf("unable to store digest: %w", err)
+	}
+	if err := MapFreeze(fd); err != nil {
+		return fmt.Errorf("unable to freeze digest: %w", err)
+	}
+	return ObjPin(fd, filepath.Join(dir, DigestPin))
+}
+
+// readDigest returns the digest pinned inside the pin directory dir.
+func readDigest(dir string) (string, error) {
+	fd, err := ObjGet(filepath.Join(dir, DigestPin))
+	if err != nil {
+		return "", err
+	}
+	defer unix.Close(fd)
+
+	key := uint32(0)
+	value := make([]byte, sha256.Size)
+	if err := MapLookupElem(fd, unsafe.Pointer(&key), unsafe.Pointer(&value[0])); err != nil {
+		return "", fmt.Errorf("unable to read digest: %w", err)
+	}
+	return hex.EncodeToString(value), nil
+}
+
+// readPins returns the IDs of the objects pinned inside dir.
+func readPins(dir string) (map[string]uint32, error) {
+	pins := make(map[string]uint32)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		obj, err := getObjInfo(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		pins[rel] = obj.ID
+		return nil
+	})
+	return pins, err
+}
+
+func samePins(a, b map[string]uint32) bool {
+	if len(a) == 0 || len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if id, ok := b[k]; !ok || id != v {
+			return false
+		}
+	}
+	return true
+}
+
+// deniesLoad returns true if the bpfrestrict program p may deny the
+// bpflock agent loading bpf programs.
+func deniesLoad(p *models.BpfProgram) bool {
+	profile := ""
+	for _, arg := range p.Args {
+		switch {
+		case strings.HasPrefix(arg, "--profile="):
+			profile = strings.TrimPrefix(arg, "--profile=")
+		case strings.HasPrefix(arg, "--block="):
+			block := strings.TrimPrefix(arg, "--block=")
+			if strings.Contains(block, "map_create") || strings.Contains(block, "prog_load") || strings.Contains(block, "btf_load") {
+				return true
+			}
+		case strings.HasPrefix(arg, "--deny-uid="), strings.HasPrefix(arg, "--deny-gid="):
+			return true
+		}
+	}
+	return profile == "restricted"
+}
+
+// planRestore returns the programs that can be adopted as they are, and
+// the pin directories that must be removed. A program is adopted when its
+// pinned digest matches the desired one and its pins were not replaced.
+// Pins of previous versions have no pinned digest, the recorded one is
+// used instead.
+func planRestore(programs []*models.BpfProgram, digests map[string]string,
+	pinned map[string]string, prev *State, current map[string]map[string]uint32) (map[string]bool, []string) {
+
+	adopt := make(map[string]bool)
+	for _, p := range programs {
+		ps, ok := prev.Programs[p.Name]
+		if !ok {
+			continue
+		}
+		digest, ok := pinned[p.Name]
+		if !ok {
+			digest = ps.Digest
+		}
+		if digest == digests[p.Name] && samePins(ps.Pins, current[p.Name]) {
+			adopt[p.Name] = true
+		}
+	}
+
+	// Loading programs needs bpf(), drop bpfrestrict if it may deny it,
+	// it is loaded again last.
+	for _, p := range programs {
+		if p.Name != components.BpfRestrict || !adopt[p.Name] || !deniesLoad(p) {
+			continue
+		}
+		for _, o := range programs {
+			if !adopt[o.Name] {
+				delete(adopt, components.BpfRestrict)
+				break
+			}
+		}
+	}
+
+	remove := make([]string, 0)
+	for name := range current {
+		if !adopt[name] {
+			remove = append(remove, name)
+		}
+	}
+	sort.Strings(remove)
+
+	return adopt, remove
+}
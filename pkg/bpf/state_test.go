@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package bpf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type StateSuite struct{}
+
+var _ = Suite(&StateSuite{})
+
+func (s *StateSuite) TestProgramDigest(c *C) {
+	dir := c.MkDir()
+	launcher := filepath.Join(dir, "kmodlock")
+	c.Assert(os.WriteFile(launcher, []byte("v1"), 0700), IsNil)
+
+	p := &models.BpfProgram{Name: "kmodlock", Command: "kmodlock", Args: []string{"--profile=baseline"}}
+	d := ProgramDigest(p, launcher)
+	c.Assert(ProgramDigest(p, launcher), Equals, d)
+
+	// Description and priority do not change the program
+	p.Description = "Restrict kernel module operations"
+	c.Assert(ProgramDigest(p, launcher), Equals, d)
+
+	p.Args = []string{"--profile=restricted"}
+	c.Assert(ProgramDigest(p, launcher), Not(Equals), d)
+
+	p.Args = []string{"--profile=baseline"}
+	c.Assert(os.WriteFile(launcher, []byte("v2"), 0700), IsNil)
+	c.Assert(ProgramDigest(p, launcher), Not(Equals), d)
+}
+
+func (s *StateSuite) TestReadWriteState(c *C) {
+	path := filepath.Join(c.MkDir(), StateFile)
+
+	st, err := ReadState(path)
+	c.Assert(err, IsNil)
+	c.Assert(st.Programs, HasLen, 0)
+
+	st.Programs["kmodlock"] = &ProgramState{Name: "kmodlock", Digest: "abc", Pins: map[string]uint32{"kmodlock_map": 4}}
+	c.Assert(WriteState(path, st), IsNil)
+
+	got, err := ReadState(path)
+	c.Assert(err, IsNil)
+	c.Assert(got, DeepEquals, st)
+}
+
+func (s *StateSuite) TestReadPins(c *C) {
+	dir := c.MkDir()
+	c.Assert(os.WriteFile(filepath.Join(dir, "kmodlock_map"), nil, 0600), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dir, "kmodlock_link"), nil, 0600), IsNil)
+
+	ids := map[string]uint32{"kmodlock_map": 7, "kmodlock_link": 9}
+	getObjInfo = func(path string) (*ObjInfo, error) {
+		return &ObjInfo{ID: ids[filepath.Base(path)]}, nil
+	}
+	defer func() { getObjInfo = GetObjInfo }()
+
+	pins, err := readPins(dir)
+	c.Assert(err, IsNil)
+	c.Assert(pins, DeepEquals, ids)
+}
+
+func (s *StateSuite) TestPlanRestore(c *C) {
+	programs := []*models.BpfProgram{
+		{Name: "selflock"},
+		{Name: "kmodlock"},
+		{Name: "bpfrestrict", Args: []string{"--profile=restricted"}},
+	}
+	digests := map[string]string{"selflock": "s1", "kmodlock": "k1", "bpfrestrict": "b1"}
+	pinned := map[string]string{"selflock": "s1", "kmodlock": "k1", "bpfrestrict": "b1"}
+	pins := func(id uint32) map[string]uint32 {
+		return map[string]uint32{"map": id}
+	}
+	prev := &State{Programs: map[string]*ProgramState{
+		"selflock":    {Name: "selflock", Digest: "s1", Pins: pins(1)},
+		"kmodlock":    {Name: "kmodlock", Digest: "k1", Pins: pins(2)},
+		"bpfrestrict": {Name: "bpfrestrict", Digest: "b1", Pins: pins(3)},
+	}}
+	current := map[string]map[string]uint32{
+		"selflock":    pins(1),
+		"kmodlock":    pins(2),
+		"bpfrestrict": pins(3),
+		"kimglock":    pins(4),
+	}
+
+	// Nothing changed, only the unconfigured program is removed
+	adopt, remove := planRestore(programs, digests, pinned, prev, current)
+	c.Assert(adopt, DeepEquals, map[string]bool{"selflock": true, "kmodlock": true, "bpfrestrict": true})
+	c.Assert(remove, DeepEquals, []string{"kimglock"})
+
+	// kmodlock configuration changed, the restricted bpfrestrict is
+	// replaced too
+	digests["kmodlock"] = "k2"
+	adopt, remove = planRestore(programs, digests, pinned, prev, current)
+	c.Assert(adopt, DeepEquals, map[string]bool{"selflock": true})
+	c.Assert(remove, DeepEquals, []string{"bpfrestrict", "kimglock", "kmodlock"})
+
+	// A baseline bpfrestrict does not deny the reload, it is kept
+	programs[2].Args = []string{"--profile=baseline", "--block=bpf_write"}
+	adopt, remove = planRestore(programs, digests, pinned, prev, current)
+	c.Assert(adopt, DeepEquals, map[string]bool{"selflock": true, "bpfrestrict": true})
+	c.Assert(remove, DeepEquals, []string{"kimglock", "kmodlock"})
+
+	programs[2].Args = []string{"--profile=baseline", "--block=prog_load"}
+	adopt, _ = planRestore(programs, digests, pinned, prev, current)
+	c.Assert(adopt, DeepEquals, map[string]bool{"selflock": true})
+
+	// The pinned digest is used over the recorded one, the recorded one
+	// only without a pinned digest
+	digests["kmodlock"] = "k1"
+	pinned["kmodlock"] = "k3"
+	adopt, _ = planRestore(programs, digests, pinned, prev, current)
+	c.Assert(adopt["kmodlock"], Equals, false)
+	delete(pinned, "kmodlock")
+	adopt, _ = planRestore(programs, digests, pinned, prev, current)
+	c.Assert(adopt["kmodlock"], Equals, true)
+
+	// selflock pins were replaced
+	current["selflock"] = pins(10)
+	delete(current, "kimglock")
+	adopt, remove = planRestore(programs, digests, pinned, prev, current)
+	c.Assert(adopt, DeepEquals, map[string]bool{"kmodlock": true})
+	c.Assert(remove, DeepEquals, []string{"bpfrestrict", "selflock"})
+
+	// No previous state
+	adopt, remove = planRestore(programs, digests, pinned, &State{}, current)
+	c.Assert(adopt, HasLen, 0)
+	c.Assert(remove, HasLen, 3)
+}
@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build linux
+// +build linux
+
+package bpf
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// ObjInfo is the common part of the information of pinned bpf objects:
+// maps, programs and links.
+type ObjInfo struct {
+	Type uint32
+	ID   uint32
+
+	// ProgID is the ID of the attached program for links, it is not
+	// meaningful for other objects.
+	ProgID uint32
+}
+
+type bpfObjGetAttr struct {
+	pathname  uint64
+	bpfFd     uint32
+	fileFlags uint32
+}
+
+type bpfObjGetInfoAttr struct {
+	bpfFd   uint32
+	infoLen uint32
+	info    uint64
+}
+
+func bpfSyscall(cmd int, attr unsafe.Pointer, size uintptr) (uintptr, error) {
+	r, _, errno := unix.Syscall(unix.SYS_BPF, uintptr(cmd), uintptr(attr), size)
+	if errno != 0 {
+		return r, errno
+	}
+	return r, nil
+}
+
+// ObjGet returns a file descriptor of the bpf object pinned at path.
+func ObjGet(path string) (int, error) {
+	p, err := unix.BytePtrFromString(path)
+	if err != nil {
+		return -1, err
+	}
+	attr := bpfObjGetAttr{
+		pathname: uint64(uintptr(unsafe.Pointer(p))),
+	}
+	fd, err := bpfSyscall(unix.BPF_OBJ_GET, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	if err != nil {
+		return -1, fmt.Errorf("unable to get pinned object %s: %w", path, err)
+	}
+	return int(fd), nil
+}
+
+// ObjPin pins the bpf object fd at path.
+func ObjPin(fd int, path string) error {
+	p, err := unix.BytePtrFromString(path)
+	if err != nil {
+		return err
+	}
+	attr := bpfObjGetAttr{
+		pathname: uint64(uintptr(unsafe.Pointer(p))),
+		bpfFd:    uint32(fd),
+	}
+	if _, err := bpfSyscall(unix.BPF_OBJ_PIN, unsafe.Pointer(&attr), unsafe.Sizeof(attr)); err != nil {
+		return fmt.Errorf("unable to pin object at %s: %w", path, err)
+	}
+	return nil
+}
+
+type bpfMapCreateAttr struct {
+	mapType    uint32
+	keySize    uint32
+	valueSize  uint32
+	maxEntries uint32
+	mapFlags   uint32
+	innerMapFd uint32
+	numaNode   uint32
+	mapName    [unix.BPF_OBJ_NAME_LEN]byte
+}
+
+// MapCreate creates a map of mapType named name and returns its file
+// descriptor.
+func MapCreate(name string, mapType, keySize, valueSize, maxEntries uint32) (int, error) {
+	attr := bpfMapCreateAttr{
+		mapType:    mapType,
+		keySize:    keySize,
+		valueSize:  valueSize,
+		maxEntries: maxEntries,
+	}
+	copy(attr.mapName[:unix.BPF_OBJ_NAME_LEN-1], name)
+	fd, err := bpfSyscall(unix.BPF_MAP_CREATE, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	if err != nil {
+		return -1, fmt.Errorf("unable to create map %s: %w", name, err)
+	}
+	return int(fd), nil
+}
+
+// GetObjInfo returns the information of the bpf object pinned at path.
+func GetObjInfo(path string) (*ObjInfo, error) {
+	fd, err := ObjGet(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unix.Close(fd)
+
+	info := ObjInfo{}
+	attr := bpfObjGetInfoAttr{
+		bpfFd:   uint32(fd),
+		infoLen: uint32(unsafe.Sizeof(info)),
+		info:    uint64(uintptr(unsafe.Pointer(&info))),
+	}
+	if _, err := bpfSyscall(unix.BPF_OBJ_GET_INFO_BY_FD, unsafe.Pointer(&attr), unsafe.Sizeof(attr)); err != nil {
+		return nil, fmt.Errorf("unable to get information of pinned object %s: %w", path, err)
+	}
+	return &info, nil
+}
+
+type bpfMapElemAttr struct {
+	mapFd uint32
+	pad0  [4]byte
+	key   uint64
+	value uint64 // value or next key
+	flags uint64
+}
+
+// MapUpdateElem creates or updates the element of key in the map fd.
+func MapUpdateElem(fd int, key, value unsafe.Pointer, flags uint64) error {
+	attr := bpfMapElemAttr{
+		mapFd: uint32(fd),
+		key:   uint64(uintptr(key)),
+		value: uint64(uintptr(value)),
+		flags: flags,
+	}
+	_, err := bpfSyscall(unix.BPF_MAP_UPDATE_ELEM, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	return err
+}
+
+// MapLookupElem stores in value the element of key in the map fd. It
+// returns unix.ENOENT if there is no such element.
+func MapLookupElem(fd int, key, value unsafe.Pointer) error {
+	attr := bpfMapElemAttr{
+		mapFd: uint32(fd),
+		key:   uint64(uintptr(key)),
+		value: uint64(uintptr(value)),
+	}
+	_, err := bpfSyscall(unix.BPF_MAP_LOOKUP_ELEM, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	return err
+}
+
+// MapDeleteElem deletes the element of key from the map fd.
+func MapDeleteElem(fd int, key unsafe.Pointer) error {
+	attr := bpfMapElemAttr{
+		mapFd: uint32(fd),
+		key:   uint64(uintptr(key)),
+	}
+	_, err := bpfSyscall(unix.BPF_MAP_DELETE_ELEM, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	return err
+}
+
+// MapGetNextKey stores in nextKey the key that follows key in the map fd,
+// or the first key if key is nil. It returns unix.ENOENT after the last key.
+func MapGetNextKey(fd int, key, nextKey unsafe.Pointer) error {
+	attr := bpfMapElemAttr{
+		mapFd: uint32(fd),
+		key:   uint64(uintptr(key)),
+		value: uint64(uintptr(nextKey)),
+	}
+	_, err := bpfSyscall(unix.BPF_MAP_GET_NEXT_KEY, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	return err
+}
+
+type bpfMapFreezeAttr struct {
+	mapFd uint32
+}
+
+// MapFreeze makes the map fd read-only from user space, bpf programs can
+// still update it. A map can not be unfrozen, freezing it again returns
+// unix.EBUSY.
+func MapFreeze(fd int) error {
+	attr := bpfMapFreezeAttr{
+		mapFd: uint32(fd),
+	}
+	_, err := bpfSyscall(unix.BPF_MAP_FREEZE, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	return err
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+// Copyright 2016-2021 Authors of Cilium
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	runtime_client "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+
+	clientapi "github.com/linux-lock/bpflock/api/v1/client"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+)
+
+// Client is the bpflock API client
+type Client struct {
+	clientapi.Bpflock
+}
+
+// DefaultSockPath returns default UNIX domain socket path or
+// path set using BPFLOCK_SOCK env variable
+func DefaultSockPath() string {
+	// Check if environment variable points to socket
+	e := os.Getenv(defaults.SockPathEnv)
+	if e == "" {
+		// If unset, fall back to default value
+		e = defaults.SockPath
+	}
+	return "unix://" + e
+}
+
+func configureTransport(tr *http.Transport, proto, addr string) *http.Transport {
+	if tr == nil {
+		tr = &http.Transport{}
+	}
+
+	if proto == "unix" {
+		// No need for compression in local communications.
+		tr.DisableCompression = true
+		tr.DialContext = func(_ context.Context, _, _ string) (net.Conn, error) {
+			return net.Dial(proto, addr)
+		}
+	} else {
+		tr.Proxy = http.ProxyFromEnvironment
+		tr.DialContext = (&net.Dialer{}).DialContext
+	}
+
+	return tr
+}
+
+// NewDefaultClient creates a client with default parameters connecting to UNIX domain socket.
+func NewDefaultClient() (*Client, error) {
+	return NewClient("")
+}
+
+// NewClient creates a client for the given `host`.
+// If host is nil then use SockPath provided by BPFLOCK_SOCK
+// or the bpflock default SockPath
+func NewClient(host string) (*Client, error) {
+	clientTrans, err := NewRuntime(host)
+	return &Client{*clientapi.New(clientTrans, strfmt.Default)}, err
+}
+
+// NewRuntime returns the transport of a client for the given `host`.
+func NewRuntime(host string) (*runtime_client.Runtime, error) {
+	if host == "" {
+		host = DefaultSockPath()
+	}
+	tmp := strings.SplitN(host, "://", 2)
+	if len(tmp) != 2 {
+		return nil, fmt.Errorf("invalid host format '%s'", host)
+	}
+
+	switch tmp[0] {
+	case "tcp":
+		if _, err := url.Parse("tcp://" + tmp[1]); err != nil {
+			return nil, err
+		}
+		host = "http://" + tmp[1]
+	case "unix":
+		host = tmp[1]
+	}
+
+	transport := configureTransport(nil, tmp[0], host)
+	httpClient := &http.Client{Transport: transport}
+	clientTrans := runtime_client.NewWithClient(tmp[1], clientapi.DefaultBasePath,
+		clientapi.DefaultSchemes, httpClient)
+	return clientTrans, nil
+}
+
+// IsUnreachable returns true if err reports that the bpflock daemon could
+// not be contacted.
+func IsUnreachable(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// Hint tries to improve the error message displayed to the user.
+func Hint(err error) error {
+	if err == nil {
+		return err
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("bpflock API client timeout exceeded")
+	}
+
+	if IsUnreachable(err) {
+		return fmt.Errorf("%w\nIs the bpflock agent running?", err)
+	}
+
+	return fmt.Errorf("%s", err)
+}
+
+// timeout returns the context used by client requests.
+func timeout() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), defaults.ClientConnectTimeout)
+}
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package client
+
+import (
+	"github.com/linux-lock/bpflock/api/v1/client/daemon"
+	"github.com/linux-lock/bpflock/api/v1/models"
+)
+
+// Seal seals the bpflock policy and returns the status of the seal
+func (c *Client) Seal() (*models.Status, error) {
+	ctx, cancel := timeout()
+	defer cancel()
+
+	resp, err := c.Daemon.PostSeal(daemon.NewPostSealParams().WithContext(ctx))
+	if err != nil {
+		return nil, Hint(err)
+	}
+	return resp.Payload, nil
+}
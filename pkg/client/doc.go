@@ -0,0 +1,5 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package client is a client of the bpflock agent API.
+package client
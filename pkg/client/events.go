@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package client
+
+import (
+	"github.com/linux-lock/bpflock/api/v1/client/events"
+	"github.com/linux-lock/bpflock/api/v1/models"
+)
+
+// EventsHistory returns the stored security events selected by params
+func (c *Client) EventsHistory(params *events.GetEventsHistoryParams) (*models.EventHistory, error) {
+	ctx, cancel := timeout()
+	defer cancel()
+
+	resp, err := c.Events.GetEventsHistory(params.WithContext(ctx))
+	if err != nil {
+		return nil, Hint(err)
+	}
+	return resp.Payload, nil
+}
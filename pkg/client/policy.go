@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/linux-lock/bpflock/api/v1/client/policy"
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+)
+
+// PolicyApplyError is returned when the bpflock agent fails to apply a bpf
+// programs configuration
+type PolicyApplyError struct {
+	*models.PolicyApplyError
+}
+
+func (e *PolicyApplyError) Error() string {
+	return e.Msg
+}
+
+// PolicyHistory returns the applied bpf programs configurations, newest first
+func (c *Client) PolicyHistory() (*models.PolicyHistory, error) {
+	ctx, cancel := timeout()
+	defer cancel()
+
+	resp, err := c.Policy.GetPolicyHistory(policy.NewGetPolicyHistoryParams().WithContext(ctx))
+	if err != nil {
+		return nil, Hint(err)
+	}
+	return resp.Payload, nil
+}
+
+// PolicyRollback applies again the bpf programs configuration snapshot id
+func (c *Client) PolicyRollback(id string) (*models.PolicySnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaults.ClientApplyTimeout)
+	defer cancel()
+
+	params := policy.NewPostPolicyRollbackIDParams().WithContext(ctx).
+		WithTimeout(defaults.ClientApplyTimeout).WithID(id)
+	resp, err := c.Policy.PostPolicyRollbackID(params)
+	if err != nil {
+		var ae *policy.PostPolicyRollbackIDInternalServerError
+		if errors.As(err, &ae) && ae.Payload != nil {
+			return nil, &PolicyApplyError{ae.Payload}
+		}
+		return nil, Hint(err)
+	}
+	return resp.Payload, nil
+}
+
+// PutPolicy applies the bpf programs configuration meta
+func (c *Client) PutPolicy(meta *models.BpfMeta) (*models.PolicySnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaults.ClientApplyTimeout)
+	defer cancel()
+
+	params := policy.NewPutPolicyParams().WithContext(ctx).
+		WithTimeout(defaults.ClientApplyTimeout).WithBpfmeta(meta)
+	resp, err := c.Policy.PutPolicy(params)
+	if err != nil {
+		var ae *policy.PutPolicyInternalServerError
+		if errors.As(err, &ae) && ae.Payload != nil {
+			return nil, &PolicyApplyError{ae.Payload}
+		}
+		return nil, Hint(err)
+	}
+	return resp.Payload, nil
+}
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package client
+
+import (
+	"github.com/linux-lock/bpflock/api/v1/client/programs"
+	"github.com/linux-lock/bpflock/api/v1/models"
+)
+
+// ProgramsList returns the configured bpf programs in the order they are started
+func (c *Client) ProgramsList() ([]*models.ProgramStatus, error) {
+	ctx, cancel := timeout()
+	defer cancel()
+
+	resp, err := c.Programs.GetPrograms(programs.NewGetProgramsParams().WithContext(ctx))
+	if err != nil {
+		return nil, Hint(err)
+	}
+	return resp.Payload, nil
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+// Copyright 2017-2020 Authors of Cilium
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputOpt string
+)
+
+// OutputOption returns true if an output option was specified.
+func OutputOption() bool {
+	return len(outputOpt) > 0
+}
+
+// OutputOptionString returns the output option as a string
+func OutputOptionString() string {
+	return outputOpt
+}
+
+// AddOutputOption adds the -o|--output option to any cmd to export to json.
+func AddOutputOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputOpt, "output", "o", "", "json")
+}
+
+// ForceJSON sets output mode to JSON (for unit tests)
+func ForceJSON() {
+	outputOpt = "json"
+}
+
+// PrintOutput receives an interface and dump the data using the --output flag.
+// Only JSON is supported.
+func PrintOutput(data interface{}) error {
+	return PrintOutputWithType(data, outputOpt)
+}
+
+// PrintOutputWithType receives an interface and dump the data using the
+// given output type. Only JSON is supported.
+func PrintOutputWithType(data interface{}, outputType string) error {
+	switch outputType {
+	case "json":
+		return dumpJSON(data)
+	}
+	return fmt.Errorf("couldn't find output printer")
+}
+
+// dumpJSON dumps the data variable to stdout as JSON.
+func dumpJSON(data interface{}) error {
+	result, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't marshal to json: '%s'", err)
+	}
+
+	fmt.Fprintln(os.Stdout, string(result))
+	return nil
+}
+
+// Fatalf prints the message to stderr and exits with a non zero code.
+func Fatalf(msg string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "Error: %s\n", fmt.Sprintf(msg, args...))
+	os.Exit(1)
+}
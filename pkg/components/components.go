@@ -14,8 +14,15 @@ const (
 	BpflockAgentName = "bpflock"
 
 	BpfRestrict = "bpfrestrict"
+	ExecLock    = "execlock"
+	FsLock      = "fslock"
 	KimgLock    = "kimglock"
 	KmodLock    = "kmodlock"
+	NetLock     = "netlock"
+	NsLock      = "nslock"
+	RootfsLock  = "rootfslock"
+	SelfLock    = "selflock"
+	UsbLock     = "usblock"
 )
 
 // IsBpflockAgent checks whether the current process is bpflock (daemon).
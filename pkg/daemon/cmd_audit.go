@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/linux-lock/bpflock/pkg/command"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+	"github.com/linux-lock/bpflock/pkg/eventlog"
+)
+
+var (
+	auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Audit the local event log",
+	}
+
+	auditVerifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the integrity of the event log",
+		Long: "Verify that the records of the event log form an unbroken hash chain, that " +
+			"checkpoints are signed by a trusted node key and that the log was not truncated " +
+			"since its last signed head. Returns a non zero exit code if the log was tampered with, " +
+			"if no trusted key is available, " +
+			"or if the signed head is missing unless --allow-missing-head is set.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ok, err := runAuditVerify()
+			if err != nil {
+				command.Fatalf("%s", err)
+			}
+			if !ok {
+				os.Exit(2)
+			}
+		},
+	}
+
+	auditLibDir           string
+	auditKeys             []string
+	auditAllowMissingHead bool
+)
+
+func init() {
+	flags := auditVerifyCmd.Flags()
+	flags.StringVar(&auditLibDir, "lib-dir", defaults.VariablePath, "Directory path of the bpflock library that contains the event log, the node key and the signed head")
+	flags.StringSliceVar(&auditKeys, "key", []string{}, "Additional trusted public key files")
+	flags.BoolVar(&auditAllowMissingHead, "allow-missing-head", false, "Do not fail if the signed head is missing, truncation of the log can then not be detected")
+	command.AddOutputOption(auditVerifyCmd)
+
+	auditCmd.AddCommand(auditVerifyCmd)
+	RootCmd.AddCommand(auditCmd)
+}
+
+func runAuditVerify() (bool, error) {
+	auditDir := filepath.Join(auditLibDir, defaults.AuditDir)
+	keys := make([]ed25519.PublicKey, 0, len(auditKeys)+1)
+	keyFiles := auditKeys
+	nodeKey := filepath.Join(auditDir, eventlog.PublicKeyFile)
+	if _, err := os.Stat(nodeKey); err == nil {
+		keyFiles = append(keyFiles, nodeKey)
+	}
+	for _, f := range keyFiles {
+		k, err := eventlog.LoadPublicKey(f)
+		if err != nil {
+			return false, err
+		}
+		keys = append(keys, k)
+	}
+
+	head, err := eventlog.ReadHead(filepath.Join(auditDir, eventlog.HeadFile))
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	report, err := eventlog.Verify(filepath.Join(auditLibDir, defaults.EventLogDir), keys, head)
+	if err != nil {
+		return false, err
+	}
+	if len(keys) == 0 {
+		report.Problems = append(report.Problems, fmt.Sprintf("no trusted key found in %s nor given with --key, signatures can not be verified", nodeKey))
+	}
+	if head == nil && !auditAllowMissingHead {
+		report.Problems = append(report.Problems, "signed head not found, truncation can not be detected")
+	}
+
+	if command.OutputOption() {
+		return report.OK(), command.PrintOutput(report)
+	}
+
+	fmt.Printf("Records:         %d to %d (%d)\n", report.First, report.Last, report.Records)
+	fmt.Printf("Checkpoints:     %d, last verified at record %d\n", report.Checkpoints, report.LastCheckpoint)
+	if report.Rotated {
+		fmt.Printf("Rotated:         older records were removed\n")
+	}
+	if head == nil && auditAllowMissingHead {
+		fmt.Printf("Head:            not found, truncation can not be detected\n")
+	}
+	for _, w := range report.Warnings {
+		fmt.Printf("Warning:         %s\n", w)
+	}
+	for _, p := range report.Problems {
+		fmt.Printf("Tampered:        %s\n", p)
+	}
+	if report.OK() {
+		fmt.Println("Status:          OK")
+	} else {
+		fmt.Println("Status:          FAILED")
+	}
+
+	return report.OK(), nil
+}
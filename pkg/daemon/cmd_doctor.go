@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/command"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+	"github.com/linux-lock/bpflock/pkg/doctor"
+	"github.com/linux-lock/bpflock/pkg/option"
+)
+
+var (
+	doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that this machine can run bpflock",
+		Long: "Run preflight checks of the kernel version, LSMs, BTF, bpf filesystem, cgroup v2, " +
+			"memlock limits, bpf program launchers and pid namespace without starting the daemon. " +
+			"Returns a non zero exit code if a check failed.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ok, err := runDoctor()
+			if err != nil {
+				command.Fatalf("%s", err)
+			}
+			if !ok {
+				os.Exit(1)
+			}
+		},
+	}
+
+	doctorBpfDir       string
+	doctorBpfConfigDir string
+	doctorMapRoot      string
+)
+
+func init() {
+	flags := doctorCmd.Flags()
+	flags.StringVar(&doctorBpfDir, "bpf-dir", filepath.Join(defaults.ProgramLibPath, defaults.BpfDir), "Directory path of the bpf programs launchers")
+	flags.StringVar(&doctorBpfConfigDir, "bpf-config-dir", filepath.Join(defaults.ConfigurationPath, "bpf.d"), "Configuration directory that contains bpf programs configurations")
+	flags.StringVar(&doctorMapRoot, "bpf-root", defaults.DefaultMapRoot, "Path where the bpf filesystem should be mounted")
+	command.AddOutputOption(doctorCmd)
+
+	RootCmd.AddCommand(doctorCmd)
+}
+
+// doctorPrograms returns the configured bpf programs, or all supported
+// programs if the configuration can not be read.
+func doctorPrograms() ([]*models.BpfProgram, error) {
+	meta := models.BpfMeta{Bpfspec: &models.BpfSpec{}}
+	err := option.ReadBpfDirConfig(doctorBpfConfigDir, &meta)
+	if err == nil && len(meta.Bpfspec.Programs) > 0 {
+		return meta.Bpfspec.Programs, nil
+	}
+
+	programs := make([]*models.BpfProgram, 0, len(option.BpflockBpfProgs))
+	for _, p := range option.BpflockBpfProgs {
+		programs = append(programs, &models.BpfProgram{Name: p.Name, Command: p.Name, Priority: p.Priority})
+	}
+	sort.Sort(option.BpfByPriority(programs))
+	return programs, err
+}
+
+func runDoctor() (bool, error) {
+	programs, cfgErr := doctorPrograms()
+
+	report := doctor.Diagnose(doctor.Config{
+		BpfDir:   doctorBpfDir,
+		MapRoot:  doctorMapRoot,
+		Programs: programs,
+	})
+	if cfgErr != nil {
+		report.Checks = append(report.Checks, doctor.Check{
+			Name:    "configuration",
+			State:   doctor.Warn,
+			Message: fmt.Sprintf("%v, checking all bpf programs", cfgErr),
+		})
+	}
+
+	if command.OutputOption() {
+		return report.OK, command.PrintOutput(report)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tRESULT\tMESSAGE")
+	for _, c := range report.Checks {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.Name, strings.ToUpper(string(c.State)), c.Message)
+	}
+	w.Flush()
+
+	if report.OK {
+		fmt.Println("\nbpflock can run on this machine")
+	} else {
+		fmt.Println("\nbpflock can not run on this machine, see failed checks")
+	}
+
+	return report.OK, nil
+}
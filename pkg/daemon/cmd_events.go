@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/spf13/cobra"
+
+	"github.com/linux-lock/bpflock/api/v1/client/events"
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/client"
+	"github.com/linux-lock/bpflock/pkg/command"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+	"github.com/linux-lock/bpflock/pkg/eventlog"
+)
+
+var (
+	eventsCmd = &cobra.Command{
+		Use:   "events",
+		Short: "Access security events",
+	}
+
+	eventsHistoryCmd = &cobra.Command{
+		Use:   "history",
+		Short: "Show stored security events",
+		Long: "Show the security events stored in the local event log. The bpflock agent is " +
+			"queried, if it is not reachable the event log is read directly from the library directory.",
+		Example: "  bpflock events history --since 24h --decision denied\n" +
+			"  bpflock events history --program kmodlock --offline",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runEventsHistory(); err != nil {
+				command.Fatalf("%s", err)
+			}
+		},
+	}
+
+	eventsHost      string
+	eventsLibDir    string
+	eventsOffline   bool
+	eventsSince     string
+	eventsUntil     string
+	eventsProgram   string
+	eventsDecision  string
+	eventsContainer string
+	eventsCursor    int64
+	eventsLimit     int64
+)
+
+func init() {
+	flags := eventsHistoryCmd.Flags()
+	flags.StringVarP(&eventsHost, "host", "H", "", "URI to server-side API")
+	flags.StringVar(&eventsLibDir, "lib-dir", defaults.VariablePath, "Directory path of the bpflock library, used when reading the event log offline")
+	flags.BoolVar(&eventsOffline, "offline", false, "Read the event log directly without querying the bpflock agent")
+	flags.StringVar(&eventsSince, "since", "", "Show events since a time in RFC3339 format or a duration ago, e.g. 2h")
+	flags.StringVar(&eventsUntil, "until", "", "Show events until a time in RFC3339 format or a duration ago")
+	flags.StringVar(&eventsProgram, "program", "", "Show events of a bpf program")
+	flags.StringVar(&eventsDecision, "decision", "", "Show events with a decision: allowed or denied")
+	flags.StringVar(&eventsContainer, "container", "", "Show events of a container ID or an ID prefix")
+	flags.Int64Var(&eventsCursor, "cursor", 0, "Show events after a cursor returned by a previous query")
+	flags.Int64Var(&eventsLimit, "limit", eventlog.DefaultQueryLimit, "Maximum number of events to show")
+	command.AddOutputOption(eventsHistoryCmd)
+
+	eventsCmd.AddCommand(eventsHistoryCmd)
+	RootCmd.AddCommand(eventsCmd)
+}
+
+// parseTimeArg parses either a RFC3339 time or a duration relative to now.
+func parseTimeArg(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s': expected RFC3339 format or a duration", value)
+	}
+	return t, nil
+}
+
+func eventsHistoryFilter() (eventlog.Filter, error) {
+	since, err := parseTimeArg(eventsSince)
+	if err != nil {
+		return eventlog.Filter{}, err
+	}
+	until, err := parseTimeArg(eventsUntil)
+	if err != nil {
+		return eventlog.Filter{}, err
+	}
+
+	switch eventsDecision {
+	case "", models.EventDecisionAllowed, models.EventDecisionDenied:
+	default:
+		return eventlog.Filter{}, fmt.Errorf("invalid decision '%s'", eventsDecision)
+	}
+
+	return eventlog.Filter{
+		Since:     since,
+		Until:     until,
+		Program:   eventsProgram,
+		Decision:  eventsDecision,
+		Container: eventsContainer,
+		Cursor:    eventsCursor,
+		Limit:     int(eventsLimit),
+	}, nil
+}
+
+func queryEventsHistory(filter eventlog.Filter) (*models.EventHistory, error) {
+	c, err := client.NewClient(eventsHost)
+	if err != nil {
+		return nil, err
+	}
+
+	params := events.NewGetEventsHistoryParams().WithLimit(&eventsLimit)
+	if !filter.Since.IsZero() {
+		since := strfmt.DateTime(filter.Since)
+		params.SetSince(&since)
+	}
+	if !filter.Until.IsZero() {
+		until := strfmt.DateTime(filter.Until)
+		params.SetUntil(&until)
+	}
+	if filter.Program != "" {
+		params.SetProgram(&filter.Program)
+	}
+	if filter.Decision != "" {
+		params.SetDecision(&filter.Decision)
+	}
+	if filter.Container != "" {
+		params.SetContainer(&filter.Container)
+	}
+	if filter.Cursor > 0 {
+		params.SetCursor(&filter.Cursor)
+	}
+
+	return c.EventsHistory(params)
+}
+
+func runEventsHistory() error {
+	filter, err := eventsHistoryFilter()
+	if err != nil {
+		return err
+	}
+
+	var history *models.EventHistory
+	if !eventsOffline {
+		history, err = queryEventsHistory(filter)
+		if err != nil && client.IsUnreachable(err) {
+			fmt.Fprintf(os.Stderr, "bpflock agent is not reachable, reading the event log offline\n")
+			history, err = nil, nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if history == nil {
+		history, err = eventlog.Query(filepath.Join(eventsLibDir, defaults.EventLogDir), filter)
+		if err != nil {
+			return err
+		}
+	}
+
+	if command.OutputOption() {
+		return command.PrintOutput(history)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "SEQ\tTIME\tPROGRAM\tPID\tCOMM\tOPERATION\tDECISION\tCONTAINER")
+	for _, ev := range history.Events {
+		container := ev.Container
+		if len(container) > 12 {
+			container = container[:12]
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n", ev.Seq,
+			time.Time(ev.Time).Format(time.RFC3339), ev.Program, ev.Pid, ev.Comm,
+			ev.Operation, decisionString(ev), container)
+	}
+	w.Flush()
+
+	if history.Next > 0 {
+		fmt.Printf("\nMore events available, continue with --cursor=%d\n", history.Next)
+	}
+
+	return nil
+}
+
+func decisionString(ev *models.Event) string {
+	if ev.Reason == "" {
+		return ev.Decision
+	}
+	if ev.Rule != "" {
+		return fmt.Sprintf("%s (%s: %s)", ev.Decision, ev.Reason, ev.Rule)
+	}
+	return fmt.Sprintf("%s (%s)", ev.Decision, ev.Reason)
+}
@@ -0,0 +1,351 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/client"
+	"github.com/linux-lock/bpflock/pkg/command"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/policy"
+)
+
+var (
+	policyCmd = &cobra.Command{
+		Use:   "policy",
+		Short: "Manage bpf programs configurations",
+	}
+
+	policyKeygenCmd = &cobra.Command{
+		Use:   "keygen KEY",
+		Short: "Generate a signing key",
+		Long: "Generate an ed25519 signing key stored in KEY, and its public key stored in KEY.pub. " +
+			"Pass the public keys to the bpflock agent with --" + option.BpfConfigKeys + ".",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if _, err := policy.GenerateKey(args[0]); err != nil {
+				command.Fatalf("%s", err)
+			}
+			fmt.Printf("Signing key: %s\nPublic key:  %s%s\n", args[0], args[0], policy.PublicKeyExt)
+		},
+	}
+
+	policySignCmd = &cobra.Command{
+		Use:   "sign [DIR|FILE]...",
+		Short: "Sign bpf programs configurations",
+		Long: "Write a detached signature FILE.sig of each configuration file, or of all configuration " +
+			"files of DIR. With --bundle, a single bundle.sig signature covers all the configuration " +
+			"files of DIR, adding, removing or changing any of them invalidates it.",
+		Example: "  bpflock policy sign --key ci.key /etc/bpflock/bpf.d\n" +
+			"  bpflock policy sign --key ci.key --bundle build/bpf.d",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runPolicySign(policyArgs(args)); err != nil {
+				command.Fatalf("%s", err)
+			}
+		},
+	}
+
+	policyVerifyCmd = &cobra.Command{
+		Use:   "verify [DIR|FILE]...",
+		Short: "Verify signatures of bpf programs configurations",
+		Long: "Verify that the configuration files are signed by one of the trusted public keys, as the " +
+			"bpflock agent does at startup. Returns a non zero exit code if a file is rejected.",
+		Example: "  bpflock policy verify --keys /etc/bpflock/keys /etc/bpflock/bpf.d",
+		Run: func(cmd *cobra.Command, args []string) {
+			ok, err := runPolicyVerify(policyArgs(args))
+			if err != nil {
+				command.Fatalf("%s", err)
+			}
+			if !ok {
+				os.Exit(2)
+			}
+		},
+	}
+
+	policyApplyCmd = &cobra.Command{
+		Use:   "apply FILE",
+		Short: "Apply a bpf programs configuration",
+		Long: "Send the complete bpf programs configuration FILE to the bpflock agent. It is validated like " +
+			"the files of the configuration directory and all program changes apply as one transaction: " +
+			"if a bpf program fails to start, the previous configuration is restored.",
+		Example: "  bpflock policy apply restricted.yaml",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runPolicyApply(args[0]); err != nil {
+				command.Fatalf("%s", err)
+			}
+		},
+	}
+
+	policyHistoryCmd = &cobra.Command{
+		Use:   "history",
+		Short: "List the bpf programs configurations applied by the bpflock agent",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runPolicyHistory(); err != nil {
+				command.Fatalf("%s", err)
+			}
+		},
+	}
+
+	policyRollbackCmd = &cobra.Command{
+		Use:   "rollback ID",
+		Short: "Apply again a previous bpf programs configuration",
+		Long: "Apply again the bpf programs configuration ID of the policy history, or a unique prefix of " +
+			"it. It is validated like a new configuration: it can not loosen a sealed policy, nor remove " +
+			"the restricted profile of a running program.",
+		Example: "  bpflock policy rollback 3f2a9c1b",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runPolicyRollback(args[0]); err != nil {
+				command.Fatalf("%s", err)
+			}
+		},
+	}
+
+	policyKey    string
+	policyBundle bool
+	policyKeys   string
+	policyHost   string
+)
+
+func init() {
+	flags := policySignCmd.Flags()
+	flags.StringVar(&policyKey, "key", "", "Signing key file")
+	flags.BoolVar(&policyBundle, "bundle", false, "Sign all configuration files of a directory as one bundle")
+	policySignCmd.MarkFlagRequired("key")
+
+	flags = policyVerifyCmd.Flags()
+	flags.StringVar(&policyKeys, "keys", "", "File or directory of the trusted public keys")
+	policyVerifyCmd.MarkFlagRequired("keys")
+
+	for _, cmd := range []*cobra.Command{policyApplyCmd, policyHistoryCmd, policyRollbackCmd} {
+		cmd.Flags().StringVarP(&policyHost, "host", "H", "", "URI to server-side API")
+		command.AddOutputOption(cmd)
+	}
+
+	policyCmd.AddCommand(policyKeygenCmd)
+	policyCmd.AddCommand(policySignCmd)
+	policyCmd.AddCommand(policyVerifyCmd)
+	policyCmd.AddCommand(policyApplyCmd)
+	policyCmd.AddCommand(policyHistoryCmd)
+	policyCmd.AddCommand(policyRollbackCmd)
+	RootCmd.AddCommand(policyCmd)
+}
+
+// policyArgs returns the paths to sign or verify, the default bpf programs
+// configuration directory if none is passed.
+func policyArgs(args []string) []string {
+	if len(args) == 0 {
+		return []string{filepath.Join(defaults.ConfigurationPath, "bpf.d")}
+	}
+	return args
+}
+
+func isDir(path string) (bool, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	return fi.IsDir(), nil
+}
+
+func runPolicySign(paths []string) error {
+	key, err := policy.LoadPrivateKey(policyKey)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		dir, err := isDir(path)
+		if err != nil {
+			return err
+		}
+		if !dir {
+			if policyBundle {
+				return fmt.Errorf("--bundle signs directories, %s is a file", path)
+			}
+			if err := policy.SignFile(key, path); err != nil {
+				return err
+			}
+			fmt.Printf("Signed %s\n", path)
+			continue
+		}
+
+		names, err := option.BpfConfigFiles(path)
+		if err != nil {
+			return err
+		}
+		if policyBundle {
+			if err := policy.SignBundle(key, path, names); err != nil {
+				return err
+			}
+			fmt.Printf("Signed %d files of %s in %s\n", len(names), path, policy.BundleSignature)
+			continue
+		}
+		for _, name := range names {
+			if err := policy.SignFile(key, filepath.Join(path, name)); err != nil {
+				return err
+			}
+			fmt.Printf("Signed %s\n", filepath.Join(path, name))
+		}
+	}
+
+	return nil
+}
+
+func runPolicyVerify(paths []string) (bool, error) {
+	keys, err := policy.LoadKeys(policyKeys)
+	if err != nil {
+		return false, err
+	}
+
+	ok := true
+	for _, path := range paths {
+		dir, err := isDir(path)
+		if err != nil {
+			return false, err
+		}
+		if !dir {
+			if err := policy.VerifyFile(keys, path); err != nil {
+				fmt.Printf("Rejected:  %s\n", err)
+				ok = false
+			} else {
+				fmt.Printf("Verified:  %s\n", path)
+			}
+			continue
+		}
+
+		names, err := option.BpfConfigFiles(path)
+		if err != nil {
+			return false, err
+		}
+		rejected, err := policy.VerifyDir(keys, path, names)
+		if err != nil {
+			return false, err
+		}
+		bad := make(map[string]bool, len(rejected))
+		for _, name := range rejected {
+			bad[name] = true
+		}
+		for _, name := range names {
+			if bad[name] {
+				fmt.Printf("Rejected:  %s: %s\n", filepath.Join(path, name), policy.ErrUnsigned)
+				ok = false
+			} else {
+				fmt.Printf("Verified:  %s\n", filepath.Join(path, name))
+			}
+		}
+	}
+
+	return ok, nil
+}
+
+// printApplyError prints the outcome of each bpf program of a
+// configuration that failed to apply.
+func printApplyError(err error) error {
+	var ae *client.PolicyApplyError
+	if !errors.As(err, &ae) || len(ae.Programs) == 0 || command.OutputOption() {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stderr, 2, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PROGRAM\tRESULT\tERROR")
+	for _, p := range ae.Programs {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.Result, p.Error)
+	}
+	w.Flush()
+	return err
+}
+
+func runPolicyApply(path string) error {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	meta := &models.BpfMeta{}
+	if err := v.Unmarshal(meta); err != nil {
+		return fmt.Errorf("unable to decode %s: %w", path, err)
+	}
+
+	c, err := client.NewClient(policyHost)
+	if err != nil {
+		return err
+	}
+
+	s, err := c.PutPolicy(meta)
+	if err != nil {
+		return printApplyError(err)
+	}
+
+	if command.OutputOption() {
+		return command.PrintOutput(s)
+	}
+	fmt.Printf("Applied bpf programs configuration %s\n", s.ID)
+	return nil
+}
+
+func runPolicyHistory() error {
+	c, err := client.NewClient(policyHost)
+	if err != nil {
+		return err
+	}
+
+	history, err := c.PolicyHistory()
+	if err != nil {
+		return err
+	}
+
+	if command.OutputOption() {
+		return command.PrintOutput(history)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tSOURCE\tIDENTITY\t")
+	for _, s := range history.Snapshots {
+		current := ""
+		if s.Current {
+			current = "(current)"
+		}
+		id := s.ID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", id, time.Time(s.Time).Format(time.RFC3339),
+			s.Source, s.Identity, current)
+	}
+	w.Flush()
+	return nil
+}
+
+func runPolicyRollback(id string) error {
+	c, err := client.NewClient(policyHost)
+	if err != nil {
+		return err
+	}
+
+	s, err := c.PolicyRollback(id)
+	if err != nil {
+		return printApplyError(err)
+	}
+
+	if command.OutputOption() {
+		return command.PrintOutput(s)
+	}
+	fmt.Printf("Applied bpf programs configuration %s\n", s.ID)
+	return nil
+}
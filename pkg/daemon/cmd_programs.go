@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/linux-lock/bpflock/pkg/client"
+	"github.com/linux-lock/bpflock/pkg/command"
+)
+
+var (
+	programsCmd = &cobra.Command{
+		Use:   "programs",
+		Short: "Manage bpf programs",
+	}
+
+	programsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the bpf programs and their dependencies",
+		Long: "List the bpf programs configured in the bpflock agent in the order they are started. " +
+			"Programs are started by stages: a program starts once the programs it depends on are " +
+			"running, the programs of a stage start concurrently.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runProgramsList(); err != nil {
+				command.Fatalf("%s", err)
+			}
+		},
+	}
+
+	programsHost string
+)
+
+func init() {
+	flags := programsListCmd.Flags()
+	flags.StringVarP(&programsHost, "host", "H", "", "URI to server-side API")
+	command.AddOutputOption(programsListCmd)
+
+	programsCmd.AddCommand(programsListCmd)
+	RootCmd.AddCommand(programsCmd)
+}
+
+func runProgramsList() error {
+	c, err := client.NewClient(programsHost)
+	if err != nil {
+		return err
+	}
+
+	programs, err := c.ProgramsList()
+	if err != nil {
+		return err
+	}
+
+	if command.OutputOption() {
+		return command.PrintOutput(programs)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "STAGE\tPROGRAM\tAFTER\tREQUIRED\tSTATUS")
+	for _, p := range programs {
+		after := "-"
+		if len(p.After) > 0 {
+			after = strings.Join(p.After, ",")
+		}
+		status := ""
+		if p.Status != nil {
+			status = p.Status.Msg
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%s\n", p.Stage, p.Name, after, p.Required, status)
+	}
+	w.Flush()
+	return nil
+}
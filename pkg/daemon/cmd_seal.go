@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/linux-lock/bpflock/pkg/client"
+	"github.com/linux-lock/bpflock/pkg/command"
+)
+
+var (
+	sealCmd = &cobra.Command{
+		Use:   "seal",
+		Short: "Freeze the bpflock policy until the next reboot",
+		Long: "Seal the current policy of the bpflock agent. The configuration maps of the bpf programs " +
+			"are frozen, active exceptions are revoked, and the agent refuses any change that could loosen " +
+			"the policy, even after a restart. Only a reboot removes the seal.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runSeal(); err != nil {
+				command.Fatalf("%s", err)
+			}
+		},
+	}
+
+	sealHost string
+)
+
+func init() {
+	flags := sealCmd.Flags()
+	flags.StringVarP(&sealHost, "host", "H", "", "URI to server-side API")
+	command.AddOutputOption(sealCmd)
+
+	RootCmd.AddCommand(sealCmd)
+}
+
+func runSeal() error {
+	c, err := client.NewClient(sealHost)
+	if err != nil {
+		return err
+	}
+
+	st, err := c.Seal()
+	if err != nil {
+		return err
+	}
+
+	if command.OutputOption() {
+		return command.PrintOutput(st)
+	}
+	fmt.Printf("%s\n", st.Msg)
+	return nil
+}
@@ -8,16 +8,22 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/linux-lock/bpflock/api/v1/models"
 	"github.com/linux-lock/bpflock/pkg/bpf"
 	"github.com/linux-lock/bpflock/pkg/defaults"
+	"github.com/linux-lock/bpflock/pkg/eventlog"
 	"github.com/linux-lock/bpflock/pkg/eventqueue"
+	"github.com/linux-lock/bpflock/pkg/integrity"
 	"github.com/linux-lock/bpflock/pkg/lock"
 	"github.com/linux-lock/bpflock/pkg/logging"
 	"github.com/linux-lock/bpflock/pkg/logging/logfields"
 	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/policy"
+	"github.com/linux-lock/bpflock/pkg/seal"
 	"github.com/linux-lock/bpflock/pkg/status"
+	"github.com/linux-lock/bpflock/pkg/sysctl"
 )
 
 const (
@@ -38,6 +44,44 @@ type Daemon struct {
 
 	// event queue for serializing configuration updates to the daemon.
 	configModifyQueue *eventqueue.EventQueue
+
+	// eventLog stores security events locally, nil if disabled
+	eventLog *eventlog.Store
+
+	// integrity detects tampering with pinned bpf objects
+	integrityMutex       lock.Mutex
+	integrity            *integrity.Monitor
+	integrityWatchCancel context.CancelFunc
+	tamperReported       map[string]struct{}
+	tamperReapplied      string
+
+	// sysctl settings of the hardening profile accepted by the kernel
+	sysctlMutex   lock.Mutex
+	sysctlApplied []sysctl.Setting
+	sysctlDrift   string
+
+	// exceptions are the active temporary exceptions of bpf programs by ID
+	exceptionsMutex lock.Mutex
+	exceptions      map[string]*activeException
+
+	// sealPolicy is the sealed policy, nil if the policy is not sealed
+	sealMutex  lock.Mutex
+	sealPolicy *seal.Policy
+	sealErr    string
+
+	// refreshCtx is canceled once the policy is sealed or when the bpf
+	// programs configuration changes, it stops the refresh of the policy
+	// maps. stopUsb stops the USB authorizer when the configuration changes.
+	refreshCtx  context.Context
+	stopRefresh context.CancelFunc
+	stopUsb     context.CancelFunc
+	usbRestore  sync.Once
+
+	// policyHistory stores the applied bpf programs configurations,
+	// policyCurrent is the ID of the running one
+	policyMutex   lock.Mutex
+	policyHistory *policy.History
+	policyCurrent string
 }
 
 // DebugEnabled returns if debug mode is enabled.
@@ -61,10 +105,60 @@ func (d *Daemon) init() error {
 	}
 
 	// Let's apply system settings
-	applySystemSettings()
+	d.applySystemSettings()
+
+	// Start all bpf programs again, or keep those that did not change.
+	// Once sealed, the running programs are kept as they are.
+	sealed := d.restoreSeal()
+	start := bpf.BpfLsmEnable
+	switch {
+	case sealed:
+		start = d.startSealed
+	case option.Config.RestoreState:
+		start = bpf.BpfLsmRestore
+	}
+	if err := start(); err != nil {
+		return err
+	}
 
-	// Start all bpf programs again
-	return bpf.BpfLsmEnable()
+	updateSelfLock()
+
+	// The policy maps are frozen once sealed
+	if sealed {
+		d.stopRefresh()
+		return nil
+	}
+	updateExemptions()
+	updateRootfsLock()
+	updateTrust()
+	updateFsLock()
+	updateKmodLock()
+	updateNsLock()
+	updateNetLock()
+	d.resetExceptions()
+	return nil
+}
+
+// startRefresh starts the USB authorizer and the refresh of the policy maps
+// for the running bpf programs configuration, those of the previous one are
+// stopped. The policy maps are not refreshed anymore once sealed. Must be
+// called with integrityMutex held, or before the API is served.
+func (d *Daemon) startRefresh() {
+	if d.stopUsb != nil {
+		d.stopUsb()
+	}
+	var usbCtx context.Context
+	usbCtx, d.stopUsb = context.WithCancel(d.ctx)
+	d.startUsbAuthorizer(usbCtx)
+
+	d.stopRefresh()
+	if d.sealed() != nil {
+		return
+	}
+	d.refreshCtx, d.stopRefresh = context.WithCancel(d.ctx)
+	d.startRootfsLockRefresh(d.refreshCtx)
+	d.startExemptWatch(d.refreshCtx)
+	d.startTrustRefresh(d.refreshCtx)
 }
 
 // NewDaemon creates and returns a new Daemon with the parameters set in c.
@@ -74,6 +168,13 @@ func NewDaemon(ctx context.Context, cancel context.CancelFunc) (*Daemon, error)
 	// before we run the cleanup functions (see `cleanup.go` for implementation).
 	cleaner.SetCancelFunc(cancel)
 
+	// Refuse bpf programs configurations that are not signed, running bpf
+	// programs are kept
+	if rejected := option.Config.BpfConfigRejected; rejected != nil {
+		auditRejectedConfig(rejected)
+		return nil, rejected
+	}
+
 	// Validate the daemon-specific global options.
 	err := option.Config.Validate()
 	if err != nil {
@@ -85,28 +186,48 @@ func NewDaemon(ctx context.Context, cancel context.CancelFunc) (*Daemon, error)
 		log.WithError(err).Fatal("Unable to set memory resource limits")
 	}
 
-	// Remove any old bpf programs
-	bpf.BpfLsmDisable()
+	// Take over selflock protection of a previous instance, then remove
+	// any old bpf programs unless they are restored or sealed
+	updateSelfLock()
+	cleaner.cleanupFuncs.Add(clearSelfLock)
+	if !option.Config.RestoreState && !isSealed() {
+		bpf.BpfLsmDisable()
+	}
 
 	d := Daemon{
-		ctx:    ctx,
-		cancel: cancel,
+		ctx:        ctx,
+		cancel:     cancel,
+		exceptions: make(map[string]*activeException),
 	}
+	d.refreshCtx, d.stopRefresh = context.WithCancel(ctx)
 
 	d.configModifyQueue = eventqueue.NewEventQueueBuffered("config-modify-queue", ConfigModifyQueueSize)
 	d.configModifyQueue.Run()
 
 	if option.Config.RmBpfOnExit {
 		cleaner.cleanupFuncs.Add(func() {
-			bpf.BpfLsmDisable()
+			if d.sealed() == nil {
+				bpf.BpfLsmDisable()
+			}
 		})
 	}
 
+	if option.Config.EnableEventLog {
+		if err := d.startEventLog(); err != nil {
+			log.WithError(err).Error("Unable to start event log")
+		}
+	}
+
 	err = d.init()
 	if err != nil {
 		return nil, fmt.Errorf("error while initializing daemon: %w", err)
 	}
 
+	d.auditConfig()
+	d.startPolicyHistory()
+	d.startIntegrityMonitor()
+	d.startRefresh()
+
 	return &d, nil
 }
 
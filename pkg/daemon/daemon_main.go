@@ -83,10 +83,15 @@ var (
 // signal has been received. This is intended to be called by main.main().
 func Execute() {
 	interruptCh := cleaner.registerSigHandler()
-	if err := RootCmd.Execute(); err != nil {
+	cmd, err := RootCmd.ExecuteC()
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+	// Client subcommands return directly
+	if cmd != RootCmd {
+		return
+	}
 	<-interruptCh
 }
 
@@ -138,6 +143,9 @@ func initializeFlags() {
 	flags.String(option.BpfConfigDir, filepath.Join(defaults.ConfigurationPath, "bpf.d"), `Configuration directory that contains bpf programs configurations`)
 	option.BindEnv(option.BpfConfigDir)
 
+	flags.String(option.BpfConfigKeys, "", "File or directory of *.pub files of the hex encoded ed25519 public keys, one per line, that must sign bpf programs configurations, signatures are not required if empty")
+	option.BindEnv(option.BpfConfigKeys)
+
 	flags.BoolP(option.DebugArg, "D", false, "Enable debugging mode")
 	option.BindEnv(option.DebugArg)
 
@@ -158,6 +166,21 @@ func initializeFlags() {
 	//		`configmap example for syslog driver: {"syslog.level":"info","syslog.facility":"local5","syslog.tag":"bpflock"}`)
 	//option.BindEnv(option.LogOpt)
 
+	flags.Bool(option.EnableEventLog, defaults.EnableEventLog, "Store security events in the local event log")
+	option.BindEnv(option.EnableEventLog)
+
+	flags.Int(option.EventLogMaxSize, defaults.EventLogMaxSize, "Maximum size in MB of the local event log")
+	option.BindEnv(option.EventLogMaxSize)
+
+	flags.String(option.SysctlProfile, "", "Sysctl hardening profile: none, baseline or restricted. Overrides the profile of the sysctl section of the configuration file")
+	option.BindEnv(option.SysctlProfile)
+
+	flags.Bool(option.Restore, defaults.Restore, "Adopt the pinned bpf programs of a previous bpflock instance that match the configuration instead of replacing them")
+	option.BindEnv(option.Restore)
+
+	flags.Bool(option.TamperReapply, defaults.TamperReapply, "Re-apply bpf programs when tampering with their pins or with the bpf filesystem is detected")
+	option.BindEnv(option.TamperReapply)
+
 	flags.String(option.StateDir, defaults.RuntimePath, "Directory path to store runtime state")
 	option.BindEnv(option.StateDir)
 
@@ -186,18 +209,101 @@ func initializeFlags() {
 	flags.String(option.BpfRestrictBlock, "", "bpfrestrict block operations")
 	option.BindEnv(option.BpfRestrictBlock)
 
+	flags.String(option.BpfRestrictExempt, "", "bpfrestrict executables that are trusted as tasks of the initial pid namespace in baseline profile")
+	option.BindEnv(option.BpfRestrictExempt)
+
 	flags.String(option.KmodLockProfile, "", "kmodlock bpf security profile to restrict kernel module operations")
 	option.BindEnv(option.KmodLockProfile)
 
 	flags.String(option.KmodLockBlock, "", "kmodlock block operations")
 	option.BindEnv(option.KmodLockBlock)
 
+	flags.String(option.KmodLockAllow, "", "kmodlock modules or modalias patterns that containers may load")
+	option.BindEnv(option.KmodLockAllow)
+
+	flags.String(option.KmodLockDeny, "", "kmodlock modules or modalias patterns that may not be loaded")
+	option.BindEnv(option.KmodLockDeny)
+
+	flags.String(option.KmodLockExempt, "", "kmodlock executables that are trusted as tasks of the initial pid namespace in baseline profile")
+	option.BindEnv(option.KmodLockExempt)
+
 	flags.String(option.KimgLockProfile, "", "kimglock bpf security profile to restrict direct and indirect kernel image modification")
 	option.BindEnv(option.KimgLockProfile)
 
 	flags.String(option.KimgLockAllow, "", "kimglock allow operations")
 	option.BindEnv(option.KimgLockAllow)
 
+	flags.String(option.KimgLockExempt, "", "kimglock executables that are trusted as tasks of the initial pid namespace in baseline profile")
+	option.BindEnv(option.KimgLockExempt)
+
+	flags.String(option.SelfLockProfile, "", "selflock bpf security profile to protect bpflock, its bpf programs and configuration")
+	option.BindEnv(option.SelfLockProfile)
+
+	flags.String(option.SelfLockBlock, "", "selflock block operations")
+	option.BindEnv(option.SelfLockBlock)
+
+	flags.String(option.FsLockProfile, "", "fslock bpf security profile to restrict access to filesystems")
+	option.BindEnv(option.FsLockProfile)
+
+	flags.String(option.FsLockAllow, "", "fslock allowed filesystems")
+	option.BindEnv(option.FsLockAllow)
+
+	flags.String(option.RootfsLockProfile, "", "rootfslock bpf security profile to restrict writes to the root filesystem and sysfs")
+	option.BindEnv(option.RootfsLockProfile)
+
+	flags.String(option.RootfsLockAllow, "", "rootfslock directories where writes are allowed")
+	option.BindEnv(option.RootfsLockAllow)
+
+	flags.String(option.ExecLockProfile, "", "execlock bpf security profile to restrict execution of memory-backed and unlinked files")
+	option.BindEnv(option.ExecLockProfile)
+
+	flags.String(option.ExecLockExempt, "", "execlock executables that may execute memory-backed and unlinked files")
+	option.BindEnv(option.ExecLockExempt)
+
+	flags.String(option.NsLockProfile, "", "nslock bpf security profile to restrict creation of user, network and mount namespaces")
+	option.BindEnv(option.NsLockProfile)
+
+	flags.String(option.NsLockBlock, "", "nslock restricted namespace types")
+	option.BindEnv(option.NsLockBlock)
+
+	flags.String(option.NsLockAllow, "", "nslock namespace types that are never restricted")
+	option.BindEnv(option.NsLockAllow)
+
+	flags.String(option.NetLockProfile, "", "netlock bpf security profile to restrict creation of sockets of selected address families")
+	option.BindEnv(option.NetLockProfile)
+
+	flags.String(option.NetLockAllow, "", "netlock allowed socket families")
+	option.BindEnv(option.NetLockAllow)
+
+	flags.String(option.UsbLockProfile, "", "usblock bpf security profile to restrict USB device additions")
+	option.BindEnv(option.UsbLockProfile)
+
+	flags.String(option.UsbLockAllow, "", "usblock allowed USB devices as vendor:product IDs")
+	option.BindEnv(option.UsbLockAllow)
+
+	flags.String(option.UsbLockAllowClass, "", "usblock allowed USB device classes")
+	option.BindEnv(option.UsbLockAllowClass)
+
+	for _, name := range option.TrustPrograms {
+		flags.String(name+option.TrustSuffix, "", name+" namespace types that must be trusted in baseline profile")
+		option.BindEnv(name + option.TrustSuffix)
+
+		flags.String(name+option.TrustNsSuffix, "", name+" processes and containers whose namespaces are trusted in baseline profile")
+		option.BindEnv(name + option.TrustNsSuffix)
+
+		flags.String(name+option.AllowUIDSuffix, "", name+" users or uid ranges whose tasks are the only trusted ones in baseline profile")
+		option.BindEnv(name + option.AllowUIDSuffix)
+
+		flags.String(name+option.AllowGIDSuffix, "", name+" groups or gid ranges whose tasks are the only trusted ones in baseline profile")
+		option.BindEnv(name + option.AllowGIDSuffix)
+
+		flags.String(name+option.DenyUIDSuffix, "", name+" users or uid ranges whose tasks are never trusted in baseline profile")
+		option.BindEnv(name + option.DenyUIDSuffix)
+
+		flags.String(name+option.DenyGIDSuffix, "", name+" groups or gid ranges whose tasks are never trusted in baseline profile")
+		option.BindEnv(name + option.DenyGIDSuffix)
+	}
+
 	viper.BindPFlags(flags)
 }
 
@@ -370,6 +476,23 @@ func (d *Daemon) instantiateAPI() *operations.BpflockAPI {
 	// /healthz/
 	api.DaemonGetHealthzHandler = NewGetHealthzHandler(d)
 
+	// /events/history
+	api.EventsGetEventsHistoryHandler = NewGetEventsHistoryHandler(d)
+
+	// /programs/
+	api.ProgramsGetProgramsHandler = NewGetProgramsHandler(d)
+
+	// /programs/{name}/exceptions
+	api.ProgramsPostProgramsNameExceptionsHandler = NewPostProgramsNameExceptionsHandler(d)
+
+	// /seal/
+	api.DaemonPostSealHandler = NewPostSealHandler(d)
+
+	// /policy/
+	api.PolicyPutPolicyHandler = NewPutPolicyHandler(d)
+	api.PolicyGetPolicyHistoryHandler = NewGetPolicyHistoryHandler(d)
+	api.PolicyPostPolicyRollbackIDHandler = NewPostPolicyRollbackIDHandler(d)
+
 	// /config/
 	//api.DaemonGetConfigHandler = NewGetConfigHandler(d)
 	//api.DaemonPatchConfigHandler = NewPatchConfigHandler(d)
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	. "github.com/linux-lock/bpflock/api/v1/restapi/operations/events"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+	"github.com/linux-lock/bpflock/pkg/eventlog"
+	"github.com/linux-lock/bpflock/pkg/events"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/policy"
+	"github.com/linux-lock/bpflock/pkg/version"
+)
+
+// openEventLog opens the local event log with the node key.
+func openEventLog() (*eventlog.Store, error) {
+	auditDir := option.Config.GetAuditDir()
+	if err := os.MkdirAll(auditDir, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create audit directory: %w", err)
+	}
+	key, err := eventlog.LoadOrCreateKey(auditDir)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize := int64(option.Config.EventLogMaxSize) << 20
+	return eventlog.Open(eventlog.Config{
+		Dir:                option.Config.GetEventLogDir(),
+		MaxSize:            maxSize,
+		SegmentSize:        maxSize / defaults.EventLogSegments,
+		FlushInterval:      defaults.EventLogFlushInterval,
+		Key:                key,
+		HeadFile:           filepath.Join(auditDir, eventlog.HeadFile),
+		CheckpointInterval: defaults.AuditCheckpointInterval,
+	})
+}
+
+// auditRejectedConfig stores the rejected bpf programs configuration files
+// in the event log.
+func auditRejectedConfig(rejected *option.BpfConfigRejectedError) {
+	if !option.Config.EnableEventLog {
+		return
+	}
+	store, err := openEventLog()
+	if err != nil {
+		log.WithError(err).Warn("Unable to open event log")
+		return
+	}
+	for _, name := range rejected.Files {
+		msg := fmt.Sprintf("rejected bpf programs configuration %s: %s", filepath.Join(rejected.Dir, name), policy.ErrUnsigned)
+		if err := store.AppendRecord(eventlog.RecordConfig, msg); err != nil {
+			log.WithError(err).Warn("Unable to store audit record")
+		}
+	}
+	if err := store.Close(); err != nil {
+		log.WithError(err).Warn("Unable to close event log")
+	}
+}
+
+// startEventLog opens the local event log and starts storing the security
+// events reported by the bpf programs.
+func (d *Daemon) startEventLog() error {
+	store, err := openEventLog()
+	if err != nil {
+		return err
+	}
+
+	d.eventLog = store
+	d.auditRecord(eventlog.RecordLifecycle, fmt.Sprintf("%s %s started", components.BpflockAgentName, version.Version))
+	cleaner.cleanupFuncs.Add(func() {
+		d.auditRecord(eventlog.RecordLifecycle, fmt.Sprintf("%s stopped", components.BpflockAgentName))
+		if err := store.Close(); err != nil {
+			log.WithError(err).Warn("Unable to close event log")
+		}
+	})
+
+	go func() {
+		err := events.ReadTracePipe(d.ctx, func(ev *models.Event) {
+			if err := store.Append(ev); err != nil {
+				log.WithError(err).Warn("Unable to store event")
+			}
+		})
+		if err != nil {
+			log.WithError(err).Error("Unable to read bpf security events")
+		}
+	}()
+
+	log.WithField(logfields.Path, option.Config.GetEventLogDir()).Info("Started event log")
+
+	return nil
+}
+
+// auditRecord stores a lifecycle or configuration change in the event log.
+func (d *Daemon) auditRecord(recordType, msg string) {
+	if d.eventLog == nil {
+		return
+	}
+	if err := d.eventLog.AppendRecord(recordType, msg); err != nil {
+		log.WithError(err).Warn("Unable to store audit record")
+	}
+}
+
+// auditConfig stores the applied bpf programs configuration in the event
+// log.
+func (d *Daemon) auditConfig() {
+	for _, p := range option.Config.BpfPrograms() {
+		d.auditRecord(eventlog.RecordConfig, strings.TrimSpace(p.Name+" "+strings.Join(p.Args, " ")))
+	}
+}
+
+// filterFromParams returns the event log filter of the request parameters.
+func filterFromParams(params GetEventsHistoryParams) eventlog.Filter {
+	filter := eventlog.Filter{}
+	if params.Since != nil {
+		filter.Since = time.Time(*params.Since)
+	}
+	if params.Until != nil {
+		filter.Until = time.Time(*params.Until)
+	}
+	if params.Program != nil {
+		filter.Program = *params.Program
+	}
+	if params.Decision != nil {
+		filter.Decision = *params.Decision
+	}
+	if params.Container != nil {
+		filter.Container = *params.Container
+	}
+	if params.Cursor != nil {
+		filter.Cursor = *params.Cursor
+	}
+	if params.Limit != nil {
+		filter.Limit = int(*params.Limit)
+	}
+	return filter
+}
+
+type getEventsHistory struct {
+	daemon *Daemon
+}
+
+func NewGetEventsHistoryHandler(d *Daemon) GetEventsHistoryHandler {
+	return &getEventsHistory{daemon: d}
+}
+
+func (h *getEventsHistory) Handle(params GetEventsHistoryParams) middleware.Responder {
+	filter := filterFromParams(params)
+
+	var (
+		history *models.EventHistory
+		err     error
+	)
+	if h.daemon.eventLog != nil {
+		history, err = h.daemon.eventLog.Query(filter)
+	} else {
+		err = fmt.Errorf("event log is disabled")
+	}
+	if err != nil {
+		return NewGetEventsHistoryInternalServerError().WithPayload(models.Error(err.Error()))
+	}
+
+	return NewGetEventsHistoryOK().WithPayload(history)
+}
@@ -0,0 +1,293 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	. "github.com/linux-lock/bpflock/api/v1/restapi/operations/programs"
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+	"github.com/linux-lock/bpflock/pkg/eventlog"
+	"github.com/linux-lock/bpflock/pkg/exception"
+	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/selflock"
+)
+
+var (
+	errNoProgram        = errors.New("no such bpf program")
+	errInvalidException = errors.New("invalid exception")
+	errLoosening        = errors.New("exception would loosen the bpf program")
+	errTooManyActive    = errors.New("too many active exceptions")
+)
+
+// activeException is a granted exception and its slot in the exceptions
+// map of its program.
+type activeException struct {
+	model *models.Exception
+	slot  int
+	timer *time.Timer
+}
+
+// configuredProgram returns the configured bpf program name, nil if there
+// is none.
+func configuredProgram(name string) *models.BpfProgram {
+	for _, p := range option.Config.BpfPrograms() {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// hasRestrictedProfile returns true if p runs with the restricted profile.
+func hasRestrictedProfile(p *models.BpfProgram) bool {
+	for _, arg := range p.Args {
+		if arg == "--profile=restricted" {
+			return true
+		}
+	}
+	return false
+}
+
+func newExceptionID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// grantException loads a temporary exception of the bpf program name and
+// revokes it when it expires. Exceptions never apply to sealed programs
+// and to programs running with the restricted profile.
+func (d *Daemon) grantException(name string, req *models.ExceptionRequest) (*models.Exception, error) {
+	p := configuredProgram(name)
+	if p == nil {
+		return nil, errNoProgram
+	}
+	if !exception.Supported(name) {
+		return nil, fmt.Errorf("%w: %s does not support exceptions", errInvalidException, name)
+	}
+
+	var ttlStr, reason string
+	if req.TTL != nil {
+		ttlStr = *req.TTL
+	}
+	if req.Reason != nil {
+		reason = strings.TrimSpace(*req.Reason)
+	}
+	ttl, err := time.ParseDuration(ttlStr)
+	if err != nil || ttl <= 0 || ttl > defaults.ExceptionMaxTTL {
+		return nil, fmt.Errorf("%w: ttl must be a duration between 1s and %s", errInvalidException, defaults.ExceptionMaxTTL)
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", errInvalidException)
+	}
+
+	if hasRestrictedProfile(p) {
+		return nil, fmt.Errorf("%w: %s runs with the restricted profile", errLoosening, name)
+	}
+	if !hasBaselineProfile(p) {
+		return nil, fmt.Errorf("%w: exceptions only apply to the baseline profile", errInvalidException)
+	}
+	if d.sealed() != nil {
+		return nil, fmt.Errorf("%w: bpflock is sealed", errLoosening)
+	}
+
+	scope := exception.Scope{
+		Operation:  req.Operation,
+		Cgroup:     req.Cgroup,
+		Executable: req.Executable,
+	}
+	entry, err := exception.NewEntry(name, scope, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidException, err)
+	}
+	id, err := newExceptionID()
+	if err != nil {
+		return nil, err
+	}
+
+	d.exceptionsMutex.Lock()
+	defer d.exceptionsMutex.Unlock()
+
+	// Sealing revokes all exceptions with exceptionsMutex held
+	sealed, err := selflock.NewLocker(bpf.MapPrefixPath()).Sealed()
+	if err != nil {
+		return nil, err
+	}
+	if sealed {
+		return nil, fmt.Errorf("%w: bpflock is sealed", errLoosening)
+	}
+
+	l := exception.NewLocker(bpf.MapPrefixPath(), name)
+	if !l.Loaded() {
+		return nil, fmt.Errorf("%s is not running", name)
+	}
+	used := make(map[int]bool)
+	for _, e := range d.exceptions {
+		if e.model.Program == name {
+			used[e.slot] = true
+		}
+	}
+	slot := -1
+	for i := 0; i < exception.MaxExceptions; i++ {
+		if !used[i] {
+			slot = i
+			break
+		}
+	}
+	if slot < 0 {
+		return nil, fmt.Errorf("%w: %s has %d active exceptions", errTooManyActive, name, exception.MaxExceptions)
+	}
+	if err := l.Set(slot, entry); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	model := &models.Exception{
+		ID:         id,
+		Program:    name,
+		Operation:  scope.Operation,
+		Cgroup:     scope.Cgroup,
+		Executable: scope.Executable,
+		Reason:     reason,
+		Created:    strfmt.DateTime(now),
+		Expires:    strfmt.DateTime(now.Add(ttl)),
+	}
+	d.exceptions[id] = &activeException{
+		model: model,
+		slot:  slot,
+		timer: time.AfterFunc(ttl, func() {
+			if d.ctx.Err() == nil {
+				d.revokeException(id, "expired")
+			}
+		}),
+	}
+
+	msg := fmt.Sprintf("Exception %s granted to %s for %s with %s: %s", id, name, ttl, scope, reason)
+	log.Info(msg)
+	d.auditRecord(eventlog.RecordConfig, msg)
+
+	return model.DeepCopy(), nil
+}
+
+// revokeException revokes the active exception id.
+func (d *Daemon) revokeException(id, why string) {
+	d.exceptionsMutex.Lock()
+	defer d.exceptionsMutex.Unlock()
+
+	e, ok := d.exceptions[id]
+	if !ok {
+		return
+	}
+	d.revokeExceptionLocked(e, why)
+}
+
+// revokeExceptionLocked must be called with exceptionsMutex held.
+func (d *Daemon) revokeExceptionLocked(e *activeException, why string) {
+	e.timer.Stop()
+	delete(d.exceptions, e.model.ID)
+
+	l := exception.NewLocker(bpf.MapPrefixPath(), e.model.Program)
+	if l.Loaded() {
+		if err := l.Clear(e.slot); err != nil {
+			log.WithError(err).Warnf("Unable to revoke exception %s of %s, it expires in the kernel", e.model.ID, e.model.Program)
+		}
+	}
+
+	msg := fmt.Sprintf("Exception %s of %s revoked: %s", e.model.ID, e.model.Program, why)
+	log.Info(msg)
+	d.auditRecord(eventlog.RecordConfig, msg)
+}
+
+// revokeExceptions revokes all active exceptions.
+func (d *Daemon) revokeExceptions(why string) {
+	d.exceptionsMutex.Lock()
+	defer d.exceptionsMutex.Unlock()
+
+	for _, e := range d.exceptions {
+		d.revokeExceptionLocked(e, why)
+	}
+}
+
+// resetExceptions revokes the exceptions left in the kernel by a previous
+// bpflock instance, exceptions never survive a restart.
+func (d *Daemon) resetExceptions() {
+	for _, p := range option.Config.BpfPrograms() {
+		if !exception.Supported(p.Name) {
+			continue
+		}
+		l := exception.NewLocker(bpf.MapPrefixPath(), p.Name)
+		if !l.Loaded() {
+			continue
+		}
+		n, err := l.Reset()
+		if err != nil {
+			log.WithError(err).Warnf("Unable to revoke exceptions of %s, they expire in the kernel", p.Name)
+		}
+		if n > 0 {
+			msg := fmt.Sprintf("%d exceptions of %s revoked: bpflock restarted", n, p.Name)
+			log.Info(msg)
+			d.auditRecord(eventlog.RecordConfig, msg)
+		}
+	}
+}
+
+// activeExceptions returns the active exceptions, the first to expire
+// first.
+func (d *Daemon) activeExceptions() []*models.Exception {
+	d.exceptionsMutex.Lock()
+	defer d.exceptionsMutex.Unlock()
+
+	list := make([]*models.Exception, 0, len(d.exceptions))
+	for _, e := range d.exceptions {
+		list = append(list, e.model.DeepCopy())
+	}
+	sort.Slice(list, func(i, j int) bool {
+		ei, ej := time.Time(list[i].Expires), time.Time(list[j].Expires)
+		if ei.Equal(ej) {
+			return list[i].ID < list[j].ID
+		}
+		return ei.Before(ej)
+	})
+	return list
+}
+
+type postProgramsNameExceptions struct {
+	daemon *Daemon
+}
+
+func NewPostProgramsNameExceptionsHandler(d *Daemon) PostProgramsNameExceptionsHandler {
+	return &postProgramsNameExceptions{daemon: d}
+}
+
+func (h *postProgramsNameExceptions) Handle(params PostProgramsNameExceptionsParams) middleware.Responder {
+	e, err := h.daemon.grantException(params.Name, params.Exception)
+	switch {
+	case err == nil:
+		return NewPostProgramsNameExceptionsCreated().WithPayload(e)
+	case errors.Is(err, errNoProgram):
+		return NewPostProgramsNameExceptionsNotFound()
+	case errors.Is(err, errInvalidException):
+		return NewPostProgramsNameExceptionsBadRequest().WithPayload(models.Error(err.Error()))
+	case errors.Is(err, errLoosening):
+		return NewPostProgramsNameExceptionsForbidden().WithPayload(models.Error(err.Error()))
+	case errors.Is(err, errTooManyActive):
+		return NewPostProgramsNameExceptionsConflict().WithPayload(models.Error(err.Error()))
+	default:
+		return NewPostProgramsNameExceptionsInternalServerError().WithPayload(models.Error(err.Error()))
+	}
+}
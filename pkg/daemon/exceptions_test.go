@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package daemon
+
+import (
+	"errors"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/seal"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *DaemonSuite) TestGrantExceptionRefused(c *C) {
+	option.Config.SetBpfPrograms(bpfMeta(
+		&models.BpfProgram{Name: "kmodlock", Command: "kmodlock", Args: []string{"--profile=baseline"}},
+		&models.BpfProgram{Name: "kimglock", Command: "kimglock", Args: []string{"--profile=baseline"}},
+	), nil)
+
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name    string
+		program string
+		args    []string
+		sealed  bool
+		req     models.ExceptionRequest
+		err     error
+		msg     string
+	}{
+		{
+			name:    "no program",
+			program: "netlock",
+			req:     models.ExceptionRequest{TTL: str("10m"), Reason: str("maintenance")},
+			err:     errNoProgram,
+			msg:     "no such bpf program",
+		},
+		{
+			name:    "not supported",
+			program: "kimglock",
+			req:     models.ExceptionRequest{TTL: str("10m"), Reason: str("maintenance")},
+			err:     errInvalidException,
+			msg:     "invalid exception: kimglock does not support exceptions",
+		},
+		{
+			name:    "no ttl",
+			program: "kmodlock",
+			req:     models.ExceptionRequest{Reason: str("maintenance")},
+			err:     errInvalidException,
+			msg:     "invalid exception: ttl must be a duration between 1s and .*",
+		},
+		{
+			name:    "ttl over max",
+			program: "kmodlock",
+			req:     models.ExceptionRequest{TTL: str("1000h"), Reason: str("maintenance")},
+			err:     errInvalidException,
+			msg:     "invalid exception: ttl must be a duration between 1s and .*",
+		},
+		{
+			name:    "missing reason",
+			program: "kmodlock",
+			req:     models.ExceptionRequest{TTL: str("10m"), Reason: str(" ")},
+			err:     errInvalidException,
+			msg:     "invalid exception: a reason is required",
+		},
+		{
+			name:    "restricted profile",
+			program: "kmodlock",
+			args:    []string{"--profile=restricted"},
+			req:     models.ExceptionRequest{TTL: str("10m"), Reason: str("maintenance")},
+			err:     errLoosening,
+			msg:     "exception would loosen the bpf program: kmodlock runs with the restricted profile",
+		},
+		{
+			name:    "allow profile",
+			program: "kmodlock",
+			args:    []string{"--profile=allow"},
+			req:     models.ExceptionRequest{TTL: str("10m"), Reason: str("maintenance")},
+			err:     errInvalidException,
+			msg:     "invalid exception: exceptions only apply to the baseline profile",
+		},
+		{
+			name:    "sealed",
+			program: "kmodlock",
+			sealed:  true,
+			req:     models.ExceptionRequest{TTL: str("10m"), Reason: str("maintenance")},
+			err:     errLoosening,
+			msg:     "exception would loosen the bpf program: bpflock is sealed",
+		},
+	}
+
+	for _, tt := range tests {
+		if tt.args != nil {
+			configuredProgram(tt.program).Args = tt.args
+		}
+		d := &Daemon{exceptions: make(map[string]*activeException)}
+		if tt.sealed {
+			d.sealPolicy = &seal.Policy{Programs: option.Config.BpfPrograms()}
+		}
+		e, err := d.grantException(tt.program, &tt.req)
+		c.Assert(e, IsNil, Commentf("%s", tt.name))
+		c.Assert(err, ErrorMatches, tt.msg, Commentf("%s", tt.name))
+		c.Assert(errors.Is(err, tt.err), Equals, true, Commentf("%s", tt.name))
+		if tt.args != nil {
+			configuredProgram(tt.program).Args = []string{"--profile=baseline"}
+		}
+	}
+}
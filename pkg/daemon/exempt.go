@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"context"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/exempt"
+	"github.com/linux-lock/bpflock/pkg/option"
+)
+
+// exemptPaths returns the exempted executables of p, nil if p does not
+// exempt executables.
+func exemptPaths(p *models.BpfProgram) []string {
+	switch p.Name {
+	case components.ExecLock:
+		return exempt.ParseArgs(p.Args)
+	case components.KmodLock, components.BpfRestrict, components.KimgLock:
+		// Exempted executables are only trusted under baseline
+		if hasBaselineProfile(p) {
+			return exempt.ParseArgs(p.Args)
+		}
+	}
+	return nil
+}
+
+// updateExemptions resolves the exempted executables of the configuration
+// and loads them into their bpf programs.
+func updateExemptions() {
+	for _, p := range option.Config.BpfPrograms() {
+		paths := exemptPaths(p)
+		if paths == nil {
+			continue
+		}
+		if err := exempt.NewLocker(bpf.MapPrefixPath(), p.Name).Update(paths); err != nil {
+			log.WithError(err).Errorf("Unable to load %s exempted executables", p.Name)
+		}
+	}
+}
+
+// startExemptWatch resolves the exempted executables again when they are
+// replaced, like during package upgrades. It stops once ctx is canceled.
+func (d *Daemon) startExemptWatch(ctx context.Context) {
+	var paths []string
+	for _, p := range option.Config.BpfPrograms() {
+		paths = append(paths, exemptPaths(p)...)
+	}
+	if len(paths) == 0 {
+		return
+	}
+
+	if err := exempt.Watch(ctx, paths, updateExemptions); err != nil {
+		log.WithError(err).Warn("Unable to watch exempted executables, they are not resolved again on upgrades")
+	}
+}
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/fslock"
+	"github.com/linux-lock/bpflock/pkg/option"
+)
+
+// updateFsLock loads the allowed filesystems of the fslock configuration
+// into the fslock program. Nothing is restricted until then, it must run
+// after bpf programs are started.
+func updateFsLock() {
+	for _, p := range option.Config.BpfPrograms() {
+		if p.Name != components.FsLock {
+			continue
+		}
+		policy, err := fslock.ParseArgs(p.Args)
+		if err != nil {
+			log.WithError(err).Error("Invalid fslock configuration, filesystems are not restricted")
+			return
+		}
+		if err := fslock.NewLocker(bpf.MapPrefixPath()).Update(policy); err != nil {
+			log.WithError(err).Warn("Unable to update fslock allowed filesystems")
+		}
+		return
+	}
+}
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/eventlog"
+	"github.com/linux-lock/bpflock/pkg/integrity"
+	"github.com/linux-lock/bpflock/pkg/option"
+)
+
+// startIntegrityMonitor takes a snapshot of the pinned bpf objects and of
+// the bpf filesystem, then watches the pins for changes.
+func (d *Daemon) startIntegrityMonitor() {
+	m := integrity.NewMonitor(bpf.GetMapRoot(), bpf.MapPrefixPath())
+	if err := m.Snapshot(); err != nil {
+		log.WithError(err).Warn("Unable to monitor integrity of bpf programs")
+		return
+	}
+
+	d.integrityMutex.Lock()
+	d.integrity = m
+	d.watchIntegrity()
+	d.integrityMutex.Unlock()
+}
+
+// watchIntegrity (re)starts watching the pins. Must be called with
+// integrityMutex held.
+func (d *Daemon) watchIntegrity() {
+	if d.integrityWatchCancel != nil {
+		d.integrityWatchCancel()
+	}
+
+	ctx, cancel := context.WithCancel(d.ctx)
+	d.integrityWatchCancel = cancel
+	err := d.integrity.Watch(ctx, func() {
+		d.checkIntegrity()
+	})
+	if err != nil {
+		log.WithError(err).Warn("Unable to watch bpf pins, relying on periodic checks")
+	}
+}
+
+// reapplyBpfPrograms mounts the bpf filesystem if needed and starts all
+// bpf programs again. Must be called with integrityMutex held.
+func (d *Daemon) reapplyBpfPrograms() error {
+	if err := bpf.RecoverFS(); err != nil {
+		return err
+	}
+	bpf.BpfLsmDisable()
+	if err := bpf.BpfLsmEnable(); err != nil {
+		return err
+	}
+	updateBpfPrograms()
+	d.revokeExceptions("bpf programs were started again")
+	if err := d.integrity.Snapshot(); err != nil {
+		return err
+	}
+	d.watchIntegrity()
+	return nil
+}
+
+// updateBpfPrograms loads the configuration maps of all bpf programs, it
+// must run after they are started.
+func updateBpfPrograms() {
+	updateSelfLock()
+	updateFsLock()
+	updateKmodLock()
+	updateExemptions()
+	updateRootfsLock()
+	updateNsLock()
+	updateNetLock()
+	updateTrust()
+}
+
+// checkIntegrity compares the pinned bpf objects and the bpf filesystem
+// with their snapshot, reports tampering and updates the integrity status.
+func (d *Daemon) checkIntegrity() *models.Status {
+	d.integrityMutex.Lock()
+	defer d.integrityMutex.Unlock()
+
+	// Pins are removed on exit
+	if d.integrity == nil || d.ctx.Err() != nil {
+		return nil
+	}
+
+	problems := d.integrity.Check()
+	reported := make(map[string]struct{}, len(problems))
+	for _, p := range problems {
+		reported[p] = struct{}{}
+		if _, ok := d.tamperReported[p]; ok {
+			continue
+		}
+		log.WithField("tamper", p).Error("CRITICAL: tampering with bpflock detected")
+		d.auditRecord(eventlog.RecordTamper, p)
+	}
+	d.tamperReported = reported
+
+	var st *models.Status
+	switch {
+	case len(problems) == 0:
+		// The re-applied programs passed a check, the history stays in
+		// the event log
+		if d.tamperReapplied != "" {
+			log.Info("bpf programs verified after re-apply")
+			d.auditRecord(eventlog.RecordTamper, fmt.Sprintf("bpf programs verified at %s, cleared: %s",
+				time.Now().Format(time.RFC3339), d.tamperReapplied))
+			d.tamperReapplied = ""
+		}
+		st = &models.Status{
+			State: models.StatusStateOk,
+		}
+	default:
+		msg := strings.Join(problems, "; ")
+		st = &models.Status{
+			State: models.StatusStateFailure,
+			Msg:   msg,
+		}
+		// Once sealed, pins can not be replaced
+		if option.Config.TamperReapply && d.sealed() == nil {
+			if err := d.reapplyBpfPrograms(); err != nil {
+				log.WithError(err).Error("Unable to re-apply bpf programs after tampering")
+				st.Msg = fmt.Sprintf("%s; re-apply failed: %s", msg, err)
+			} else {
+				d.tamperReapplied = fmt.Sprintf("bpf programs re-applied at %s after tampering: %s",
+					time.Now().Format(time.RFC3339), msg)
+				d.auditRecord(eventlog.RecordTamper, d.tamperReapplied)
+				d.tamperReported = nil
+				st = &models.Status{
+					State: models.StatusStateWarning,
+					Msg:   d.tamperReapplied,
+				}
+			}
+		}
+	}
+
+	d.statusCollectMutex.Lock()
+	d.statusResponse.Integrity = st
+	d.statusCollectMutex.Unlock()
+
+	return st
+}
@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/kmodlock"
+	"github.com/linux-lock/bpflock/pkg/option"
+)
+
+// updateKmodLock loads the allowed and denied modules of the kmodlock
+// configuration into the kmodlock program. Until then containers may not
+// load any module under the baseline profile.
+func updateKmodLock() {
+	for _, p := range option.Config.BpfPrograms() {
+		if p.Name != components.KmodLock {
+			continue
+		}
+		if err := kmodlock.NewLocker(bpf.MapPrefixPath()).Update(kmodlock.ParseArgs(p.Args)); err != nil {
+			log.WithError(err).Error("Unable to load kmodlock module rules")
+		}
+		return
+	}
+}
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/netlock"
+	"github.com/linux-lock/bpflock/pkg/option"
+)
+
+// updateNetLock loads the restricted socket families of the netlock
+// configuration into the netlock program. Nothing is restricted until
+// then, it must run after bpf programs are started.
+func updateNetLock() {
+	for _, p := range option.Config.BpfPrograms() {
+		if p.Name != components.NetLock {
+			continue
+		}
+		policy, err := netlock.ParseArgs(p.Args)
+		if err != nil {
+			log.WithError(err).Error("Invalid netlock configuration, socket families are not restricted")
+			return
+		}
+		if err := netlock.NewLocker(bpf.MapPrefixPath()).Update(policy); err != nil {
+			log.WithError(err).Warn("Unable to update netlock restricted socket families")
+		}
+		return
+	}
+}
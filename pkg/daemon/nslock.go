@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/nslock"
+	"github.com/linux-lock/bpflock/pkg/option"
+)
+
+// updateNsLock loads the blocked and allowed namespace types of the nslock
+// configuration into the nslock program. Until then all namespace types
+// are restricted, it must run after bpf programs are started.
+func updateNsLock() {
+	for _, p := range option.Config.BpfPrograms() {
+		if p.Name != components.NsLock {
+			continue
+		}
+		policy, err := nslock.ParseArgs(p.Args)
+		if err != nil {
+			log.WithError(err).Error("Invalid nslock configuration, all namespace types are restricted")
+			return
+		}
+		if err := nslock.NewLocker(bpf.MapPrefixPath()).Update(policy); err != nil {
+			log.WithError(err).Warn("Unable to update nslock namespace types")
+		}
+		return
+	}
+}
@@ -0,0 +1,335 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/api/v1/restapi"
+	. "github.com/linux-lock/bpflock/api/v1/restapi/operations/policy"
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+	"github.com/linux-lock/bpflock/pkg/eventlog"
+	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/policy"
+	linuxrequirements "github.com/linux-lock/bpflock/pkg/requirements/linux"
+)
+
+var (
+	errInvalidPolicy = errors.New("invalid bpf programs configuration")
+	errPolicyRefused = errors.New("bpf programs configuration refused")
+	errSignedPolicy  = fmt.Errorf("%w: signed bpf programs configurations are required, update the configuration directory", errPolicyRefused)
+)
+
+// applyError is returned when the bpf programs of a configuration fail to
+// start, restored is true if the previous configuration is running again.
+type applyError struct {
+	err      error
+	restored bool
+}
+
+func (e *applyError) Error() string {
+	if e.restored {
+		return fmt.Sprintf("%s, previous configuration restored", e.err)
+	}
+	return fmt.Sprintf("%s, unable to restore previous configuration", e.err)
+}
+
+func (e *applyError) Unwrap() error {
+	return e.err
+}
+
+// identity formats the process pid and uid that applies a configuration.
+func identity(pid int32, uid uint32) string {
+	id := fmt.Sprintf("pid=%d uid=%d", pid, uid)
+	if u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10)); err == nil {
+		id = fmt.Sprintf("%s(%s)", id, u.Username)
+	}
+	return id
+}
+
+// agentIdentity is the identity of this bpflock agent.
+func agentIdentity() string {
+	return identity(int32(os.Getpid()), uint32(os.Getuid()))
+}
+
+// peerIdentity returns the identity of the API client that sent r.
+func peerIdentity(r *http.Request) string {
+	cred, ok := restapi.PeerCredentials(r.Context())
+	if !ok {
+		return "unknown"
+	}
+	return identity(cred.Pid, cred.Uid)
+}
+
+// startPolicyHistory records the configuration applied at startup.
+func (d *Daemon) startPolicyHistory() {
+	d.policyHistory = policy.NewHistory(option.Config.GetPolicyHistoryDir(), defaults.PolicyHistorySize)
+
+	source := "bpf-config-dir:" + option.Config.BpfConfigDir
+	if d.sealed() != nil {
+		source = "sealed"
+	}
+	meta, _ := option.Config.GetBpfPrograms()
+	if _, err := d.recordPolicy(meta, source, agentIdentity()); err != nil {
+		log.WithError(err).Warn("Unable to record the applied bpf programs configuration")
+	}
+}
+
+// recordPolicy stores meta in the policy history. Must be called with
+// policyMutex held, or before the API is served.
+func (d *Daemon) recordPolicy(meta *models.BpfMeta, source, identity string) (*policy.Snapshot, error) {
+	s, err := d.policyHistory.Record(meta, source, identity)
+	if err != nil {
+		return nil, err
+	}
+	d.policyCurrent = s.ID
+	return s, nil
+}
+
+// checkRatchets refuses configurations that loosen the policy: nothing can
+// change once sealed, and programs running with the restricted profile
+// must keep it and their launcher without loosening their arguments.
+func (d *Daemon) checkRatchets(meta *models.BpfMeta) error {
+	if sealed := d.sealed(); sealed != nil {
+		if changed := sealed.Changes(meta.Bpfspec.Programs); len(changed) > 0 {
+			return fmt.Errorf("%w: policy is sealed, %s can not change", errPolicyRefused, strings.Join(changed, ", "))
+		}
+	}
+
+	programs := make(map[string]*models.BpfProgram, len(meta.Bpfspec.Programs))
+	for _, p := range meta.Bpfspec.Programs {
+		programs[p.Name] = p
+	}
+	for _, p := range option.Config.BpfPrograms() {
+		if !hasRestrictedProfile(p) {
+			continue
+		}
+		n, ok := programs[p.Name]
+		if !ok || !hasRestrictedProfile(n) {
+			return fmt.Errorf("%w: %s runs with the restricted profile", errPolicyRefused, p.Name)
+		}
+		if n.Command != p.Command {
+			return fmt.Errorf("%w: %s runs with the restricted profile, its command can not change", errPolicyRefused, p.Name)
+		}
+		if loosened := option.LoosenedArgs(p, n); len(loosened) > 0 {
+			return fmt.Errorf("%w: %s runs with the restricted profile, %s can not be loosened",
+				errPolicyRefused, p.Name, strings.Join(loosened, ", "))
+		}
+	}
+	return nil
+}
+
+// applyPolicy validates meta, replaces the running bpf programs that
+// changed and records it in the policy history. All changes apply as one
+// transaction: if a bpf program fails to start, the previous
+// configuration is restored. The restore is best-effort: the programs that
+// changed are stopped before the new ones start, so they are not enforced
+// until their previous configuration runs again, and it may fail too.
+func (d *Daemon) applyPolicy(meta *models.BpfMeta, source, identity string) (*policy.Snapshot, error) {
+	d.policyMutex.Lock()
+	defer d.policyMutex.Unlock()
+
+	// The API documents are not signed, they would bypass the trusted keys
+	if option.Config.BpfConfigKeys != "" {
+		msg := fmt.Sprintf("Refused bpf programs configuration from %s by %s: %s", source, identity, errSignedPolicy)
+		log.Warn(msg)
+		d.auditRecord(eventlog.RecordConfig, msg)
+		return nil, errSignedPolicy
+	}
+
+	meta, err := option.ValidateBpfMeta(meta)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidPolicy, err)
+	}
+	if err := d.checkRatchets(meta); err != nil {
+		msg := fmt.Sprintf("Refused bpf programs configuration from %s by %s: %s", source, identity, err)
+		log.Warn(msg)
+		d.auditRecord(eventlog.RecordConfig, msg)
+		return nil, err
+	}
+
+	// Once sealed, the configuration that passed the ratchets is the
+	// running one and the configuration maps are frozen
+	if d.sealed() == nil {
+		prevMeta, prevDisabled := option.Config.GetBpfPrograms()
+		option.Config.SetBpfPrograms(meta, linuxrequirements.ProbePrograms(meta.Bpfspec.Programs, linuxrequirements.ProbeFeatures()))
+		if err := d.reloadBpfPrograms(bpf.BpfLsmApply, "bpf programs configuration changed"); err != nil {
+			option.Config.SetBpfPrograms(prevMeta, prevDisabled)
+			rerr := d.reloadBpfPrograms(bpf.BpfLsmRestore, "bpf programs configuration restored")
+			if rerr != nil {
+				log.WithError(rerr).Error("Unable to restore the previous bpf programs configuration")
+			}
+			err = &applyError{err: err, restored: rerr == nil}
+			msg := fmt.Sprintf("Unable to apply bpf programs configuration from %s by %s: %s", source, identity, err)
+			log.Error(msg)
+			d.auditRecord(eventlog.RecordConfig, msg)
+			return nil, err
+		}
+	}
+
+	s, err := d.recordPolicy(meta, source, identity)
+	if err != nil {
+		log.WithError(err).Warn("Unable to record the applied bpf programs configuration")
+		id, _, _ := policy.Digest(meta)
+		s = &policy.Snapshot{ID: id, Time: time.Now(), Source: source, Identity: identity}
+	}
+	msg := fmt.Sprintf("Policy %s applied from %s by %s", s.ID, source, identity)
+	log.Info(msg)
+	d.auditRecord(eventlog.RecordConfig, msg)
+	return s, nil
+}
+
+// rollbackPolicy applies again the snapshot id of the policy history.
+func (d *Daemon) rollbackPolicy(id, identity string) (*policy.Snapshot, *models.BpfMeta, error) {
+	s, meta, err := d.policyHistory.Load(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err = d.applyPolicy(meta, "rollback:"+s.ID, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta, _ = option.Config.GetBpfPrograms()
+	return s, meta, nil
+}
+
+// reloadBpfPrograms replaces the running bpf programs whose configuration
+// changed with start, then loads their configuration maps.
+func (d *Daemon) reloadBpfPrograms(start func() error, why string) error {
+	d.integrityMutex.Lock()
+	defer d.integrityMutex.Unlock()
+
+	if err := start(); err != nil {
+		return err
+	}
+	updateBpfPrograms()
+	d.revokeExceptions(why)
+	d.startRefresh()
+	if d.integrity == nil {
+		return nil
+	}
+	if err := d.integrity.Snapshot(); err != nil {
+		return err
+	}
+	d.watchIntegrity()
+	return nil
+}
+
+// applyErrorModel returns the failure of err with the outcome of each bpf
+// program if they failed to start.
+func applyErrorModel(err error) *models.PolicyApplyError {
+	m := &models.PolicyApplyError{Msg: err.Error()}
+	var ae *applyError
+	if errors.As(err, &ae) {
+		m.Restored = ae.restored
+	}
+	var be *bpf.ApplyError
+	if errors.As(err, &be) {
+		m.Programs = be.Programs
+	}
+	return m
+}
+
+func snapshotModel(s *policy.Snapshot, current string) *models.PolicySnapshot {
+	return &models.PolicySnapshot{
+		ID:       s.ID,
+		Time:     strfmt.DateTime(s.Time),
+		Source:   s.Source,
+		Identity: s.Identity,
+		Current:  s.ID == current,
+	}
+}
+
+type getPolicyHistory struct {
+	daemon *Daemon
+}
+
+func NewGetPolicyHistoryHandler(d *Daemon) GetPolicyHistoryHandler {
+	return &getPolicyHistory{daemon: d}
+}
+
+func (h *getPolicyHistory) Handle(params GetPolicyHistoryParams) middleware.Responder {
+	d := h.daemon
+	d.policyMutex.Lock()
+	defer d.policyMutex.Unlock()
+
+	list, err := d.policyHistory.List()
+	if err != nil {
+		return NewGetPolicyHistoryInternalServerError().WithPayload(models.Error(err.Error()))
+	}
+	history := &models.PolicyHistory{
+		Snapshots: make([]*models.PolicySnapshot, 0, len(list)),
+	}
+	current := d.policyCurrent
+	for _, s := range list {
+		history.Snapshots = append(history.Snapshots, snapshotModel(s, current))
+		// Only the last application is the current one
+		if s.ID == current {
+			current = ""
+		}
+	}
+	return NewGetPolicyHistoryOK().WithPayload(history)
+}
+
+type postPolicyRollbackID struct {
+	daemon *Daemon
+}
+
+func NewPostPolicyRollbackIDHandler(d *Daemon) PostPolicyRollbackIDHandler {
+	return &postPolicyRollbackID{daemon: d}
+}
+
+func (h *postPolicyRollbackID) Handle(params PostPolicyRollbackIDParams) middleware.Responder {
+	s, meta, err := h.daemon.rollbackPolicy(params.ID, peerIdentity(params.HTTPRequest))
+	switch {
+	case err == nil:
+		m := snapshotModel(s, s.ID)
+		m.Bpfmeta = meta
+		return NewPostPolicyRollbackIDOK().WithPayload(m)
+	case errors.Is(err, policy.ErrNotFound):
+		return NewPostPolicyRollbackIDNotFound()
+	case errors.Is(err, errInvalidPolicy), errors.Is(err, policy.ErrAmbiguous):
+		return NewPostPolicyRollbackIDBadRequest().WithPayload(models.Error(err.Error()))
+	case errors.Is(err, errPolicyRefused):
+		return NewPostPolicyRollbackIDForbidden().WithPayload(models.Error(err.Error()))
+	default:
+		return NewPostPolicyRollbackIDInternalServerError().WithPayload(applyErrorModel(err))
+	}
+}
+
+type putPolicy struct {
+	daemon *Daemon
+}
+
+func NewPutPolicyHandler(d *Daemon) PutPolicyHandler {
+	return &putPolicy{daemon: d}
+}
+
+func (h *putPolicy) Handle(params PutPolicyParams) middleware.Responder {
+	s, err := h.daemon.applyPolicy(params.Bpfmeta, "api", peerIdentity(params.HTTPRequest))
+	switch {
+	case err == nil:
+		m := snapshotModel(s, s.ID)
+		m.Bpfmeta, _ = option.Config.GetBpfPrograms()
+		return NewPutPolicyOK().WithPayload(m)
+	case errors.Is(err, errInvalidPolicy):
+		return NewPutPolicyBadRequest().WithPayload(models.Error(err.Error()))
+	case errors.Is(err, errPolicyRefused):
+		return NewPutPolicyForbidden().WithPayload(models.Error(err.Error()))
+	default:
+		return NewPutPolicyInternalServerError().WithPayload(applyErrorModel(err))
+	}
+}
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package daemon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/seal"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type DaemonSuite struct {
+	meta     *models.BpfMeta
+	disabled map[string]string
+}
+
+var _ = Suite(&DaemonSuite{})
+
+func (s *DaemonSuite) SetUpTest(c *C) {
+	s.meta, s.disabled = option.Config.GetBpfPrograms()
+}
+
+func (s *DaemonSuite) TearDownTest(c *C) {
+	option.Config.SetBpfPrograms(s.meta, s.disabled)
+}
+
+func bpfMeta(programs ...*models.BpfProgram) *models.BpfMeta {
+	return &models.BpfMeta{Bpfspec: &models.BpfSpec{Programs: programs}}
+}
+
+func (s *DaemonSuite) TestCheckRatchets(c *C) {
+	running := []*models.BpfProgram{
+		{Name: "kmodlock", Command: "kmodlock", Args: []string{"--profile=restricted", "--block=unsigned_module,unload_module"}},
+		{Name: "kimglock", Command: "kimglock", Args: []string{"--profile=baseline", "--allow=bpf_write_user"}},
+	}
+	option.Config.SetBpfPrograms(bpfMeta(running...), nil)
+
+	tests := []struct {
+		name     string
+		programs []*models.BpfProgram
+		sealed   bool
+		err      string
+	}{
+		{
+			name:     "unchanged",
+			programs: running,
+		},
+		{
+			name: "baseline loosened",
+			programs: []*models.BpfProgram{
+				running[0],
+				{Name: "kimglock", Command: "kimglock", Args: []string{"--profile=allow"}},
+			},
+		},
+		{
+			name: "restricted tightened",
+			programs: []*models.BpfProgram{
+				{Name: "kmodlock", Command: "kmodlock", Args: []string{"--profile=restricted"}},
+				running[1],
+			},
+		},
+		{
+			name:     "restricted removed",
+			programs: running[1:],
+			err:      "bpf programs configuration refused: kmodlock runs with the restricted profile",
+		},
+		{
+			name: "restricted profile changed",
+			programs: []*models.BpfProgram{
+				{Name: "kmodlock", Command: "kmodlock", Args: []string{"--profile=baseline", "--block=unsigned_module,unload_module"}},
+				running[1],
+			},
+			err: "bpf programs configuration refused: kmodlock runs with the restricted profile",
+		},
+		{
+			name: "restricted command changed",
+			programs: []*models.BpfProgram{
+				{Name: "kmodlock", Command: "/tmp/kmodlock", Args: []string{"--profile=restricted", "--block=unsigned_module,unload_module"}},
+				running[1],
+			},
+			err: "bpf programs configuration refused: kmodlock runs with the restricted profile, its command can not change",
+		},
+		{
+			name: "restricted args loosened",
+			programs: []*models.BpfProgram{
+				{Name: "kmodlock", Command: "kmodlock", Args: []string{"--profile=restricted", "--block=unsigned_module"}},
+				running[1],
+			},
+			err: "bpf programs configuration refused: kmodlock runs with the restricted profile, block can not be loosened",
+		},
+		{
+			name:     "sealed unchanged",
+			programs: running,
+			sealed:   true,
+		},
+		{
+			name: "sealed tightened",
+			programs: []*models.BpfProgram{
+				running[0],
+				{Name: "kimglock", Command: "kimglock", Args: []string{"--profile=restricted"}},
+			},
+			sealed: true,
+			err:    "bpf programs configuration refused: policy is sealed, kimglock can not change",
+		},
+		{
+			name: "sealed command changed",
+			programs: []*models.BpfProgram{
+				running[0],
+				{Name: "kimglock", Command: "/tmp/kimglock", Args: []string{"--profile=baseline", "--allow=bpf_write_user"}},
+			},
+			sealed: true,
+			err:    "bpf programs configuration refused: policy is sealed, kimglock can not change",
+		},
+	}
+
+	for _, tt := range tests {
+		d := &Daemon{}
+		if tt.sealed {
+			d.sealPolicy = &seal.Policy{Programs: running}
+		}
+		err := d.checkRatchets(bpfMeta(tt.programs...))
+		if tt.err == "" {
+			c.Assert(err, IsNil, Commentf("%s", tt.name))
+			continue
+		}
+		c.Assert(err, ErrorMatches, tt.err, Commentf("%s", tt.name))
+		c.Assert(errors.Is(err, errPolicyRefused), Equals, true, Commentf("%s", tt.name))
+	}
+}
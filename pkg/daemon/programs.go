@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/go-openapi/runtime/middleware"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	. "github.com/linux-lock/bpflock/api/v1/restapi/operations/programs"
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/option"
+)
+
+// isRunning returns true if the bpf program p is pinned.
+func isRunning(p *models.BpfProgram) bool {
+	_, err := os.Stat(filepath.Join(bpf.MapPrefixPath(), p.Name))
+	return err == nil
+}
+
+// getProgramsStatus returns the status of each configured bpf program, in
+// the order they are started.
+func getProgramsStatus() []*models.ProgramStatus {
+	meta, disabled := option.Config.GetBpfPrograms()
+	programs := meta.Bpfspec.Programs
+	deps := option.ProgramDependencies(programs)
+	stages, err := option.ProgramStages(programs)
+	if err != nil {
+		// Configurations with cycles are refused
+		stages = [][]*models.BpfProgram{programs}
+	}
+
+	statuses := make([]*models.ProgramStatus, 0, len(programs))
+	for n, stage := range stages {
+		for _, p := range stage {
+			statuses = append(statuses, programStatus(p, disabled, int32(n), deps[p.Name]))
+		}
+	}
+	return statuses
+}
+
+// programStatus returns the status of the bpf program p, disabled are the
+// disabled bpf programs.
+func programStatus(p *models.BpfProgram, disabled map[string]string, stage int32, after []string) *models.ProgramStatus {
+	s := &models.Status{}
+	if reason, ok := disabled[p.Name]; ok {
+		s.State = models.StatusStateDisabled
+		s.Msg = reason
+		if p.Required {
+			s.State = models.StatusStateFailure
+		}
+	} else if isRunning(p) {
+		s.State = models.StatusStateOk
+		s.Msg = "running"
+	} else {
+		s.State = models.StatusStateFailure
+		s.Msg = "not running"
+	}
+	if p.Required {
+		s.Msg += " (required)"
+	}
+	return &models.ProgramStatus{
+		Name:     p.Name,
+		Status:   s,
+		Required: p.Required,
+		Stage:    stage,
+		After:    after,
+	}
+}
+
+// missingPrograms returns the required bpf programs that are not running.
+func missingPrograms() []string {
+	var missing []string
+	meta, disabled := option.Config.GetBpfPrograms()
+	for _, p := range meta.Bpfspec.Programs {
+		if !p.Required {
+			continue
+		}
+		if _, ok := disabled[p.Name]; ok || !isRunning(p) {
+			missing = append(missing, p.Name)
+		}
+	}
+	return missing
+}
+
+type getPrograms struct {
+	daemon *Daemon
+}
+
+func NewGetProgramsHandler(d *Daemon) GetProgramsHandler {
+	return &getPrograms{daemon: d}
+}
+
+func (h *getPrograms) Handle(params GetProgramsParams) middleware.Responder {
+	return NewGetProgramsOK().WithPayload(getProgramsStatus())
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/rootfslock"
+)
+
+// rootfsLockPaths returns the directories where writes are allowed by the
+// rootfslock configuration, with the directories of bpflock itself. It
+// returns nil if rootfslock is not configured.
+func rootfsLockPaths() []string {
+	for _, p := range option.Config.BpfPrograms() {
+		if p.Name != components.RootfsLock {
+			continue
+		}
+		paths := rootfslock.ParseArgs(p.Args)
+		for _, dir := range []string{defaults.RuntimePath, option.Config.StateDir, option.Config.VarLibDir} {
+			if dir != "" {
+				paths = append(paths, dir)
+			}
+		}
+		return paths
+	}
+	return nil
+}
+
+// updateRootfsLock loads the allowed directories and the root filesystem
+// device into the rootfslock program. Nothing is restricted until then.
+func updateRootfsLock() {
+	paths := rootfsLockPaths()
+	if paths == nil {
+		return
+	}
+	if err := rootfslock.NewLocker(bpf.MapPrefixPath()).Update(paths); err != nil {
+		log.WithError(err).Error("Unable to load rootfslock allowed directories")
+	}
+}
+
+// startRootfsLockRefresh periodically updates the rootfslock allowed
+// directories, their inodes change when they are mounted again. It stops
+// once ctx is canceled.
+func (d *Daemon) startRootfsLockRefresh(ctx context.Context) {
+	if rootfsLockPaths() == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(defaults.RootfsLockRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				updateRootfsLock()
+			}
+		}
+	}()
+}
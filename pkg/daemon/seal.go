@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	. "github.com/linux-lock/bpflock/api/v1/restapi/operations/daemon"
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/eventlog"
+	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/seal"
+	"github.com/linux-lock/bpflock/pkg/selflock"
+)
+
+var errNoSelfLock = errors.New("the selflock bpf program is not running, it is required to seal the policy")
+
+func sealPolicyFile() string {
+	return filepath.Join(option.Config.StateDir, seal.PolicyFile)
+}
+
+// isSealed returns true if the selflock program holds the seal of the
+// policy, the seal is gone after a reboot.
+func isSealed() bool {
+	sealed, err := selflock.NewLocker(bpf.MapPrefixPath()).Sealed()
+	if err != nil {
+		log.WithError(err).Warn("Unable to read seal state")
+		return false
+	}
+	return sealed
+}
+
+func copyProgram(p *models.BpfProgram) *models.BpfProgram {
+	c := *p
+	c.Args = append([]string(nil), p.Args...)
+	return &c
+}
+
+// runningPrograms returns the configured bpf programs that are pinned.
+func runningPrograms() []*models.BpfProgram {
+	var programs []*models.BpfProgram
+	for _, p := range option.Config.BpfPrograms() {
+		if _, err := os.Stat(filepath.Join(bpf.MapPrefixPath(), p.Name)); err != nil {
+			continue
+		}
+		programs = append(programs, copyProgram(p))
+	}
+	return programs
+}
+
+// sealed returns the sealed policy, nil if the policy is not sealed.
+func (d *Daemon) sealed() *seal.Policy {
+	d.sealMutex.Lock()
+	defer d.sealMutex.Unlock()
+	return d.sealPolicy
+}
+
+// seal freezes the current policy: active exceptions are revoked, the
+// selflock restricted profile applies to all, the policy maps of the bpf
+// programs are frozen and they are not refreshed anymore. Sealing again
+// has no effect. It holds policyMutex and integrityMutex so no policy
+// change nor re-apply can replace the bpf programs while they are sealed.
+func (d *Daemon) seal() (*models.Status, error) {
+	d.policyMutex.Lock()
+	defer d.policyMutex.Unlock()
+	d.integrityMutex.Lock()
+	defer d.integrityMutex.Unlock()
+	d.sealMutex.Lock()
+	defer d.sealMutex.Unlock()
+
+	if d.sealPolicy != nil {
+		return d.sealStatusLocked(), nil
+	}
+
+	l := selflock.NewLocker(bpf.MapPrefixPath())
+	if !l.Loaded() {
+		return nil, errNoSelfLock
+	}
+
+	p := &seal.Policy{
+		Sealed:   time.Now(),
+		Programs: runningPrograms(),
+	}
+	if err := seal.WritePolicy(sealPolicyFile(), p); err != nil {
+		return nil, fmt.Errorf("unable to record sealed policy: %w", err)
+	}
+	// Protect the sealed policy record
+	updateSelfLock()
+
+	// No exception can be granted once selflock is sealed
+	d.exceptionsMutex.Lock()
+	for _, e := range d.exceptions {
+		d.revokeExceptionLocked(e, "bpflock is sealed")
+	}
+	err := l.Seal()
+	d.exceptionsMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	d.sealPolicy = p
+	d.stopRefresh()
+
+	n, err := seal.Freeze(bpf.MapPrefixPath())
+	if err != nil {
+		d.sealErr = err.Error()
+		log.WithError(err).Error("Unable to freeze the configuration of bpf programs")
+	}
+
+	names := make([]string, 0, len(p.Programs))
+	for _, prog := range p.Programs {
+		names = append(names, prog.Name)
+	}
+	msg := fmt.Sprintf("Policy sealed: %d policy maps of %s frozen", n, strings.Join(names, ", "))
+	log.Info(msg)
+	d.auditRecord(eventlog.RecordConfig, msg)
+
+	return d.sealStatusLocked(), nil
+}
+
+// restoreSeal keeps the policy that was sealed by a previous bpflock
+// instance: configuration changes are refused and the sealed one is
+// applied instead. It returns false if the policy is not sealed.
+func (d *Daemon) restoreSeal() bool {
+	if !isSealed() {
+		return false
+	}
+
+	p, err := seal.ReadPolicy(sealPolicyFile())
+	if err != nil || p == nil {
+		// Keep whatever is running, it can not be replaced anyway
+		log.WithError(err).Warn("Unable to read the sealed policy, keeping the running bpf programs")
+		p = &seal.Policy{Programs: runningPrograms()}
+	}
+
+	if changed := p.Changes(option.Config.BpfPrograms()); len(changed) > 0 {
+		msg := fmt.Sprintf("Policy is sealed, refused configuration changes of %s", strings.Join(changed, ", "))
+		log.Error(msg)
+		d.auditRecord(eventlog.RecordTamper, msg)
+	}
+
+	programs := make([]*models.BpfProgram, 0, len(p.Programs))
+	for _, prog := range p.Programs {
+		programs = append(programs, copyProgram(prog))
+	}
+	sort.Sort(option.BpfByPriority(programs))
+	option.Config.BpfMeta.Bpfspec.Programs = programs
+
+	d.sealMutex.Lock()
+	d.sealPolicy = p
+	d.sealMutex.Unlock()
+
+	log.Info("Policy is sealed, adopting the running bpf programs")
+	return true
+}
+
+// startSealed adopts all the bpf programs of the sealed policy.
+func (d *Daemon) startSealed() error {
+	adopt := make(map[string]bool)
+	for _, p := range d.sealed().Programs {
+		adopt[p.Name] = true
+	}
+	return bpf.BpfLsmAdopt(adopt)
+}
+
+// sealStatus returns the status of the seal of the policy.
+func (d *Daemon) sealStatus() *models.Status {
+	d.sealMutex.Lock()
+	defer d.sealMutex.Unlock()
+	return d.sealStatusLocked()
+}
+
+// sealStatusLocked must be called with sealMutex held.
+func (d *Daemon) sealStatusLocked() *models.Status {
+	switch {
+	case d.sealPolicy == nil:
+		return &models.Status{
+			State: models.StatusStateDisabled,
+			Msg:   "Not sealed",
+		}
+	case d.sealErr != "":
+		return &models.Status{
+			State: models.StatusStateWarning,
+			Msg:   fmt.Sprintf("Sealed, but some policy maps are not frozen: %s", d.sealErr),
+		}
+	case d.sealPolicy.Sealed.IsZero():
+		return &models.Status{
+			State: models.StatusStateOk,
+			Msg:   "Sealed",
+		}
+	default:
+		return &models.Status{
+			State: models.StatusStateOk,
+			Msg:   fmt.Sprintf("Sealed since %s", d.sealPolicy.Sealed.Format(time.RFC3339)),
+		}
+	}
+}
+
+type postSeal struct {
+	daemon *Daemon
+}
+
+func NewPostSealHandler(d *Daemon) PostSealHandler {
+	return &postSeal{daemon: d}
+}
+
+func (h *postSeal) Handle(params PostSealParams) middleware.Responder {
+	st, err := h.daemon.seal()
+	switch {
+	case err == nil:
+		return NewPostSealOK().WithPayload(st)
+	case errors.Is(err, errNoSelfLock):
+		return NewPostSealPreconditionFailed().WithPayload(models.Error(err.Error()))
+	default:
+		return NewPostSealInternalServerError().WithPayload(models.Error(err.Error()))
+	}
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"os"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/selflock"
+)
+
+// selfLockPaths returns the pins and configuration paths that are protected
+// by selflock.
+func selfLockPaths() []string {
+	paths := []string{bpf.MapPrefixPath(), defaults.ConfigurationPath, sealPolicyFile()}
+	for _, p := range []string{option.Config.ConfigFile, option.Config.ConfigDir, option.Config.BpfConfigDir, option.Config.BpfConfigKeys} {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// updateSelfLock hands the selflock program the identity of this bpflock
+// instance and the current inodes of its pins and configuration. It must
+// run before touching pins left by a previous instance, and after pins
+// are created again. Once sealed, the protected inodes are frozen and only
+// the identity of a restarted instance is registered.
+func updateSelfLock() {
+	l := selflock.NewLocker(bpf.MapPrefixPath())
+	var err error
+	if isSealed() {
+		var agent *selflock.Agent
+		if agent, err = selflock.NewAgent(os.Getpid()); err == nil {
+			err = l.SetAgent(agent)
+		}
+	} else {
+		err = l.Update(os.Getpid(), selfLockPaths()...)
+	}
+	if err != nil {
+		log.WithError(err).Warn("Unable to update selflock protection")
+	}
+}
+
+// clearSelfLock removes the identity of this bpflock instance from the
+// selflock program when it stops.
+func clearSelfLock() {
+	if err := selflock.NewLocker(bpf.MapPrefixPath()).ClearAgent(); err != nil {
+		log.WithError(err).Warn("Unable to clear selflock agent")
+	}
+}
@@ -7,12 +7,14 @@ package daemon
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/cilium/cilium/pkg/rand"
 
 	"github.com/linux-lock/bpflock/api/v1/models"
 	. "github.com/linux-lock/bpflock/api/v1/restapi/operations/daemon"
+	"github.com/linux-lock/bpflock/pkg/defaults"
 	"github.com/linux-lock/bpflock/pkg/option"
 	"github.com/linux-lock/bpflock/pkg/status"
 	"github.com/linux-lock/bpflock/pkg/version"
@@ -33,14 +35,25 @@ func NewGetHealthzHandler(d *Daemon) GetHealthzHandler {
 
 func (h *getHealthz) Handle(params GetHealthzParams) middleware.Responder {
 	brief := params.Brief != nil && *params.Brief
-	sr := h.daemon.getStatus(brief)
+	missing := missingPrograms()
+	sr := h.daemon.status(brief, missing)
 
+	if len(missing) > 0 {
+		return NewGetHealthzServiceUnavailable().WithPayload(&sr)
+	}
 	return NewGetHealthzOK().WithPayload(&sr)
 }
 
 // getStatus returns the daemon status. If brief is provided a minimal version
 // of the StatusResponse is provided.
 func (d *Daemon) getStatus(brief bool) models.StatusResponse {
+	return d.status(brief, missingPrograms())
+}
+
+// status returns the daemon status, it is a failure if required bpf
+// programs are missing. Sysctl drift is only reported in sr.Sysctl, it is
+// not a reason to restart the agent.
+func (d *Daemon) status(brief bool, missing []string) models.StatusResponse {
 	staleProbes := d.statusCollector.GetStaleProbes()
 	stale := make(map[string]strfmt.DateTime, len(staleProbes))
 	for probe, startTime := range staleProbes {
@@ -56,12 +69,24 @@ func (d *Daemon) getStatus(brief bool) models.StatusResponse {
 	sr = *d.statusResponse.DeepCopy()
 
 	sr.Stale = stale
+	sr.Exceptions = d.activeExceptions()
+	sr.Seal = d.sealStatus()
 
 	// BpflockVersion definition
 	ver := version.GetBpflockVersion()
 	bpflockVer := fmt.Sprintf("%s (v%s-%s)", ver.Version, ver.Version, ver.Revision)
 
 	switch {
+	case len(missing) > 0:
+		sr.Bpflock = &models.Status{
+			State: models.StatusStateFailure,
+			Msg:   fmt.Sprintf("%s    required bpf programs not running: %s", bpflockVer, strings.Join(missing, ", ")),
+		}
+	case sr.Integrity != nil && sr.Integrity.State != models.StatusStateOk:
+		sr.Bpflock = &models.Status{
+			State: sr.Integrity.State,
+			Msg:   fmt.Sprintf("%s    %s", bpflockVer, sr.Integrity.Msg),
+		}
 	case len(sr.Stale) > 0:
 		msg := "Stale status data"
 		sr.Bpflock = &models.Status{
@@ -95,6 +120,49 @@ func (d *Daemon) startStatusCollector() {
 				// FIXME we have no field for the lock status
 			},
 		},
+		{
+			Name: "integrity",
+			Probe: func(ctx context.Context) (interface{}, error) {
+				return d.checkIntegrity(), nil
+			},
+			OnStatusUpdate: func(status status.Status) {
+				// The integrity status is updated by checkIntegrity()
+			},
+			Interval: func(failures int) time.Duration {
+				return defaults.IntegrityCheckInterval
+			},
+		},
+		{
+			Name: "sysctl",
+			Probe: func(ctx context.Context) (interface{}, error) {
+				return d.checkSysctl(), nil
+			},
+			OnStatusUpdate: func(status status.Status) {
+				d.statusCollectMutex.Lock()
+				defer d.statusCollectMutex.Unlock()
+
+				if s, ok := status.Data.(*models.Status); ok {
+					d.statusResponse.Sysctl = s
+				}
+			},
+			Interval: func(failures int) time.Duration {
+				return defaults.SysctlCheckInterval
+			},
+		},
+		{
+			Name: "programs",
+			Probe: func(ctx context.Context) (interface{}, error) {
+				return getProgramsStatus(), nil
+			},
+			OnStatusUpdate: func(status status.Status) {
+				d.statusCollectMutex.Lock()
+				defer d.statusCollectMutex.Unlock()
+
+				if s, ok := status.Data.([]*models.ProgramStatus); ok {
+					d.statusResponse.Programs = s
+				}
+			},
+		},
 	}
 
 	d.statusCollector = status.NewCollector(probes, status.Config{})
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/eventlog"
+	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/sysctl"
+)
+
+const (
+	// sysctlOriginalsFile records in the state directory the sysctl values
+	// before the hardening profile was applied
+	sysctlOriginalsFile = "sysctl-originals.json"
+)
+
+// applySystemSettings applies the sysctl hardening profile after recording
+// the original values, and restores them on exit if bpf programs are
+// removed on exit.
+func (d *Daemon) applySystemSettings() {
+	settings, err := sysctl.ProfileSettings(option.Config.SysctlProfile, option.Config.SysctlSettings)
+	if err != nil {
+		log.WithError(err).Error("Invalid sysctl configuration")
+		return
+	}
+	if len(settings) == 0 {
+		return
+	}
+
+	originals := filepath.Join(option.Config.StateDir, sysctlOriginalsFile)
+	if err := sysctl.SaveOriginals(originals, settings); err != nil {
+		log.WithError(err).Warn("Unable to record original sysctl values, they will not be restored")
+	}
+
+	// Apply settings one by one so a failure does not skip the others
+	applied := make([]sysctl.Setting, 0, len(settings))
+	for _, s := range settings {
+		if err := sysctl.ApplySettings([]sysctl.Setting{s}); err != nil {
+			log.WithError(err).Warn("Unable to apply sysctl hardening")
+		}
+		// Only watch the settings that the kernel accepted
+		if val, err := sysctl.Read(s.Name); err == nil && val == s.Val {
+			applied = append(applied, s)
+		}
+	}
+
+	d.sysctlMutex.Lock()
+	d.sysctlApplied = applied
+	d.sysctlMutex.Unlock()
+
+	d.auditRecord(eventlog.RecordConfig, fmt.Sprintf("sysctl profile=%s applied=%d/%d",
+		option.Config.SysctlProfile, len(applied), len(settings)))
+
+	if option.Config.RmBpfOnExit {
+		cleaner.cleanupFuncs.Add(func() {
+			if err := sysctl.RestoreOriginals(originals); err != nil {
+				log.WithError(err).Warn("Unable to restore original sysctl values")
+			}
+		})
+	}
+}
+
+// checkSysctl reports the applied sysctl settings that were changed since.
+func (d *Daemon) checkSysctl() *models.Status {
+	d.sysctlMutex.Lock()
+	defer d.sysctlMutex.Unlock()
+
+	if len(d.sysctlApplied) == 0 {
+		return &models.Status{
+			State: models.StatusStateDisabled,
+			Msg:   "no sysctl hardening applied",
+		}
+	}
+
+	drift := sysctl.Drift(d.sysctlApplied)
+	if len(drift) == 0 {
+		d.sysctlDrift = ""
+		return &models.Status{
+			State: models.StatusStateOk,
+			Msg:   fmt.Sprintf("profile %s: %d settings applied", option.Config.SysctlProfile, len(d.sysctlApplied)),
+		}
+	}
+
+	msg := "sysctl drift: " + strings.Join(drift, ", ")
+	if msg != d.sysctlDrift {
+		d.sysctlDrift = msg
+		log.Warn(msg)
+		d.auditRecord(eventlog.RecordConfig, msg)
+	}
+	return &models.Status{
+		State: models.StatusStateWarning,
+		Msg:   msg,
+	}
+}
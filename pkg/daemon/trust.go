@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/trust"
+)
+
+// hasBaselineProfile returns true if p runs with the baseline profile.
+// Trust exceptions only apply to baseline, and bpfrestrict denies the
+// bpf() calls of the agent under restricted.
+func hasBaselineProfile(p *models.BpfProgram) bool {
+	for _, arg := range p.Args {
+		if arg == "--profile=baseline" {
+			return true
+		}
+	}
+	return false
+}
+
+// updateTrust resolves the trusted namespaces and the user and group rules
+// of the programs that run with the baseline profile and loads them with
+// their trust criteria. Programs without a trust map are skipped.
+func updateTrust() {
+	for _, p := range option.Config.BpfPrograms() {
+		if !hasBaselineProfile(p) {
+			continue
+		}
+		policy, err := trust.ParseArgs(p.Args)
+		if err != nil {
+			log.WithError(err).Errorf("Invalid %s trust criteria, only the initial pid namespace is trusted", p.Name)
+			continue
+		}
+		if err := trust.NewLocker(bpf.MapPrefixPath(), p.Name).Update(policy); err != nil {
+			log.WithError(err).Warnf("Unable to update %s trusted namespaces", p.Name)
+		}
+	}
+}
+
+// startTrustRefresh periodically resolves the namespaces of trusted
+// processes and containers again, they change when they restart, and the
+// user and group names of rules, they may be created after the agent. It
+// stops once ctx is canceled.
+func (d *Daemon) startTrustRefresh(ctx context.Context) {
+	trusting := false
+	for _, p := range option.Config.BpfPrograms() {
+		policy, err := trust.ParseArgs(p.Args)
+		if err == nil && (policy.Trusting() || policy.HasRules()) && hasBaselineProfile(p) {
+			trusting = true
+			break
+		}
+	}
+	if !trusting {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(defaults.TrustRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				updateTrust()
+			}
+		}
+	}()
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+	"github.com/linux-lock/bpflock/pkg/eventlog"
+	"github.com/linux-lock/bpflock/pkg/option"
+	"github.com/linux-lock/bpflock/pkg/usblock"
+)
+
+// usbLockPolicy returns the policy of the usblock program, nil if usblock
+// is not loaded.
+func usbLockPolicy() (*usblock.Policy, error) {
+	meta, disabled := option.Config.GetBpfPrograms()
+	for _, p := range meta.Bpfspec.Programs {
+		if p.Name != components.UsbLock {
+			continue
+		}
+		if _, ok := disabled[p.Name]; ok {
+			return nil, nil
+		}
+		return usblock.ParseArgs(p.Args)
+	}
+	return nil, nil
+}
+
+// startUsbAuthorizer authorizes the USB devices allowed by the usblock
+// baseline profile, the usblock program stops the kernel from doing so. It
+// stops once ctx is canceled.
+func (d *Daemon) startUsbAuthorizer(ctx context.Context) {
+	policy, err := usbLockPolicy()
+	if err != nil {
+		log.WithError(err).Error("Invalid usblock configuration")
+		return
+	}
+	if policy == nil || policy.Profile == usblock.ProfileAllow {
+		return
+	}
+
+	if option.Config.RmBpfOnExit {
+		d.usbRestore.Do(func() {
+			cleaner.cleanupFuncs.Add(func() {
+				if err := usblock.RestoreDefaults(); err != nil {
+					log.WithError(err).Warn("Unable to restore USB authorized defaults")
+				}
+			})
+		})
+	}
+
+	if policy.Profile != usblock.ProfileBaseline {
+		return
+	}
+
+	go func() {
+		reported := make(map[string]struct{})
+		ticker := time.NewTicker(defaults.UsbAuthorizeInterval)
+		defer ticker.Stop()
+		for {
+			d.authorizeUsbDevices(policy, reported)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// authorizeUsbDevices authorizes allowed USB devices and reports blocked
+// devices once.
+func (d *Daemon) authorizeUsbDevices(policy *usblock.Policy, reported map[string]struct{}) {
+	authorized, blocked, err := usblock.Authorize(policy)
+	if err != nil {
+		log.WithError(err).Debug("Unable to list USB devices")
+		return
+	}
+	for _, dev := range authorized {
+		log.WithField("device", dev.Name).Infof("Authorized allowed USB device %s", dev.ID())
+	}
+
+	current := make(map[string]struct{}, len(blocked))
+	for _, dev := range blocked {
+		key := dev.Name + " " + dev.ID()
+		current[key] = struct{}{}
+		if _, ok := reported[key]; ok {
+			continue
+		}
+		reported[key] = struct{}{}
+		log.WithField("device", dev.Name).Warnf("Blocked USB device %s class=%02x", dev.ID(), dev.Class)
+		d.auditRecord(eventlog.RecordEvent, fmt.Sprintf("usblock blocked USB device %s %s class=%02x", dev.Name, dev.ID(), dev.Class))
+	}
+	// Report again devices that are unplugged and plugged back
+	for key := range reported {
+		if _, ok := current[key]; !ok {
+			delete(reported, key)
+		}
+	}
+}
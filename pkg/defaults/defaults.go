@@ -75,10 +75,22 @@ const (
 	// ExecTimeout is a timeout for executing commands.
 	ExecTimeout = 30 * time.Second
 
+	// ProgramRetries is the default number of retries of a bpf program
+	// with the retry on-failure policy
+	ProgramRetries = 3
+
+	// ProgramRetryBackoff is the default delay before the first retry of a
+	// bpf program, it is doubled after each retry
+	ProgramRetryBackoff = time.Second
+
 	// ClientConnectTimeout is the time the bpflock agent client is
 	// (optionally) waiting before returning an error.
 	ClientConnectTimeout = 30 * time.Second
 
+	// ClientApplyTimeout is the time the bpflock agent client is waiting
+	// for a configuration to be applied, bpf programs are started again.
+	ClientApplyTimeout = 2 * time.Minute
+
 	// StatusCollectorInterval is the interval between a probe invocations
 	StatusCollectorInterval = 5 * time.Second
 
@@ -96,6 +108,69 @@ const (
 	// EnableIPv6 is the default value for IPv6 enablement
 	EnableIPv6 = true
 
+	// EnableEventLog is the default value for option.EnableEventLog
+	EnableEventLog = true
+
+	// EventLogDir is the directory of the event log relative to VariablePath
+	EventLogDir = "events"
+
+	// AuditDir is the directory of the node key and of the signed head of
+	// the event log relative to VariablePath, they must persist across
+	// reboots to detect truncation
+	AuditDir = "audit"
+
+	// EventLogMaxSize is the default maximum size in MB of the event log
+	EventLogMaxSize = 16
+
+	// EventLogSegments is the number of segments the event log is split into
+	EventLogSegments = 8
+
+	// PolicyHistoryDir is the directory of the applied bpf programs
+	// configurations relative to VariablePath
+	PolicyHistoryDir = "policy"
+
+	// PolicyHistorySize is the number of applied bpf programs configurations
+	// that are kept
+	PolicyHistorySize = 64
+
+	// EventLogFlushInterval is the interval between writes of buffered
+	// events to the event log
+	EventLogFlushInterval = 30 * time.Second
+
+	// AuditCheckpointInterval is the interval between signed checkpoints of
+	// the event log
+	AuditCheckpointInterval = 15 * time.Minute
+
+	// IntegrityCheckInterval is the interval between checks of the pinned
+	// bpf objects and of the bpf filesystem
+	IntegrityCheckInterval = 10 * time.Second
+
+	// Restore is the default value for option.Restore
+	Restore = true
+
+	// SysctlCheckInterval is the interval between checks of the applied
+	// sysctl settings
+	SysctlCheckInterval = time.Minute
+
+	// UsbAuthorizeInterval is the interval between authorizations of the
+	// USB devices allowed by usblock
+	UsbAuthorizeInterval = time.Second
+
+	// RootfsLockRefreshInterval is the interval between updates of the
+	// directories allowed by rootfslock, to follow remounts
+	RootfsLockRefreshInterval = 30 * time.Second
+
+	// TrustRefreshInterval is the interval between resolutions of the
+	// namespaces of trusted processes and containers, to follow restarts
+	TrustRefreshInterval = 30 * time.Second
+
+	// ExceptionMaxTTL is the maximum duration of a temporary exception of
+	// a bpf program
+	ExceptionMaxTTL = 24 * time.Hour
+
+	// TamperReapply is the default value for option.TamperReapply
+	TamperReapply = false
+
 	// BpfProfileAllow is the "allow" "none" or "privileged" profile
 	BpfProfileAllow      = "allow"
 	BpfProfileNone       = "none"
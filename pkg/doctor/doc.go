@@ -0,0 +1,6 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package doctor checks that a machine meets the requirements of bpflock
+// without starting the daemon.
+package doctor
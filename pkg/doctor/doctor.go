@@ -0,0 +1,263 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package doctor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/mountinfo"
+	linuxrequirements "github.com/linux-lock/bpflock/pkg/requirements/linux"
+	"github.com/linux-lock/bpflock/pkg/version"
+
+	"golang.org/x/sys/unix"
+)
+
+// State is the result of a check.
+type State string
+
+const (
+	Pass State = "pass"
+	Warn State = "warn"
+	Fail State = "fail"
+
+	// LockdownFile is the active kernel lockdown mode
+	LockdownFile = "/sys/kernel/security/lockdown"
+
+	// initPidNS is the pid namespace of the initial namespace
+	initPidNS = "pid:[4026531836]"
+
+	bpffsType   = "bpf"
+	cgroup2Type = "cgroup2"
+)
+
+// Check is the result of one preflight check.
+type Check struct {
+	Name    string `json:"name"`
+	State   State  `json:"state"`
+	Message string `json:"message"`
+}
+
+// Report is the result of all preflight checks.
+type Report struct {
+	Checks []Check `json:"checks"`
+	OK     bool    `json:"ok"`
+}
+
+func (r *Report) add(name string, res State, format string, a ...interface{}) {
+	r.Checks = append(r.Checks, Check{Name: name, State: res, Message: fmt.Sprintf(format, a...)})
+	if res == Fail {
+		r.OK = false
+	}
+}
+
+// Config of the checks.
+type Config struct {
+	// BpfDir is the directory of the bpf program launchers
+	BpfDir string
+
+	// MapRoot is where the bpf filesystem is expected to be mounted
+	MapRoot string
+
+	// Programs are the configured bpf programs
+	Programs []*models.BpfProgram
+}
+
+// replaced in tests
+var (
+	getKernelVersion = version.GetKernelVersion
+	getMountInfo     = mountinfo.GetMountInfo
+	probeFeatures    = linuxrequirements.ProbeFeatures
+	readFile         = ioutil.ReadFile
+	geteuid          = unix.Geteuid
+	readPidNS        = func() (string, error) { return os.Readlink("/proc/self/ns/pid") }
+	getMemlock       = func() (*unix.Rlimit, error) {
+		var rl unix.Rlimit
+		err := unix.Getrlimit(unix.RLIMIT_MEMLOCK, &rl)
+		return &rl, err
+	}
+)
+
+// Diagnose runs all checks.
+func Diagnose(cfg Config) *Report {
+	r := &Report{OK: true}
+
+	checkKernelVersion(r)
+	checkLsm(r)
+	checkBTF(r, cfg.Programs)
+	checkMounts(r, cfg.MapRoot)
+	checkMemlock(r)
+	checkLaunchers(r, cfg.BpfDir, cfg.Programs)
+	checkPidNS(r)
+
+	return r
+}
+
+func checkKernelVersion(r *Report) {
+	const name = "kernel-version"
+	v, err := getKernelVersion()
+	if err != nil {
+		r.add(name, Fail, "unable to get kernel version: %v", err)
+		return
+	}
+	if !linuxrequirements.IsMinKernelVersion(v) {
+		r.add(name, Warn, "kernel %s is older than the minimal tested version %s, "+
+			"bpf programs are enabled according to the kernel features", v, linuxrequirements.MinKernelVersion)
+		return
+	}
+	r.add(name, Pass, "kernel %s satisfies >= %s", v, linuxrequirements.MinKernelVersion)
+}
+
+func checkLsm(r *Report) {
+	b, err := readFile(linuxrequirements.LsmConfigFile)
+	if err != nil {
+		r.add("lsm", Fail, "unable to read LSM list: %v", err)
+	} else if lsms := strings.TrimSpace(string(b)); !linuxrequirements.HasBpfLsm(lsms) {
+		r.add("lsm", Fail, "bpf is not in the active LSMs '%s', needs CONFIG_BPF_LSM=y and CONFIG_LSM=\"...,bpf\"", lsms)
+	} else {
+		r.add("lsm", Pass, "active LSMs: %s", lsms)
+	}
+
+	b, err = readFile(LockdownFile)
+	if err != nil {
+		r.add("lockdown", Warn, "unable to read lockdown mode: %v", err)
+		return
+	}
+	mode := lockdownMode(string(b))
+	r.add("lockdown", Pass, "lockdown mode: %s", mode)
+}
+
+// lockdownMode returns the selected mode, e.g. "none [integrity] confidentiality"
+func lockdownMode(s string) string {
+	for _, f := range strings.Fields(s) {
+		if strings.HasPrefix(f, "[") && strings.HasSuffix(f, "]") {
+			return strings.Trim(f, "[]")
+		}
+	}
+	return "unknown"
+}
+
+func checkBTF(r *Report, programs []*models.BpfProgram) {
+	f := probeFeatures()
+	if f.BTF == nil {
+		r.add("btf", Fail, "kernel BTF is not available: %v", f.BTFErr)
+	} else {
+		r.add("btf", Pass, "kernel BTF is available at %s", linuxrequirements.VmlinuxBTF)
+	}
+
+	disabled := linuxrequirements.ProbePrograms(programs, f)
+	for _, p := range programs {
+		name := "program-" + p.Name
+		if reason, ok := disabled[p.Name]; ok {
+			r.add(name, Warn, "%s will be disabled: %s", p.Name, reason)
+		} else {
+			r.add(name, Pass, "kernel supports %s", p.Name)
+		}
+	}
+}
+
+func checkMounts(r *Report, mapRoot string) {
+	mounts, err := getMountInfo()
+	if err != nil {
+		r.add("bpffs", Fail, "unable to read mountinfo: %v", err)
+		return
+	}
+
+	var bpffs, other []string
+	atRoot := 0
+	cgroup2 := ""
+	for _, m := range mounts {
+		switch m.FilesystemType {
+		case bpffsType:
+			if m.MountPoint == mapRoot {
+				atRoot++
+			} else {
+				other = append(other, m.MountPoint)
+			}
+			bpffs = append(bpffs, m.MountPoint)
+		case cgroup2Type:
+			if cgroup2 == "" {
+				cgroup2 = m.MountPoint
+			}
+		}
+	}
+
+	switch {
+	case atRoot == 0 && len(bpffs) == 0:
+		r.add("bpffs", Warn, "bpf filesystem is not mounted, bpflock will mount it at %s", mapRoot)
+	case atRoot == 0:
+		r.add("bpffs", Warn, "bpf filesystem is not mounted at %s but at: %s", mapRoot, strings.Join(bpffs, ", "))
+	case atRoot > 1:
+		r.add("bpffs", Fail, "%d bpf filesystems are mounted at %s, pins may be hidden", atRoot, mapRoot)
+	case len(other) > 0:
+		sort.Strings(other)
+		r.add("bpffs", Warn, "bpf filesystem is mounted at %s and also at: %s", mapRoot, strings.Join(other, ", "))
+	default:
+		r.add("bpffs", Pass, "bpf filesystem is mounted at %s", mapRoot)
+	}
+
+	if cgroup2 == "" {
+		r.add("cgroup2", Warn, "cgroup v2 is not mounted, container events can not be attributed")
+	} else {
+		r.add("cgroup2", Pass, "cgroup v2 is mounted at %s", cgroup2)
+	}
+}
+
+func checkMemlock(r *Report) {
+	rl, err := getMemlock()
+	if err != nil {
+		r.add("memlock", Warn, "unable to get memlock limit: %v", err)
+		return
+	}
+	if rl.Cur == unix.RLIM_INFINITY {
+		r.add("memlock", Pass, "memlock limit is unlimited")
+		return
+	}
+	if rl.Max != unix.RLIM_INFINITY && geteuid() != 0 {
+		r.add("memlock", Warn, "memlock limit is %d bytes and can not be raised to unlimited, "+
+			"loading bpf programs may fail on kernels without memcg accounting", rl.Max)
+		return
+	}
+	r.add("memlock", Pass, "memlock limit is %d bytes, bpflock raises it to unlimited", rl.Cur)
+}
+
+func checkLaunchers(r *Report, dir string, programs []*models.BpfProgram) {
+	if _, err := os.Stat(dir); err != nil {
+		r.add("launchers", Fail, "bpf programs directory: %v", err)
+		return
+	}
+	for _, p := range programs {
+		name := "launcher-" + p.Name
+		launcher := filepath.Join(dir, p.Command)
+		fi, err := os.Stat(launcher)
+		switch {
+		case err != nil:
+			r.add(name, Fail, "%v", err)
+		case !fi.Mode().IsRegular():
+			r.add(name, Fail, "%s is not a regular file", launcher)
+		case unix.Access(launcher, unix.X_OK) != nil:
+			r.add(name, Fail, "%s is not executable", launcher)
+		default:
+			r.add(name, Pass, "%s is executable", launcher)
+		}
+	}
+}
+
+func checkPidNS(r *Report) {
+	ns, err := readPidNS()
+	if err != nil {
+		r.add("pid-namespace", Warn, "unable to read pid namespace: %v", err)
+		return
+	}
+	if ns != initPidNS {
+		r.add("pid-namespace", Fail, "running in pid namespace %s, bpflock must run in the initial pid namespace", ns)
+		return
+	}
+	r.add("pid-namespace", Pass, "running in the initial pid namespace")
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package doctor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver/v4"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/mountinfo"
+	linuxrequirements "github.com/linux-lock/bpflock/pkg/requirements/linux"
+
+	"golang.org/x/sys/unix"
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type DoctorSuite struct {
+	files  map[string]string
+	mounts []*mountinfo.MountInfo
+	pidns  string
+}
+
+var _ = Suite(&DoctorSuite{})
+
+func (s *DoctorSuite) SetUpTest(c *C) {
+	s.files = map[string]string{
+		linuxrequirements.LsmConfigFile: "lockdown,capability,yama,bpf",
+		LockdownFile:                    "none [integrity] confidentiality\n",
+	}
+	s.mounts = []*mountinfo.MountInfo{
+		{MountPoint: "/sys/fs/bpf", FilesystemType: "bpf"},
+		{MountPoint: "/sys/fs/cgroup", FilesystemType: "cgroup2"},
+	}
+	s.pidns = initPidNS
+
+	getKernelVersion = func() (semver.Version, error) { return semver.MustParse("5.15.0"), nil }
+	getMountInfo = func() ([]*mountinfo.MountInfo, error) { return s.mounts, nil }
+	probeFeatures = func() *linuxrequirements.Features {
+		return &linuxrequirements.Features{LsmBpf: true, BTFErr: errors.New("no BTF")}
+	}
+	readFile = func(path string) ([]byte, error) {
+		if v, ok := s.files[path]; ok {
+			return []byte(v), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	readPidNS = func() (string, error) { return s.pidns, nil }
+	geteuid = func() int { return 0 }
+	getMemlock = func() (*unix.Rlimit, error) {
+		return &unix.Rlimit{Cur: 65536, Max: unix.RLIM_INFINITY}, nil
+	}
+}
+
+func results(r *Report) map[string]State {
+	m := make(map[string]State)
+	for _, ch := range r.Checks {
+		m[ch.Name] = ch.State
+	}
+	return m
+}
+
+func (s *DoctorSuite) TestRun(c *C) {
+	dir := c.MkDir()
+	c.Assert(os.WriteFile(filepath.Join(dir, "kmodlock"), nil, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dir, "bpfrestrict"), nil, 0644), IsNil)
+
+	r := Diagnose(Config{
+		BpfDir:  dir,
+		MapRoot: "/sys/fs/bpf",
+		Programs: []*models.BpfProgram{
+			{Name: "kmodlock", Command: "kmodlock"},
+			{Name: "bpfrestrict", Command: "bpfrestrict"},
+			{Name: "selflock", Command: "selflock"},
+		},
+	})
+	res := results(r)
+	c.Assert(r.OK, Equals, false)
+	c.Assert(res["kernel-version"], Equals, Pass)
+	c.Assert(res["lsm"], Equals, Pass)
+	c.Assert(res["lockdown"], Equals, Pass)
+	c.Assert(res["btf"], Equals, Fail)
+	c.Assert(res["program-kmodlock"], Equals, Warn)
+	c.Assert(res["bpffs"], Equals, Pass)
+	c.Assert(res["cgroup2"], Equals, Pass)
+	c.Assert(res["memlock"], Equals, Pass)
+	c.Assert(res["pid-namespace"], Equals, Pass)
+	c.Assert(res["launcher-kmodlock"], Equals, Pass)
+	c.Assert(res["launcher-selflock"], Equals, Fail)
+	if unix.Geteuid() != 0 {
+		c.Assert(res["launcher-bpfrestrict"], Equals, Fail)
+	}
+}
+
+func (s *DoctorSuite) TestOldKernelAndNoLsm(c *C) {
+	getKernelVersion = func() (semver.Version, error) { return semver.MustParse("5.10.0"), nil }
+	s.files[linuxrequirements.LsmConfigFile] = "lockdown,capability,apparmor"
+	s.pidns = "pid:[4026532300]"
+
+	r := &Report{OK: true}
+	checkKernelVersion(r)
+	checkLsm(r)
+	checkPidNS(r)
+	res := results(r)
+	c.Assert(res["kernel-version"], Equals, Warn)
+	c.Assert(res["lsm"], Equals, Fail)
+	c.Assert(res["pid-namespace"], Equals, Fail)
+	c.Assert(r.OK, Equals, false)
+}
+
+func (s *DoctorSuite) TestMounts(c *C) {
+	r := &Report{OK: true}
+	s.mounts = append(s.mounts, &mountinfo.MountInfo{MountPoint: "/sys/fs/bpf", FilesystemType: "bpf"})
+	checkMounts(r, "/sys/fs/bpf")
+	c.Assert(results(r)["bpffs"], Equals, Fail)
+
+	r = &Report{OK: true}
+	s.mounts = []*mountinfo.MountInfo{
+		{MountPoint: "/sys/fs/bpf", FilesystemType: "bpf"},
+		{MountPoint: "/run/bpf", FilesystemType: "bpf"},
+	}
+	checkMounts(r, "/sys/fs/bpf")
+	c.Assert(results(r)["bpffs"], Equals, Warn)
+	c.Assert(results(r)["cgroup2"], Equals, Warn)
+
+	r = &Report{OK: true}
+	s.mounts = nil
+	checkMounts(r, "/sys/fs/bpf")
+	c.Assert(results(r)["bpffs"], Equals, Warn)
+	c.Assert(r.OK, Equals, true)
+}
+
+func (s *DoctorSuite) TestLockdownMode(c *C) {
+	c.Assert(lockdownMode("[none] integrity confidentiality\n"), Equals, "none")
+	c.Assert(lockdownMode("none integrity [confidentiality]"), Equals, "confidentiality")
+	c.Assert(lockdownMode(""), Equals, "unknown")
+}
+
+func (s *DoctorSuite) TestMemlock(c *C) {
+	getMemlock = func() (*unix.Rlimit, error) {
+		return &unix.Rlimit{Cur: 65536, Max: 65536}, nil
+	}
+	r := &Report{OK: true}
+	checkMemlock(r)
+	c.Assert(results(r)["memlock"], Equals, Pass)
+
+	geteuid = func() int { return 1000 }
+	r = &Report{OK: true}
+	checkMemlock(r)
+	c.Assert(results(r)["memlock"], Equals, Warn)
+}
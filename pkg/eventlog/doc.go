@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package eventlog implements a local append-only and size bounded store
+// of security events.
+//
+// Events are appended to segment files, once a segment is full a new one is
+// started and the oldest segments are removed to keep the log under its
+// maximum size. Writes are buffered and segments are synced to disk only
+// at checkpoints, when they are sealed or when the store is closed to
+// reduce the wear of flash storage.
+//
+// The log is tamper-evident: each record holds the hash of the previous
+// one and checkpoints of the chain are signed with a node key. The last
+// checkpoint is also stored outside of the log as its head, so removing
+// records at the end of the log is detected by Verify.
+package eventlog
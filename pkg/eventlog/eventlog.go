@@ -0,0 +1,513 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package eventlog
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/lock"
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+)
+
+const (
+	subsystem = "eventlog"
+
+	segmentPrefix = "events-"
+	segmentSuffix = ".log"
+
+	// segmentFileRights are the access rights of segment files
+	segmentFileRights = 0640
+)
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+)
+
+// Config is the event log configuration
+type Config struct {
+	// Dir is the directory where segments are stored
+	Dir string
+
+	// MaxSize is the maximum size in bytes of all segments
+	MaxSize int64
+
+	// SegmentSize is the size in bytes after which a segment is sealed
+	SegmentSize int64
+
+	// FlushInterval is the interval between flushes of buffered events
+	// to the current segment. Zero disables periodic flushes.
+	FlushInterval time.Duration
+
+	// Key signs checkpoints of the records chain, if nil no checkpoints
+	// are written.
+	Key ed25519.PrivateKey
+
+	// HeadFile is the path where the signed head of the log is stored
+	// at each checkpoint.
+	HeadFile string
+
+	// CheckpointInterval is the interval between checkpoints. Checkpoints
+	// are also written when a segment is sealed and when the log is
+	// closed.
+	CheckpointInterval time.Duration
+}
+
+type segment struct {
+	// first is the sequence number of the first record of the segment
+	first int64
+	path  string
+	size  int64
+}
+
+// Store is an append-only, size bounded and segmented event log.
+type Store struct {
+	mutex lock.Mutex
+
+	config   Config
+	segments []*segment
+
+	file     *os.File
+	writer   *bufio.Writer
+	lastSeq  int64
+	lastHash string
+
+	// dirty is true if records were appended since the last checkpoint
+	dirty bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func segmentName(first int64) string {
+	return fmt.Sprintf("%s%016d%s", segmentPrefix, first, segmentSuffix)
+}
+
+// listSegments returns the segments of dir sorted by their first record.
+func listSegments(dir string) ([]*segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := make([]*segment, 0, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		first, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix), 10, 64)
+		if err != nil {
+			log.WithField(logfields.Path, name).Warn("Ignoring invalid event log segment name")
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		segments = append(segments, &segment{
+			first: first,
+			path:  filepath.Join(dir, name),
+			size:  info.Size(),
+		})
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].first < segments[j].first
+	})
+
+	return segments, nil
+}
+
+// Open opens or creates the event log of config.Dir. Writing starts after
+// the last complete record that was stored.
+func Open(config Config) (*Store, error) {
+	if config.MaxSize <= 0 || config.SegmentSize <= 0 || config.SegmentSize > config.MaxSize {
+		return nil, fmt.Errorf("invalid event log size: max %d segment %d", config.MaxSize, config.SegmentSize)
+	}
+
+	if err := os.MkdirAll(config.Dir, 0750); err != nil {
+		return nil, fmt.Errorf("unable to create event log directory: %w", err)
+	}
+
+	segments, err := listSegments(config.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list event log segments: %w", err)
+	}
+
+	s := &Store{
+		config:   config,
+		segments: segments,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	// Drop empty segments at the tail, and recover the last one
+	for len(s.segments) > 0 {
+		last := s.segments[len(s.segments)-1]
+		var lastRec *Record
+		size, partial, err := readRecords(last.path, func(rec *Record) error {
+			lastRec = rec
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to read event log segment %s: %w", last.path, err)
+		}
+		if lastRec == nil {
+			os.Remove(last.path)
+			s.segments = s.segments[:len(s.segments)-1]
+			continue
+		}
+		if partial {
+			log.WithField(logfields.Path, last.path).Warn("Discarding partially written record")
+			if err := os.Truncate(last.path, size); err != nil {
+				return nil, fmt.Errorf("unable to truncate event log segment %s: %w", last.path, err)
+			}
+		}
+		last.size = size
+		s.lastSeq = lastRec.Seq
+		s.lastHash = lastRec.Hash
+		break
+	}
+
+	if err := s.openCurrent(); err != nil {
+		return nil, err
+	}
+
+	go s.flusher()
+
+	return s, nil
+}
+
+// openCurrent opens the last segment for appending or creates a new one if
+// the last segment is full. Must be called with mutex held.
+func (s *Store) openCurrent() error {
+	var seg *segment
+	if n := len(s.segments); n > 0 && s.segments[n-1].size < s.config.SegmentSize {
+		seg = s.segments[n-1]
+	} else {
+		seg = &segment{
+			first: s.lastSeq + 1,
+			path:  filepath.Join(s.config.Dir, segmentName(s.lastSeq+1)),
+		}
+		s.segments = append(s.segments, seg)
+	}
+
+	f, err := os.OpenFile(seg.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, segmentFileRights)
+	if err != nil {
+		return fmt.Errorf("unable to open event log segment: %w", err)
+	}
+
+	s.file = f
+	s.writer = bufio.NewWriter(f)
+
+	return nil
+}
+
+// sealCurrent flushes and syncs the current segment to disk and closes it.
+// Must be called with mutex held.
+func (s *Store) sealCurrent() error {
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.writer.Flush()
+	if serr := s.file.Sync(); err == nil {
+		err = serr
+	}
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+	s.file = nil
+	s.writer = nil
+
+	return err
+}
+
+// trim removes the oldest segments until the log fits in its maximum size,
+// with room for the current segment to fill up. It returns true if segments
+// were removed. Must be called with mutex held.
+func (s *Store) trim() bool {
+	removed := false
+	var total int64
+	for _, seg := range s.segments {
+		total += seg.size
+	}
+
+	for total+s.config.SegmentSize > s.config.MaxSize && len(s.segments) > 1 {
+		oldest := s.segments[0]
+		if err := os.Remove(oldest.path); err != nil && !os.IsNotExist(err) {
+			log.WithError(err).WithField(logfields.Path, oldest.path).Warn("Unable to remove event log segment")
+			return removed
+		}
+		total -= oldest.size
+		s.segments = s.segments[1:]
+		removed = true
+	}
+	return removed
+}
+
+// write chains rec to the previous record and stores it. Must be called
+// with mutex held.
+func (s *Store) write(rec *Record) error {
+	if s.writer == nil {
+		return fmt.Errorf("event log is closed")
+	}
+
+	rec.Seq = s.lastSeq + 1
+	rec.Prev = s.lastHash
+	if err := rec.seal(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if _, err := s.writer.Write(data); err != nil {
+		return fmt.Errorf("unable to write record: %w", err)
+	}
+	s.lastSeq = rec.Seq
+	s.lastHash = rec.Hash
+	s.dirty = rec.Type != RecordCheckpoint
+
+	cur := s.segments[len(s.segments)-1]
+	cur.size += int64(len(data))
+	if cur.size >= s.config.SegmentSize && rec.Type != RecordCheckpoint {
+		if err := s.checkpoint(); err != nil {
+			log.WithError(err).Warn("Unable to write event log checkpoint")
+		}
+		if err := s.sealCurrent(); err != nil {
+			log.WithError(err).WithField(logfields.Path, cur.path).Warn("Unable to seal event log segment")
+		}
+		if err := s.openCurrent(); err != nil {
+			return err
+		}
+		// Record rotations so removed records are not reported as
+		// tampering, until the log fits with the rotation records
+		for s.trim() {
+			first := s.segments[0].first
+			if err := s.write(&Record{
+				Time:    strfmt.DateTime(now()),
+				Type:    RecordRotation,
+				Message: fmt.Sprintf("records before %d were removed", first),
+				First:   first,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkpoint appends a signed checkpoint record if records were appended
+// since the last one, syncs it to disk and updates the head file. Must be
+// called with mutex held.
+func (s *Store) checkpoint() error {
+	if s.config.Key == nil || !s.dirty {
+		return nil
+	}
+
+	rec := &Record{
+		Seq:  s.lastSeq + 1,
+		Time: strfmt.DateTime(now()),
+		Type: RecordCheckpoint,
+		Key:  KeyID(s.config.Key.Public().(ed25519.PublicKey)),
+		Prev: s.lastHash,
+	}
+	if err := rec.seal(); err != nil {
+		return err
+	}
+	hash, err := hex.DecodeString(rec.Hash)
+	if err != nil {
+		return err
+	}
+	rec.Signature = hex.EncodeToString(ed25519.Sign(s.config.Key, hash))
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if _, err := s.writer.Write(data); err != nil {
+		return fmt.Errorf("unable to write checkpoint: %w", err)
+	}
+	s.lastSeq = rec.Seq
+	s.lastHash = rec.Hash
+	s.dirty = false
+	s.segments[len(s.segments)-1].size += int64(len(data))
+
+	// The head must never be ahead of the records on disk
+	if err := s.writer.Flush(); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+
+	if s.config.HeadFile != "" {
+		return WriteHead(s.config.HeadFile, s.config.Key, rec.Seq, rec.Hash)
+	}
+
+	return nil
+}
+
+// Append stores ev in the log and sets its sequence number.
+func (s *Store) Append(ev *models.Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if time.Time(ev.Time).IsZero() {
+		ev.Time = strfmt.DateTime(now())
+	}
+	rec := &Record{
+		Time:  ev.Time,
+		Type:  RecordEvent,
+		Event: ev,
+	}
+	// The sequence number of the event is the one of its record
+	ev.Seq = s.lastSeq + 1
+
+	return s.write(rec)
+}
+
+// AppendRecord stores a record of the given type with msg in the log, it
+// is used to record lifecycle and configuration changes.
+func (s *Store) AppendRecord(recordType, msg string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.write(&Record{
+		Time:    strfmt.DateTime(now()),
+		Type:    recordType,
+		Message: msg,
+	})
+}
+
+// Checkpoint writes a signed checkpoint of the records chain.
+func (s *Store) Checkpoint() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.writer == nil {
+		return nil
+	}
+	return s.checkpoint()
+}
+
+// LastSeq returns the sequence number of the last stored record.
+func (s *Store) LastSeq() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.lastSeq
+}
+
+// Flush writes buffered records to the current segment without syncing it.
+func (s *Store) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.writer == nil {
+		return nil
+	}
+	return s.writer.Flush()
+}
+
+// tickerChan returns the channel of a ticker of interval d, or nil if d is
+// not set.
+func tickerChan(d time.Duration) (<-chan time.Time, func()) {
+	if d <= 0 {
+		return nil, func() {}
+	}
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
+
+func (s *Store) flusher() {
+	defer close(s.done)
+
+	flushC, stopFlush := tickerChan(s.config.FlushInterval)
+	defer stopFlush()
+	checkpointC, stopCheckpoint := tickerChan(s.config.CheckpointInterval)
+	defer stopCheckpoint()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-flushC:
+			if err := s.Flush(); err != nil {
+				log.WithError(err).Warn("Unable to flush event log")
+			}
+		case <-checkpointC:
+			if err := s.Checkpoint(); err != nil {
+				log.WithError(err).Warn("Unable to write event log checkpoint")
+			}
+		}
+	}
+}
+
+// Close writes a last checkpoint, syncs all stored records to disk and
+// closes the log.
+func (s *Store) Close() error {
+	s.mutex.Lock()
+	if s.writer == nil {
+		s.mutex.Unlock()
+		return nil
+	}
+	if err := s.checkpoint(); err != nil {
+		log.WithError(err).Warn("Unable to write event log checkpoint")
+	}
+	err := s.sealCurrent()
+	s.mutex.Unlock()
+
+	close(s.stop)
+	<-s.done
+
+	return err
+}
+
+// Query returns the stored events that match filter.
+func (s *Store) Query(filter Filter) (*models.EventHistory, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.writer != nil {
+		if err := s.writer.Flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	return querySegments(s.segments, filter)
+}
+
+// Query reads the event log stored in dir without opening it for writing
+// and returns the events that match filter. It can be used while the log is
+// not opened by the daemon.
+func Query(dir string, filter Filter) (*models.EventHistory, error) {
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list event log segments: %w", err)
+	}
+
+	return querySegments(segments, filter)
+}
+
+// now is replaced in tests.
+var now = time.Now
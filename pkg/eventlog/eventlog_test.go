@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package eventlog
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type EventLogSuite struct{}
+
+var _ = Suite(&EventLogSuite{})
+
+var baseTime = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newEvent(i int, program, decision string) *models.Event {
+	return &models.Event{
+		Time:      strfmt.DateTime(baseTime.Add(time.Duration(i) * time.Second)),
+		Program:   program,
+		Pid:       int32(1000 + i),
+		Comm:      "test",
+		Operation: "load_module",
+		Decision:  decision,
+	}
+}
+
+func (s *EventLogSuite) TestAppendQuery(c *C) {
+	dir := c.MkDir()
+	st, err := Open(Config{Dir: dir, MaxSize: 1 << 20, SegmentSize: 1 << 16})
+	c.Assert(err, IsNil)
+
+	for i := 0; i < 10; i++ {
+		decision := models.EventDecisionAllowed
+		if i%2 == 0 {
+			decision = models.EventDecisionDenied
+		}
+		c.Assert(st.Append(newEvent(i, "kmodlock", decision)), IsNil)
+	}
+	c.Assert(st.Append(newEvent(10, "bpfrestrict", models.EventDecisionDenied)), IsNil)
+	c.Assert(st.LastSeq(), Equals, int64(11))
+
+	h, err := st.Query(Filter{Decision: models.EventDecisionDenied, Program: "kmodlock"})
+	c.Assert(err, IsNil)
+	c.Assert(len(h.Events), Equals, 5)
+	c.Assert(h.Next, Equals, int64(0))
+
+	h, err = st.Query(Filter{Since: baseTime.Add(3 * time.Second), Until: baseTime.Add(5 * time.Second)})
+	c.Assert(err, IsNil)
+	c.Assert(len(h.Events), Equals, 3)
+	c.Assert(h.Events[0].Seq, Equals, int64(4))
+
+	// Pagination
+	h, err = st.Query(Filter{Limit: 4})
+	c.Assert(err, IsNil)
+	c.Assert(len(h.Events), Equals, 4)
+	c.Assert(h.Next, Equals, int64(4))
+	h, err = st.Query(Filter{Limit: 4, Cursor: h.Next})
+	c.Assert(err, IsNil)
+	c.Assert(h.Events[0].Seq, Equals, int64(5))
+	h, err = st.Query(Filter{Limit: 4, Cursor: 8})
+	c.Assert(err, IsNil)
+	c.Assert(len(h.Events), Equals, 3)
+	c.Assert(h.Next, Equals, int64(0))
+
+	c.Assert(st.Close(), IsNil)
+
+	// Offline query
+	h, err = Query(dir, Filter{Program: "bpfrestrict"})
+	c.Assert(err, IsNil)
+	c.Assert(len(h.Events), Equals, 1)
+	c.Assert(h.Events[0].Seq, Equals, int64(11))
+}
+
+func (s *EventLogSuite) TestRotation(c *C) {
+	dir := c.MkDir()
+	st, err := Open(Config{Dir: dir, MaxSize: 4096, SegmentSize: 1024})
+	c.Assert(err, IsNil)
+
+	for i := 0; i < 200; i++ {
+		c.Assert(st.Append(newEvent(i, "kmodlock", models.EventDecisionDenied)), IsNil)
+	}
+	c.Assert(st.Close(), IsNil)
+
+	segments, err := listSegments(dir)
+	c.Assert(err, IsNil)
+	var total int64
+	for _, seg := range segments {
+		total += seg.size
+	}
+	c.Assert(total <= 4096, Equals, true)
+
+	// Oldest events were dropped, newest are kept
+	h, err := Query(dir, Filter{Limit: MaxQueryLimit})
+	c.Assert(err, IsNil)
+	c.Assert(h.Events[0].Seq > 1, Equals, true)
+	c.Assert(h.Events[len(h.Events)-1].Pid, Equals, int32(1199))
+
+	// Rotated records are not reported as tampering, other removed
+	// records are
+	report, err := Verify(dir, nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(report.OK(), Equals, true, Commentf("%v", report.Problems))
+	c.Assert(report.Rotated, Equals, true)
+
+	c.Assert(os.Remove(segments[0].path), IsNil)
+	report, err = Verify(dir, nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(report.Rotated, Equals, false)
+	c.Assert(report.Problems, DeepEquals, []string{
+		fmt.Sprintf("records before %d were removed", segments[1].first),
+	})
+}
+
+func (s *EventLogSuite) TestRecover(c *C) {
+	dir := c.MkDir()
+	st, err := Open(Config{Dir: dir, MaxSize: 1 << 20, SegmentSize: 1 << 16})
+	c.Assert(err, IsNil)
+	for i := 0; i < 3; i++ {
+		c.Assert(st.Append(newEvent(i, "kmodlock", models.EventDecisionDenied)), IsNil)
+	}
+	c.Assert(st.Close(), IsNil)
+
+	// Simulate a partially written event
+	segments, err := listSegments(dir)
+	c.Assert(err, IsNil)
+	f, err := os.OpenFile(segments[0].path, os.O_WRONLY|os.O_APPEND, 0)
+	c.Assert(err, IsNil)
+	_, err = f.WriteString(`{"seq":4,"prog`)
+	c.Assert(err, IsNil)
+	f.Close()
+
+	st, err = Open(Config{Dir: dir, MaxSize: 1 << 20, SegmentSize: 1 << 16})
+	c.Assert(err, IsNil)
+	c.Assert(st.LastSeq(), Equals, int64(3))
+	ev := newEvent(3, "kmodlock", models.EventDecisionDenied)
+	c.Assert(st.Append(ev), IsNil)
+	c.Assert(ev.Seq, Equals, int64(4))
+
+	h, err := st.Query(Filter{})
+	c.Assert(err, IsNil)
+	c.Assert(len(h.Events), Equals, 4)
+	c.Assert(st.Close(), IsNil)
+}
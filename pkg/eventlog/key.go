@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package eventlog
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// KeyFile is the name of the node key that signs checkpoints
+	KeyFile = "audit.key"
+
+	// PublicKeyFile is the name of the public part of the node key
+	PublicKeyFile = "audit.pub"
+
+	// HeadFile is the name of the file that holds the signed head of the
+	// event log
+	HeadFile = "audit.head"
+)
+
+// KeyID returns the identifier of a public key.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// LoadOrCreateKey returns the node key stored in dir, a new key is
+// generated and stored if none exists.
+func LoadOrCreateKey(dir string) (ed25519.PrivateKey, error) {
+	path := filepath.Join(dir, KeyFile)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid node key %s", path)
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key.Seed())+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("unable to store node key: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, PublicKeyFile), []byte(hex.EncodeToString(pub)+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("unable to store node public key: %w", err)
+	}
+
+	return key, nil
+}
+
+// LoadPublicKey reads a hex encoded public key from path.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key %s", path)
+	}
+	return ed25519.PublicKey(pub), nil
+}
+
+// Head is the last checkpoint of the event log. It is stored outside of
+// the log to detect the removal of records at its end.
+type Head struct {
+	Seq       int64  `json:"seq"`
+	Hash      string `json:"hash"`
+	Key       string `json:"key"`
+	Signature string `json:"sig"`
+}
+
+func (h *Head) message() []byte {
+	return []byte(fmt.Sprintf("%d:%s", h.Seq, h.Hash))
+}
+
+// WriteHead signs and stores the head of the log in path.
+func WriteHead(path string, key ed25519.PrivateKey, seq int64, hash string) error {
+	h := &Head{
+		Seq:  seq,
+		Hash: hash,
+		Key:  KeyID(key.Public().(ed25519.PublicKey)),
+	}
+	h.Signature = hex.EncodeToString(ed25519.Sign(key, h.message()))
+
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadHead reads the head of the log stored in path.
+func ReadHead(path string) (*Head, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	h := &Head{}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("invalid head %s: %w", path, err)
+	}
+	return h, nil
+}
+
+// verifySignature checks that sig is a valid signature of msg by the key
+// identified by id.
+func verifySignature(keys map[string]ed25519.PublicKey, id string, msg []byte, sig string) error {
+	pub, ok := keys[id]
+	if !ok {
+		return fmt.Errorf("unknown key %s", id)
+	}
+	s, err := hex.DecodeString(sig)
+	if err != nil || !ed25519.Verify(pub, msg, s) {
+		return fmt.Errorf("invalid signature by key %s", id)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package eventlog
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+)
+
+const (
+	// DefaultQueryLimit is the number of events returned when no limit is set
+	DefaultQueryLimit = 100
+
+	// MaxQueryLimit is the maximum number of events returned by one query
+	MaxQueryLimit = 1000
+)
+
+// Filter selects events of the log, empty fields match all events.
+type Filter struct {
+	// Since and Until restrict the time range of events
+	Since time.Time
+	Until time.Time
+
+	// Program is the name of the bpf program that reported the event
+	Program string
+
+	// Decision is either allowed or denied
+	Decision string
+
+	// Container is the container ID or a prefix of it
+	Container string
+
+	// Cursor continues a previous query, only events with a greater
+	// sequence number are returned
+	Cursor int64
+
+	// Limit is the maximum number of returned events
+	Limit int
+}
+
+// Match returns true if ev is selected by the filter.
+func (f *Filter) Match(ev *models.Event) bool {
+	if ev.Seq <= f.Cursor {
+		return false
+	}
+
+	t := time.Time(ev.Time)
+	if !f.Since.IsZero() && t.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && t.After(f.Until) {
+		return false
+	}
+
+	if f.Program != "" && f.Program != ev.Program {
+		return false
+	}
+	if f.Decision != "" && f.Decision != ev.Decision {
+		return false
+	}
+	if f.Container != "" && !strings.HasPrefix(ev.Container, f.Container) {
+		return false
+	}
+
+	return true
+}
+
+func (f *Filter) limit() int {
+	switch {
+	case f.Limit <= 0:
+		return DefaultQueryLimit
+	case f.Limit > MaxQueryLimit:
+		return MaxQueryLimit
+	}
+	return f.Limit
+}
+
+// querySegments returns the events of segments that match filter. Next is
+// set to the sequence number of the last returned event if more events
+// match, so it can be passed as the cursor of the following query.
+func querySegments(segments []*segment, filter Filter) (*models.EventHistory, error) {
+	limit := filter.limit()
+	history := &models.EventHistory{
+		Events: make([]*models.Event, 0),
+	}
+
+	for i, seg := range segments {
+		// Skip segments that only hold events before the cursor
+		if i+1 < len(segments) && segments[i+1].first <= filter.Cursor+1 {
+			continue
+		}
+
+		more, err := querySegment(seg, &filter, limit, history)
+		if err != nil {
+			return nil, err
+		}
+		if more {
+			history.Next = history.Events[len(history.Events)-1].Seq
+			break
+		}
+	}
+
+	return history, nil
+}
+
+// querySegment appends the events of seg that match filter to history. It
+// returns true once limit is reached and another matching event exists.
+func querySegment(seg *segment, filter *Filter, limit int, history *models.EventHistory) (bool, error) {
+	more := false
+	_, _, err := readRecords(seg.path, func(rec *Record) error {
+		if rec.Type != RecordEvent || rec.Event == nil {
+			return nil
+		}
+		if !filter.Match(rec.Event) {
+			return nil
+		}
+		if len(history.Events) == limit {
+			more = true
+			return errStopReading
+		}
+		history.Events = append(history.Events, rec.Event)
+		return nil
+	})
+	if os.IsNotExist(err) {
+		// Removed by rotation meanwhile
+		return false, nil
+	}
+
+	return more, err
+}
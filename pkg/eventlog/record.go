@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package eventlog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+)
+
+const (
+	// RecordEvent is a security event reported by a bpf program
+	RecordEvent = "event"
+
+	// RecordLifecycle is a start or stop of the bpflock agent
+	RecordLifecycle = "lifecycle"
+
+	// RecordConfig is a change of the security configuration
+	RecordConfig = "config"
+
+	// RecordTamper is a detected tampering with bpflock
+	RecordTamper = "tamper"
+
+	// RecordCheckpoint is a signed checkpoint of the records chain
+	RecordCheckpoint = "checkpoint"
+
+	// RecordRotation is a removal of the oldest segments of the log
+	RecordRotation = "rotation"
+)
+
+// errStopReading stops reading records without returning an error
+var errStopReading = errors.New("stop reading records")
+
+// Record is an entry of the event log. Each record holds the hash of the
+// previous one, so modifying, removing or reordering records breaks the
+// chain.
+type Record struct {
+	Seq     int64           `json:"seq"`
+	Time    strfmt.DateTime `json:"time"`
+	Type    string          `json:"type"`
+	Event   *models.Event   `json:"event,omitempty"`
+	Message string          `json:"message,omitempty"`
+
+	// Key is the ID of the key that signed a checkpoint
+	Key string `json:"key,omitempty"`
+
+	// First is the first record that a rotation kept
+	First int64 `json:"first,omitempty"`
+
+	// Prev is the hash of the previous record
+	Prev string `json:"prev"`
+
+	// Hash is the hash of this record without its Hash and Signature
+	Hash string `json:"hash"`
+
+	// Signature is the signature of Hash by Key for checkpoints
+	Signature string `json:"sig,omitempty"`
+}
+
+// digest returns the hash of the record content.
+func (r *Record) digest() (string, error) {
+	c := *r
+	c.Hash = ""
+	c.Signature = ""
+	data, err := json.Marshal(&c)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// seal sets the hash of the record.
+func (r *Record) seal() error {
+	hash, err := r.digest()
+	if err != nil {
+		return err
+	}
+	r.Hash = hash
+	return nil
+}
+
+// readRecords calls fn for each record of the segment at path. It returns
+// the size of the complete records and true if the segment ends with a
+// partially written record.
+func readRecords(path string, fn func(rec *Record) error) (int64, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, false, err
+	}
+	defer f.Close()
+
+	var size int64
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			return size, len(line) > 0, nil
+		} else if err != nil {
+			return size, false, err
+		}
+
+		rec := &Record{}
+		if err := json.Unmarshal(bytes.TrimSpace(line), rec); err != nil {
+			if _, perr := r.Peek(1); perr == io.EOF {
+				// Partially written record at the end
+				return size, true, nil
+			}
+			return size, false, fmt.Errorf("invalid record at offset %d: %w", size, err)
+		}
+		size += int64(len(line))
+
+		if err := fn(rec); err == errStopReading {
+			return size, false, nil
+		} else if err != nil {
+			return size, false, err
+		}
+	}
+}
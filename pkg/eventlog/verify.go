@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package eventlog
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+)
+
+// Report is the result of the verification of an event log.
+type Report struct {
+	// First and Last are the sequence numbers of the first and last
+	// records of the log
+	First int64 `json:"first"`
+	Last  int64 `json:"last"`
+
+	Records     int `json:"records"`
+	Checkpoints int `json:"checkpoints"`
+
+	// LastCheckpoint is the sequence number of the last valid checkpoint
+	LastCheckpoint int64 `json:"last-checkpoint"`
+
+	// Rotated is true if the log does not start with the first record of
+	// the chain because older segments were removed by a rotation. Records
+	// that were removed otherwise are integrity violations.
+	Rotated bool `json:"rotated"`
+
+	// Problems are the integrity violations that were found
+	Problems []string `json:"problems,omitempty"`
+
+	// Warnings are findings that can not be verified
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// OK returns true if no integrity violation was found.
+func (r *Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *Report) problem(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+func (r *Report) warning(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Verify checks the integrity of the event log stored in dir. Records must
+// form an unbroken hash chain, checkpoints must be signed by one of keys,
+// and if head is set the log must contain the record it points to. Leading
+// records may only be missing if a rotation removed them. If keys
+// are given, signatures by other keys are integrity violations, otherwise
+// they can not be verified.
+func Verify(dir string, keys []ed25519.PublicKey, head *Head) (*Report, error) {
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list event log segments: %w", err)
+	}
+
+	trusted := make(map[string]ed25519.PublicKey, len(keys))
+	for _, k := range keys {
+		trusted[KeyID(k)] = k
+	}
+
+	report := &Report{}
+	signature := func(what string, err error) {
+		if len(trusted) > 0 {
+			report.problem("%s: %s", what, err)
+		} else {
+			report.warning("%s: %s", what, err)
+		}
+	}
+	var (
+		prev      *Record
+		headFound bool
+		cut       bool
+		rotated   int64
+	)
+
+	for i, seg := range segments {
+		segFirst := true
+		_, partial, err := readRecords(seg.path, func(rec *Record) error {
+			report.Records++
+
+			if segFirst && rec.Seq != seg.first {
+				report.problem("segment %s starts with record %d", seg.path, rec.Seq)
+			}
+			segFirst = false
+
+			if hash, err := rec.digest(); err != nil || hash != rec.Hash {
+				report.problem("record %d was modified", rec.Seq)
+			}
+
+			if prev == nil {
+				report.First = rec.Seq
+				cut = rec.Seq != 1 || rec.Prev != ""
+			} else {
+				if rec.Seq != prev.Seq+1 {
+					report.problem("records %d to %d are missing or reordered", prev.Seq+1, rec.Seq-1)
+				}
+				if rec.Prev != prev.Hash {
+					report.problem("record %d is not chained to record %d", rec.Seq, prev.Seq)
+				}
+			}
+
+			if rec.Type == RecordCheckpoint {
+				report.Checkpoints++
+				hash, _ := hex.DecodeString(rec.Hash)
+				if err := verifySignature(trusted, rec.Key, hash, rec.Signature); err != nil {
+					signature(fmt.Sprintf("checkpoint %d", rec.Seq), err)
+				} else {
+					report.LastCheckpoint = rec.Seq
+				}
+			}
+
+			if rec.Type == RecordRotation {
+				rotated = rec.First
+			}
+
+			if head != nil && rec.Seq == head.Seq {
+				headFound = true
+				if rec.Hash != head.Hash {
+					report.problem("record %d does not match the signed head", rec.Seq)
+				}
+			}
+
+			report.Last = rec.Seq
+			prev = rec
+			return nil
+		})
+		if err != nil {
+			report.problem("segment %s: %s", seg.path, err)
+			continue
+		}
+		if partial {
+			if i == len(segments)-1 {
+				report.warning("segment %s ends with a partially written record", seg.path)
+			} else {
+				report.problem("segment %s ends with a truncated record", seg.path)
+			}
+		}
+	}
+
+	// Only the oldest segments may be removed and the last rotation must
+	// have kept the first record
+	if cut {
+		if rotated > 0 && report.First <= rotated {
+			report.Rotated = true
+		} else {
+			report.problem("records before %d were removed", report.First)
+		}
+	}
+
+	if head != nil {
+		if err := verifySignature(trusted, head.Key, head.message(), head.Signature); err != nil {
+			signature("head", err)
+		}
+		if head.Seq > report.Last {
+			report.problem("log was truncated: last record is %d but signed head is %d", report.Last, head.Seq)
+		} else if !headFound && head.Seq >= report.First {
+			report.problem("record %d of the signed head is missing", head.Seq)
+		}
+	}
+
+	if report.Records > 0 && report.LastCheckpoint < report.Last {
+		from := report.LastCheckpoint + 1
+		if from < report.First {
+			from = report.First
+		}
+		report.warning("records %d to %d are not covered by a checkpoint yet", from, report.Last)
+	}
+
+	return report, nil
+}
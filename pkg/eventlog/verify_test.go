@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package eventlog
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+
+	. "gopkg.in/check.v1"
+)
+
+type VerifySuite struct {
+	dir  string
+	head string
+	key  ed25519.PrivateKey
+}
+
+var _ = Suite(&VerifySuite{})
+
+func (s *VerifySuite) SetUpTest(c *C) {
+	var err error
+	keyDir := c.MkDir()
+	s.key, err = LoadOrCreateKey(keyDir)
+	c.Assert(err, IsNil)
+	s.dir = c.MkDir()
+	s.head = filepath.Join(keyDir, HeadFile)
+
+	st, err := Open(Config{
+		Dir:         s.dir,
+		MaxSize:     1 << 20,
+		SegmentSize: 1 << 16,
+		Key:         s.key,
+		HeadFile:    s.head,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(st.AppendRecord(RecordLifecycle, "started"), IsNil)
+	for i := 0; i < 5; i++ {
+		c.Assert(st.Append(newEvent(i, "kmodlock", models.EventDecisionDenied)), IsNil)
+	}
+	c.Assert(st.Checkpoint(), IsNil)
+	c.Assert(st.AppendRecord(RecordConfig, "kmodlock --profile=baseline"), IsNil)
+	c.Assert(st.AppendRecord(RecordLifecycle, "stopped"), IsNil)
+	c.Assert(st.Close(), IsNil)
+}
+
+func (s *VerifySuite) verify(c *C) *Report {
+	head, err := ReadHead(s.head)
+	c.Assert(err, IsNil)
+	report, err := Verify(s.dir, []ed25519.PublicKey{s.key.Public().(ed25519.PublicKey)}, head)
+	c.Assert(err, IsNil)
+	return report
+}
+
+// rewrite replaces the records of the only segment of the log.
+func (s *VerifySuite) rewrite(c *C, fn func(lines []string) []string) {
+	segments, err := listSegments(s.dir)
+	c.Assert(err, IsNil)
+	c.Assert(len(segments), Equals, 1)
+	data, err := os.ReadFile(segments[0].path)
+	c.Assert(err, IsNil)
+	lines := strings.SplitAfter(string(data), "\n")
+	lines = fn(lines[:len(lines)-1])
+	c.Assert(os.WriteFile(segments[0].path, []byte(strings.Join(lines, "")), 0600), IsNil)
+}
+
+func (s *VerifySuite) TestVerifyOK(c *C) {
+	report := s.verify(c)
+	c.Assert(report.OK(), Equals, true, Commentf("%v", report.Problems))
+	c.Assert(report.Records, Equals, 10)
+	c.Assert(report.Checkpoints, Equals, 2)
+	c.Assert(report.LastCheckpoint, Equals, int64(10))
+	c.Assert(report.Rotated, Equals, false)
+	c.Assert(len(report.Warnings), Equals, 0)
+
+	// Events are still returned by queries
+	h, err := Query(s.dir, Filter{})
+	c.Assert(err, IsNil)
+	c.Assert(len(h.Events), Equals, 5)
+	c.Assert(h.Events[0].Seq, Equals, int64(2))
+}
+
+func (s *VerifySuite) TestVerifyModified(c *C) {
+	s.rewrite(c, func(lines []string) []string {
+		lines[2] = strings.Replace(lines[2], "denied", "allowed", -1)
+		return lines
+	})
+	report := s.verify(c)
+	c.Assert(report.OK(), Equals, false)
+	c.Assert(report.Problems[0], Equals, "record 3 was modified")
+}
+
+func (s *VerifySuite) TestVerifyRemoved(c *C) {
+	s.rewrite(c, func(lines []string) []string {
+		return append(lines[:3], lines[4:]...)
+	})
+	report := s.verify(c)
+	c.Assert(report.OK(), Equals, false)
+	c.Assert(report.Problems, DeepEquals, []string{
+		"records 4 to 4 are missing or reordered",
+		"record 5 is not chained to record 3",
+	})
+}
+
+func (s *VerifySuite) TestVerifyReordered(c *C) {
+	s.rewrite(c, func(lines []string) []string {
+		lines[2], lines[3] = lines[3], lines[2]
+		return lines
+	})
+	report := s.verify(c)
+	c.Assert(report.OK(), Equals, false)
+}
+
+func (s *VerifySuite) TestVerifyCut(c *C) {
+	s.rewrite(c, func(lines []string) []string {
+		return lines[3:]
+	})
+	report := s.verify(c)
+	c.Assert(report.OK(), Equals, false)
+	c.Assert(report.Rotated, Equals, false)
+	c.Assert(report.Problems, DeepEquals, []string{
+		"segment " + filepath.Join(s.dir, segmentName(1)) + " starts with record 4",
+		"records before 4 were removed",
+	})
+}
+
+func (s *VerifySuite) TestVerifyTruncated(c *C) {
+	s.rewrite(c, func(lines []string) []string {
+		return lines[:8]
+	})
+	report := s.verify(c)
+	c.Assert(report.OK(), Equals, false)
+	c.Assert(report.Problems, DeepEquals, []string{
+		"log was truncated: last record is 8 but signed head is 10",
+	})
+}
+
+func (s *VerifySuite) TestVerifyUnknownKey(c *C) {
+	_, other, err := ed25519.GenerateKey(nil)
+	c.Assert(err, IsNil)
+	head, err := ReadHead(s.head)
+	c.Assert(err, IsNil)
+
+	// Signatures by keys that are not trusted are violations
+	report, err := Verify(s.dir, []ed25519.PublicKey{other.Public().(ed25519.PublicKey)}, head)
+	c.Assert(err, IsNil)
+	c.Assert(report.OK(), Equals, false)
+	c.Assert(report.LastCheckpoint, Equals, int64(0))
+	c.Assert(report.Problems, HasLen, 3)
+	c.Assert(report.Problems[2], Matches, "head: unknown key .*")
+
+	// Without trusted keys they can not be verified
+	report, err = Verify(s.dir, nil, head)
+	c.Assert(err, IsNil)
+	c.Assert(report.OK(), Equals, true)
+	c.Assert(report.LastCheckpoint, Equals, int64(0))
+	c.Assert(report.Warnings, HasLen, 4)
+}
+
+func (s *VerifySuite) TestReopenContinuesChain(c *C) {
+	st, err := Open(Config{Dir: s.dir, MaxSize: 1 << 20, SegmentSize: 1 << 16, Key: s.key, HeadFile: s.head})
+	c.Assert(err, IsNil)
+	c.Assert(st.AppendRecord(RecordLifecycle, "started"), IsNil)
+	c.Assert(st.Close(), IsNil)
+
+	report := s.verify(c)
+	c.Assert(report.OK(), Equals, true, Commentf("%v", report.Problems))
+	c.Assert(report.Last, Equals, int64(12))
+}
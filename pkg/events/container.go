@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package events
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+var (
+	// containerIDRx matches the 64 hex characters container ID that
+	// container managers put in the cgroup path of their containers.
+	containerIDRx = regexp.MustCompile(`[0-9a-f]{64}`)
+)
+
+// ContainerID returns the ID of the container that the task pid runs in by
+// looking at its cgroup path. It returns an empty string for tasks that
+// are not inside a container or that already exited.
+func ContainerID(pid int32) string {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if id := containerIDRx.FindString(scanner.Text()); id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,6 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package events reads and decodes the security events reported by the
+// bpflock bpf programs.
+package events
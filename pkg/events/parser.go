@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package events
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/lock"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	// eventPrefix is the prefix of all messages printed by bpflock bpf
+	// programs, it is followed by the name of the program.
+	eventPrefix = "bpflock bpf="
+
+	// maxPendingComms is the maximum number of task command names that
+	// are cached while waiting for their decision message.
+	maxPendingComms = 4096
+
+	// maxPendingRules is the maximum number of matched user and group
+	// rules that are cached while waiting for their decision message.
+	maxPendingRules = 1024
+)
+
+// ruleKey identifies the pending rule of a task checked by a program.
+type ruleKey struct {
+	program string
+	pid     int32
+}
+
+// Parser decodes the trace messages of bpflock bpf programs into events.
+//
+// bpf programs report an access check with two messages as bpf_printk()
+// accepts only three arguments:
+//
+//	bpflock bpf=<program> pid=<pid> comm=<comm> event=<operation>
+//	bpflock bpf=<program> pid=<pid> event=<operation> status=<decision> (<reason>)
+//
+// The command name of the first message is kept until the decision message
+// of the same task is received. When a user or group rule of the baseline
+// trust matched the task, it is reported before these messages:
+//
+//	bpflock bpf=<program> pid=<pid> rule=<rule>
+//
+// and kept until the next decision message of the same program and task.
+type Parser struct {
+	mutex lock.Mutex
+	comms map[int32]string
+	rules map[ruleKey]string
+
+	// ContainerOf returns the container ID of the given pid, if not set
+	// the container ID is not resolved.
+	ContainerOf func(pid int32) string
+}
+
+// NewParser returns a new Parser that resolves container IDs of tasks.
+func NewParser() *Parser {
+	return &Parser{
+		comms:       make(map[int32]string),
+		rules:       make(map[ruleKey]string),
+		ContainerOf: ContainerID,
+	}
+}
+
+// cut returns the value of key in s and the rest of s after the value. The
+// value ends at the first occurrence of next or at the end of s if next
+// is empty.
+func cut(s, key, next string) (string, string, bool) {
+	if !strings.HasPrefix(s, key) {
+		return "", s, false
+	}
+	s = s[len(key):]
+	if next == "" {
+		return s, "", true
+	}
+	i := strings.Index(s, next)
+	if i < 0 {
+		return "", s, false
+	}
+	return s[:i], s[i+1:], true
+}
+
+// parseStatus splits a status as "denied (baseline)" into its decision and
+// reason.
+func parseStatus(status string) (string, string) {
+	decision, reason := status, ""
+	if i := strings.Index(status, " ("); i >= 0 {
+		decision = status[:i]
+		reason = strings.TrimSuffix(status[i+2:], ")")
+	}
+	return decision, reason
+}
+
+// Parse decodes a trace line and returns the corresponding event. It returns
+// nil if the line is not a bpflock decision message.
+func (p *Parser) Parse(line string) *models.Event {
+	i := strings.Index(line, eventPrefix)
+	if i < 0 {
+		return nil
+	}
+	msg := strings.TrimRight(line[i+len("bpflock "):], "\n ")
+
+	program, msg, ok := cut(msg, "bpf=", " ")
+	if !ok || program == "" {
+		return nil
+	}
+	pidStr, msg, ok := cut(msg, "pid=", " ")
+	if !ok {
+		return nil
+	}
+	pid, err := strconv.ParseInt(pidStr, 10, 32)
+	if err != nil {
+		return nil
+	}
+
+	if comm, _, ok := cut(msg, "comm=", " event="); ok {
+		p.mutex.Lock()
+		if len(p.comms) >= maxPendingComms {
+			p.comms = make(map[int32]string)
+		}
+		p.comms[int32(pid)] = comm
+		p.mutex.Unlock()
+		return nil
+	}
+
+	if rule, _, ok := cut(msg, "rule=", ""); ok {
+		p.mutex.Lock()
+		if len(p.rules) >= maxPendingRules {
+			p.rules = make(map[ruleKey]string)
+		}
+		p.rules[ruleKey{program, int32(pid)}] = rule
+		p.mutex.Unlock()
+		return nil
+	}
+
+	operation, msg, ok := cut(msg, "event=", " status=")
+	if !ok {
+		return nil
+	}
+	status, _, _ := cut(msg, "status=", "")
+	decision, reason := parseStatus(status)
+	if decision != models.EventDecisionAllowed && decision != models.EventDecisionDenied {
+		return nil
+	}
+
+	p.mutex.Lock()
+	comm := p.comms[int32(pid)]
+	delete(p.comms, int32(pid))
+	rule := p.rules[ruleKey{program, int32(pid)}]
+	delete(p.rules, ruleKey{program, int32(pid)})
+	p.mutex.Unlock()
+
+	ev := &models.Event{
+		Time:      strfmt.DateTime(now()),
+		Program:   program,
+		Pid:       int32(pid),
+		Comm:      comm,
+		Operation: operation,
+		Decision:  decision,
+		Reason:    reason,
+		Rule:      rule,
+	}
+	if p.ContainerOf != nil {
+		ev.Container = p.ContainerOf(ev.Pid)
+	}
+
+	return ev
+}
+
+// now is replaced in tests.
+var now = time.Now
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type EventsSuite struct{}
+
+var _ = Suite(&EventsSuite{})
+
+func (s *EventsSuite) TestParse(c *C) {
+	p := NewParser()
+	p.ContainerOf = func(pid int32) string {
+		return "0123abcd"
+	}
+
+	ev := p.Parse("           modprobe-1234    [002] d..31  1234.5678: bpf_trace_printk: bpflock bpf=kmodlock pid=1234 comm=modprobe event=load_module\n")
+	c.Assert(ev, IsNil)
+
+	ev = p.Parse("           modprobe-1234    [002] d..31  1234.5679: bpf_trace_printk: bpflock bpf=kmodlock pid=1234 event=load_module status=denied (baseline)\n")
+	c.Assert(ev, NotNil)
+	c.Assert(ev.Program, Equals, "kmodlock")
+	c.Assert(ev.Pid, Equals, int32(1234))
+	c.Assert(ev.Comm, Equals, "modprobe")
+	c.Assert(ev.Operation, Equals, "load_module")
+	c.Assert(ev.Decision, Equals, models.EventDecisionDenied)
+	c.Assert(ev.Reason, Equals, "baseline")
+	c.Assert(ev.Container, Equals, "0123abcd")
+	c.Assert(time.Time(ev.Time).IsZero(), Equals, false)
+
+	// Decision without a previous command message
+	ev = p.Parse("bpflock bpf=bpfrestrict pid=42 event=bpf status=allowed (privileged)")
+	c.Assert(ev, NotNil)
+	c.Assert(ev.Comm, Equals, "")
+	c.Assert(ev.Decision, Equals, models.EventDecisionAllowed)
+	c.Assert(ev.Reason, Equals, "privileged")
+
+	c.Assert(p.Parse("some other trace message"), IsNil)
+	c.Assert(p.Parse("bpflock bpf=kmodlock pid=abc event=bpf status=denied"), IsNil)
+	c.Assert(p.Parse("bpflock bpf=kmodlock pid=1 event=bpf status="), IsNil)
+}
+
+func (s *EventsSuite) TestParseOperationWithSpaces(c *C) {
+	p := NewParser()
+	p.ContainerOf = nil
+
+	c.Assert(p.Parse("bpflock bpf=execlock pid=77 comm=python3 event=exec of memfd file"), IsNil)
+	ev := p.Parse("bpflock bpf=execlock pid=77 event=exec of memfd file status=denied (baseline)")
+	c.Assert(ev, NotNil)
+	c.Assert(ev.Program, Equals, "execlock")
+	c.Assert(ev.Comm, Equals, "python3")
+	c.Assert(ev.Operation, Equals, "exec of memfd file")
+	c.Assert(ev.Decision, Equals, models.EventDecisionDenied)
+	c.Assert(ev.Reason, Equals, "baseline")
+}
+
+func (s *EventsSuite) TestParseRule(c *C) {
+	p := NewParser()
+	p.ContainerOf = nil
+
+	c.Assert(p.Parse("bpflock bpf=kmodlock pid=90 rule=deny-uid=svc-backup"), IsNil)
+
+	// Decisions of other programs do not take the rule
+	ev := p.Parse("bpflock bpf=netlock pid=90 event=raw socket creation status=allowed (baseline)")
+	c.Assert(ev, NotNil)
+	c.Assert(ev.Rule, Equals, "")
+
+	c.Assert(p.Parse("bpflock bpf=kmodlock pid=90 comm=modprobe event=module load"), IsNil)
+
+	ev = p.Parse("bpflock bpf=kmodlock pid=90 event=module load from non init pid namespace status=denied (baseline)")
+	c.Assert(ev, NotNil)
+	c.Assert(ev.Comm, Equals, "modprobe")
+	c.Assert(ev.Reason, Equals, "baseline")
+	c.Assert(ev.Rule, Equals, "deny-uid=svc-backup")
+
+	// The rule is only attached to one decision
+	ev = p.Parse("bpflock bpf=kmodlock pid=90 event=module load status=allowed (baseline)")
+	c.Assert(ev, NotNil)
+	c.Assert(ev.Rule, Equals, "")
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package events
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	subsystem = "events"
+)
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+	// TracePipePaths are the possible locations of the kernel trace pipe
+	// where bpf programs messages are written. The first one that exists
+	// is used.
+	TracePipePaths = []string{
+		"/sys/kernel/tracing/trace_pipe",
+		"/sys/kernel/debug/tracing/trace_pipe",
+	}
+)
+
+// openTracePipe opens the trace pipe in non blocking mode so that reads can
+// be interrupted by closing it.
+func openTracePipe() (*os.File, error) {
+	for _, path := range TracePipePaths {
+		fd, err := unix.Open(path, unix.O_RDONLY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+		if err == nil {
+			return os.NewFile(uintptr(fd), path), nil
+		}
+		if err != unix.ENOENT {
+			return nil, fmt.Errorf("unable to open trace pipe %s: %w", path, err)
+		}
+	}
+
+	return nil, fmt.Errorf("unable to find trace pipe, is tracefs mounted?")
+}
+
+// ReadTracePipe reads the kernel trace pipe until ctx is canceled and calls
+// handler for every event that was decoded.
+func ReadTracePipe(ctx context.Context, handler func(ev *models.Event)) error {
+	f, err := openTracePipe()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+
+	log.WithField(logfields.Path, f.Name()).Info("Reading bpf programs events")
+
+	parser := NewParser()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if ev := parser.Parse(scanner.Text()); ev != nil {
+			handler(ev)
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,8 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package exception resolves the scope of temporary exceptions of bpf
+// programs, operations, cgroups and executables, and loads them into the
+// <program>_exceptions_map pinned map of the program. Exceptions expire in
+// the kernel on their own.
+package exception
@@ -0,0 +1,268 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package exception
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	subsystem = "exception"
+
+	// MaxExceptions must match BPFLOCK_MAX_EXCEPTIONS of
+	// bpf/bpflock_shared_defs.h
+	MaxExceptions = 16
+)
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+	// CgroupRoot is the mount point of the cgroup v2 filesystem
+	CgroupRoot = "/sys/fs/cgroup"
+
+	// operations are the operations of the programs that support
+	// exceptions, named as by their block option
+	operations = map[string]map[string]uint32{
+		components.KmodLock: {
+			"load_module":              1 << 0,
+			"unload_module":            1 << 1,
+			"autoload_module":          1 << 2,
+			"unsigned_module":          1 << 3,
+			"unsafe_module_parameters": 1 << 4,
+		},
+	}
+
+	// bootTime returns the current CLOCK_BOOTTIME in nanoseconds
+	bootTime = func() (uint64, error) {
+		var ts unix.Timespec
+		if err := unix.ClockGettime(unix.CLOCK_BOOTTIME, &ts); err != nil {
+			return 0, err
+		}
+		return uint64(ts.Nano()), nil
+	}
+)
+
+// Supported returns true if program supports temporary exceptions.
+func Supported(program string) bool {
+	_, ok := operations[program]
+	return ok
+}
+
+// Operations returns the sorted names of the operations of program.
+func Operations(program string) []string {
+	var names []string
+	for name := range operations[program] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Scope is the scope of an exception, empty fields match all.
+type Scope struct {
+	Operation  string
+	Cgroup     string
+	Executable string
+}
+
+func (s Scope) String() string {
+	var parts []string
+	if s.Operation != "" {
+		parts = append(parts, "operation="+s.Operation)
+	}
+	if s.Cgroup != "" {
+		parts = append(parts, "cgroup="+s.Cgroup)
+	}
+	if s.Executable != "" {
+		parts = append(parts, "executable="+s.Executable)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Entry is a temporary exception, it must match struct bl_exception of
+// bpf/bpflock_shared_defs.h.
+type Entry struct {
+	Ops     uint32
+	Pad     uint32
+	Cgroup  uint64
+	Dev     uint64
+	Ino     uint64
+	Expires uint64
+}
+
+// cgroupID returns the ID of the cgroup v2 at path, relative to
+// CgroupRoot, it is the inode number of its directory.
+func cgroupID(path string) (uint64, error) {
+	dir := filepath.Join(CgroupRoot, filepath.Clean("/"+path))
+	var st syscall.Stat_t
+	if err := syscall.Stat(dir, &st); err != nil {
+		return 0, fmt.Errorf("unable to find cgroup '%s': %w", path, err)
+	}
+	if st.Mode&syscall.S_IFMT != syscall.S_IFDIR {
+		return 0, fmt.Errorf("cgroup '%s' is not a directory", path)
+	}
+	return st.Ino, nil
+}
+
+// NewEntry resolves the scope of an exception of program that expires
+// after ttl. At least one scope must be set.
+func NewEntry(program string, s Scope, ttl time.Duration) (Entry, error) {
+	e := Entry{}
+	ops, ok := operations[program]
+	if !ok {
+		return e, fmt.Errorf("%s does not support exceptions", program)
+	}
+	if s.Operation == "" && s.Cgroup == "" && s.Executable == "" {
+		return e, fmt.Errorf("exception must be scoped by operation, cgroup or executable")
+	}
+	if ttl <= 0 {
+		return e, fmt.Errorf("invalid exception ttl %s", ttl)
+	}
+
+	if s.Operation != "" {
+		if e.Ops, ok = ops[s.Operation]; !ok {
+			return e, fmt.Errorf("unknown %s operation '%s', possible values: %s", program, s.Operation, strings.Join(Operations(program), ", "))
+		}
+	}
+
+	if s.Cgroup != "" {
+		id, err := cgroupID(s.Cgroup)
+		if err != nil {
+			return e, err
+		}
+		e.Cgroup = id
+	}
+
+	if s.Executable != "" {
+		if !filepath.IsAbs(s.Executable) {
+			return e, fmt.Errorf("executable '%s' is not an absolute path", s.Executable)
+		}
+		var st syscall.Stat_t
+		if err := syscall.Stat(s.Executable, &st); err != nil {
+			return e, fmt.Errorf("unable to find executable '%s': %w", s.Executable, err)
+		}
+		if st.Mode&syscall.S_IFMT != syscall.S_IFREG {
+			return e, fmt.Errorf("executable '%s' is not a regular file", s.Executable)
+		}
+		exe := bpf.NewStat(&st)
+		e.Dev, e.Ino = exe.Dev, exe.Ino
+	}
+
+	now, err := bootTime()
+	if err != nil {
+		return e, err
+	}
+	e.Expires = now + uint64(ttl.Nanoseconds())
+	return e, nil
+}
+
+// MapName returns the name of the pinned map of temporary exceptions of
+// program.
+func MapName(program string) string {
+	return program + "_exceptions_map"
+}
+
+// Locker updates the pinned map of temporary exceptions of a program.
+type Locker struct {
+	// path is the pin of the exceptions map
+	path string
+}
+
+// NewLocker returns a locker of the exceptions of program pinned inside
+// pinPrefix, usually bpf.MapPrefixPath().
+func NewLocker(pinPrefix, program string) *Locker {
+	return &Locker{
+		path: filepath.Join(pinPrefix, program, MapName(program)),
+	}
+}
+
+// Loaded returns true if the map of exceptions is pinned.
+func (l *Locker) Loaded() bool {
+	_, err := os.Stat(l.path)
+	return err == nil
+}
+
+func checkSlot(slot int) error {
+	if slot < 0 || slot >= MaxExceptions {
+		return fmt.Errorf("invalid exception slot %d", slot)
+	}
+	return nil
+}
+
+// Set loads the exception e into slot.
+func (l *Locker) Set(slot int, e Entry) error {
+	if err := checkSlot(slot); err != nil {
+		return err
+	}
+	fd, err := bpf.ObjGet(l.path)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	k := uint32(slot)
+	if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&k), unsafe.Pointer(&e), unix.BPF_ANY); err != nil {
+		return fmt.Errorf("unable to set exception %d: %w", slot, err)
+	}
+	log.WithField(logfields.Path, l.path).Debugf("Set exception %d", slot)
+	return nil
+}
+
+// Clear revokes the exception of slot.
+func (l *Locker) Clear(slot int) error {
+	return l.Set(slot, Entry{})
+}
+
+// Reset revokes all exceptions and returns the number of those that were
+// still active, like exceptions of a previous bpflock instance.
+func (l *Locker) Reset() (int, error) {
+	fd, err := bpf.ObjGet(l.path)
+	if err != nil {
+		return 0, err
+	}
+	defer unix.Close(fd)
+
+	now, err := bootTime()
+	if err != nil {
+		return 0, err
+	}
+
+	active := 0
+	for slot := 0; slot < MaxExceptions; slot++ {
+		k := uint32(slot)
+		e := Entry{}
+		if err := bpf.MapLookupElem(fd, unsafe.Pointer(&k), unsafe.Pointer(&e)); err != nil {
+			if errors.Is(err, unix.ENOENT) {
+				continue
+			}
+			return active, fmt.Errorf("unable to read exception %d: %w", slot, err)
+		}
+		if e.Expires == 0 {
+			continue
+		}
+		if e.Expires > now {
+			active++
+		}
+		e = Entry{}
+		if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&k), unsafe.Pointer(&e), unix.BPF_ANY); err != nil {
+			return active, fmt.Errorf("unable to revoke exception %d: %w", slot, err)
+		}
+	}
+	return active, nil
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package exception
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type ExceptionSuite struct {
+	cgroupRoot string
+	bootTime   func() (uint64, error)
+}
+
+var _ = Suite(&ExceptionSuite{})
+
+func (s *ExceptionSuite) SetUpTest(c *C) {
+	s.cgroupRoot = CgroupRoot
+	s.bootTime = bootTime
+	CgroupRoot = c.MkDir()
+	bootTime = func() (uint64, error) {
+		return 1000, nil
+	}
+}
+
+func (s *ExceptionSuite) TearDownTest(c *C) {
+	CgroupRoot = s.cgroupRoot
+	bootTime = s.bootTime
+}
+
+func (s *ExceptionSuite) TestSupported(c *C) {
+	c.Assert(Supported(components.KmodLock), Equals, true)
+	c.Assert(Supported(components.NetLock), Equals, false)
+	c.Assert(Operations(components.KmodLock), DeepEquals, []string{
+		"autoload_module", "load_module", "unload_module", "unsafe_module_parameters", "unsigned_module",
+	})
+}
+
+func (s *ExceptionSuite) TestNewEntry(c *C) {
+	cgroup := filepath.Join(CgroupRoot, "system.slice", "kernel-update.service")
+	c.Assert(os.MkdirAll(cgroup, 0755), IsNil)
+	exe := filepath.Join(c.MkDir(), "modprobe")
+	c.Assert(ioutil.WriteFile(exe, nil, 0755), IsNil)
+
+	e, err := NewEntry(components.KmodLock, Scope{Operation: "load_module"}, time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(e, Equals, Entry{Ops: 1, Expires: 1000 + uint64(time.Minute)})
+
+	e, err = NewEntry(components.KmodLock, Scope{
+		Cgroup:     "/system.slice/kernel-update.service",
+		Executable: exe,
+	}, time.Second)
+	c.Assert(err, IsNil)
+	var st syscall.Stat_t
+	c.Assert(syscall.Stat(cgroup, &st), IsNil)
+	c.Assert(e.Cgroup, Equals, st.Ino)
+	c.Assert(syscall.Stat(exe, &st), IsNil)
+	c.Assert(bpf.Stat{Dev: e.Dev, Ino: e.Ino}, Equals, bpf.NewStat(&st))
+	c.Assert(e.Ops, Equals, uint32(0))
+
+	// Cgroup paths can not escape the cgroup filesystem
+	_, err = NewEntry(components.KmodLock, Scope{Cgroup: "../../etc"}, time.Second)
+	c.Assert(err, ErrorMatches, "unable to find cgroup.*")
+}
+
+func (s *ExceptionSuite) TestNewEntryInvalid(c *C) {
+	_, err := NewEntry(components.NetLock, Scope{Operation: "load_module"}, time.Minute)
+	c.Assert(err, ErrorMatches, "netlock does not support exceptions")
+
+	_, err = NewEntry(components.KmodLock, Scope{}, time.Minute)
+	c.Assert(err, ErrorMatches, "exception must be scoped by operation, cgroup or executable")
+
+	_, err = NewEntry(components.KmodLock, Scope{Operation: "load_module"}, 0)
+	c.Assert(err, NotNil)
+
+	_, err = NewEntry(components.KmodLock, Scope{Operation: "load"}, time.Minute)
+	c.Assert(err, ErrorMatches, "unknown kmodlock operation 'load'.*")
+
+	_, err = NewEntry(components.KmodLock, Scope{Executable: "modprobe"}, time.Minute)
+	c.Assert(err, ErrorMatches, "executable 'modprobe' is not an absolute path")
+
+	_, err = NewEntry(components.KmodLock, Scope{Executable: CgroupRoot}, time.Minute)
+	c.Assert(err, ErrorMatches, ".* is not a regular file")
+}
+
+func (s *ExceptionSuite) TestLockerNotLoaded(c *C) {
+	l := NewLocker(c.MkDir(), components.KmodLock)
+	c.Assert(l.Loaded(), Equals, false)
+	c.Assert(l.Set(MaxExceptions, Entry{}), ErrorMatches, "invalid exception slot 16")
+	c.Assert(l.Clear(0), NotNil)
+}
@@ -0,0 +1,7 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package exempt resolves the executables that are exempted from the
+// restrictions of a bpf program to their inodes, and loads them into the
+// <program>_exempt_map pinned map of the program.
+package exempt
@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package exempt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+
+	"github.com/fsnotify/fsnotify"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	subsystem = "exempt"
+
+	// maxExempt must match the BPFLOCK_*_MAX_EXEMPT of bpf programs
+	maxExempt = 256
+)
+
+var log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+// MapName returns the name of the pinned map of exempted executables of
+// program.
+func MapName(program string) string {
+	return program + "_exempt_map"
+}
+
+// ParseArgs returns the exempted executable paths of the program
+// arguments.
+func ParseArgs(args []string) []string {
+	var paths []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--exempt=") {
+			continue
+		}
+		for _, p := range strings.Split(strings.TrimPrefix(arg, "--exempt="), ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths
+}
+
+// Executables returns the executables of paths. Paths must be absolute,
+// those that do not exist are skipped.
+func Executables(paths []string) (map[bpf.Stat]struct{}, error) {
+	exes := make(map[bpf.Stat]struct{})
+	for _, p := range paths {
+		if !filepath.IsAbs(p) {
+			return nil, fmt.Errorf("exempted executable '%s' is not an absolute path", p)
+		}
+		var st syscall.Stat_t
+		if err := syscall.Stat(p, &st); err != nil {
+			if os.IsNotExist(err) {
+				log.WithField(logfields.Path, p).Warn("Exempted executable does not exist")
+				continue
+			}
+			return nil, err
+		}
+		if st.Mode&syscall.S_IFMT != syscall.S_IFREG {
+			return nil, fmt.Errorf("exempted executable '%s' is not a regular file", p)
+		}
+		exes[bpf.NewStat(&st)] = struct{}{}
+	}
+	if len(exes) > maxExempt {
+		return nil, fmt.Errorf("too many exempted executables: %d, maximum is %d", len(exes), maxExempt)
+	}
+	return exes, nil
+}
+
+// Locker updates the pinned map of exempted executables of a program.
+type Locker struct {
+	// path is the pin of the exempted executables map
+	path string
+}
+
+// NewLocker returns a locker of the exempted executables of program pinned
+// inside pinPrefix, usually bpf.MapPrefixPath().
+func NewLocker(pinPrefix, program string) *Locker {
+	return &Locker{
+		path: filepath.Join(pinPrefix, program, MapName(program)),
+	}
+}
+
+// Loaded returns true if the map of exempted executables is pinned.
+func (l *Locker) Loaded() bool {
+	_, err := os.Stat(l.path)
+	return err == nil
+}
+
+// Exempt sets the exempted executables to those of paths and removes the
+// others.
+func (l *Locker) Exempt(paths []string) error {
+	exes, err := Executables(paths)
+	if err != nil {
+		return err
+	}
+
+	fd, err := bpf.ObjGet(l.path)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	var stale []bpf.Stat
+	var key, next bpf.Stat
+	var pkey unsafe.Pointer
+	for {
+		err := bpf.MapGetNextKey(fd, pkey, unsafe.Pointer(&next))
+		if errors.Is(err, unix.ENOENT) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("unable to list exempted executables: %w", err)
+		}
+		if _, ok := exes[next]; !ok {
+			stale = append(stale, next)
+		}
+		key = next
+		pkey = unsafe.Pointer(&key)
+	}
+
+	for _, exe := range stale {
+		exe := exe
+		if err := bpf.MapDeleteElem(fd, unsafe.Pointer(&exe)); err != nil && !errors.Is(err, unix.ENOENT) {
+			return fmt.Errorf("unable to remove exempted executable %d: %w", exe.Ino, err)
+		}
+	}
+
+	value := uint32(1)
+	for exe := range exes {
+		exe := exe
+		if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&exe), unsafe.Pointer(&value), unix.BPF_ANY); err != nil {
+			return fmt.Errorf("unable to exempt executable %d: %w", exe.Ino, err)
+		}
+	}
+
+	log.WithField(logfields.Path, l.path).Debugf("Exempting %d executables, removed %d stale entries", len(exes), len(stale))
+	return nil
+}
+
+// Update loads the exempted executables of paths, it does nothing if the
+// program is not loaded.
+func (l *Locker) Update(paths []string) error {
+	if !l.Loaded() {
+		return nil
+	}
+	return l.Exempt(paths)
+}
+
+// Watch calls notify when one of the executables of paths, or the target
+// of a symlink of paths, is created, replaced or removed, until ctx is
+// done. Package upgrades replace executables with new inodes.
+func Watch(ctx context.Context, paths []string, notify func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{})
+	dirs := make(map[string]struct{})
+	for _, p := range paths {
+		names[p] = struct{}{}
+		dirs[filepath.Dir(p)] = struct{}{}
+		if target, err := filepath.EvalSymlinks(p); err == nil && target != p {
+			names[target] = struct{}{}
+			dirs[filepath.Dir(target)] = struct{}{}
+		}
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil && !os.IsNotExist(err) {
+			watcher.Close()
+			return fmt.Errorf("unable to watch exempted executables: %w", err)
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if _, ok := names[ev.Name]; !ok {
+					continue
+				}
+				if ev.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+					log.WithField(logfields.Path, ev.Name).Debugf("Exempted executable changed: %s", ev.Op)
+					notify()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.WithError(err).Warn("Error while watching exempted executables")
+			}
+		}
+	}()
+
+	return nil
+}
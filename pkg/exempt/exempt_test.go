@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package exempt
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type ExemptSuite struct{}
+
+var _ = Suite(&ExemptSuite{})
+
+func (s *ExemptSuite) TestParseArgs(c *C) {
+	c.Assert(ParseArgs([]string{"--profile=baseline", "--exempt=/usr/bin/runc, /usr/bin/crun"}),
+		DeepEquals, []string{"/usr/bin/runc", "/usr/bin/crun"})
+	c.Assert(ParseArgs([]string{"--profile=baseline"}), HasLen, 0)
+}
+
+func (s *ExemptSuite) TestExecutables(c *C) {
+	dir := c.MkDir()
+	exe := filepath.Join(dir, "runc")
+	c.Assert(ioutil.WriteFile(exe, []byte("#!/bin/sh\n"), 0755), IsNil)
+
+	exes, err := Executables([]string{exe, filepath.Join(dir, "missing")})
+	c.Assert(err, IsNil)
+	c.Assert(exes, HasLen, 1)
+
+	var st syscall.Stat_t
+	c.Assert(syscall.Stat(exe, &st), IsNil)
+	for e := range exes {
+		c.Assert(e.Ino, Equals, st.Ino)
+	}
+
+	_, err = Executables([]string{"runc"})
+	c.Assert(err, ErrorMatches, "exempted executable 'runc' is not an absolute path")
+	_, err = Executables([]string{dir})
+	c.Assert(err, ErrorMatches, "exempted executable '.*' is not a regular file")
+}
+
+func (s *ExemptSuite) TestLockerNotLoaded(c *C) {
+	l := NewLocker(c.MkDir(), "kmodlock")
+	c.Assert(l.Loaded(), Equals, false)
+	c.Assert(l.Update([]string{"/usr/bin/runc"}), IsNil)
+	c.Assert(MapName("kmodlock"), Equals, "kmodlock_exempt_map")
+}
+
+func (s *ExemptSuite) TestWatch(c *C) {
+	dir := c.MkDir()
+	exe := filepath.Join(dir, "modprobe")
+	c.Assert(ioutil.WriteFile(exe, []byte("#!/bin/sh\n"), 0755), IsNil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 8)
+	c.Assert(Watch(ctx, []string{exe}, func() { changed <- struct{}{} }), IsNil)
+
+	// Files of the same directory are ignored
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "other"), nil, 0644), IsNil)
+
+	// Upgrades write a new file and rename it over the executable
+	upgrade := filepath.Join(dir, "modprobe.new")
+	c.Assert(ioutil.WriteFile(upgrade, []byte("#!/bin/sh\n"), 0755), IsNil)
+	c.Assert(os.Rename(upgrade, exe), IsNil)
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		c.Fatal("executable replacement was not notified")
+	}
+}
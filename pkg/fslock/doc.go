@@ -0,0 +1,7 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package fslock translates the filesystem names allowed by the fslock
+// configuration into superblock magics and loads them into the pinned
+// map of the fslock bpf program.
+package fslock
@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package fslock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	subsystem = "fslock"
+
+	ProfileAllow      = "allow"
+	ProfileBaseline   = "baseline"
+	ProfileRestricted = "restricted"
+
+	// OptionsMap is the name of the pinned map of fslock options
+	OptionsMap = "fslock_map"
+
+	// MagicsMap is the name of the pinned map of allowed filesystems
+	MagicsMap = "fslock_magics_map"
+
+	// keyReady of OptionsMap must match bpf/fslock.h
+	keyReady uint32 = 2
+
+	// maxMagics must match BPFLOCK_FS_MAX_MAGICS of bpf/fslock.h
+	maxMagics = 64
+)
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+	// magics maps filesystem names to their superblock magics, see
+	// include/uapi/linux/magic.h
+	magics = map[string][]uint64{
+		"anon_inodefs": {0x09041934},
+		"autofs":       {0x0187},
+		"binfmt_misc":  {0x42494e4d},
+		"bpf":          {0xcafe4a11},
+		"btrfs":        {0x9123683e},
+		"cgroup":       {0x27e0eb},
+		"cgroup2":      {0x63677270},
+		"configfs":     {0x62656570},
+		"cramfs":       {0x28cd3d45, 0x453dcd28},
+		"debugfs":      {0x64626720},
+		"devpts":       {0x1cd1},
+		"efivarfs":     {0xde5e81e4},
+		"erofs":        {0xe0f5e1e2},
+		"exfat":        {0x2011bab0},
+		"ext2":         {0xef53},
+		"ext3":         {0xef53},
+		"ext4":         {0xef53},
+		"f2fs":         {0xf2f52010},
+		"fuse":         {0x65735546},
+		"hugetlbfs":    {0x958458f6},
+		"iso9660":      {0x9660},
+		"mqueue":       {0x19800202},
+		"nfs":          {0x6969},
+		"nsfs":         {0x6e736673},
+		"ntfs":         {0x5346544e},
+		"overlay":      {0x794c7630},
+		"pipefs":       {0x50495045},
+		"proc":         {0x9fa0},
+		"pstore":       {0x6165676c},
+		"ramfs":        {0x858458f6},
+		"securityfs":   {0x73636673},
+		"sockfs":       {0x534f434b},
+		"squashfs":     {0x73717368},
+		"sysfs":        {0x62656572},
+		"tmpfs":        {0x01021994},
+		"tracefs":      {0x74726163},
+		"vfat":         {0x4d44},
+		"xfs":          {0x58465342},
+		"zfs":          {0x2fc12fc1},
+	}
+)
+
+// Policy is the fslock configuration of the bpf program arguments.
+type Policy struct {
+	Profile string
+
+	// Filesystems are the names of the allowed filesystems
+	Filesystems []string
+}
+
+// ParseArgs returns the policy of the fslock program arguments.
+func ParseArgs(args []string) (*Policy, error) {
+	p := &Policy{Profile: ProfileAllow}
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--profile="):
+			switch profile := strings.TrimPrefix(arg, "--profile="); profile {
+			case ProfileBaseline, ProfileRestricted:
+				p.Profile = profile
+			default:
+				p.Profile = ProfileAllow
+			}
+		case strings.HasPrefix(arg, "--allow="):
+			for _, name := range strings.Split(strings.TrimPrefix(arg, "--allow="), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					p.Filesystems = append(p.Filesystems, name)
+				}
+			}
+		}
+	}
+	if _, err := Magics(p.Filesystems); err != nil {
+		return nil, err
+	}
+	if p.Profile != ProfileAllow && len(p.Filesystems) == 0 {
+		return nil, fmt.Errorf("profile %s requires a list of allowed filesystems", p.Profile)
+	}
+	return p, nil
+}
+
+// Magics returns the sorted superblock magics of the filesystem names.
+func Magics(names []string) ([]uint64, error) {
+	set := make(map[uint64]struct{})
+	for _, name := range names {
+		m, ok := magics[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown filesystem '%s'", name)
+		}
+		for _, magic := range m {
+			set[magic] = struct{}{}
+		}
+	}
+	if len(set) > maxMagics {
+		return nil, fmt.Errorf("too many allowed filesystems: %d, maximum is %d", len(set), maxMagics)
+	}
+	out := make([]uint64, 0, len(set))
+	for magic := range set {
+		out = append(out, magic)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out, nil
+}
+
+// Locker updates the pinned maps of the fslock program.
+type Locker struct {
+	// pinDir is the directory of fslock pins
+	pinDir string
+}
+
+// NewLocker returns a locker of the fslock program pinned inside
+// pinPrefix, usually bpf.MapPrefixPath().
+func NewLocker(pinPrefix string) *Locker {
+	return &Locker{
+		pinDir: filepath.Join(pinPrefix, components.FsLock),
+	}
+}
+
+// Loaded returns true if the fslock program is pinned.
+func (l *Locker) Loaded() bool {
+	_, err := os.Stat(filepath.Join(l.pinDir, OptionsMap))
+	return err == nil
+}
+
+func (l *Locker) openMap(name string) (int, error) {
+	return bpf.ObjGet(filepath.Join(l.pinDir, name))
+}
+
+// Allow sets the allowed filesystems to names and removes the others.
+// Restrictions are enforced once the allowed filesystems are loaded.
+func (l *Locker) Allow(names []string) error {
+	allowed, err := Magics(names)
+	if err != nil {
+		return err
+	}
+	set := make(map[uint64]struct{}, len(allowed))
+	for _, magic := range allowed {
+		set[magic] = struct{}{}
+	}
+
+	fd, err := l.openMap(MagicsMap)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	// Add new filesystems first, so allowed ones are never denied
+	value := uint32(1)
+	for _, magic := range allowed {
+		magic := magic
+		if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&magic), unsafe.Pointer(&value), unix.BPF_ANY); err != nil {
+			return fmt.Errorf("unable to allow filesystem magic %#x: %w", magic, err)
+		}
+	}
+
+	var stale []uint64
+	var key, next uint64
+	var pkey unsafe.Pointer
+	for {
+		err := bpf.MapGetNextKey(fd, pkey, unsafe.Pointer(&next))
+		if errors.Is(err, unix.ENOENT) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("unable to list allowed filesystems: %w", err)
+		}
+		if _, ok := set[next]; !ok {
+			stale = append(stale, next)
+		}
+		key = next
+		pkey = unsafe.Pointer(&key)
+	}
+	for _, magic := range stale {
+		magic := magic
+		if err := bpf.MapDeleteElem(fd, unsafe.Pointer(&magic)); err != nil && !errors.Is(err, unix.ENOENT) {
+			return fmt.Errorf("unable to remove filesystem magic %#x: %w", magic, err)
+		}
+	}
+
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	ofd, err := l.openMap(OptionsMap)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(ofd)
+
+	k := keyReady
+	if err := bpf.MapUpdateElem(ofd, unsafe.Pointer(&k), unsafe.Pointer(&value), unix.BPF_ANY); err != nil {
+		return fmt.Errorf("unable to enable fslock restrictions: %w", err)
+	}
+
+	log.Debugf("Allowing %d filesystems, removed %d stale entries", len(allowed), len(stale))
+	return nil
+}
+
+// Update loads the allowed filesystems of the policy, it does nothing if
+// the fslock program is not loaded.
+func (l *Locker) Update(p *Policy) error {
+	if !l.Loaded() {
+		return nil
+	}
+	return l.Allow(p.Filesystems)
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package fslock
+
+import (
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type FsLockSuite struct{}
+
+var _ = Suite(&FsLockSuite{})
+
+func (s *FsLockSuite) TestMagics(c *C) {
+	m, err := Magics([]string{"ext4", "ext3", "tmpfs", "cramfs"})
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, []uint64{0xef53, 0x01021994, 0x28cd3d45, 0x453dcd28})
+
+	_, err = Magics([]string{"ext4", "reiserfs"})
+	c.Assert(err, ErrorMatches, "unknown filesystem 'reiserfs'")
+}
+
+func (s *FsLockSuite) TestParseArgs(c *C) {
+	p, err := ParseArgs([]string{"--profile=baseline", "--allow=ext4,overlay, tmpfs,squashfs"})
+	c.Assert(err, IsNil)
+	c.Assert(p.Profile, Equals, ProfileBaseline)
+	c.Assert(p.Filesystems, DeepEquals, []string{"ext4", "overlay", "tmpfs", "squashfs"})
+
+	p, err = ParseArgs([]string{"--profile=none"})
+	c.Assert(err, IsNil)
+	c.Assert(p.Profile, Equals, ProfileAllow)
+
+	_, err = ParseArgs([]string{"--profile=restricted"})
+	c.Assert(err, ErrorMatches, "profile restricted requires a list of allowed filesystems")
+
+	_, err = ParseArgs([]string{"--profile=baseline", "--allow=ext4,foofs"})
+	c.Assert(err, ErrorMatches, "unknown filesystem 'foofs'")
+}
+
+func (s *FsLockSuite) TestLockerNotLoaded(c *C) {
+	l := NewLocker(c.MkDir())
+	c.Assert(l.Loaded(), Equals, false)
+	c.Assert(l.Update(&Policy{Profile: ProfileBaseline, Filesystems: []string{"ext4"}}), IsNil)
+}
@@ -0,0 +1,6 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package integrity detects tampering with the pinned bpf programs, maps
+// and links of bpflock and with the bpf filesystem where they are pinned.
+package integrity
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package integrity
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/lock"
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+	"github.com/linux-lock/bpflock/pkg/mountinfo"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	subsystem = "integrity"
+
+	// bpffsType is the filesystem type of bpffs in mountinfo
+	bpffsType = "bpf"
+)
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+	// replaced in tests
+	getMountInfo = mountinfo.GetMountInfo
+	getObjInfo   = bpf.GetObjInfo
+)
+
+// Monitor compares the pinned bpf objects and the bpf filesystem mount
+// with a snapshot taken after bpf programs were started.
+type Monitor struct {
+	mutex lock.Mutex
+
+	// mapRoot is where the bpf filesystem is mounted
+	mapRoot string
+
+	// pinDir is the directory of bpflock pins inside mapRoot
+	pinDir string
+
+	pins  map[string]*bpf.ObjInfo
+	mount *mountinfo.MountInfo
+}
+
+// NewMonitor returns a monitor of the pins inside pinDir, and of the bpf
+// filesystem mounted at mapRoot.
+func NewMonitor(mapRoot, pinDir string) *Monitor {
+	return &Monitor{
+		mapRoot: mapRoot,
+		pinDir:  pinDir,
+		pins:    make(map[string]*bpf.ObjInfo),
+	}
+}
+
+// bpffsMounts returns the bpf filesystems mounted at mapRoot.
+func (m *Monitor) bpffsMounts() ([]*mountinfo.MountInfo, error) {
+	infos, err := getMountInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	var mounts []*mountinfo.MountInfo
+	for _, info := range infos {
+		if info.MountPoint == m.mapRoot && info.FilesystemType == bpffsType {
+			mounts = append(mounts, info)
+		}
+	}
+	return mounts, nil
+}
+
+// Snapshot records the current pins and bpf filesystem mount as the
+// expected state.
+func (m *Monitor) Snapshot() error {
+	mounts, err := m.bpffsMounts()
+	if err != nil {
+		return fmt.Errorf("unable to read mount information: %w", err)
+	}
+	if len(mounts) == 0 {
+		return fmt.Errorf("no bpf filesystem mounted at %s", m.mapRoot)
+	}
+
+	pins := make(map[string]*bpf.ObjInfo)
+	err = filepath.Walk(m.pinDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		obj, err := getObjInfo(path)
+		if err != nil {
+			log.WithError(err).WithField(logfields.Path, path).Warn("Ignoring pin")
+			return nil
+		}
+		pins[path] = obj
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to read pins: %w", err)
+	}
+
+	mount := *mounts[len(mounts)-1]
+	m.mutex.Lock()
+	m.mount = &mount
+	m.pins = pins
+	m.mutex.Unlock()
+
+	log.WithField(logfields.Path, m.pinDir).Infof("Monitoring %d pinned bpf objects", len(pins))
+
+	return nil
+}
+
+// Check returns the differences between the current pins and bpf
+// filesystem mount and the snapshot.
+func (m *Monitor) Check() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var problems []string
+
+	if m.mount != nil {
+		mounts, err := m.bpffsMounts()
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("unable to read mount information: %s", err))
+		case len(mounts) == 0:
+			problems = append(problems, fmt.Sprintf("bpf filesystem at %s was unmounted", m.mapRoot))
+		case len(mounts) > 1:
+			problems = append(problems, fmt.Sprintf("multiple bpf filesystems are mounted at %s", m.mapRoot))
+		default:
+			cur := mounts[0]
+			if cur.MountID != m.mount.MountID {
+				problems = append(problems, fmt.Sprintf("bpf filesystem at %s was replaced", m.mapRoot))
+			} else if cur.MountOptions != m.mount.MountOptions || cur.SuperOptions != m.mount.SuperOptions {
+				problems = append(problems, fmt.Sprintf("bpf filesystem at %s was remounted with options '%s'", m.mapRoot, cur.MountOptions))
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(m.pins))
+	for path := range m.pins {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		expected := m.pins[path]
+		if _, err := os.Lstat(path); err != nil {
+			problems = append(problems, fmt.Sprintf("pin %s was removed", path))
+			continue
+		}
+		obj, err := getObjInfo(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("pin %s is not a valid bpf object", path))
+			continue
+		}
+		if obj.Type != expected.Type || obj.ID != expected.ID {
+			problems = append(problems, fmt.Sprintf("pin %s was replaced", path))
+		} else if obj.ProgID != expected.ProgID {
+			problems = append(problems, fmt.Sprintf("link %s is attached to another program", path))
+		}
+	}
+
+	return problems
+}
+
+// Watch calls notify when a pin or a directory inside the pin directory
+// is removed or renamed, until ctx is done.
+func (m *Monitor) Watch(ctx context.Context, notify func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	err = filepath.Walk(m.pinDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+	if err != nil {
+		watcher.Close()
+		return fmt.Errorf("unable to watch pins: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if ev.Op&(fsnotify.Remove|fsnotify.Rename|fsnotify.Chmod) != 0 {
+					log.WithField(logfields.Path, ev.Name).Debugf("Pin changed: %s", ev.Op)
+					notify()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.WithError(err).Warn("Error while watching pins")
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package integrity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/mountinfo"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type IntegritySuite struct {
+	pinDir string
+	mounts []*mountinfo.MountInfo
+	objs   map[string]*bpf.ObjInfo
+}
+
+var _ = Suite(&IntegritySuite{})
+
+func (s *IntegritySuite) SetUpTest(c *C) {
+	s.pinDir = c.MkDir()
+	s.mounts = []*mountinfo.MountInfo{
+		{MountID: 10, MountPoint: "/sys/fs/bpf", FilesystemType: "bpf", MountOptions: "rw,nosuid"},
+		{MountID: 11, MountPoint: "/sys", FilesystemType: "sysfs", MountOptions: "rw"},
+	}
+	s.objs = make(map[string]*bpf.ObjInfo)
+
+	getMountInfo = func() ([]*mountinfo.MountInfo, error) {
+		return s.mounts, nil
+	}
+	getObjInfo = func(path string) (*bpf.ObjInfo, error) {
+		if _, err := os.Stat(path); err != nil {
+			return nil, err
+		}
+		return s.objs[path], nil
+	}
+
+	c.Assert(os.Mkdir(filepath.Join(s.pinDir, "kmodlock"), 0700), IsNil)
+	for i, name := range []string{"kmodlock_map", "kmodlock_link"} {
+		path := filepath.Join(s.pinDir, "kmodlock", name)
+		c.Assert(os.WriteFile(path, nil, 0600), IsNil)
+		s.objs[path] = &bpf.ObjInfo{Type: 1, ID: uint32(i + 1), ProgID: 7}
+	}
+}
+
+func (s *IntegritySuite) TearDownTest(c *C) {
+	getMountInfo = mountinfo.GetMountInfo
+	getObjInfo = bpf.GetObjInfo
+}
+
+func (s *IntegritySuite) TestCheck(c *C) {
+	m := NewMonitor("/sys/fs/bpf", s.pinDir)
+	c.Assert(m.Snapshot(), IsNil)
+	c.Assert(len(m.Check()), Equals, 0)
+
+	link := filepath.Join(s.pinDir, "kmodlock", "kmodlock_link")
+	s.objs[link] = &bpf.ObjInfo{Type: 1, ID: 2, ProgID: 8}
+	c.Assert(m.Check(), DeepEquals, []string{"link " + link + " is attached to another program"})
+
+	s.objs[link] = &bpf.ObjInfo{Type: 1, ID: 3, ProgID: 7}
+	c.Assert(m.Check(), DeepEquals, []string{"pin " + link + " was replaced"})
+
+	c.Assert(os.Remove(link), IsNil)
+	c.Assert(m.Check(), DeepEquals, []string{"pin " + link + " was removed"})
+}
+
+func (s *IntegritySuite) TestCheckMount(c *C) {
+	m := NewMonitor("/sys/fs/bpf", s.pinDir)
+	c.Assert(m.Snapshot(), IsNil)
+
+	s.mounts[0].MountOptions = "ro,nosuid"
+	c.Assert(m.Check(), DeepEquals, []string{"bpf filesystem at /sys/fs/bpf was remounted with options 'ro,nosuid'"})
+
+	s.mounts[0].MountID = 12
+	c.Assert(m.Check(), DeepEquals, []string{"bpf filesystem at /sys/fs/bpf was replaced"})
+
+	s.mounts = append(s.mounts, &mountinfo.MountInfo{MountID: 13, MountPoint: "/sys/fs/bpf", FilesystemType: "bpf"})
+	c.Assert(m.Check(), DeepEquals, []string{"multiple bpf filesystems are mounted at /sys/fs/bpf"})
+
+	s.mounts = s.mounts[1:2]
+	c.Assert(m.Check(), DeepEquals, []string{"bpf filesystem at /sys/fs/bpf was unmounted"})
+}
@@ -0,0 +1,8 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package kmodlock validates the module names and autoload modalias
+// patterns of the kmodlock configuration against the modules of the
+// running kernel, and loads them into the pinned map of the kmodlock bpf
+// program.
+package kmodlock
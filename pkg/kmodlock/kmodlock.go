@@ -0,0 +1,368 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package kmodlock
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	subsystem = "kmodlock"
+
+	// NamesMap is the name of the pinned map of allowed and denied modules
+	NamesMap = "kmodlock_names_map"
+
+	// Values of NamesMap, they must match bpf/kmodlock.h
+	ruleAllow uint32 = 1
+	ruleDeny  uint32 = 2
+
+	// nameLen and maxNames must match bpf/kmodlock.h
+	nameLen  = 64
+	maxNames = 4096
+)
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+	// ModulesDir is the directory of the modules of all kernels
+	ModulesDir = "/lib/modules"
+)
+
+// Policy is the kmodlock configuration of the bpf program arguments.
+type Policy struct {
+	// Allow are the modules that may be loaded from containers
+	Allow []string
+
+	// Deny are the modules that may not be loaded at all
+	Deny []string
+}
+
+// ParseArgs returns the policy of the kmodlock program arguments.
+func ParseArgs(args []string) *Policy {
+	p := &Policy{}
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--allow="):
+			p.Allow = append(p.Allow, splitList(strings.TrimPrefix(arg, "--allow="))...)
+		case strings.HasPrefix(arg, "--deny="):
+			p.Deny = append(p.Deny, splitList(strings.TrimPrefix(arg, "--deny="))...)
+		}
+	}
+	return p
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// normalize returns the module name as used by the kernel, modprobe
+// handles dashes and underscores the same.
+func normalize(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// Index are the modules and aliases of a kernel.
+type Index struct {
+	// Modules are the normalized names of loadable and builtin modules
+	Modules map[string]struct{}
+
+	// Aliases maps literal aliases to their modules, aliases with
+	// wildcards are not autoload requests and are skipped
+	Aliases map[string][]string
+}
+
+// ReadIndex reads the modules and aliases of the kernel release from dir,
+// usually /lib/modules/$(uname -r).
+func ReadIndex(dir string) (*Index, error) {
+	idx := &Index{
+		Modules: make(map[string]struct{}),
+		Aliases: make(map[string][]string),
+	}
+
+	err := readLines(filepath.Join(dir, "modules.dep"), func(line string) {
+		// kernel/net/ipv4/netfilter/ip_tables.ko.xz: kernel/...
+		file := strings.SplitN(line, ":", 2)[0]
+		if file != "" {
+			idx.Modules[moduleName(file)] = struct{}{}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Builtin modules can not be loaded, they are still valid names
+	err = readLines(filepath.Join(dir, "modules.builtin"), func(line string) {
+		idx.Modules[moduleName(line)] = struct{}{}
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	err = readLines(filepath.Join(dir, "modules.alias"), func(line string) {
+		// alias fs-xfs xfs
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "alias" || strings.ContainsAny(fields[1], "*?[") {
+			return
+		}
+		idx.Aliases[fields[1]] = append(idx.Aliases[fields[1]], normalize(fields[2]))
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+func readLines(file string, fn func(line string)) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			fn(line)
+		}
+	}
+	return scanner.Err()
+}
+
+// moduleName returns the normalized module name of a module file path.
+func moduleName(file string) string {
+	base := filepath.Base(file)
+	if i := strings.Index(base, "."); i >= 0 {
+		base = base[:i]
+	}
+	return normalize(base)
+}
+
+// Resolve returns the module names and autoload requests that match the
+// entries. An entry is a module name, that also matches its aliases, a
+// literal alias or an alias pattern with wildcards.
+func (idx *Index) Resolve(entries []string) ([]string, error) {
+	set := make(map[string]struct{})
+	for _, e := range entries {
+		if strings.ContainsAny(e, "*?[") {
+			if _, err := path.Match(e, ""); err != nil {
+				return nil, fmt.Errorf("invalid modalias pattern '%s': %w", e, err)
+			}
+			found := false
+			for alias := range idx.Aliases {
+				if ok, _ := path.Match(e, alias); ok {
+					set[alias] = struct{}{}
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("modalias pattern '%s' does not match any module alias", e)
+			}
+			continue
+		}
+
+		if _, ok := idx.Modules[normalize(e)]; ok {
+			name := normalize(e)
+			set[name] = struct{}{}
+			for alias, modules := range idx.Aliases {
+				for _, m := range modules {
+					if m == name {
+						set[alias] = struct{}{}
+					}
+				}
+			}
+			continue
+		}
+
+		if _, ok := idx.Aliases[e]; ok {
+			set[e] = struct{}{}
+			continue
+		}
+
+		return nil, fmt.Errorf("unknown module or alias '%s'", e)
+	}
+	return sortedNames(set)
+}
+
+// Unresolved returns the entries as they are, when the modules of the
+// running kernel are not available. Patterns can not be expanded.
+func Unresolved(entries []string) ([]string, error) {
+	set := make(map[string]struct{})
+	for _, e := range entries {
+		if strings.ContainsAny(e, "*?[") {
+			return nil, fmt.Errorf("modalias pattern '%s' can not be resolved without kernel modules", e)
+		}
+		set[e] = struct{}{}
+		set[normalize(e)] = struct{}{}
+	}
+	return sortedNames(set)
+}
+
+func sortedNames(set map[string]struct{}) ([]string, error) {
+	out := make([]string, 0, len(set))
+	for name := range set {
+		if len(name) >= nameLen {
+			return nil, fmt.Errorf("module name '%s' is too long, maximum is %d", name, nameLen-1)
+		}
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// KernelModulesDir returns the modules directory of the running kernel.
+func KernelModulesDir() (string, error) {
+	var uts unix.Utsname
+	if err := unix.Uname(&uts); err != nil {
+		return "", err
+	}
+	return filepath.Join(ModulesDir, unix.ByteSliceToString(uts.Release[:])), nil
+}
+
+// Rules returns the allow and deny rules of the policy, keyed by module
+// name or autoload request. Deny rules take precedence.
+func (p *Policy) Rules(idx *Index) (map[string]uint32, error) {
+	resolve := Unresolved
+	if idx != nil {
+		resolve = idx.Resolve
+	}
+
+	rules := make(map[string]uint32)
+	allow, err := resolve(p.Allow)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range allow {
+		rules[name] = ruleAllow
+	}
+	deny, err := resolve(p.Deny)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range deny {
+		rules[name] = ruleDeny
+	}
+	if len(rules) > maxNames {
+		return nil, fmt.Errorf("too many module names: %d, maximum is %d", len(rules), maxNames)
+	}
+	return rules, nil
+}
+
+// Locker updates the pinned maps of the kmodlock program.
+type Locker struct {
+	// pinDir is the directory of kmodlock pins
+	pinDir string
+}
+
+// NewLocker returns a locker of the kmodlock program pinned inside
+// pinPrefix, usually bpf.MapPrefixPath().
+func NewLocker(pinPrefix string) *Locker {
+	return &Locker{
+		pinDir: filepath.Join(pinPrefix, components.KmodLock),
+	}
+}
+
+// Loaded returns true if the kmodlock program is pinned.
+func (l *Locker) Loaded() bool {
+	_, err := os.Stat(filepath.Join(l.pinDir, NamesMap))
+	return err == nil
+}
+
+type nameKey [nameLen]byte
+
+// SetRules sets the module rules and removes the others.
+func (l *Locker) SetRules(rules map[string]uint32) error {
+	fd, err := bpf.ObjGet(filepath.Join(l.pinDir, NamesMap))
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	keys := make(map[nameKey]uint32, len(rules))
+	for name, rule := range rules {
+		var k nameKey
+		copy(k[:], name)
+		keys[k] = rule
+	}
+
+	var stale []nameKey
+	var key, next nameKey
+	var pkey unsafe.Pointer
+	for {
+		err := bpf.MapGetNextKey(fd, pkey, unsafe.Pointer(&next))
+		if errors.Is(err, unix.ENOENT) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("unable to list module rules: %w", err)
+		}
+		if _, ok := keys[next]; !ok {
+			stale = append(stale, next)
+		}
+		key = next
+		pkey = unsafe.Pointer(&key)
+	}
+
+	for _, k := range stale {
+		k := k
+		if err := bpf.MapDeleteElem(fd, unsafe.Pointer(&k)); err != nil && !errors.Is(err, unix.ENOENT) {
+			return fmt.Errorf("unable to remove module rule: %w", err)
+		}
+	}
+
+	for k, rule := range keys {
+		k, rule := k, rule
+		if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&k), unsafe.Pointer(&rule), unix.BPF_ANY); err != nil {
+			return fmt.Errorf("unable to set module rule: %w", err)
+		}
+	}
+
+	log.Debugf("Loaded %d module rules, removed %d stale entries", len(keys), len(stale))
+	return nil
+}
+
+// Update validates the policy against the modules of the running kernel
+// and loads its rules, it does nothing if the kmodlock program is not
+// loaded.
+func (l *Locker) Update(p *Policy) error {
+	if !l.Loaded() {
+		return nil
+	}
+
+	var idx *Index
+	dir, err := KernelModulesDir()
+	if err == nil {
+		idx, err = ReadIndex(dir)
+	}
+	if err != nil {
+		log.WithError(err).Warn("Unable to read kernel modules, module names are not validated")
+		idx = nil
+	}
+
+	rules, err := p.Rules(idx)
+	if err != nil {
+		return err
+	}
+	return l.SetRules(rules)
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package kmodlock
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type KmodLockSuite struct{}
+
+var _ = Suite(&KmodLockSuite{})
+
+const (
+	testDep = `kernel/net/ipv4/netfilter/ip_tables.ko.xz: kernel/net/netfilter/x_tables.ko.xz
+kernel/net/netfilter/x_tables.ko.xz:
+kernel/net/ipv4/ipip.ko.zst: kernel/net/ipv4/tunnel4.ko.zst kernel/net/ipv4/ip_tunnel.ko.zst
+kernel/fs/xfs/xfs.ko:
+kernel/drivers/hid/hid-generic.ko:
+`
+	testAlias = `# Aliases extracted from modules themselves.
+alias ipt_icmp ip_tables
+alias netdev-tunl0 ipip
+alias rtnl-link-ipip ipip
+alias fs-xfs xfs
+alias hid:b*g0001v*p* hid_generic
+`
+	testBuiltin = `kernel/fs/ext4/ext4.ko
+`
+)
+
+func writeIndex(c *C) string {
+	dir := c.MkDir()
+	for name, content := range map[string]string{
+		"modules.dep":     testDep,
+		"modules.alias":   testAlias,
+		"modules.builtin": testBuiltin,
+	} {
+		c.Assert(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644), IsNil)
+	}
+	return dir
+}
+
+func (s *KmodLockSuite) TestParseArgs(c *C) {
+	p := ParseArgs([]string{"--profile=baseline", "--block=unsigned_module", "--allow=ip_tables, fs-*", "--deny=ipip"})
+	c.Assert(p.Allow, DeepEquals, []string{"ip_tables", "fs-*"})
+	c.Assert(p.Deny, DeepEquals, []string{"ipip"})
+}
+
+func (s *KmodLockSuite) TestReadIndex(c *C) {
+	idx, err := ReadIndex(writeIndex(c))
+	c.Assert(err, IsNil)
+	c.Assert(idx.Modules, HasLen, 6)
+	_, ok := idx.Modules["hid_generic"]
+	c.Assert(ok, Equals, true)
+	_, ok = idx.Modules["ext4"]
+	c.Assert(ok, Equals, true)
+	c.Assert(idx.Aliases, HasLen, 4)
+	c.Assert(idx.Aliases["netdev-tunl0"], DeepEquals, []string{"ipip"})
+
+	_, err = ReadIndex(c.MkDir())
+	c.Assert(err, NotNil)
+}
+
+func (s *KmodLockSuite) TestResolve(c *C) {
+	idx, err := ReadIndex(writeIndex(c))
+	c.Assert(err, IsNil)
+
+	names, err := idx.Resolve([]string{"ip_tables", "hid-generic", "fs-*", "rtnl-link-ipip"})
+	c.Assert(err, IsNil)
+	c.Assert(names, DeepEquals, []string{"fs-xfs", "hid_generic", "ip_tables", "ipt_icmp", "rtnl-link-ipip"})
+
+	_, err = idx.Resolve([]string{"nosuchmod"})
+	c.Assert(err, ErrorMatches, "unknown module or alias 'nosuchmod'")
+	_, err = idx.Resolve([]string{"net-pf-*"})
+	c.Assert(err, ErrorMatches, "modalias pattern 'net-pf-\\*' does not match any module alias")
+}
+
+func (s *KmodLockSuite) TestRules(c *C) {
+	idx, err := ReadIndex(writeIndex(c))
+	c.Assert(err, IsNil)
+
+	p := &Policy{Allow: []string{"ip_tables", "ipip"}, Deny: []string{"ipip"}}
+	rules, err := p.Rules(idx)
+	c.Assert(err, IsNil)
+	c.Assert(rules, DeepEquals, map[string]uint32{
+		"ip_tables":      ruleAllow,
+		"ipt_icmp":       ruleAllow,
+		"ipip":           ruleDeny,
+		"netdev-tunl0":   ruleDeny,
+		"rtnl-link-ipip": ruleDeny,
+	})
+
+	// Without kernel modules names are loaded as they are
+	p = &Policy{Allow: []string{"hid-generic"}}
+	rules, err = p.Rules(nil)
+	c.Assert(err, IsNil)
+	c.Assert(rules, DeepEquals, map[string]uint32{"hid-generic": ruleAllow, "hid_generic": ruleAllow})
+
+	p = &Policy{Allow: []string{"fs-*"}}
+	_, err = p.Rules(nil)
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,7 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package netlock translates the socket family names of the netlock
+// configuration into address family and socket type numbers and loads the
+// restricted ones into the pinned map of the netlock bpf program.
+package netlock
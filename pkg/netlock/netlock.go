@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package netlock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	subsystem = "netlock"
+
+	ProfileAllow      = "allow"
+	ProfileBaseline   = "baseline"
+	ProfileRestricted = "restricted"
+
+	// OptionsMap is the name of the pinned map of netlock options
+	OptionsMap = "netlock_map"
+
+	// FamiliesMap is the name of the pinned map of restricted families
+	FamiliesMap = "netlock_families_map"
+
+	// keyReady of OptionsMap must match bpf/netlock.h
+	keyReady uint32 = 2
+)
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+	// families maps the restricted family names to their address family
+	// and socket type numbers.
+	families = map[string][]Family{
+		"bluetooth": {{Family: unix.AF_BLUETOOTH}},
+		"can":       {{Family: unix.AF_CAN}},
+		"packet":    {{Family: unix.AF_PACKET}},
+		"raw": {
+			{Family: unix.AF_INET, Type: unix.SOCK_RAW},
+			{Family: unix.AF_INET6, Type: unix.SOCK_RAW},
+		},
+		"vsock": {{Family: unix.AF_VSOCK}},
+	}
+)
+
+// Family is a socket address family, sockets of all types are matched
+// if Type is zero. It must match struct bl_socket_family of bpf/netlock.h.
+type Family struct {
+	Family uint32
+	Type   uint32
+}
+
+// Policy is the netlock configuration of the bpf program arguments.
+type Policy struct {
+	Profile string
+
+	// Allow are the names of the allowed families
+	Allow []string
+}
+
+// ParseArgs returns the policy of the netlock program arguments.
+func ParseArgs(args []string) (*Policy, error) {
+	p := &Policy{Profile: ProfileAllow}
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--profile="):
+			switch profile := strings.TrimPrefix(arg, "--profile="); profile {
+			case ProfileBaseline, ProfileRestricted:
+				p.Profile = profile
+			default:
+				p.Profile = ProfileAllow
+			}
+		case strings.HasPrefix(arg, "--allow="):
+			for _, name := range strings.Split(strings.TrimPrefix(arg, "--allow="), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					p.Allow = append(p.Allow, name)
+				}
+			}
+		}
+	}
+	if _, err := Restricted(p.Allow); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Restricted returns the sorted families that are restricted when the
+// family names in allow are allowed.
+func Restricted(allow []string) ([]Family, error) {
+	allowed := make(map[string]struct{}, len(allow))
+	for _, name := range allow {
+		if _, ok := families[name]; !ok {
+			return nil, fmt.Errorf("unknown socket family '%s'", name)
+		}
+		allowed[name] = struct{}{}
+	}
+	var out []Family
+	for name, fams := range families {
+		if _, ok := allowed[name]; !ok {
+			out = append(out, fams...)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Family != out[j].Family {
+			return out[i].Family < out[j].Family
+		}
+		return out[i].Type < out[j].Type
+	})
+	return out, nil
+}
+
+// Locker updates the pinned maps of the netlock program.
+type Locker struct {
+	// pinDir is the directory of netlock pins
+	pinDir string
+}
+
+// NewLocker returns a locker of the netlock program pinned inside
+// pinPrefix, usually bpf.MapPrefixPath().
+func NewLocker(pinPrefix string) *Locker {
+	return &Locker{
+		pinDir: filepath.Join(pinPrefix, components.NetLock),
+	}
+}
+
+// Loaded returns true if the netlock program is pinned.
+func (l *Locker) Loaded() bool {
+	_, err := os.Stat(filepath.Join(l.pinDir, OptionsMap))
+	return err == nil
+}
+
+func (l *Locker) openMap(name string) (int, error) {
+	return bpf.ObjGet(filepath.Join(l.pinDir, name))
+}
+
+// Restrict sets the restricted families to fams and removes the others.
+// Restrictions are enforced once the restricted families are loaded.
+func (l *Locker) Restrict(fams []Family) error {
+	set := make(map[Family]struct{}, len(fams))
+	for _, f := range fams {
+		set[f] = struct{}{}
+	}
+
+	fd, err := l.openMap(FamiliesMap)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	value := uint32(1)
+	for _, f := range fams {
+		f := f
+		if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&f), unsafe.Pointer(&value), unix.BPF_ANY); err != nil {
+			return fmt.Errorf("unable to restrict socket family %d type %d: %w", f.Family, f.Type, err)
+		}
+	}
+
+	var stale []Family
+	var key, next Family
+	var pkey unsafe.Pointer
+	for {
+		err := bpf.MapGetNextKey(fd, pkey, unsafe.Pointer(&next))
+		if errors.Is(err, unix.ENOENT) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("unable to list restricted socket families: %w", err)
+		}
+		if _, ok := set[next]; !ok {
+			stale = append(stale, next)
+		}
+		key = next
+		pkey = unsafe.Pointer(&key)
+	}
+	for _, f := range stale {
+		f := f
+		if err := bpf.MapDeleteElem(fd, unsafe.Pointer(&f)); err != nil && !errors.Is(err, unix.ENOENT) {
+			return fmt.Errorf("unable to remove socket family %d type %d: %w", f.Family, f.Type, err)
+		}
+	}
+
+	ofd, err := l.openMap(OptionsMap)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(ofd)
+
+	k := keyReady
+	if err := bpf.MapUpdateElem(ofd, unsafe.Pointer(&k), unsafe.Pointer(&value), unix.BPF_ANY); err != nil {
+		return fmt.Errorf("unable to enable netlock restrictions: %w", err)
+	}
+
+	log.Debugf("Restricting %d socket families, removed %d stale entries", len(fams), len(stale))
+	return nil
+}
+
+// Update loads the restricted families of the policy, it does nothing if
+// the netlock program is not loaded.
+func (l *Locker) Update(p *Policy) error {
+	if !l.Loaded() {
+		return nil
+	}
+	fams, err := Restricted(p.Allow)
+	if err != nil {
+		return err
+	}
+	return l.Restrict(fams)
+}
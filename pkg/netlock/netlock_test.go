@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package netlock
+
+import (
+	"testing"
+
+	"golang.org/x/sys/unix"
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type NetLockSuite struct{}
+
+var _ = Suite(&NetLockSuite{})
+
+func (s *NetLockSuite) TestRestricted(c *C) {
+	fams, err := Restricted([]string{"bluetooth", "can", "vsock"})
+	c.Assert(err, IsNil)
+	c.Assert(fams, DeepEquals, []Family{
+		{Family: unix.AF_INET, Type: unix.SOCK_RAW},
+		{Family: unix.AF_INET6, Type: unix.SOCK_RAW},
+		{Family: unix.AF_PACKET},
+	})
+
+	fams, err = Restricted(nil)
+	c.Assert(err, IsNil)
+	c.Assert(fams, HasLen, 6)
+
+	_, err = Restricted([]string{"can", "appletalk"})
+	c.Assert(err, ErrorMatches, "unknown socket family 'appletalk'")
+}
+
+func (s *NetLockSuite) TestParseArgs(c *C) {
+	p, err := ParseArgs([]string{"--profile=restricted", "--allow=can, vsock"})
+	c.Assert(err, IsNil)
+	c.Assert(p.Profile, Equals, ProfileRestricted)
+	c.Assert(p.Allow, DeepEquals, []string{"can", "vsock"})
+
+	p, err = ParseArgs([]string{"--profile=privileged"})
+	c.Assert(err, IsNil)
+	c.Assert(p.Profile, Equals, ProfileAllow)
+
+	_, err = ParseArgs([]string{"--profile=baseline", "--allow=ipx"})
+	c.Assert(err, ErrorMatches, "unknown socket family 'ipx'")
+}
+
+func (s *NetLockSuite) TestLockerNotLoaded(c *C) {
+	l := NewLocker(c.MkDir())
+	c.Assert(l.Loaded(), Equals, false)
+	c.Assert(l.Update(&Policy{Profile: ProfileBaseline}), IsNil)
+}
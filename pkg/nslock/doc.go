@@ -0,0 +1,7 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package nslock translates the blocked and allowed namespace types of the
+// nslock configuration into bit masks and loads them into the pinned map
+// of the nslock bpf program.
+package nslock
@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package nslock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	subsystem = "nslock"
+
+	// OptionsMap is the name of the pinned map of nslock options
+	OptionsMap = "nslock_map"
+
+	// Keys of OptionsMap, they must match bpf/nslock.h
+	keyBlock uint32 = 2
+	keyAllow uint32 = 3
+)
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+	// namespaces maps the namespace type names to their bits, they must
+	// match bpf/nslock.h
+	namespaces = map[string]uint32{
+		"userns": 1 << 0,
+		"netns":  1 << 1,
+		"mntns":  1 << 2,
+	}
+)
+
+// Policy is the nslock configuration of the bpf program arguments.
+type Policy struct {
+	// Block are the restricted namespace types, all types if empty
+	Block []string
+
+	// Allow are the namespace types that are never restricted
+	Allow []string
+}
+
+// ParseArgs returns the policy of the nslock program arguments.
+func ParseArgs(args []string) (*Policy, error) {
+	p := &Policy{}
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--block="):
+			p.Block = append(p.Block, splitList(strings.TrimPrefix(arg, "--block="))...)
+		case strings.HasPrefix(arg, "--allow="):
+			p.Allow = append(p.Allow, splitList(strings.TrimPrefix(arg, "--allow="))...)
+		}
+	}
+	if _, err := p.Entries(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func mask(names []string) (uint32, error) {
+	var m uint32
+	for _, name := range names {
+		bit, ok := namespaces[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown namespace type '%s'", name)
+		}
+		m |= bit
+	}
+	return m, nil
+}
+
+// Entries returns the entries of OptionsMap of the policy. A zero block
+// mask restricts all namespace types, a type can not be both blocked and
+// allowed.
+func (p *Policy) Entries() (map[uint32]uint32, error) {
+	block, err := mask(p.Block)
+	if err != nil {
+		return nil, err
+	}
+	allow, err := mask(p.Allow)
+	if err != nil {
+		return nil, err
+	}
+	if both := block & allow; both != 0 {
+		var names []string
+		for name, bit := range namespaces {
+			if both&bit != 0 {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("namespace types '%s' are both blocked and allowed", strings.Join(names, ","))
+	}
+	return map[uint32]uint32{
+		keyBlock: block,
+		keyAllow: allow,
+	}, nil
+}
+
+// Locker updates the pinned map of the nslock program.
+type Locker struct {
+	// pinDir is the directory of nslock pins
+	pinDir string
+}
+
+// NewLocker returns a locker of the nslock program pinned inside
+// pinPrefix, usually bpf.MapPrefixPath().
+func NewLocker(pinPrefix string) *Locker {
+	return &Locker{
+		pinDir: filepath.Join(pinPrefix, components.NsLock),
+	}
+}
+
+// Loaded returns true if the nslock program is pinned.
+func (l *Locker) Loaded() bool {
+	_, err := os.Stat(filepath.Join(l.pinDir, OptionsMap))
+	return err == nil
+}
+
+// SetEntries writes the entries into OptionsMap.
+func (l *Locker) SetEntries(entries map[uint32]uint32) error {
+	fd, err := bpf.ObjGet(filepath.Join(l.pinDir, OptionsMap))
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	for k, v := range entries {
+		k, v := k, v
+		if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&k), unsafe.Pointer(&v), unix.BPF_ANY); err != nil {
+			return fmt.Errorf("unable to update nslock option %d: %w", k, err)
+		}
+	}
+
+	log.Debugf("Restricting namespace types 0x%x, allowing 0x%x", entries[keyBlock], entries[keyAllow])
+	return nil
+}
+
+// Update loads the blocked and allowed namespace types of the policy, it
+// does nothing if the nslock program is not loaded.
+func (l *Locker) Update(p *Policy) error {
+	if !l.Loaded() {
+		return nil
+	}
+	entries, err := p.Entries()
+	if err != nil {
+		return err
+	}
+	return l.SetEntries(entries)
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package nslock
+
+import (
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type NsLockSuite struct{}
+
+var _ = Suite(&NsLockSuite{})
+
+func (s *NsLockSuite) TestParseArgs(c *C) {
+	p, err := ParseArgs([]string{"--profile=baseline", "--block=userns, mntns", "--allow=netns"})
+	c.Assert(err, IsNil)
+	c.Assert(p.Block, DeepEquals, []string{"userns", "mntns"})
+	c.Assert(p.Allow, DeepEquals, []string{"netns"})
+
+	p, err = ParseArgs([]string{"--profile=restricted"})
+	c.Assert(err, IsNil)
+	c.Assert(p.Block, HasLen, 0)
+	c.Assert(p.Allow, HasLen, 0)
+
+	_, err = ParseArgs([]string{"--block=userns,pidns"})
+	c.Assert(err, ErrorMatches, "unknown namespace type 'pidns'")
+
+	_, err = ParseArgs([]string{"--allow=user"})
+	c.Assert(err, ErrorMatches, "unknown namespace type 'user'")
+
+	_, err = ParseArgs([]string{"--block=userns,netns", "--allow=netns,userns"})
+	c.Assert(err, ErrorMatches, "namespace types 'netns,userns' are both blocked and allowed")
+}
+
+func (s *NsLockSuite) TestEntries(c *C) {
+	entries, err := (&Policy{Block: []string{"userns", "mntns"}}).Entries()
+	c.Assert(err, IsNil)
+	c.Assert(entries, DeepEquals, map[uint32]uint32{keyBlock: 0x5, keyAllow: 0})
+
+	// All types are blocked but the allowed ones
+	entries, err = (&Policy{Allow: []string{"netns"}}).Entries()
+	c.Assert(err, IsNil)
+	c.Assert(entries, DeepEquals, map[uint32]uint32{keyBlock: 0, keyAllow: 0x2})
+
+	// Stale entries are reset
+	entries, err = (&Policy{}).Entries()
+	c.Assert(err, IsNil)
+	c.Assert(entries, DeepEquals, map[uint32]uint32{keyBlock: 0, keyAllow: 0})
+}
+
+func (s *NsLockSuite) TestLockerNotLoaded(c *C) {
+	l := NewLocker(c.MkDir())
+	c.Assert(l.Loaded(), Equals, false)
+	c.Assert(l.Update(&Policy{Block: []string{"userns"}}), IsNil)
+}
@@ -7,6 +7,7 @@ package option
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -20,6 +21,8 @@ import (
 	"github.com/linux-lock/bpflock/pkg/lock"
 	"github.com/linux-lock/bpflock/pkg/logging"
 	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+	"github.com/linux-lock/bpflock/pkg/policy"
+	"github.com/linux-lock/bpflock/pkg/sysctl"
 	"github.com/linux-lock/bpflock/pkg/version"
 
 	"github.com/sirupsen/logrus"
@@ -55,6 +58,10 @@ const (
 	// BpfConfigDir is the directory that contains bpf programs conifigurations
 	BpfConfigDir = "bpf-config-dir"
 
+	// BpfConfigKeys is the file or directory of the trusted public keys
+	// that must sign the bpf programs configurations
+	BpfConfigKeys = "bpf-config-keys"
+
 	// DebugArg is the argument enables debugging mode
 	DebugArg = "debug"
 
@@ -106,17 +113,96 @@ const (
 	// bpfrestrict
 	BpfRestrictProfile = "bpfrestrict-profile"
 	BpfRestrictBlock   = "bpfrestrict-block"
+	BpfRestrictExempt  = "bpfrestrict-exempt"
 
 	// kmodlock
 	KmodLockProfile = "kmodlock-profile"
 	KmodLockBlock   = "kmodlock-block"
+	KmodLockAllow   = "kmodlock-allow"
+	KmodLockDeny    = "kmodlock-deny"
+	KmodLockExempt  = "kmodlock-exempt"
+
+	// EnableEventLog enables storing security events in the local event log
+	EnableEventLog = "event-log"
+
+	// EventLogMaxSize is the maximum size in MB of the local event log
+	EventLogMaxSize = "event-log-max-size"
+
+	// SysctlProfile is the sysctl hardening profile: none, baseline or restricted
+	SysctlProfile = "sysctl-profile"
+
+	// SysctlSection is the sysctl section of the configuration file with
+	// the "profile" and "settings" keys
+	SysctlSection = "sysctl"
+
+	// Restore adopts the pinned bpf programs of a previous bpflock instance
+	Restore = "restore"
+
+	// TamperReapply re-applies bpf programs when tampering is detected
+	TamperReapply = "tamper-reapply"
 
 	KimgLockProfile = "kimglock-profile"
 	KimgLockAllow   = "kimglock-allow"
+	KimgLockExempt  = "kimglock-exempt"
+
+	// selflock
+	SelfLockProfile = "selflock-profile"
+	SelfLockBlock   = "selflock-block"
+
+	// fslock
+	FsLockProfile = "fslock-profile"
+	FsLockAllow   = "fslock-allow"
+
+	// rootfslock
+	RootfsLockProfile = "rootfslock-profile"
+	RootfsLockAllow   = "rootfslock-allow"
+
+	// execlock
+	ExecLockProfile = "execlock-profile"
+	ExecLockExempt  = "execlock-exempt"
+
+	// nslock
+	NsLockProfile = "nslock-profile"
+	NsLockBlock   = "nslock-block"
+	NsLockAllow   = "nslock-allow"
+
+	// netlock
+	NetLockProfile = "netlock-profile"
+	NetLockAllow   = "netlock-allow"
+
+	// usblock
+	UsbLockProfile    = "usblock-profile"
+	UsbLockAllow      = "usblock-allow"
+	UsbLockAllowClass = "usblock-allow-class"
+
+	// TrustSuffix and TrustNsSuffix are the suffixes of the baseline trust
+	// options of programs, like "kmodlock-trust" and "kmodlock-trust-ns"
+	TrustSuffix   = "-trust"
+	TrustNsSuffix = "-trust-ns"
+
+	// AllowUIDSuffix, AllowGIDSuffix, DenyUIDSuffix and DenyGIDSuffix are
+	// the suffixes of the user and group rules of the baseline trust,
+	// like "kmodlock-allow-uid"
+	AllowUIDSuffix = "-allow-uid"
+	AllowGIDSuffix = "-allow-gid"
+	DenyUIDSuffix  = "-deny-uid"
+	DenyGIDSuffix  = "-deny-gid"
 
 	bpflockEnvPrefix = "BPFLOCK_"
 )
 
+// TrustPrograms are the programs that accept baseline trust criteria.
+var TrustPrograms = []string{
+	components.SelfLock,
+	components.FsLock,
+	components.RootfsLock,
+	components.KmodLock,
+	components.NsLock,
+	components.ExecLock,
+	components.NetLock,
+	components.BpfRestrict,
+}
+
 // getEnvName returns the environment variable to be used for the given option name.
 func getEnvName(option string) string {
 	under := strings.Replace(option, "-", "_", -1)
@@ -188,6 +274,17 @@ type DaemonConfig struct {
 	// RestoreState enables restoring the state from previous running daemons.
 	RestoreState bool
 
+	// SysctlProfile is the sysctl hardening profile
+	SysctlProfile string
+
+	// SysctlSettings override or add sysctl settings to SysctlProfile
+	SysctlSettings map[string]string
+
+	// DisabledBpfProgs are the bpf programs that the kernel can not run,
+	// mapped to the reason. Set by probing kernel features. Once the
+	// daemon runs, use GetBpfPrograms.
+	DisabledBpfProgs map[string]string
+
 	// Remove Bpf programs on exit
 	RmBpfOnExit bool
 
@@ -212,6 +309,16 @@ type DaemonConfig struct {
 	// EnableIPv4 is true when IPv4 is enabled
 	EnableIPv4 bool
 
+	// EnableEventLog is true when security events are stored locally
+	EnableEventLog bool
+
+	// EventLogMaxSize is the maximum size in MB of the local event log
+	EventLogMaxSize int
+
+	// TamperReapply is true if bpf programs are re-applied when tampering
+	// with their pins or with the bpf filesystem is detected
+	TamperReapply bool
+
 	// EnableIPv6 is true when IPv6 is enabled
 	EnableIPv6 bool
 
@@ -223,6 +330,16 @@ type DaemonConfig struct {
 	ConfigFile   string
 	ConfigDir    string
 	BpfConfigDir string
+
+	// BpfConfigKeys are the trusted public keys of bpf programs
+	// configurations, signatures are not required if empty
+	BpfConfigKeys string
+
+	// BpfConfigRejected is set if bpf programs configurations were
+	// rejected, the agent refuses to start once they are recorded in the
+	// event log
+	BpfConfigRejected *BpfConfigRejectedError
+
 	Debug        bool
 	DebugVerbose []string
 	LogDriver    []string
@@ -235,7 +352,13 @@ type DaemonConfig struct {
 	PProfPort           int
 	PrometheusServeAddr string
 
+	// BpfMeta is the effective bpf programs configuration. Once the daemon
+	// runs, use GetBpfPrograms.
 	BpfMeta *models.BpfMeta
+
+	// bpfProgramsMutex protects BpfMeta and DisabledBpfProgs, they are
+	// replaced when a bpf programs configuration is applied
+	bpfProgramsMutex lock.RWMutex
 }
 
 var (
@@ -252,21 +375,68 @@ var (
 
 	BpflockBpfProgs = map[string]models.BpfProgram{
 		// For now lets keep bpf programs sorted here
+		// bpflock self protection is loaded first
+		components.SelfLock: {
+			Name:        "selflock",
+			Priority:    10,
+			Description: "Protect bpflock, its bpf programs and configuration",
+		},
+		// hardware restrictions
+		components.UsbLock: {
+			Name:        "usblock",
+			Priority:    40,
+			Description: "Restrict USB device additions",
+			After:       []string{components.SelfLock},
+		},
+		components.FsLock: {
+			Name:        "fslock",
+			Priority:    45,
+			Description: "Restrict access to filesystems",
+			After:       []string{components.SelfLock},
+		},
+		components.RootfsLock: {
+			Name:        "rootfslock",
+			Priority:    47,
+			Description: "Restrict writes to the root filesystem and sysfs",
+			After:       []string{components.SelfLock},
+		},
 		// kernel features restrictions priority starts from 50
 		components.KimgLock: {
 			Name:        "kimglock",
 			Priority:    50,
 			Description: "Restrict both direct and indirect modification to a running kernel image",
+			After:       []string{components.SelfLock},
 		},
 		components.KmodLock: {
 			Name:        "kmodlock",
 			Priority:    60,
 			Description: "Restrict kernel module operations on modular kernels",
+			After:       []string{components.SelfLock},
+		},
+		components.NsLock: {
+			Name:        "nslock",
+			Priority:    65,
+			Description: "Restrict creation of user, network and mount namespaces",
+			After:       []string{components.SelfLock},
+		},
+		components.ExecLock: {
+			Name:        "execlock",
+			Priority:    70,
+			Description: "Restrict execution of memory-backed and unlinked files",
+			After:       []string{components.SelfLock},
+		},
+		components.NetLock: {
+			Name:        "netlock",
+			Priority:    80,
+			Description: "Restrict creation of sockets of selected address families",
+			After:       []string{components.SelfLock},
 		},
 		components.BpfRestrict: {
 			Name:        "bpfrestrict",
 			Priority:    90,
 			Description: "Restrict access to the bpf() system call",
+			// bpfrestrict may deny loading the other ones
+			After: []string{AllPrograms},
 		},
 	}
 
@@ -295,6 +465,46 @@ func (progs BpfByPriority) Swap(i, j int) {
 	progs[i], progs[j] = progs[j], progs[i]
 }
 
+// GetBpfPrograms returns the effective bpf programs configuration and the
+// disabled bpf programs. They are replaced as a whole by SetBpfPrograms and
+// must not be modified.
+func (c *DaemonConfig) GetBpfPrograms() (*models.BpfMeta, map[string]string) {
+	c.bpfProgramsMutex.RLock()
+	defer c.bpfProgramsMutex.RUnlock()
+	return c.BpfMeta, c.DisabledBpfProgs
+}
+
+// BpfPrograms returns the programs of the effective bpf programs
+// configuration, they must not be modified.
+func (c *DaemonConfig) BpfPrograms() []*models.BpfProgram {
+	meta, _ := c.GetBpfPrograms()
+	return meta.Bpfspec.Programs
+}
+
+// SetBpfPrograms replaces the effective bpf programs configuration and the
+// disabled bpf programs.
+func (c *DaemonConfig) SetBpfPrograms(meta *models.BpfMeta, disabled map[string]string) {
+	c.bpfProgramsMutex.Lock()
+	defer c.bpfProgramsMutex.Unlock()
+	c.BpfMeta, c.DisabledBpfProgs = meta, disabled
+}
+
+// GetEventLogDir returns the path for the event log directory.
+func (c *DaemonConfig) GetEventLogDir() string {
+	return filepath.Join(c.VarLibDir, defaults.EventLogDir)
+}
+
+// GetAuditDir returns the path for the node key and the signed head of the
+// event log.
+func (c *DaemonConfig) GetAuditDir() string {
+	return filepath.Join(c.VarLibDir, defaults.AuditDir)
+}
+
+// GetPolicyHistoryDir returns the path for the policy history directory.
+func (c *DaemonConfig) GetPolicyHistoryDir() string {
+	return filepath.Join(c.VarLibDir, defaults.PolicyHistoryDir)
+}
+
 // GetGlobalsDir returns the path for the globals directory.
 func (c *DaemonConfig) GetGlobalsDir() string {
 	return filepath.Join(c.StateDir, "globals")
@@ -322,7 +532,10 @@ func isBpfProfileValid(profile string) error {
 }
 
 func (c *DaemonConfig) areBpfProgramsOk() error {
-	bpfMeta := c.BpfMeta
+	return areBpfProgramsOk(c.BpfMeta)
+}
+
+func areBpfProgramsOk(bpfMeta *models.BpfMeta) error {
 	if bpfMeta.Bpfspec == nil || len(bpfMeta.Bpfspec.Programs) == 0 {
 		return fmt.Errorf("unable to find spec and bpf programs configuration")
 	}
@@ -332,8 +545,10 @@ func (c *DaemonConfig) areBpfProgramsOk() error {
 		profile := ""
 		for _, n := range p.Args {
 			if strings.HasPrefix(n, "--profile") {
-				arg := strings.Split(n, "=")
-				profile = arg[1]
+				arg := strings.SplitN(n, "=", 2)
+				if len(arg) == 2 {
+					profile = arg[1]
+				}
 				break
 			}
 		}
@@ -342,11 +557,80 @@ func (c *DaemonConfig) areBpfProgramsOk() error {
 		if err != nil {
 			return fmt.Errorf("BpfMeta invalid program '%s': %v", p.Name, err)
 		}
+
+		if err := validateProgramFailure(p); err != nil {
+			return fmt.Errorf("BpfMeta invalid program '%s': %v", p.Name, err)
+		}
 	}
 
+	return validateProgramDependencies(spec.Programs)
+}
+
+// validateProgramFailure checks the on-failure policy, retries and
+// timeouts of p.
+func validateProgramFailure(p *models.BpfProgram) error {
+	switch p.OnFailure {
+	case "", models.BpfProgramOnFailureExit, models.BpfProgramOnFailureDegrade, models.BpfProgramOnFailureRetry:
+	default:
+		return fmt.Errorf("onFailure '%s' not supported", p.OnFailure)
+	}
+	if p.Retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	for _, d := range []struct{ name, value string }{{"timeout", p.Timeout}, {"retryBackoff", p.RetryBackoff}} {
+		if d.value == "" {
+			continue
+		}
+		if v, err := time.ParseDuration(d.value); err != nil || v <= 0 {
+			return fmt.Errorf("%s '%s' is not a valid duration", d.name, d.value)
+		}
+	}
 	return nil
 }
 
+// ProgramOnFailure returns the on-failure policy of p: exit for required
+// programs and degrade for the others if none is set.
+func ProgramOnFailure(p *models.BpfProgram) string {
+	switch {
+	case p.OnFailure != "":
+		return p.OnFailure
+	case p.Required:
+		return models.BpfProgramOnFailureExit
+	default:
+		return models.BpfProgramOnFailureDegrade
+	}
+}
+
+// ProgramTimeout returns the time the launcher of p has to load and
+// attach its bpf program, defaults.ShortExecTimeout if not set.
+func ProgramTimeout(p *models.BpfProgram) time.Duration {
+	if v, err := time.ParseDuration(p.Timeout); err == nil && v > 0 {
+		return v
+	}
+	return defaults.ShortExecTimeout
+}
+
+// ProgramRetries returns the number of retries of p, zero if its
+// on-failure policy is not retry.
+func ProgramRetries(p *models.BpfProgram) int {
+	switch {
+	case ProgramOnFailure(p) != models.BpfProgramOnFailureRetry:
+		return 0
+	case p.Retries > 0:
+		return int(p.Retries)
+	default:
+		return defaults.ProgramRetries
+	}
+}
+
+// ProgramRetryBackoff returns the delay before the first retry of p.
+func ProgramRetryBackoff(p *models.BpfProgram) time.Duration {
+	if v, err := time.ParseDuration(p.RetryBackoff); err == nil && v > 0 {
+		return v
+	}
+	return defaults.ProgramRetryBackoff
+}
+
 func (c *DaemonConfig) isBpfMetaOk() error {
 	bpfMeta := c.BpfMeta
 	if bpfMeta.Bpfmetaver != "v1" {
@@ -372,6 +656,10 @@ func (c *DaemonConfig) Validate() error {
 		return fmt.Errorf("invalid BpfMeta: %v", err)
 	}
 
+	if _, err := sysctl.ProfileSettings(c.SysctlProfile, c.SysctlSettings); err != nil {
+		return fmt.Errorf("invalid sysctl configuration: %v", err)
+	}
+
 	return nil
 }
 
@@ -405,6 +693,13 @@ func validateBpfMeta(bpfMeta *models.BpfMeta, storeProgs *[]*models.BpfProgram)
 		if !ok {
 			return fmt.Errorf("bpf program '%s' not supported", prog.Name)
 		}
+		// Launchers are the programs of BpfDir named after them
+		if strings.ContainsRune(prog.Command, '/') || strings.Contains(prog.Command, "..") {
+			return fmt.Errorf("bpf program '%s' command '%s' must not be a path", prog.Name, prog.Command)
+		}
+		if prog.Command != prog.Name {
+			return fmt.Errorf("bpf program '%s' command '%s' must be its name", prog.Name, prog.Command)
+		}
 		for _, p := range *storeProgs {
 			if prog.Name == p.Name {
 				log.Warnf("program '%s' was already provided, duplicate entry", prog.Name)
@@ -416,6 +711,40 @@ func validateBpfMeta(bpfMeta *models.BpfMeta, storeProgs *[]*models.BpfProgram)
 	return nil
 }
 
+// ValidateBpfMeta validates bpfMeta with the rules of the bpf.d
+// configurations, and returns the configuration to apply: programs have
+// their description and priority set and are sorted by priority.
+func ValidateBpfMeta(bpfMeta *models.BpfMeta) (*models.BpfMeta, error) {
+	if bpfMeta == nil || bpfMeta.Bpfspec == nil {
+		return nil, fmt.Errorf("bpfspec is missing")
+	}
+
+	progs := make([]*models.BpfProgram, 0)
+	if err := validateBpfMeta(bpfMeta, &progs); err != nil {
+		return nil, err
+	}
+
+	meta := &models.BpfMeta{
+		Bpfmetaver: bpfMeta.Bpfmetaver,
+		Kind:       bpfMeta.Kind,
+		Bpfmetadata: &models.BpfMetadata{
+			Name: bpfMeta.Bpfmetadata.Name,
+		},
+		Bpfspec: &models.BpfSpec{
+			Programs: make([]*models.BpfProgram, 0, len(progs)),
+		},
+	}
+	if err := populateBpfMetaProgs(meta, progs); err != nil {
+		return nil, err
+	}
+	sort.Sort(BpfByPriority(meta.Bpfspec.Programs))
+
+	if err := areBpfProgramsOk(meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
 // validateConfigmap checks whether the flag exists and validate the value of flag
 func validateConfigmap(cmd *cobra.Command, m map[string]interface{}) (error, string) {
 	// validate the config-map
@@ -445,11 +774,17 @@ func populateBpfMetaProgs(dst *models.BpfMeta, passedprogs []*models.BpfProgram)
 		}
 
 		prog := &models.BpfProgram{
-			Name:        p.Name,
-			Command:     p.Command,
-			Description: pbpf.Description,
-			Priority:    pbpf.Priority,
-			Args:        p.Args,
+			Name:         p.Name,
+			Command:      p.Command,
+			Description:  pbpf.Description,
+			Priority:     pbpf.Priority,
+			Args:         p.Args,
+			Required:     p.Required,
+			OnFailure:    p.OnFailure,
+			Retries:      p.Retries,
+			RetryBackoff: p.RetryBackoff,
+			Timeout:      p.Timeout,
+			After:        mergeDependencies(pbpf.After, p.After),
 		}
 
 		if _, ok = pushed[p.Name]; ok {
@@ -467,10 +802,98 @@ func populateBpfMetaProgs(dst *models.BpfMeta, passedprogs []*models.BpfProgram)
 	return nil
 }
 
+// BpfConfigRejectedError is returned when bpf configuration files are not
+// signed by one of the trusted keys.
+type BpfConfigRejectedError struct {
+	Dir   string
+	Files []string
+}
+
+func (e *BpfConfigRejectedError) Error() string {
+	return fmt.Sprintf("config '%s' rejected: %v", strings.Join(e.Files, "', '"), policy.ErrUnsigned)
+}
+
+func (e *BpfConfigRejectedError) Unwrap() error {
+	return policy.ErrUnsigned
+}
+
+// verifyBpfDirConfig rejects the bpf configuration files that are not
+// signed by one of the trusted keys of Config.BpfConfigKeys, contents are
+// the files of dirName by name as they were read.
+func verifyBpfDirConfig(dirName string, contents map[string][]byte) error {
+	keys, err := policy.LoadKeys(Config.BpfConfigKeys)
+	if err != nil {
+		return fmt.Errorf("unable to load trusted keys of bpf configurations: %v", err)
+	}
+
+	rejected, err := policy.VerifyContents(keys, dirName, contents)
+	if err != nil {
+		return fmt.Errorf("unable to verify signatures of bpf configurations: %v", err)
+	}
+	for _, name := range rejected {
+		log.WithFields(logrus.Fields{
+			logfields.Path: filepath.Join(dirName, name),
+			"keys":         Config.BpfConfigKeys,
+		}).Error("Rejected bpf configuration file: no valid signature from a trusted key")
+	}
+	if len(rejected) > 0 {
+		return &BpfConfigRejectedError{Dir: dirName, Files: rejected}
+	}
+	return nil
+}
+
+// bpfConfigEntries returns the bpf configuration files of dirName, their
+// signatures are skipped.
+func bpfConfigEntries(dirName string) ([]os.DirEntry, error) {
+	entries, err := readDirConfig(dirName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read configuration directory %s", dirName)
+	}
+
+	files := make([]os.DirEntry, 0, len(entries))
+	for _, f := range entries {
+		if !policy.IsSignature(f.Name()) {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// BpfConfigFiles returns the names of the bpf configuration files of
+// dirName.
+func BpfConfigFiles(dirName string) ([]string, error) {
+	files, err := bpfConfigEntries(dirName)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	return names, nil
+}
+
 func ReadBpfDirConfig(dirName string, BpfMeta *models.BpfMeta) error {
-	files, err := readDirConfig(dirName)
+	files, err := bpfConfigEntries(dirName)
 	if err != nil {
-		return fmt.Errorf("unable to read configuration directory %s", dirName)
+		return err
+	}
+
+	// Files are read once, the verified contents are the parsed ones
+	contents := make(map[string][]byte, len(files))
+	for _, f := range files {
+		fileName := filepath.Join(dirName, f.Name())
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			return fmt.Errorf("config '%s' unable to read: %v", fileName, err)
+		}
+		contents[f.Name()] = data
+	}
+
+	if Config.BpfConfigKeys != "" {
+		if err := verifyBpfDirConfig(dirName, contents); err != nil {
+			return err
+		}
 	}
 
 	progs := make([]*models.BpfProgram, 0)
@@ -478,12 +901,11 @@ func ReadBpfDirConfig(dirName string, BpfMeta *models.BpfMeta) error {
 		fileName := filepath.Join(dirName, f.Name())
 
 		viper.SetConfigType("yaml")
-		viper.SetConfigFile(fileName)
-		err = viper.ReadInConfig()
+		err = viper.ReadConfig(bytes.NewReader(contents[f.Name()]))
 		if err != nil {
 			return fmt.Errorf("config '%s' unable to read with viper: %v", fileName, err)
 		} else {
-			log.WithField(logfields.Path, viper.ConfigFileUsed()).
+			log.WithField(logfields.Path, fileName).
 				Info("Using bpflock config from file")
 		}
 
@@ -607,6 +1029,15 @@ func (c *DaemonConfig) Populate() {
 	c.EnableIPv4 = viper.GetBool(EnableIPv4Name)
 	c.EnableIPv6 = viper.GetBool(EnableIPv6Name)
 	c.RmBpfOnExit = viper.GetBool(RmBpfOnExit)
+	c.EnableEventLog = viper.GetBool(EnableEventLog)
+	c.EventLogMaxSize = sanitizeIntParam(EventLogMaxSize, defaults.EventLogMaxSize)
+	c.TamperReapply = viper.GetBool(TamperReapply)
+	c.RestoreState = viper.GetBool(Restore)
+	c.SysctlProfile = viper.GetString(SysctlProfile)
+	if c.SysctlProfile == "" {
+		c.SysctlProfile = viper.GetString(SysctlSection + ".profile")
+	}
+	c.SysctlSettings = viper.GetStringMapString(SysctlSection + ".settings")
 
 	bpfrargs := ""
 	value := viper.GetString(BpfRestrictProfile)
@@ -616,6 +1047,10 @@ func (c *DaemonConfig) Populate() {
 		if value != "" {
 			bpfrargs = fmt.Sprintf("%s --block=%s", bpfrargs, value)
 		}
+		value = viper.GetString(BpfRestrictExempt)
+		if value != "" {
+			bpfrargs = fmt.Sprintf("%s --exempt=%s", bpfrargs, value)
+		}
 	}
 
 	kimgrargs := ""
@@ -626,6 +1061,10 @@ func (c *DaemonConfig) Populate() {
 		if value != "" {
 			kimgrargs = fmt.Sprintf("%s --allow=%s", kimgrargs, value)
 		}
+		value = viper.GetString(KimgLockExempt)
+		if value != "" {
+			kimgrargs = fmt.Sprintf("%s --exempt=%s", kimgrargs, value)
+		}
 	}
 
 	kmodrargs := ""
@@ -636,10 +1075,128 @@ func (c *DaemonConfig) Populate() {
 		if value != "" {
 			kmodrargs = fmt.Sprintf("%s --block=%s", kmodrargs, value)
 		}
+		value = viper.GetString(KmodLockAllow)
+		if value != "" {
+			kmodrargs = fmt.Sprintf("%s --allow=%s", kmodrargs, value)
+		}
+		value = viper.GetString(KmodLockDeny)
+		if value != "" {
+			kmodrargs = fmt.Sprintf("%s --deny=%s", kmodrargs, value)
+		}
+		value = viper.GetString(KmodLockExempt)
+		if value != "" {
+			kmodrargs = fmt.Sprintf("%s --exempt=%s", kmodrargs, value)
+		}
+	}
+
+	selfargs := ""
+	value = viper.GetString(SelfLockProfile)
+	if value != "" {
+		selfargs = fmt.Sprintf("--profile=%s", value)
+		value = viper.GetString(SelfLockBlock)
+		if value != "" {
+			selfargs = fmt.Sprintf("%s --block=%s", selfargs, value)
+		}
+	}
+
+	usbargs := ""
+	value = viper.GetString(UsbLockProfile)
+	if value != "" {
+		usbargs = fmt.Sprintf("--profile=%s", value)
+		value = viper.GetString(UsbLockAllow)
+		if value != "" {
+			usbargs = fmt.Sprintf("%s --allow=%s", usbargs, value)
+		}
+		value = viper.GetString(UsbLockAllowClass)
+		if value != "" {
+			usbargs = fmt.Sprintf("%s --allow-class=%s", usbargs, value)
+		}
+	}
+
+	fsargs := ""
+	value = viper.GetString(FsLockProfile)
+	if value != "" {
+		fsargs = fmt.Sprintf("--profile=%s", value)
+		value = viper.GetString(FsLockAllow)
+		if value != "" {
+			fsargs = fmt.Sprintf("%s --allow=%s", fsargs, value)
+		}
+	}
+
+	rootfsargs := ""
+	value = viper.GetString(RootfsLockProfile)
+	if value != "" {
+		rootfsargs = fmt.Sprintf("--profile=%s", value)
+		value = viper.GetString(RootfsLockAllow)
+		if value != "" {
+			rootfsargs = fmt.Sprintf("%s --allow=%s", rootfsargs, value)
+		}
+	}
+
+	execargs := ""
+	value = viper.GetString(ExecLockProfile)
+	if value != "" {
+		execargs = fmt.Sprintf("--profile=%s", value)
+		value = viper.GetString(ExecLockExempt)
+		if value != "" {
+			execargs = fmt.Sprintf("%s --exempt=%s", execargs, value)
+		}
+	}
+
+	nsargs := ""
+	value = viper.GetString(NsLockProfile)
+	if value != "" {
+		nsargs = fmt.Sprintf("--profile=%s", value)
+		value = viper.GetString(NsLockBlock)
+		if value != "" {
+			nsargs = fmt.Sprintf("%s --block=%s", nsargs, value)
+		}
+		value = viper.GetString(NsLockAllow)
+		if value != "" {
+			nsargs = fmt.Sprintf("%s --allow=%s", nsargs, value)
+		}
+	}
+
+	netargs := ""
+	value = viper.GetString(NetLockProfile)
+	if value != "" {
+		netargs = fmt.Sprintf("--profile=%s", value)
+		value = viper.GetString(NetLockAllow)
+		if value != "" {
+			netargs = fmt.Sprintf("%s --allow=%s", netargs, value)
+		}
 	}
 
 	for _, p := range BpfM.Bpfspec.Programs {
 		switch p.Name {
+		case components.SelfLock:
+			if selfargs != "" {
+				p.Args = strings.Fields(selfargs)
+			}
+		case components.UsbLock:
+			if usbargs != "" {
+				p.Args = strings.Fields(usbargs)
+			}
+		case components.FsLock:
+			if fsargs != "" {
+				p.Args = strings.Fields(fsargs)
+			}
+		case components.ExecLock:
+			if execargs != "" {
+				p.Args = strings.Fields(execargs)
+			}
+		case components.NsLock:
+			if nsargs != "" {
+				p.Args = strings.Fields(nsargs)
+			}
+		case components.NetLock:
+			if netargs != "" {
+				p.Args = strings.Fields(netargs)
+			}
+		case components.RootfsLock:
+			if rootfsargs != "" {
+				p.Args = strings.Fields(rootfsargs)
+			}
 		case components.KimgLock:
 			if kimgrargs != "" {
 				p.Args = strings.Fields(kimgrargs)
@@ -653,6 +1210,21 @@ func (c *DaemonConfig) Populate() {
 				p.Args = strings.Fields(bpfrargs)
 			}
 		}
+
+		// Trust options complete the profile option of the program
+		if viper.GetString(p.Name+"-profile") != "" {
+			if value = viper.GetString(p.Name + TrustSuffix); value != "" {
+				p.Args = append(p.Args, fmt.Sprintf("--trust=%s", value))
+			}
+			if value = viper.GetString(p.Name + TrustNsSuffix); value != "" {
+				p.Args = append(p.Args, fmt.Sprintf("--trust-ns=%s", value))
+			}
+			for _, suffix := range []string{AllowUIDSuffix, AllowGIDSuffix, DenyUIDSuffix, DenyGIDSuffix} {
+				if value = viper.GetString(p.Name + suffix); value != "" {
+					p.Args = append(p.Args, fmt.Sprintf("-%s=%s", suffix, value))
+				}
+			}
+		}
 	}
 
 	c.BpfMeta = &BpfM
@@ -740,9 +1312,15 @@ func InitConfig(cmd *cobra.Command, programName, configName string) func() {
 			return
 		}
 
+		// Client subcommands do not use the agent configuration
+		if cmd.CalledAs() == "" {
+			return
+		}
+
 		Config.ConfigFile = viper.GetString(ConfigFile) // enable ability to specify config file via flag
 		Config.ConfigDir = viper.GetString(ConfigDir)
 		Config.BpfConfigDir = viper.GetString(BpfConfigDir)
+		Config.BpfConfigKeys = viper.GetString(BpfConfigKeys)
 		viper.SetEnvPrefix("bpflock")
 
 		if Config.BpfConfigDir == "" {
@@ -753,7 +1331,11 @@ func InitConfig(cmd *cobra.Command, programName, configName string) func() {
 		}
 
 		if err := ReadBpfDirConfig(Config.BpfConfigDir, &BpfM); err != nil {
-			log.WithError(err).Fatalf("unable to process bpf configurations: %s", Config.BpfConfigDir)
+			var rejected *BpfConfigRejectedError
+			if !errors.As(err, &rejected) {
+				log.WithError(err).Fatalf("unable to process bpf configurations: %s", Config.BpfConfigDir)
+			}
+			Config.BpfConfigRejected = rejected
 		}
 
 		if Config.ConfigDir != "" {
@@ -11,6 +11,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/defaults"
+	"github.com/linux-lock/bpflock/pkg/policy"
 
 	flag "github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -222,3 +227,108 @@ func (s *OptionSuite) Test_backupFiles(c *C) {
 	c.Assert(files[0].Name(), Equals, "test-1.json")
 	c.Assert(files[1].Name(), Equals, "test-2.json")
 }
+
+func (s *OptionSuite) TestValidateBpfMetaPrograms(c *C) {
+	meta := &models.BpfMeta{
+		Bpfmetaver:  "v1",
+		Kind:        "bpf",
+		Bpfmetadata: &models.BpfMetadata{Name: "bpflock"},
+		Bpfspec: &models.BpfSpec{
+			Programs: []*models.BpfProgram{
+				{Name: "execlock", Command: "execlock", Args: []string{"--profile=baseline", "--exempt=/usr/bin/runc"}},
+			},
+		},
+	}
+	progs := make([]*models.BpfProgram, 0)
+	c.Assert(validateBpfMeta(meta, &progs), IsNil)
+	c.Assert(progs, HasLen, 1)
+
+	meta.Bpfspec.Programs = append(meta.Bpfspec.Programs, &models.BpfProgram{Name: "nosuchlock"})
+	progs = progs[:0]
+	c.Assert(validateBpfMeta(meta, &progs), ErrorMatches, "bpf program 'nosuchlock' not supported")
+
+	for cmd, msg := range map[string]string{
+		"../../../usr/bin/kmodlock": "bpf program 'kmodlock' command '../../../usr/bin/kmodlock' must not be a path",
+		"/usr/bin/kmodlock":         "bpf program 'kmodlock' command '/usr/bin/kmodlock' must not be a path",
+		"..":                        "bpf program 'kmodlock' command '..' must not be a path",
+		"execlock":                  "bpf program 'kmodlock' command 'execlock' must be its name",
+		"":                          "bpf program 'kmodlock' command '' must be its name",
+	} {
+		meta.Bpfspec.Programs[1] = &models.BpfProgram{Name: "kmodlock", Command: cmd}
+		progs = progs[:0]
+		c.Assert(validateBpfMeta(meta, &progs), ErrorMatches, msg)
+	}
+}
+
+func (s *OptionSuite) TestProgramFailure(c *C) {
+	p := &models.BpfProgram{Name: "kmodlock", Args: []string{"--profile=baseline"}}
+	c.Assert(validateProgramFailure(p), IsNil)
+	c.Assert(ProgramOnFailure(p), Equals, models.BpfProgramOnFailureDegrade)
+	c.Assert(ProgramTimeout(p), Equals, defaults.ShortExecTimeout)
+	c.Assert(ProgramRetries(p), Equals, 0)
+
+	p.Required = true
+	c.Assert(ProgramOnFailure(p), Equals, models.BpfProgramOnFailureExit)
+
+	p.OnFailure = models.BpfProgramOnFailureRetry
+	p.Timeout = "30s"
+	p.RetryBackoff = "500ms"
+	c.Assert(validateProgramFailure(p), IsNil)
+	c.Assert(ProgramTimeout(p), Equals, 30*time.Second)
+	c.Assert(ProgramRetries(p), Equals, defaults.ProgramRetries)
+	c.Assert(ProgramRetryBackoff(p), Equals, 500*time.Millisecond)
+	p.Retries = 5
+	c.Assert(ProgramRetries(p), Equals, 5)
+
+	p.OnFailure = "ignore"
+	c.Assert(validateProgramFailure(p), ErrorMatches, "onFailure 'ignore' not supported")
+	p.OnFailure = models.BpfProgramOnFailureRetry
+	p.Timeout = "-1s"
+	c.Assert(validateProgramFailure(p), ErrorMatches, "timeout '-1s' is not a valid duration")
+	p.Timeout = ""
+	p.Retries = -1
+	c.Assert(validateProgramFailure(p), ErrorMatches, "retries must not be negative")
+}
+
+func (s *OptionSuite) TestReadBpfDirConfigSigned(c *C) {
+	key := filepath.Join(c.MkDir(), "ci")
+	_, err := policy.GenerateKey(key)
+	c.Assert(err, IsNil)
+	priv, err := policy.LoadPrivateKey(key)
+	c.Assert(err, IsNil)
+	keys := key + policy.PublicKeyExt
+
+	dir := c.MkDir()
+	conf := filepath.Join(dir, "execlock.yaml")
+	c.Assert(os.WriteFile(conf, []byte(`bpfmetaver: "v1"
+kind: "bpf"
+bpfmetadata:
+  name: bpflock
+bpfspec:
+  programs:
+    - name: execlock
+      command: execlock
+      args:
+        - --profile=baseline
+`), 0644), IsNil)
+
+	// ReadBpfDirConfig reads files with the global viper
+	savedKeys := Config.BpfConfigKeys
+	defer func() {
+		Config.BpfConfigKeys = savedKeys
+		viper.Reset()
+	}()
+
+	Config.BpfConfigKeys = keys
+	meta := models.BpfMeta{Bpfspec: &models.BpfSpec{}}
+	err = ReadBpfDirConfig(dir, &meta)
+	c.Assert(err, ErrorMatches, "config 'execlock.yaml' rejected: .*")
+	rejected, ok := err.(*BpfConfigRejectedError)
+	c.Assert(ok, Equals, true)
+	c.Assert(rejected.Files, DeepEquals, []string{"execlock.yaml"})
+
+	c.Assert(policy.SignFile(priv, conf), IsNil)
+	c.Assert(ReadBpfDirConfig(dir, &meta), IsNil)
+	c.Assert(meta.Bpfspec.Programs, HasLen, 1)
+	c.Assert(meta.Bpfspec.Programs[0].Name, Equals, "execlock")
+}
@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package option
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+)
+
+// AllPrograms in the dependencies of a bpf program stands for all the
+// other programs that do not depend on all programs themselves.
+const AllPrograms = "*"
+
+// dependsOnAll returns true if p is started after all the other programs.
+func dependsOnAll(p *models.BpfProgram) bool {
+	for _, a := range p.After {
+		if a == AllPrograms {
+			return true
+		}
+	}
+	return false
+}
+
+// ProgramDependencies returns for each program the configured programs
+// that must be started before it, sorted by name. Dependencies on
+// programs that are not configured are ignored.
+func ProgramDependencies(programs []*models.BpfProgram) map[string][]string {
+	configured := make(map[string]bool, len(programs))
+	for _, p := range programs {
+		configured[p.Name] = true
+	}
+
+	deps := make(map[string][]string, len(programs))
+	for _, p := range programs {
+		seen := make(map[string]bool)
+		add := func(name string) {
+			if name == p.Name || !configured[name] || seen[name] {
+				return
+			}
+			seen[name] = true
+			deps[p.Name] = append(deps[p.Name], name)
+		}
+		for _, a := range p.After {
+			if a != AllPrograms {
+				add(a)
+				continue
+			}
+			for _, o := range programs {
+				if !dependsOnAll(o) {
+					add(o.Name)
+				}
+			}
+		}
+		sort.Strings(deps[p.Name])
+	}
+	return deps
+}
+
+// ProgramStages orders programs in stages: a program only depends on
+// programs of previous stages, so the programs of a stage can be started
+// concurrently. Programs of a stage are sorted by priority.
+func ProgramStages(programs []*models.BpfProgram) ([][]*models.BpfProgram, error) {
+	deps := ProgramDependencies(programs)
+
+	remaining := make([]*models.BpfProgram, len(programs))
+	copy(remaining, programs)
+	sort.Stable(BpfByPriority(remaining))
+
+	started := make(map[string]bool, len(programs))
+	var stages [][]*models.BpfProgram
+	for len(remaining) > 0 {
+		var stage, next []*models.BpfProgram
+		for _, p := range remaining {
+			ready := true
+			for _, d := range deps[p.Name] {
+				if !started[d] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				stage = append(stage, p)
+			} else {
+				next = append(next, p)
+			}
+		}
+		if len(stage) == 0 {
+			names := make([]string, 0, len(remaining))
+			for _, p := range remaining {
+				names = append(names, p.Name)
+			}
+			return nil, fmt.Errorf("dependency cycle between programs %s", strings.Join(names, ", "))
+		}
+		for _, p := range stage {
+			started[p.Name] = true
+		}
+		stages = append(stages, stage)
+		remaining = next
+	}
+	return stages, nil
+}
+
+// validateProgramDependencies checks that programs only depend on
+// supported programs, and that their dependencies have no cycle.
+func validateProgramDependencies(programs []*models.BpfProgram) error {
+	for _, p := range programs {
+		for _, a := range p.After {
+			if _, ok := BpflockBpfProgs[a]; !ok && a != AllPrograms {
+				return fmt.Errorf("BpfMeta invalid program '%s': dependency '%s' not supported", p.Name, a)
+			}
+		}
+	}
+	_, err := ProgramStages(programs)
+	return err
+}
+
+// mergeDependencies returns the dependencies of a and b without
+// duplicates.
+func mergeDependencies(a, b []string) []string {
+	var merged []string
+	seen := make(map[string]bool, len(a)+len(b))
+	for _, l := range [][]string{a, b} {
+		for _, name := range l {
+			if !seen[name] {
+				seen[name] = true
+				merged = append(merged, name)
+			}
+		}
+	}
+	return merged
+}
+
+var (
+	// grantArgs are the arguments of bpf programs whose values allow
+	// operations, removing values tightens the policy
+	grantArgs = map[string]bool{
+		"allow":       true,
+		"allow-class": true,
+		"exempt":      true,
+		"trust-ns":    true,
+	}
+
+	// denyArgs are the arguments of bpf programs whose values deny
+	// operations, adding values tightens the policy. They are mapped to
+	// true if all operations are denied without values.
+	denyArgs = map[string]bool{
+		"block":    true,
+		"deny":     false,
+		"deny-uid": false,
+		"deny-gid": false,
+	}
+)
+
+// argValues returns the values of each "--name=v1,v2" argument of args.
+func argValues(args []string) map[string]map[string]bool {
+	values := make(map[string]map[string]bool, len(args))
+	for _, arg := range args {
+		arg = strings.TrimPrefix(arg, "--")
+		name, list := arg, ""
+		if i := strings.IndexByte(arg, '='); i >= 0 {
+			name, list = arg[:i], arg[i+1:]
+		}
+		if values[name] == nil {
+			values[name] = make(map[string]bool)
+		}
+		for _, v := range strings.Split(list, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values[name][v] = true
+			}
+		}
+	}
+	return values
+}
+
+// LoosenedArgs returns the arguments of the bpf program prev that next
+// loosens, sorted by name: values added to lists of allowed operations,
+// values removed from lists of denied operations and any other change.
+func LoosenedArgs(prev, next *models.BpfProgram) []string {
+	old, cur := argValues(prev.Args), argValues(next.Args)
+
+	var loosened []string
+	names := make(map[string]bool, len(old)+len(cur))
+	for name := range old {
+		names[name] = true
+	}
+	for name := range cur {
+		names[name] = true
+	}
+	for name := range names {
+		o, c := old[name], cur[name]
+		var ok bool
+		if all, deny := denyArgs[name]; deny {
+			switch {
+			case all && len(c) == 0:
+				ok = true
+			case all && len(o) == 0:
+				ok = false
+			default:
+				ok = isSubset(o, c)
+			}
+		} else if grantArgs[name] {
+			ok = isSubset(c, o)
+		} else {
+			ok = o != nil && c != nil && isSubset(o, c) && isSubset(c, o)
+		}
+		if !ok {
+			loosened = append(loosened, name)
+		}
+	}
+	sort.Strings(loosened)
+	return loosened
+}
+
+// isSubset returns true if all values of a are in b.
+func isSubset(a, b map[string]bool) bool {
+	for v := range a {
+		if !b[v] {
+			return false
+		}
+	}
+	return true
+}
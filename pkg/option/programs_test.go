@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package option
+
+import (
+	"github.com/linux-lock/bpflock/api/v1/models"
+
+	. "gopkg.in/check.v1"
+)
+
+func stageNames(stages [][]*models.BpfProgram) [][]string {
+	names := make([][]string, 0, len(stages))
+	for _, stage := range stages {
+		s := make([]string, 0, len(stage))
+		for _, p := range stage {
+			s = append(s, p.Name)
+		}
+		names = append(names, s)
+	}
+	return names
+}
+
+func defaultProgram(name string, after ...string) *models.BpfProgram {
+	p := BpflockBpfProgs[name]
+	p.After = mergeDependencies(p.After, after)
+	return &p
+}
+
+func (s *OptionSuite) TestProgramStages(c *C) {
+	programs := []*models.BpfProgram{
+		defaultProgram("bpfrestrict"),
+		defaultProgram("kmodlock"),
+		defaultProgram("selflock"),
+		defaultProgram("kimglock"),
+		defaultProgram("netlock", "kmodlock"),
+	}
+
+	deps := ProgramDependencies(programs)
+	c.Assert(deps["selflock"], HasLen, 0)
+	c.Assert(deps["netlock"], DeepEquals, []string{"kmodlock", "selflock"})
+	c.Assert(deps["bpfrestrict"], DeepEquals, []string{"kimglock", "kmodlock", "netlock", "selflock"})
+
+	stages, err := ProgramStages(programs)
+	c.Assert(err, IsNil)
+	c.Assert(stageNames(stages), DeepEquals, [][]string{
+		{"selflock"},
+		{"kimglock", "kmodlock"},
+		{"netlock"},
+		{"bpfrestrict"},
+	})
+	c.Assert(validateProgramDependencies(programs), IsNil)
+
+	// Dependencies on programs that are not configured are ignored
+	stages, err = ProgramStages(programs[:2])
+	c.Assert(err, IsNil)
+	c.Assert(stageNames(stages), DeepEquals, [][]string{{"kmodlock"}, {"bpfrestrict"}})
+
+	programs[1].After = append(programs[1].After, "netlock")
+	_, err = ProgramStages(programs)
+	c.Assert(err, ErrorMatches, "dependency cycle between programs kmodlock, netlock, bpfrestrict")
+
+	programs[1].After = []string{"nosuchlock"}
+	c.Assert(validateProgramDependencies(programs), ErrorMatches, ".*dependency 'nosuchlock' not supported")
+}
+
+func (s *OptionSuite) TestLoosenedArgs(c *C) {
+	program := func(args ...string) *models.BpfProgram {
+		return &models.BpfProgram{Name: "selflock", Command: "selflock", Args: args}
+	}
+	prev := program("--profile=restricted", "--block=kill,ptrace", "--allow=ext4,xfs", "--deny=usb_storage")
+
+	c.Assert(LoosenedArgs(prev, prev), HasLen, 0)
+	// Fewer allowed and more denied values, or all operations blocked
+	c.Assert(LoosenedArgs(prev, program("--profile=restricted", "--block=kill,ptrace,unlink",
+		"--allow=ext4", "--deny=usb_storage,firewire")), HasLen, 0)
+	c.Assert(LoosenedArgs(prev, program("--profile=restricted", "--allow=xfs", "--deny=usb_storage")), HasLen, 0)
+
+	c.Assert(LoosenedArgs(prev, program("--profile=restricted", "--block=kill",
+		"--allow=ext4,xfs,vfat", "--deny=usb_storage")), DeepEquals, []string{"allow", "block"})
+	c.Assert(LoosenedArgs(prev, program("--profile=restricted", "--block=kill,ptrace",
+		"--allow=ext4,xfs")), DeepEquals, []string{"deny"})
+	c.Assert(LoosenedArgs(prev, program("--profile=baseline", "--block=kill,ptrace",
+		"--allow=ext4,xfs", "--deny=usb_storage", "--trust=pid")), DeepEquals, []string{"profile", "trust"})
+
+	// Blocking all operations can not be narrowed
+	c.Assert(LoosenedArgs(program("--profile=restricted"), program("--profile=restricted", "--block=kill")),
+		DeepEquals, []string{"block"})
+}
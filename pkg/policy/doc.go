@@ -0,0 +1,7 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package policy signs and verifies the bpf programs configuration files
+// of bpflock with ed25519 keys. Each file carries a detached signature in
+// <file>.sig, or a whole directory is signed as a bundle in bundle.sig.
+package policy
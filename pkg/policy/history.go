@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/lock"
+)
+
+const (
+	// HistoryFile is the index of the applied snapshots inside the
+	// history directory, oldest first
+	HistoryFile = "history.json"
+
+	// snapshotsDir holds the content of snapshots by digest
+	snapshotsDir = "snapshots"
+)
+
+var (
+	// ErrNotFound is returned for an unknown snapshot
+	ErrNotFound = errors.New("no such snapshot")
+
+	// ErrAmbiguous is returned for a prefix of several snapshots
+	ErrAmbiguous = errors.New("ambiguous snapshot id")
+
+	// replaced in tests
+	now = time.Now
+)
+
+// Snapshot is an applied bpf programs configuration.
+type Snapshot struct {
+	// ID is the digest of the configuration
+	ID string `json:"id"`
+
+	// Time is when the configuration was applied
+	Time time.Time `json:"time"`
+
+	// Source is where the configuration came from
+	Source string `json:"source"`
+
+	// Identity is the process that applied the configuration
+	Identity string `json:"identity"`
+}
+
+// Digest returns the content address of meta and its encoding.
+func Digest(meta *models.BpfMeta) (string, []byte, error) {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return "", nil, err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), data, nil
+}
+
+// History stores every applied bpf programs configuration as a content
+// addressed snapshot, and the index of when and by whom they were applied.
+type History struct {
+	mutex lock.Mutex
+	dir   string
+	max   int
+}
+
+// NewHistory returns the history stored in dir that keeps the last max
+// applied snapshots.
+func NewHistory(dir string, max int) *History {
+	return &History{
+		dir: dir,
+		max: max,
+	}
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (h *History) snapshotPath(id string) string {
+	return filepath.Join(h.dir, snapshotsDir, id+".json")
+}
+
+// readIndex must be called with mutex held.
+func (h *History) readIndex() ([]*Snapshot, error) {
+	path := filepath.Join(h.dir, HistoryFile)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []*Snapshot
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Record stores meta and adds it to the history.
+func (h *History) Record(meta *models.BpfMeta, source, identity string) (*Snapshot, error) {
+	id, data, err := Digest(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Join(h.dir, snapshotsDir), 0700); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(h.snapshotPath(id)); os.IsNotExist(err) {
+		if err := writeFileAtomic(h.snapshotPath(id), data); err != nil {
+			return nil, fmt.Errorf("unable to store snapshot: %w", err)
+		}
+	}
+
+	entries, err := h.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{
+		ID:       id,
+		Time:     now(),
+		Source:   source,
+		Identity: identity,
+	}
+	entries = append(entries, s)
+
+	var dropped []*Snapshot
+	if h.max > 0 && len(entries) > h.max {
+		dropped = entries[:len(entries)-h.max]
+		entries = entries[len(entries)-h.max:]
+	}
+
+	index, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(filepath.Join(h.dir, HistoryFile), index); err != nil {
+		return nil, fmt.Errorf("unable to store policy history: %w", err)
+	}
+
+	// Remove the content of snapshots that are not referenced anymore
+	kept := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		kept[e.ID] = true
+	}
+	for _, e := range dropped {
+		if !kept[e.ID] {
+			os.Remove(h.snapshotPath(e.ID))
+		}
+	}
+
+	return s, nil
+}
+
+// List returns the applied snapshots, newest first.
+func (h *History) List() ([]*Snapshot, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	entries, err := h.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*Snapshot, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		list = append(list, entries[i])
+	}
+	return list, nil
+}
+
+// Load returns the last application of the snapshot id and its
+// configuration. id may be a unique prefix of the snapshot digest.
+func (h *History) Load(id string) (*Snapshot, *models.BpfMeta, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	entries, err := h.readIndex()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var found *Snapshot
+	for i := len(entries) - 1; id != "" && i >= 0; i-- {
+		e := entries[i]
+		if !strings.HasPrefix(e.ID, id) {
+			continue
+		}
+		if found == nil {
+			found = e
+		} else if found.ID != e.ID {
+			return nil, nil, fmt.Errorf("%w: %s", ErrAmbiguous, id)
+		}
+	}
+	if found == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	data, err := ioutil.ReadFile(h.snapshotPath(found.ID))
+	if err != nil {
+		return nil, nil, err
+	}
+	// The digest is of the stored content, it must not depend on the
+	// fields known by this version
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != found.ID {
+		return nil, nil, fmt.Errorf("snapshot %s does not match its digest", found.ID)
+	}
+	meta := &models.BpfMeta{}
+	if err := json.Unmarshal(data, meta); err != nil {
+		return nil, nil, fmt.Errorf("unable to parse snapshot %s: %w", found.ID, err)
+	}
+	return found, meta, nil
+}
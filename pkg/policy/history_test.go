@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+)
+
+func testMeta(args ...string) *models.BpfMeta {
+	return &models.BpfMeta{
+		Bpfmetaver:  "v1",
+		Kind:        "bpf",
+		Bpfmetadata: &models.BpfMetadata{Name: "bpflock"},
+		Bpfspec: &models.BpfSpec{
+			Programs: []*models.BpfProgram{
+				{Name: "kmodlock", Command: "kmodlock", Args: args},
+			},
+		},
+	}
+}
+
+func (s *PolicySuite) TestHistory(c *C) {
+	dir := c.MkDir()
+	t := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	defer func() { now = time.Now }()
+	now = func() time.Time {
+		t = t.Add(time.Minute)
+		return t
+	}
+
+	h := NewHistory(dir, 3)
+	list, err := h.List()
+	c.Assert(err, IsNil)
+	c.Assert(list, HasLen, 0)
+
+	baseline := testMeta("--profile=baseline")
+	restricted := testMeta("--profile=restricted")
+	s1, err := h.Record(baseline, "bpf-config-dir:/etc/bpflock/bpf.d", "pid=1 uid=0(root)")
+	c.Assert(err, IsNil)
+	s2, err := h.Record(restricted, "api", "pid=2 uid=0(root)")
+	c.Assert(err, IsNil)
+	c.Assert(s1.ID, Not(Equals), s2.ID)
+
+	// Snapshots are content addressed
+	id, _, err := Digest(testMeta("--profile=baseline"))
+	c.Assert(err, IsNil)
+	c.Assert(s1.ID, Equals, id)
+
+	list, err = h.List()
+	c.Assert(err, IsNil)
+	c.Assert(list, HasLen, 2)
+	c.Assert(list[0].ID, Equals, s2.ID)
+	c.Assert(list[0].Source, Equals, "api")
+	c.Assert(list[1].ID, Equals, s1.ID)
+	c.Assert(list[0].Time.After(list[1].Time), Equals, true)
+
+	// Unique prefixes are accepted
+	found, meta, err := h.Load(s1.ID[:12])
+	c.Assert(err, IsNil)
+	c.Assert(found.ID, Equals, s1.ID)
+	c.Assert(meta.Bpfspec.Programs[0].Args, DeepEquals, []string{"--profile=baseline"})
+
+	_, _, err = h.Load("")
+	c.Assert(errors.Is(err, ErrNotFound), Equals, true)
+	_, _, err = h.Load("zz")
+	c.Assert(errors.Is(err, ErrNotFound), Equals, true)
+
+	// A tampered snapshot is refused
+	path := filepath.Join(dir, snapshotsDir, s2.ID+".json")
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	tampered := []byte(string(data[:len(data)-1]) + `,"kind":"other"}`)
+	c.Assert(ioutil.WriteFile(path, tampered, 0600), IsNil)
+	_, _, err = h.Load(s2.ID)
+	c.Assert(err, NotNil)
+	c.Assert(ioutil.WriteFile(path, data, 0600), IsNil)
+
+	// Only the last entries are kept, with the snapshots they reference
+	_, err = h.Record(baseline, "rollback:"+s1.ID, "pid=3 uid=0(root)")
+	c.Assert(err, IsNil)
+	_, err = h.Record(testMeta("--profile=allow"), "api", "pid=4 uid=0(root)")
+	c.Assert(err, IsNil)
+	_, err = h.Record(restricted, "api", "pid=5 uid=0(root)")
+	c.Assert(err, IsNil)
+	list, err = h.List()
+	c.Assert(err, IsNil)
+	c.Assert(list, HasLen, 3)
+	c.Assert(list[2].Source, Equals, "rollback:"+s1.ID)
+
+	_, err = h.Record(testMeta("--profile=privileged"), "api", "pid=6 uid=0(root)")
+	c.Assert(err, IsNil)
+	_, _, err = h.Load(s1.ID)
+	c.Assert(errors.Is(err, ErrNotFound), Equals, true)
+	files, err := ioutil.ReadDir(filepath.Join(dir, snapshotsDir))
+	c.Assert(err, IsNil)
+	c.Assert(files, HasLen, 3)
+}
+
+func (s *PolicySuite) TestHistoryOlderSnapshot(c *C) {
+	dir := c.MkDir()
+	h := NewHistory(dir, 3)
+	_, err := h.Record(testMeta("--profile=baseline"), "api", "pid=1 uid=0(root)")
+	c.Assert(err, IsNil)
+
+	// A snapshot stored by an older version, without the fields added
+	// since, is still loadable
+	data := []byte(`{"bpfmetaver":"v1","kind":"bpf","bpfmetadata":{"name":"bpflock"},` +
+		`"bpfspec":{"programs":[{"name":"kmodlock","command":"kmodlock","args":["--profile=restricted"]}]}}`)
+	sum := sha256.Sum256(data)
+	id := hex.EncodeToString(sum[:])
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, snapshotsDir, id+".json"), data, 0600), IsNil)
+	index, err := ioutil.ReadFile(filepath.Join(dir, HistoryFile))
+	c.Assert(err, IsNil)
+	entry := `{"id":"` + id + `","time":"2022-03-01T10:00:00Z","source":"api","identity":"pid=2 uid=0(root)"}`
+	index = []byte(strings.Replace(string(index), "[", "["+entry+",", 1))
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, HistoryFile), index, 0600), IsNil)
+
+	found, meta, err := h.Load(id)
+	c.Assert(err, IsNil)
+	c.Assert(found.ID, Equals, id)
+	c.Assert(meta.Bpfspec.Programs[0].Args, DeepEquals, []string{"--profile=restricted"})
+}
@@ -0,0 +1,278 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package policy
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+)
+
+const (
+	subsystem = "policy"
+
+	// SignatureExt is the extension of detached signature files
+	SignatureExt = ".sig"
+
+	// PublicKeyExt is the extension of public key files
+	PublicKeyExt = ".pub"
+
+	// BundleSignature is the file that signs all the configuration files
+	// of a directory at once
+	BundleSignature = "bundle" + SignatureExt
+)
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+	// ErrUnsigned is returned when a file has no valid signature of a
+	// trusted key
+	ErrUnsigned = errors.New("no valid signature from a trusted key")
+)
+
+// IsSignature returns true if name is a signature file.
+func IsSignature(name string) bool {
+	return strings.HasSuffix(name, SignatureExt)
+}
+
+// KeySet is a set of trusted ed25519 public keys.
+type KeySet []ed25519.PublicKey
+
+// GenerateKey generates a signing key and stores it in path, hex encoded
+// like the node key of the event log, and its public key in path.pub.
+func GenerateKey(path string) (ed25519.PublicKey, error) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, []byte(hex.EncodeToString(key.Seed())+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("unable to store signing key: %w", err)
+	}
+	if err := ioutil.WriteFile(path+PublicKeyExt, []byte(hex.EncodeToString(pub)+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("unable to store public key: %w", err)
+	}
+	return pub, nil
+}
+
+// LoadPrivateKey reads the hex encoded signing key of path.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid signing key %s", path)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// LoadKeys reads the hex encoded public keys of path, either a file or a
+// directory of *.pub files. Files hold one key per line, empty lines and
+// lines starting with '#' are ignored.
+func LoadKeys(path string) (KeySet, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if fi.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*"+PublicKeyExt))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var keys KeySet
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			pub, err := hex.DecodeString(line)
+			if err != nil || len(pub) != ed25519.PublicKeySize {
+				return nil, fmt.Errorf("invalid public key in %s", f)
+			}
+			keys = append(keys, ed25519.PublicKey(pub))
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public key found in %s", path)
+	}
+	return keys, nil
+}
+
+// Verify returns true if sig is a valid signature of data by one of the
+// keys.
+func (k KeySet) Verify(data, sig []byte) bool {
+	if len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	for _, key := range k {
+		if ed25519.Verify(key, data, sig) {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeSignature(sig []byte) []byte {
+	return []byte(hex.EncodeToString(sig) + "\n")
+}
+
+func readSignature(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature %s: %w", path, err)
+	}
+	return sig, nil
+}
+
+// Manifest returns the content signed by a bundle signature: the sorted
+// names of files inside dir and the sha256 of their content, one per line
+// in the format of sha256sum. Adding, removing or renaming a file
+// invalidates the bundle.
+func Manifest(dir string, files []string) ([]byte, error) {
+	contents, err := readFiles(dir, files)
+	if err != nil {
+		return nil, err
+	}
+	return manifestOf(contents), nil
+}
+
+// manifestOf returns the manifest of the contents of files by name.
+func manifestOf(contents map[string][]byte) []byte {
+	names := make([]string, 0, len(contents))
+	for name := range contents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		sum := sha256.Sum256(contents[name])
+		fmt.Fprintf(&buf, "%s  %s\n", hex.EncodeToString(sum[:]), name)
+	}
+	return buf.Bytes()
+}
+
+// readFiles returns the contents of files inside dir by name.
+func readFiles(dir string, files []string) (map[string][]byte, error) {
+	contents := make(map[string][]byte, len(files))
+	for _, name := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		contents[name] = data
+	}
+	return contents, nil
+}
+
+// SignFile writes the detached signature of the file path to path.sig.
+func SignFile(key ed25519.PrivateKey, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path+SignatureExt, encodeSignature(ed25519.Sign(key, data)), 0644)
+}
+
+// SignBundle writes to dir/bundle.sig the signature of the manifest of
+// files inside dir.
+func SignBundle(key ed25519.PrivateKey, dir string, files []string) error {
+	manifest, err := Manifest(dir, files)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, BundleSignature), encodeSignature(ed25519.Sign(key, manifest)), 0644)
+}
+
+// VerifyDir checks that files inside dir are signed by a trusted key. A
+// valid bundle signature covers all of them, otherwise each file must have
+// a valid detached signature. It returns the names of rejected files.
+func VerifyDir(keys KeySet, dir string, files []string) ([]string, error) {
+	contents, err := readFiles(dir, files)
+	if err != nil {
+		return nil, err
+	}
+	return VerifyContents(keys, dir, contents)
+}
+
+// VerifyContents checks like VerifyDir that the contents of files inside
+// dir, by name, are signed by a trusted key. The files are not read again,
+// the verified contents are those that are used.
+func VerifyContents(keys KeySet, dir string, contents map[string][]byte) ([]string, error) {
+	bundle := filepath.Join(dir, BundleSignature)
+	sig, err := readSignature(bundle)
+	switch {
+	case err == nil:
+		if keys.Verify(manifestOf(contents), sig) {
+			return nil, nil
+		}
+		log.WithField(logfields.Path, bundle).Warn("Bundle signature does not match the configuration files, checking detached signatures")
+	case !os.IsNotExist(err):
+		log.WithError(err).WithField(logfields.Path, bundle).Warn("Unable to read bundle signature, checking detached signatures")
+	}
+
+	var rejected []string
+	for name, data := range contents {
+		path := filepath.Join(dir, name)
+		if err := VerifyData(keys, path, data); err != nil {
+			if !errors.Is(err, ErrUnsigned) {
+				return nil, err
+			}
+			rejected = append(rejected, name)
+		}
+	}
+	sort.Strings(rejected)
+	return rejected, nil
+}
+
+// VerifyFile checks that the file path has a valid detached signature of a
+// trusted key, it returns ErrUnsigned if not.
+func VerifyFile(keys KeySet, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return VerifyData(keys, path, data)
+}
+
+// VerifyData checks that data, the content of the file path, has a valid
+// detached signature of a trusted key, it returns ErrUnsigned if not.
+func VerifyData(keys KeySet, path string, data []byte) error {
+	sig, err := readSignature(path + SignatureExt)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s: %w", path, ErrUnsigned)
+		}
+		return fmt.Errorf("%s: %w: %v", path, ErrUnsigned, err)
+	}
+	if !keys.Verify(data, sig) {
+		return fmt.Errorf("%s: %w", path, ErrUnsigned)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package policy
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type PolicySuite struct{}
+
+var _ = Suite(&PolicySuite{})
+
+// writeKeyPair generates a key pair inside dir and returns the paths of
+// the signing and public keys.
+func writeKeyPair(c *C, dir, name string) (string, string) {
+	path := filepath.Join(dir, name)
+	_, err := GenerateKey(path)
+	c.Assert(err, IsNil)
+	return path, path + PublicKeyExt
+}
+
+func writeConfigs(c *C, dir string) []string {
+	files := []string{"baseline.yaml", "kmodlock.yaml"}
+	for _, f := range files {
+		c.Assert(ioutil.WriteFile(filepath.Join(dir, f), []byte("bpfmetaver: v1\n# "+f+"\n"), 0644), IsNil)
+	}
+	return files
+}
+
+func (s *PolicySuite) TestLoadKeys(c *C) {
+	keysDir := c.MkDir()
+	_, pub1 := writeKeyPair(c, keysDir, "ci")
+	writeKeyPair(c, c.MkDir(), "other")
+
+	keys, err := LoadKeys(pub1)
+	c.Assert(err, IsNil)
+	c.Assert(keys, HasLen, 1)
+
+	writeKeyPair(c, keysDir, "release")
+	keys, err = LoadKeys(keysDir)
+	c.Assert(err, IsNil)
+	// Only public keys are trusted
+	c.Assert(keys, HasLen, 2)
+
+	c.Assert(ioutil.WriteFile(filepath.Join(keysDir, "bad.pub"), []byte("# comment\nzz\n"), 0644), IsNil)
+	_, err = LoadKeys(keysDir)
+	c.Assert(err, NotNil)
+
+	_, err = LoadKeys(c.MkDir())
+	c.Assert(err, NotNil)
+
+	_, err = LoadPrivateKey(filepath.Join(keysDir, "bad.pub"))
+	c.Assert(err, NotNil)
+}
+
+func (s *PolicySuite) TestDetachedSignatures(c *C) {
+	keysDir := c.MkDir()
+	priv, pub := writeKeyPair(c, keysDir, "ci")
+	untrusted, _ := writeKeyPair(c, keysDir, "untrusted")
+	keys, err := LoadKeys(pub)
+	c.Assert(err, IsNil)
+
+	dir := c.MkDir()
+	files := writeConfigs(c, dir)
+
+	rejected, err := VerifyDir(keys, dir, files)
+	c.Assert(err, IsNil)
+	c.Assert(rejected, DeepEquals, files)
+
+	key, err := LoadPrivateKey(priv)
+	c.Assert(err, IsNil)
+	for _, f := range files {
+		c.Assert(SignFile(key, filepath.Join(dir, f)), IsNil)
+	}
+	rejected, err = VerifyDir(keys, dir, files)
+	c.Assert(err, IsNil)
+	c.Assert(rejected, HasLen, 0)
+
+	// Contents that differ from the signed files
+	contents, err := readFiles(dir, files)
+	c.Assert(err, IsNil)
+	contents["kmodlock.yaml"] = []byte("bpfmetaver: v1\n")
+	rejected, err = VerifyContents(keys, dir, contents)
+	c.Assert(err, IsNil)
+	c.Assert(rejected, DeepEquals, []string{"kmodlock.yaml"})
+
+	// Modified file
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "kmodlock.yaml"), []byte("bpfmetaver: v1\n"), 0644), IsNil)
+	rejected, err = VerifyDir(keys, dir, files)
+	c.Assert(err, IsNil)
+	c.Assert(rejected, DeepEquals, []string{"kmodlock.yaml"})
+
+	// Signed by an untrusted key
+	other, err := LoadPrivateKey(untrusted)
+	c.Assert(err, IsNil)
+	c.Assert(SignFile(other, filepath.Join(dir, "kmodlock.yaml")), IsNil)
+	err = VerifyFile(keys, filepath.Join(dir, "kmodlock.yaml"))
+	c.Assert(errors.Is(err, ErrUnsigned), Equals, true)
+
+	// Corrupted signature
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "baseline.yaml.sig"), []byte("!!"), 0644), IsNil)
+	err = VerifyFile(keys, filepath.Join(dir, "baseline.yaml"))
+	c.Assert(errors.Is(err, ErrUnsigned), Equals, true)
+}
+
+func (s *PolicySuite) TestBundleSignature(c *C) {
+	priv, pub := writeKeyPair(c, c.MkDir(), "ci")
+	keys, err := LoadKeys(pub)
+	c.Assert(err, IsNil)
+	key, err := LoadPrivateKey(priv)
+	c.Assert(err, IsNil)
+
+	dir := c.MkDir()
+	files := writeConfigs(c, dir)
+	c.Assert(SignBundle(key, dir, files), IsNil)
+
+	rejected, err := VerifyDir(keys, dir, files)
+	c.Assert(err, IsNil)
+	c.Assert(rejected, HasLen, 0)
+
+	// The manifest does not depend on the order of files
+	rejected, err = VerifyDir(keys, dir, []string{files[1], files[0]})
+	c.Assert(err, IsNil)
+	c.Assert(rejected, HasLen, 0)
+
+	// A removed file invalidates the bundle
+	rejected, err = VerifyDir(keys, dir, files[:1])
+	c.Assert(err, IsNil)
+	c.Assert(rejected, DeepEquals, files[:1])
+
+	// An added file invalidates the bundle, unless all files carry a
+	// detached signature
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "netlock.yaml"), []byte("bpfmetaver: v1\n"), 0644), IsNil)
+	all := append(files, "netlock.yaml")
+	rejected, err = VerifyDir(keys, dir, all)
+	c.Assert(err, IsNil)
+	c.Assert(rejected, DeepEquals, []string{"baseline.yaml", "kmodlock.yaml", "netlock.yaml"})
+
+	for _, f := range all {
+		c.Assert(SignFile(key, filepath.Join(dir, f)), IsNil)
+	}
+	rejected, err = VerifyDir(keys, dir, all)
+	c.Assert(err, IsNil)
+	c.Assert(rejected, HasLen, 0)
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package linux
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+)
+
+const (
+	// VmlinuxBTF is the path of the kernel BTF
+	VmlinuxBTF = "/sys/kernel/btf/vmlinux"
+
+	btfMagic = 0xeB9F
+
+	btfKindInt       = 1
+	btfKindArray     = 3
+	btfKindStruct    = 4
+	btfKindUnion     = 5
+	btfKindEnum      = 6
+	btfKindFunc      = 12
+	btfKindFuncProto = 13
+	btfKindVar       = 14
+	btfKindDatasec   = 15
+	btfKindDeclTag   = 17
+	btfKindEnum64    = 19
+)
+
+type btfHeader struct {
+	Magic   uint16
+	Version uint8
+	Flags   uint8
+	HdrLen  uint32
+	TypeOff uint32
+	TypeLen uint32
+	StrOff  uint32
+	StrLen  uint32
+}
+
+type btfType struct {
+	NameOff    uint32
+	Info       uint32
+	SizeOrType uint32
+}
+
+// BTFNames are the names of functions and structs of the kernel BTF.
+type BTFNames struct {
+	Funcs   map[string]struct{}
+	Structs map[string]struct{}
+}
+
+// HasFunc returns true if the kernel has the function name.
+func (n *BTFNames) HasFunc(name string) bool {
+	_, ok := n.Funcs[name]
+	return ok
+}
+
+// HasStruct returns true if the kernel has the struct name.
+func (n *BTFNames) HasStruct(name string) bool {
+	_, ok := n.Structs[name]
+	return ok
+}
+
+// ReadBTFNames reads the names of functions and structs from the BTF file
+// at path, usually VmlinuxBTF.
+func ReadBTFNames(path string) (*BTFNames, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseBTFNames(data)
+}
+
+func parseBTFNames(data []byte) (*BTFNames, error) {
+	var order binary.ByteOrder = binary.LittleEndian
+	if len(data) < 2 {
+		return nil, fmt.Errorf("invalid BTF: too short")
+	}
+	if binary.BigEndian.Uint16(data) == btfMagic {
+		order = binary.BigEndian
+	}
+
+	var hdr btfHeader
+	if err := binary.Read(bytes.NewReader(data), order, &hdr); err != nil {
+		return nil, fmt.Errorf("invalid BTF header: %w", err)
+	}
+	if hdr.Magic != btfMagic {
+		return nil, fmt.Errorf("invalid BTF magic %#x", hdr.Magic)
+	}
+
+	typeStart := uint64(hdr.HdrLen) + uint64(hdr.TypeOff)
+	strStart := uint64(hdr.HdrLen) + uint64(hdr.StrOff)
+	if typeStart+uint64(hdr.TypeLen) > uint64(len(data)) || strStart+uint64(hdr.StrLen) > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid BTF: sections out of bounds")
+	}
+	types := data[typeStart : typeStart+uint64(hdr.TypeLen)]
+	strs := data[strStart : strStart+uint64(hdr.StrLen)]
+
+	name := func(off uint32) string {
+		if uint64(off) >= uint64(len(strs)) {
+			return ""
+		}
+		s := strs[off:]
+		if i := bytes.IndexByte(s, 0); i >= 0 {
+			s = s[:i]
+		}
+		return string(s)
+	}
+
+	names := &BTFNames{
+		Funcs:   make(map[string]struct{}),
+		Structs: make(map[string]struct{}),
+	}
+
+	for off := 0; off < len(types); {
+		if off+12 > len(types) {
+			return nil, fmt.Errorf("invalid BTF: truncated type at offset %d", off)
+		}
+		t := btfType{
+			NameOff:    order.Uint32(types[off:]),
+			Info:       order.Uint32(types[off+4:]),
+			SizeOrType: order.Uint32(types[off+8:]),
+		}
+		off += 12
+
+		kind := (t.Info >> 24) & 0x1f
+		vlen := int(t.Info & 0xffff)
+		switch kind {
+		case btfKindInt, btfKindVar, btfKindDeclTag:
+			off += 4
+		case btfKindArray:
+			off += 12
+		case btfKindStruct, btfKindUnion:
+			if kind == btfKindStruct {
+				names.Structs[name(t.NameOff)] = struct{}{}
+			}
+			off += vlen * 12
+		case btfKindEnum, btfKindFuncProto:
+			off += vlen * 8
+		case btfKindDatasec, btfKindEnum64:
+			off += vlen * 12
+		case btfKindFunc:
+			names.Funcs[name(t.NameOff)] = struct{}{}
+		}
+	}
+
+	return names, nil
+}
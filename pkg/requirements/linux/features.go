@@ -5,10 +5,7 @@
 package linux
 
 import (
-	"fmt"
-	"io/ioutil"
 	"os"
-	"strings"
 
 	"github.com/linux-lock/bpflock/pkg/defaults"
 	"github.com/linux-lock/bpflock/pkg/logging"
@@ -19,42 +16,43 @@ import (
 )
 
 const (
-	minKernelVer  = "5.15.0"
-	lsmConfigFile = "/sys/kernel/security/lsm"
-)
+	// MinKernelVersion is the minimal tested kernel version
+	MinKernelVersion = "5.15.0"
 
-var (
-	isMinKernelVer = versioncheck.MustCompile(">=" + minKernelVer)
-	log            = logging.DefaultLogger.WithField(logfields.LogSubsys, "linux-bpf")
+	// LsmConfigFile is the list of active LSMs
+	LsmConfigFile = "/sys/kernel/security/lsm"
 )
 
-// CheckBpfLsmConfig() check that the kernel was compiled with:
-// CONFIG_LSM="...,bpf" and CONFIG_BPF_LSM=y
-func checkBpfLsmConfig() {
-	b, err := ioutil.ReadFile(lsmConfigFile)
-	if err != nil {
-		log.WithError(err).Fatalf("reading lsm kernel config '%s'", lsmConfigFile)
-	}
+var (
+	// IsMinKernelVersion returns true if the kernel version is at least
+	// MinKernelVersion
+	IsMinKernelVersion = versioncheck.MustCompile(">=" + MinKernelVersion)
 
-	if strings.Contains(string(b), "bpf") == false {
-		err = fmt.Errorf("LSM BPF is not supported in this kernel")
-		log.WithError(err).Fatalf("must have a kernel with 'CONFIG_BPF_LSM=y' 'CONFIG_LSM=\"...,bpf\"'")
-	}
-}
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, "linux-bpf")
+)
 
 // CheckMinRequirements checks that minimum kernel requirements are met for
-// using some BPF LSM features
+// using some BPF LSM features. Bpf programs that the kernel can not run
+// are disabled, the others keep running.
 func CheckMinRequirements() {
 	kernelVersion, err := version.GetKernelVersion()
 	if err != nil {
-		log.WithError(err).Fatal("kernel version: NOT OK")
+		log.WithError(err).Warn("kernel version: unknown")
+	} else if !IsMinKernelVersion(kernelVersion) {
+		log.Warnf("kernel version: minimal tested kernel version is %s; kernel version "+
+			"that is running is: %s, probing kernel features", MinKernelVersion, kernelVersion)
 	}
-	if !isMinKernelVer(kernelVersion) {
-		log.Fatalf("kernel version: NOT OK: minimal supported kernel "+
-			"version is %s; kernel version that is running is: %s", minKernelVer, kernelVersion)
+
+	features := ProbeFeatures()
+	if !features.LsmBpf {
+		log.WithError(features.LsmErr).Warn("LSM BPF is not supported in this kernel, must have a kernel " +
+			"with 'CONFIG_BPF_LSM=y' 'CONFIG_LSM=\"...,bpf\"'")
 	}
 
-	checkBpfLsmConfig()
+	option.Config.DisabledBpfProgs = ProbePrograms(option.Config.BpfMeta.Bpfspec.Programs, features)
+	for name, reason := range option.Config.DisabledBpfProgs {
+		log.Warnf("bpf program %s disabled: %s", name, reason)
+	}
 
 	globalsDir := option.Config.GetGlobalsDir()
 	if err := os.MkdirAll(globalsDir, defaults.StateDirRights); err != nil {
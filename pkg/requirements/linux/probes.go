@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package linux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"strings"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/components"
+)
+
+// Requirements are the kernel features needed by a bpf program.
+type Requirements struct {
+	// LsmHooks are the BPF LSM hooks the program attaches to
+	LsmHooks []string
+
+	// Functions are the kernel functions the program traces with fentry or
+	// fexit
+	Functions []string
+
+	// Structs are the kernel structs the program reads
+	Structs []string
+
+	// Ringbuf is set if the program uses a ring buffer map
+	Ringbuf bool
+
+	// SpinLock is set if the program uses bpf_spin_lock
+	SpinLock bool
+}
+
+// syscallPrefix is the arch prefix of the syscall wrappers that fmod_ret
+// programs attach to.
+var syscallPrefix = map[string]string{
+	"amd64": "__x64_",
+	"arm64": "__arm64_",
+}[runtime.GOARCH]
+
+// ProgramRequirements declares the kernel requirements of each bpf program,
+// they must be kept in sync with the bpf programs in bpf/.
+var ProgramRequirements = map[string]Requirements{
+	components.SelfLock: {
+		LsmHooks: []string{"task_kill", "ptrace_access_check", "inode_unlink", "inode_rmdir", "inode_rename"},
+		Structs:  []string{"task_struct", "inode", "dentry", "kernel_siginfo"},
+	},
+	components.UsbLock: {
+		Functions: []string{"usb_new_device"},
+		Structs:   []string{"task_struct", "usb_device", "usb_device_descriptor", "usb_host_config", "usb_interface_cache"},
+	},
+	components.FsLock: {
+		LsmHooks: []string{"file_open"},
+		Structs:  []string{"task_struct", "file", "inode", "super_block"},
+	},
+	components.ExecLock: {
+		LsmHooks: []string{"bprm_check_security"},
+		Structs:  []string{"task_struct", "linux_binprm", "mm_struct", "file", "inode", "dentry"},
+	},
+	components.RootfsLock: {
+		LsmHooks: []string{"file_open", "inode_create", "inode_mkdir", "inode_unlink", "inode_rmdir", "inode_rename"},
+		Structs:  []string{"task_struct", "file", "dentry", "inode", "super_block"},
+	},
+	components.KimgLock: {
+		LsmHooks: []string{"locked_down", "bpf"},
+		Structs:  []string{"task_struct", "mm_struct", "file", "inode", "super_block"},
+	},
+	components.KmodLock: {
+		LsmHooks: []string{"sb_free_security", "locked_down", "kernel_module_request", "kernel_read_file", "kernel_load_data"},
+		Structs:  []string{"task_struct", "file", "super_block", "vfsmount"},
+		SpinLock: true,
+	},
+	components.NsLock: {
+		LsmHooks:  []string{"task_alloc"},
+		Functions: []string{syscallPrefix + "sys_unshare", syscallPrefix + "sys_setns"},
+		Structs: []string{"task_struct", "pt_regs", "files_struct", "fdtable", "file",
+			"inode", "super_block", "ns_common", "proc_ns_operations"},
+	},
+	components.NetLock: {
+		LsmHooks: []string{"socket_create"},
+		Structs:  []string{"task_struct"},
+	},
+	components.BpfRestrict: {
+		LsmHooks: []string{"bpf", "locked_down"},
+		Structs:  []string{"task_struct"},
+		Ringbuf:  true,
+	},
+}
+
+// Features are the probed kernel features.
+type Features struct {
+	// LsmBpf is set if "bpf" is in the list of active LSMs
+	LsmBpf bool
+	LsmErr error
+
+	// BTF are the names of the kernel BTF, nil if not available
+	BTF    *BTFNames
+	BTFErr error
+}
+
+// ProbeFeatures probes the kernel features that bpf programs require.
+func ProbeFeatures() *Features {
+	f := &Features{}
+
+	b, err := ioutil.ReadFile(LsmConfigFile)
+	if err != nil {
+		f.LsmErr = err
+	} else {
+		f.LsmBpf = HasBpfLsm(string(b))
+	}
+
+	f.BTF, f.BTFErr = ReadBTFNames(VmlinuxBTF)
+
+	return f
+}
+
+// HasBpfLsm returns true if "bpf" is in the comma separated list of LSMs.
+func HasBpfLsm(lsms string) bool {
+	for _, l := range strings.Split(strings.TrimSpace(lsms), ",") {
+		if l == "bpf" {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing returns the requirements r that the kernel does not support.
+func (f *Features) Missing(r Requirements) []string {
+	if len(r.LsmHooks) > 0 && !f.LsmBpf {
+		if f.LsmErr != nil {
+			return []string{fmt.Sprintf("BPF LSM: %v", f.LsmErr)}
+		}
+		return []string{"BPF LSM is not enabled"}
+	}
+	if f.BTF == nil {
+		return []string{fmt.Sprintf("kernel BTF: %v", f.BTFErr)}
+	}
+
+	var missing []string
+	for _, h := range r.LsmHooks {
+		if !f.BTF.HasFunc("bpf_lsm_" + h) {
+			missing = append(missing, fmt.Sprintf("LSM hook %s", h))
+		}
+	}
+	for _, fn := range r.Functions {
+		if !f.BTF.HasFunc(fn) {
+			missing = append(missing, fmt.Sprintf("function %s", fn))
+		}
+	}
+	for _, s := range r.Structs {
+		if !f.BTF.HasStruct(s) {
+			missing = append(missing, fmt.Sprintf("struct %s", s))
+		}
+	}
+	if r.Ringbuf && !f.BTF.HasStruct("bpf_ringbuf") {
+		missing = append(missing, "ring buffer")
+	}
+	if r.SpinLock && !f.BTF.HasStruct("bpf_spin_lock") {
+		missing = append(missing, "bpf_spin_lock")
+	}
+	return missing
+}
+
+// ProbePrograms returns the programs that the kernel can not run, mapped
+// to the reason.
+func ProbePrograms(programs []*models.BpfProgram, f *Features) map[string]string {
+	disabled := make(map[string]string)
+	for _, p := range programs {
+		r, ok := ProgramRequirements[p.Name]
+		if !ok {
+			continue
+		}
+		if missing := f.Missing(r); len(missing) > 0 {
+			disabled[p.Name] = "kernel does not support: " + strings.Join(missing, ", ")
+		}
+	}
+	return disabled
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package linux
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type ProbesSuite struct{}
+
+var _ = Suite(&ProbesSuite{})
+
+// buildBTF returns a BTF blob with a struct and a func per name.
+func buildBTF(structs, funcs []string) []byte {
+	strs := []byte{0}
+	types := new(bytes.Buffer)
+	addName := func(n string) uint32 {
+		off := uint32(len(strs))
+		strs = append(strs, append([]byte(n), 0)...)
+		return off
+	}
+	w := func(v ...uint32) {
+		for _, x := range v {
+			binary.Write(types, binary.LittleEndian, x)
+		}
+	}
+
+	// int, then one struct with a member per struct name, enum and func proto
+	w(addName("int"), btfKindInt<<24, 4, 32)
+	for _, s := range structs {
+		w(addName(s), btfKindStruct<<24|1, 8)
+		w(addName("member"), 1, 0)
+	}
+	w(0, btfKindEnum<<24|2, 4, addName("A"), 0, addName("B"), 1)
+	w(0, btfKindFuncProto<<24, 1)
+	proto := uint32(2 + len(structs) + 1)
+	for _, f := range funcs {
+		w(addName(f), btfKindFunc<<24, proto)
+	}
+
+	hdr := btfHeader{
+		Magic:   btfMagic,
+		Version: 1,
+		HdrLen:  24,
+		TypeOff: 0,
+		TypeLen: uint32(types.Len()),
+		StrOff:  uint32(types.Len()),
+		StrLen:  uint32(len(strs)),
+	}
+	out := new(bytes.Buffer)
+	binary.Write(out, binary.LittleEndian, hdr)
+	out.Write(types.Bytes())
+	out.Write(strs)
+	return out.Bytes()
+}
+
+func (s *ProbesSuite) TestParseBTFNames(c *C) {
+	data := buildBTF([]string{"task_struct", "bpf_spin_lock"}, []string{"bpf_lsm_bpf", "bpf_lsm_locked_down"})
+	names, err := parseBTFNames(data)
+	c.Assert(err, IsNil)
+	c.Assert(names.HasStruct("task_struct"), Equals, true)
+	c.Assert(names.HasStruct("bpf_spin_lock"), Equals, true)
+	c.Assert(names.HasStruct("inode"), Equals, false)
+	c.Assert(names.HasFunc("bpf_lsm_bpf"), Equals, true)
+	c.Assert(names.HasFunc("bpf_lsm_task_kill"), Equals, false)
+
+	_, err = parseBTFNames(data[:10])
+	c.Assert(err, NotNil)
+	_, err = parseBTFNames([]byte{1, 2, 3, 4})
+	c.Assert(err, NotNil)
+}
+
+func (s *ProbesSuite) TestHasBpfLsm(c *C) {
+	c.Assert(HasBpfLsm("lockdown,capability,yama,bpf\n"), Equals, true)
+	c.Assert(HasBpfLsm("lockdown,capability,bpfilter"), Equals, false)
+	c.Assert(HasBpfLsm(""), Equals, false)
+}
+
+func (s *ProbesSuite) TestProbePrograms(c *C) {
+	names, err := parseBTFNames(buildBTF(
+		[]string{"task_struct", "bpf_ringbuf"},
+		[]string{"bpf_lsm_bpf", "bpf_lsm_locked_down"}))
+	c.Assert(err, IsNil)
+
+	programs := []*models.BpfProgram{{Name: "bpfrestrict"}, {Name: "kmodlock"}, {Name: "unknown"}}
+
+	f := &Features{LsmBpf: true, BTF: names}
+	disabled := ProbePrograms(programs, f)
+	c.Assert(disabled, HasLen, 1)
+	c.Assert(disabled["kmodlock"], Matches, "kernel does not support: LSM hook sb_free_security, .*bpf_spin_lock")
+
+	f = &Features{LsmBpf: true, BTFErr: errors.New("no such file")}
+	disabled = ProbePrograms(programs, f)
+	c.Assert(disabled, HasLen, 2)
+	c.Assert(disabled["bpfrestrict"], Equals, "kernel does not support: kernel BTF: no such file")
+
+	f = &Features{LsmBpf: false, BTF: names}
+	disabled = ProbePrograms(programs, f)
+	c.Assert(disabled["bpfrestrict"], Equals, "kernel does not support: BPF LSM is not enabled")
+}
+
+func (s *ProbesSuite) TestProbeFentryPrograms(c *C) {
+	names, err := parseBTFNames(buildBTF(
+		[]string{"task_struct", "usb_device", "usb_device_descriptor", "usb_host_config", "usb_interface_cache"},
+		[]string{"usb_new_device"}))
+	c.Assert(err, IsNil)
+
+	programs := []*models.BpfProgram{{Name: "usblock"}}
+
+	// fentry programs do not need the BPF LSM
+	f := &Features{LsmBpf: false, BTF: names}
+	c.Assert(ProbePrograms(programs, f), HasLen, 0)
+
+	names, err = parseBTFNames(buildBTF([]string{"task_struct"}, nil))
+	c.Assert(err, IsNil)
+	f = &Features{LsmBpf: true, BTF: names}
+	c.Assert(ProbePrograms(programs, f)["usblock"], Equals,
+		"kernel does not support: function usb_new_device, struct usb_device, struct usb_device_descriptor, struct usb_host_config, struct usb_interface_cache")
+}
+
+func (s *ProbesSuite) TestProbeSyscallPrograms(c *C) {
+	if syscallPrefix == "" {
+		c.Skip("no syscall wrappers on this architecture")
+	}
+
+	names, err := parseBTFNames(buildBTF(
+		[]string{"task_struct", "pt_regs", "files_struct", "fdtable", "file",
+			"inode", "super_block", "ns_common", "proc_ns_operations"},
+		[]string{"bpf_lsm_task_alloc", syscallPrefix + "sys_unshare"}))
+	c.Assert(err, IsNil)
+
+	programs := []*models.BpfProgram{{Name: "nslock"}}
+	f := &Features{LsmBpf: true, BTF: names}
+	c.Assert(ProbePrograms(programs, f)["nslock"], Equals,
+		"kernel does not support: function "+syscallPrefix+"sys_setns")
+}
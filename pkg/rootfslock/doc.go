@@ -0,0 +1,8 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package rootfslock resolves the directories where writes are allowed by
+// the rootfslock configuration into inodes, and keeps them and the root
+// filesystem device up to date in the pinned maps of the rootfslock bpf
+// program.
+package rootfslock
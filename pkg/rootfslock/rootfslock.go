@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package rootfslock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	subsystem = "rootfslock"
+
+	// OptionsMap is the name of the pinned map of rootfslock options
+	OptionsMap = "rootfslock_map"
+
+	// AllowMap is the name of the pinned map of allowed directories
+	AllowMap = "rootfslock_allow_map"
+
+	// Keys of OptionsMap, they must match bpf/rootfslock.h
+	keyRootDev uint32 = 2
+	keyReady   uint32 = 3
+
+	// maxAllow must match BPFLOCK_RF_MAX_ALLOW of bpf/rootfslock.h
+	maxAllow = 256
+)
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+	// RootDir is the root filesystem that is protected
+	RootDir = "/"
+)
+
+// Inode identifies an allowed directory.
+type Inode = bpf.Stat
+
+// ParseArgs returns the allowed directories of the rootfslock program
+// arguments.
+func ParseArgs(args []string) []string {
+	var paths []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--allow=") {
+			continue
+		}
+		for _, p := range strings.Split(strings.TrimPrefix(arg, "--allow="), ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths
+}
+
+// ResolveInodes returns the inodes of the directories of paths, following
+// symlinks. Paths must be absolute, those that do not exist are skipped.
+func ResolveInodes(paths []string) (map[Inode]struct{}, error) {
+	inodes := make(map[Inode]struct{})
+	for _, p := range paths {
+		if !filepath.IsAbs(p) {
+			return nil, fmt.Errorf("allowed directory '%s' is not an absolute path", p)
+		}
+		var st syscall.Stat_t
+		if err := syscall.Stat(p, &st); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if st.Mode&syscall.S_IFMT != syscall.S_IFDIR {
+			return nil, fmt.Errorf("allowed directory '%s' is not a directory", p)
+		}
+		inodes[bpf.NewStat(&st)] = struct{}{}
+	}
+	if len(inodes) > maxAllow {
+		return nil, fmt.Errorf("too many allowed directories: %d, maximum is %d", len(inodes), maxAllow)
+	}
+	return inodes, nil
+}
+
+// RootDev returns the kernel encoded device number of the root filesystem.
+func RootDev() (uint32, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(RootDir, &st); err != nil {
+		return 0, err
+	}
+	return uint32(bpf.KernelDev(uint64(st.Dev))), nil
+}
+
+// Locker updates the pinned maps of the rootfslock program.
+type Locker struct {
+	// pinDir is the directory of rootfslock pins
+	pinDir string
+}
+
+// NewLocker returns a locker of the rootfslock program pinned inside
+// pinPrefix, usually bpf.MapPrefixPath().
+func NewLocker(pinPrefix string) *Locker {
+	return &Locker{
+		pinDir: filepath.Join(pinPrefix, components.RootfsLock),
+	}
+}
+
+// Loaded returns true if the rootfslock program is pinned.
+func (l *Locker) Loaded() bool {
+	_, err := os.Stat(filepath.Join(l.pinDir, AllowMap))
+	return err == nil
+}
+
+func (l *Locker) openMap(name string) (int, error) {
+	return bpf.ObjGet(filepath.Join(l.pinDir, name))
+}
+
+// Allow sets the allowed directories to those of paths and removes the
+// others, then it updates the root filesystem device. Restrictions are
+// enforced once the allowed directories are loaded.
+func (l *Locker) Allow(paths []string) error {
+	inodes, err := ResolveInodes(paths)
+	if err != nil {
+		return err
+	}
+	rootDev, err := RootDev()
+	if err != nil {
+		return err
+	}
+
+	fd, err := l.openMap(AllowMap)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	// Add new directories first, so allowed ones are never denied
+	value := uint32(1)
+	for ino := range inodes {
+		ino := ino
+		if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&ino), unsafe.Pointer(&value), unix.BPF_ANY); err != nil {
+			return fmt.Errorf("unable to allow directory inode %d: %w", ino.Ino, err)
+		}
+	}
+
+	var stale []Inode
+	var key, next Inode
+	var pkey unsafe.Pointer
+	for {
+		err := bpf.MapGetNextKey(fd, pkey, unsafe.Pointer(&next))
+		if errors.Is(err, unix.ENOENT) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("unable to list allowed directories: %w", err)
+		}
+		if _, ok := inodes[next]; !ok {
+			stale = append(stale, next)
+		}
+		key = next
+		pkey = unsafe.Pointer(&key)
+	}
+	for _, ino := range stale {
+		ino := ino
+		if err := bpf.MapDeleteElem(fd, unsafe.Pointer(&ino)); err != nil && !errors.Is(err, unix.ENOENT) {
+			return fmt.Errorf("unable to remove allowed directory inode %d: %w", ino.Ino, err)
+		}
+	}
+
+	ofd, err := l.openMap(OptionsMap)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(ofd)
+
+	k := keyRootDev
+	if err := bpf.MapUpdateElem(ofd, unsafe.Pointer(&k), unsafe.Pointer(&rootDev), unix.BPF_ANY); err != nil {
+		return fmt.Errorf("unable to set root filesystem device: %w", err)
+	}
+	k = keyReady
+	if err := bpf.MapUpdateElem(ofd, unsafe.Pointer(&k), unsafe.Pointer(&value), unix.BPF_ANY); err != nil {
+		return fmt.Errorf("unable to enable rootfslock restrictions: %w", err)
+	}
+
+	if len(stale) > 0 {
+		log.Debugf("Allowing writes below %d directories, removed %d stale entries", len(inodes), len(stale))
+	}
+	return nil
+}
+
+// Update loads the allowed directories of paths, it does nothing if the
+// rootfslock program is not loaded.
+func (l *Locker) Update(paths []string) error {
+	if !l.Loaded() {
+		return nil
+	}
+	return l.Allow(paths)
+}
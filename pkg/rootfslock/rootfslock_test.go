@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package rootfslock
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+
+	"golang.org/x/sys/unix"
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type RootfsLockSuite struct{}
+
+var _ = Suite(&RootfsLockSuite{})
+
+func (s *RootfsLockSuite) TestParseArgs(c *C) {
+	c.Assert(ParseArgs([]string{"--profile=restricted", "--allow=/var, /tmp"}),
+		DeepEquals, []string{"/var", "/tmp"})
+	c.Assert(ParseArgs(nil), HasLen, 0)
+}
+
+func (s *RootfsLockSuite) TestResolveInodes(c *C) {
+	dir := c.MkDir()
+	sub := filepath.Join(dir, "var")
+	c.Assert(os.Mkdir(sub, 0755), IsNil)
+	link := filepath.Join(dir, "run")
+	c.Assert(os.Symlink(sub, link), IsNil)
+	file := filepath.Join(dir, "file")
+	c.Assert(ioutil.WriteFile(file, nil, 0644), IsNil)
+
+	inodes, err := ResolveInodes([]string{sub, link, filepath.Join(dir, "missing")})
+	c.Assert(err, IsNil)
+	c.Assert(inodes, HasLen, 1)
+
+	var st syscall.Stat_t
+	c.Assert(syscall.Stat(sub, &st), IsNil)
+	_, ok := inodes[bpf.NewStat(&st)]
+	c.Assert(ok, Equals, true)
+
+	_, err = ResolveInodes([]string{"var"})
+	c.Assert(err, ErrorMatches, "allowed directory 'var' is not an absolute path")
+	_, err = ResolveInodes([]string{file})
+	c.Assert(err, ErrorMatches, "allowed directory '.*' is not a directory")
+}
+
+func (s *RootfsLockSuite) TestRootDev(c *C) {
+	saved := RootDir
+	defer func() { RootDir = saved }()
+
+	RootDir = c.MkDir()
+	var st syscall.Stat_t
+	c.Assert(syscall.Stat(RootDir, &st), IsNil)
+
+	dev, err := RootDev()
+	c.Assert(err, IsNil)
+	c.Assert(dev, Equals, uint32(unix.Major(uint64(st.Dev))<<20|unix.Minor(uint64(st.Dev))))
+}
+
+func (s *RootfsLockSuite) TestLockerNotLoaded(c *C) {
+	l := NewLocker(c.MkDir())
+	c.Assert(l.Loaded(), Equals, false)
+	c.Assert(l.Update([]string{"/var"}), IsNil)
+}
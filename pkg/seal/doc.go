@@ -0,0 +1,8 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package seal freezes the policy of bpflock until the next reboot. It
+// freezes the configuration maps of the pinned bpf programs and records
+// the sealed configuration, so a restarted bpflock agent can refuse
+// configuration changes that could loosen the policy.
+package seal
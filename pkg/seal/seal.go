@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package seal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/exempt"
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+	"github.com/linux-lock/bpflock/pkg/trust"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	subsystem = "seal"
+
+	// PolicyFile is the file inside the state directory that records the
+	// sealed configuration of the bpf programs.
+	PolicyFile = "sealed-policy.json"
+)
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+	// configMaps are the pinned maps of each bpf program that hold its
+	// policy, they must match bpf/<program>.bpf.c. The trusted namespaces,
+	// user and group rules and exempted executables maps of the program
+	// are frozen too. Only selflock_agent_map is never frozen, a restarted
+	// agent must register its new identity.
+	configMaps = map[string][]string{
+		components.BpfRestrict: {"bpfrestrict_map", "bpfrestrict_ns_map"},
+		components.ExecLock:    {"execlock_map"},
+		components.FsLock:      {"fslock_map", "fslock_magics_map"},
+		components.KmodLock:    {"disablemods_map", "disablemods_ns_map", "kmodlock_names_map", "kmodlock_exceptions_map"},
+		components.NetLock:     {"netlock_map", "netlock_families_map"},
+		components.NsLock:      {"nslock_map"},
+		components.RootfsLock:  {"rootfslock_map", "rootfslock_allow_map"},
+		components.SelfLock:    {"selflock_map", "selflock_inodes_map"},
+		components.UsbLock:     {"usblock_map", "usblock_ids_map", "usblock_class_map"},
+	}
+
+	// replaced in tests
+	freezeMap = func(path string) error {
+		fd, err := bpf.ObjGet(path)
+		if err != nil {
+			return err
+		}
+		defer unix.Close(fd)
+		return bpf.MapFreeze(fd)
+	}
+)
+
+// Policy is the sealed configuration of the bpf programs.
+type Policy struct {
+	// Sealed is the time when the policy was sealed
+	Sealed time.Time `json:"sealed"`
+
+	Programs []*models.BpfProgram `json:"programs"`
+}
+
+// ReadPolicy reads the sealed policy from path, it returns nil if the
+// file does not exist.
+func ReadPolicy(path string) (*Policy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	p := &Policy{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// WritePolicy atomically writes the sealed policy p to path.
+func WritePolicy(path string, p *Policy) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Program returns the sealed configuration of the bpf program name, nil
+// if it was not running when the policy was sealed.
+func (p *Policy) Program(name string) *models.BpfProgram {
+	for _, prog := range p.Programs {
+		if prog.Name == name {
+			return prog
+		}
+	}
+	return nil
+}
+
+// Changes returns the names of the bpf programs whose configuration in
+// programs differs from the sealed one, including programs that were
+// added or removed.
+func (p *Policy) Changes(programs []*models.BpfProgram) []string {
+	current := make(map[string]*models.BpfProgram, len(programs))
+	for _, prog := range programs {
+		current[prog.Name] = prog
+	}
+
+	var changed []string
+	for _, sealed := range p.Programs {
+		prog, ok := current[sealed.Name]
+		if !ok || prog.Command != sealed.Command || !reflect.DeepEqual(prog.Args, sealed.Args) {
+			changed = append(changed, sealed.Name)
+		}
+		delete(current, sealed.Name)
+	}
+	for name := range current {
+		changed = append(changed, name)
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// policyMaps returns the maps of program that are frozen.
+func policyMaps(program string) []string {
+	maps := append([]string(nil), configMaps[program]...)
+	return append(maps, trust.MapName(program), trust.RulesMapName(program), exempt.MapName(program))
+}
+
+// Freeze freezes the policy maps of the bpf programs pinned inside
+// pinPrefix, usually bpf.MapPrefixPath(). Programs that are not running
+// are skipped and maps that are already frozen are ignored. It returns
+// the number of frozen maps.
+func Freeze(pinPrefix string) (int, error) {
+	programs := make([]string, 0, len(configMaps))
+	for name := range configMaps {
+		programs = append(programs, name)
+	}
+	sort.Strings(programs)
+
+	n := 0
+	for _, name := range programs {
+		dir := filepath.Join(pinPrefix, name)
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		for _, m := range policyMaps(name) {
+			path := filepath.Join(dir, m)
+			if _, err := os.Stat(path); err != nil {
+				log.WithField(logfields.Path, path).Debug("Not freezing missing map")
+				continue
+			}
+			if err := freezeMap(path); err != nil && !errors.Is(err, unix.EBUSY) {
+				return n, fmt.Errorf("unable to freeze %s: %w", path, err)
+			}
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package seal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linux-lock/bpflock/api/v1/models"
+	"github.com/linux-lock/bpflock/pkg/components"
+
+	"golang.org/x/sys/unix"
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type SealSuite struct {
+	freezeMap func(string) error
+}
+
+var _ = Suite(&SealSuite{})
+
+func (s *SealSuite) SetUpTest(c *C) {
+	s.freezeMap = freezeMap
+}
+
+func (s *SealSuite) TearDownTest(c *C) {
+	freezeMap = s.freezeMap
+}
+
+func (s *SealSuite) TestPolicy(c *C) {
+	path := filepath.Join(c.MkDir(), PolicyFile)
+
+	p, err := ReadPolicy(path)
+	c.Assert(err, IsNil)
+	c.Assert(p, IsNil)
+
+	sealed := &Policy{
+		Sealed: time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC),
+		Programs: []*models.BpfProgram{
+			{Name: components.KmodLock, Command: "kmodlock", Args: []string{"--block=load_module"}},
+		},
+	}
+	c.Assert(WritePolicy(path, sealed), IsNil)
+
+	p, err = ReadPolicy(path)
+	c.Assert(err, IsNil)
+	c.Assert(p.Sealed.Equal(sealed.Sealed), Equals, true)
+	c.Assert(p.Program(components.KmodLock), DeepEquals, sealed.Programs[0])
+	c.Assert(p.Program(components.NetLock), IsNil)
+
+	c.Assert(ioutil.WriteFile(path, []byte("{"), 0600), IsNil)
+	_, err = ReadPolicy(path)
+	c.Assert(err, NotNil)
+}
+
+func (s *SealSuite) TestChanges(c *C) {
+	p := &Policy{
+		Programs: []*models.BpfProgram{
+			{Name: components.KmodLock, Command: "kmodlock", Args: []string{"--profile=restricted"}},
+			{Name: components.FsLock, Command: "fslock", Args: []string{"--profile=baseline"}},
+			{Name: components.SelfLock, Command: "selflock", Args: []string{"--profile=restricted"}},
+		},
+	}
+
+	c.Assert(p.Changes(p.Programs), HasLen, 0)
+
+	current := []*models.BpfProgram{
+		{Name: components.KmodLock, Command: "kmodlock", Args: []string{"--profile=allow"}},
+		{Name: components.SelfLock, Command: "selflock", Args: []string{"--profile=restricted"}},
+		{Name: components.NetLock, Command: "netlock", Args: []string{"--profile=baseline"}},
+	}
+	c.Assert(p.Changes(current), DeepEquals, []string{components.FsLock, components.KmodLock, components.NetLock})
+}
+
+func (s *SealSuite) TestFreeze(c *C) {
+	prefix := c.MkDir()
+	for _, name := range []string{"selflock_map", "selflock_agent_map", "kmodlock_names_map", "disablemods_map", "kmodlock_trust_map", "kmodlock_ids_map", "kmodlock_exempt_map"} {
+		dir := filepath.Join(prefix, components.SelfLock)
+		if !strings.HasPrefix(name, "selflock") {
+			dir = filepath.Join(prefix, components.KmodLock)
+		}
+		c.Assert(os.MkdirAll(dir, 0755), IsNil)
+		c.Assert(ioutil.WriteFile(filepath.Join(dir, name), nil, 0600), IsNil)
+	}
+
+	var frozen []string
+	freezeMap = func(path string) error {
+		rel, _ := filepath.Rel(prefix, path)
+		frozen = append(frozen, rel)
+		if filepath.Base(path) == "disablemods_map" {
+			return unix.EBUSY
+		}
+		return nil
+	}
+
+	n, err := Freeze(prefix)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 6)
+	c.Assert(frozen, DeepEquals, []string{
+		"kmodlock/disablemods_map",
+		"kmodlock/kmodlock_names_map",
+		"kmodlock/kmodlock_trust_map",
+		"kmodlock/kmodlock_ids_map",
+		"kmodlock/kmodlock_exempt_map",
+		"selflock/selflock_map",
+	})
+
+	freezeMap = func(path string) error {
+		return unix.EPERM
+	}
+	_, err = Freeze(prefix)
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,6 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package selflock feeds the selflock bpf program with the identity of
+// the bpflock agent and the inodes of its pins and configuration files.
+package selflock
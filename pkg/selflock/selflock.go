@@ -0,0 +1,277 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package selflock
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/components"
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	subsystem = "selflock"
+
+	// OptionsMap is the name of the pinned map of selflock options
+	OptionsMap = "selflock_map"
+
+	// InodesMap is the name of the pinned map of protected inodes
+	InodesMap = "selflock_inodes_map"
+
+	// AgentMap is the name of the pinned map of the bpflock agent identity
+	AgentMap = "selflock_agent_map"
+
+	// Keys of OptionsMap, they must match bpf/selflock.h
+	keySealed uint32 = 4
+
+	// maxInodes must match BPFLOCK_SL_MAX_INODES of bpf/selflock.h
+	maxInodes = 4096
+)
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+	// ProcDir is the proc filesystem of the host
+	ProcDir = "/proc"
+)
+
+// Agent is the identity of the bpflock agent, it must match struct
+// bl_sl_agent of bpf/selflock.h.
+type Agent struct {
+	Pid uint32
+	_   uint32
+
+	// StartTime is the start time of the agent in clock ticks since boot,
+	// pids are recycled
+	StartTime uint64
+}
+
+// parseStartTime returns the start time of the content of /proc/<pid>/stat.
+func parseStartTime(stat string) (uint64, error) {
+	// The command may contain spaces, fields start after its last ')'
+	i := strings.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0, fmt.Errorf("invalid stat format")
+	}
+	fields := strings.Fields(stat[i+1:])
+	// starttime is the 22nd field, the 20th after the command
+	if len(fields) < 20 {
+		return 0, fmt.Errorf("invalid stat format")
+	}
+	return strconv.ParseUint(fields[19], 10, 64)
+}
+
+// NewAgent returns the identity of the process pid.
+func NewAgent(pid int) (*Agent, error) {
+	data, err := ioutil.ReadFile(filepath.Join(ProcDir, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return nil, err
+	}
+	start, err := parseStartTime(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read start time of pid %d: %w", pid, err)
+	}
+	return &Agent{Pid: uint32(pid), StartTime: start}, nil
+}
+
+// Inode identifies a protected file.
+type Inode = bpf.Stat
+
+// CollectInodes returns the inodes of paths and of all the files inside
+// them. Paths that do not exist are ignored.
+func CollectInodes(paths ...string) (map[Inode]struct{}, error) {
+	inodes := make(map[Inode]struct{})
+	for _, p := range paths {
+		err := filepath.Walk(p, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			st, ok := info.Sys().(*syscall.Stat_t)
+			if !ok {
+				return fmt.Errorf("unable to stat %s", path)
+			}
+			inodes[bpf.NewStat(st)] = struct{}{}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(inodes) > maxInodes {
+		return nil, fmt.Errorf("too many files to protect: %d, maximum is %d", len(inodes), maxInodes)
+	}
+	return inodes, nil
+}
+
+// Locker updates the pinned maps of the selflock program.
+type Locker struct {
+	// pinDir is the directory of selflock pins
+	pinDir string
+}
+
+// NewLocker returns a locker of the selflock program pinned inside
+// pinPrefix, usually bpf.MapPrefixPath().
+func NewLocker(pinPrefix string) *Locker {
+	return &Locker{
+		pinDir: filepath.Join(pinPrefix, components.SelfLock),
+	}
+}
+
+// Loaded returns true if the selflock program is pinned.
+func (l *Locker) Loaded() bool {
+	_, err := os.Stat(filepath.Join(l.pinDir, OptionsMap))
+	return err == nil
+}
+
+func (l *Locker) openMap(name string) (int, error) {
+	return bpf.ObjGet(filepath.Join(l.pinDir, name))
+}
+
+// SetAgent sets the identity of the bpflock agent that is allowed to
+// operate on the protected files, a zero agent clears it.
+func (l *Locker) SetAgent(agent *Agent) error {
+	fd, err := l.openMap(AgentMap)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	key := uint32(0)
+	if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&key), unsafe.Pointer(agent), unix.BPF_ANY); err != nil {
+		return fmt.Errorf("unable to set bpflock agent: %w", err)
+	}
+	return nil
+}
+
+// ClearAgent removes the identity of the bpflock agent, it must be called
+// when the agent stops so a process that reuses its pid is not trusted. It
+// does nothing if the selflock program is not loaded.
+func (l *Locker) ClearAgent() error {
+	if !l.Loaded() {
+		return nil
+	}
+	return l.SetAgent(&Agent{})
+}
+
+// Sealed returns true if the bpflock policy is sealed, it is false if the
+// selflock program is not loaded.
+func (l *Locker) Sealed() (bool, error) {
+	if !l.Loaded() {
+		return false, nil
+	}
+	fd, err := l.openMap(OptionsMap)
+	if err != nil {
+		return false, err
+	}
+	defer unix.Close(fd)
+
+	key := keySealed
+	value := uint32(0)
+	if err := bpf.MapLookupElem(fd, unsafe.Pointer(&key), unsafe.Pointer(&value)); err != nil {
+		if errors.Is(err, unix.ENOENT) {
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to read seal state: %w", err)
+	}
+	return value != 0, nil
+}
+
+// Seal makes the restricted profile apply to all protected operations,
+// whatever the configured profile is. It can not be undone once the
+// options map is frozen.
+func (l *Locker) Seal() error {
+	fd, err := l.openMap(OptionsMap)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	key := keySealed
+	value := uint32(1)
+	if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&key), unsafe.Pointer(&value), unix.BPF_ANY); err != nil {
+		return fmt.Errorf("unable to seal selflock: %w", err)
+	}
+	return nil
+}
+
+// Protect sets the protected inodes to the files in paths and removes the
+// inodes of files that are gone.
+func (l *Locker) Protect(paths ...string) error {
+	inodes, err := CollectInodes(paths...)
+	if err != nil {
+		return err
+	}
+
+	fd, err := l.openMap(InodesMap)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	var stale []Inode
+	var key, next Inode
+	var pkey unsafe.Pointer
+	for {
+		err := bpf.MapGetNextKey(fd, pkey, unsafe.Pointer(&next))
+		if errors.Is(err, unix.ENOENT) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("unable to list protected inodes: %w", err)
+		}
+		if _, ok := inodes[next]; !ok {
+			stale = append(stale, next)
+		}
+		key = next
+		pkey = unsafe.Pointer(&key)
+	}
+
+	for _, ino := range stale {
+		ino := ino
+		if err := bpf.MapDeleteElem(fd, unsafe.Pointer(&ino)); err != nil && !errors.Is(err, unix.ENOENT) {
+			return fmt.Errorf("unable to remove protected inode %d: %w", ino.Ino, err)
+		}
+	}
+
+	value := uint32(1)
+	for ino := range inodes {
+		ino := ino
+		if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&ino), unsafe.Pointer(&value), unix.BPF_ANY); err != nil {
+			return fmt.Errorf("unable to protect inode %d: %w", ino.Ino, err)
+		}
+	}
+
+	log.Debugf("Protecting %d files, removed %d stale entries", len(inodes), len(stale))
+	return nil
+}
+
+// Update sets the bpflock agent to the process pid and the protected
+// files, it does nothing if the selflock program is not loaded.
+func (l *Locker) Update(pid int, paths ...string) error {
+	if !l.Loaded() {
+		return nil
+	}
+	agent, err := NewAgent(pid)
+	if err != nil {
+		return err
+	}
+	if err := l.SetAgent(agent); err != nil {
+		return err
+	}
+	return l.Protect(paths...)
+}
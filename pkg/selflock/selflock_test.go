@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package selflock
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type SelfLockSuite struct{}
+
+var _ = Suite(&SelfLockSuite{})
+
+func (s *SelfLockSuite) TestCollectInodes(c *C) {
+	dir := c.MkDir()
+	sub := filepath.Join(dir, "bpf.d")
+	c.Assert(os.Mkdir(sub, 0700), IsNil)
+	f := filepath.Join(sub, "allow.yaml")
+	c.Assert(os.WriteFile(f, []byte("bpfprograms:\n"), 0600), IsNil)
+
+	inodes, err := CollectInodes(dir, filepath.Join(dir, "missing"))
+	c.Assert(err, IsNil)
+	c.Assert(inodes, HasLen, 3)
+
+	fi, err := os.Stat(f)
+	c.Assert(err, IsNil)
+	st := fi.Sys().(*syscall.Stat_t)
+	_, ok := inodes[bpf.NewStat(st)]
+	c.Assert(ok, Equals, true)
+}
+
+func (s *SelfLockSuite) TestParseStartTime(c *C) {
+	stat := "4242 (bpflock (agent)) S 1 4242 4242 0 -1 4194560 2830 0 0 0 12 5 0 0 20 0 14 0 1234567 " +
+		"1534914560 9845 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 3 0 0 0 0 0"
+	start, err := parseStartTime(stat)
+	c.Assert(err, IsNil)
+	c.Assert(start, Equals, uint64(1234567))
+
+	_, err = parseStartTime("4242 (bpflock) S 1")
+	c.Assert(err, NotNil)
+
+	agent, err := NewAgent(os.Getpid())
+	c.Assert(err, IsNil)
+	c.Assert(agent.Pid, Equals, uint32(os.Getpid()))
+	c.Assert(agent.StartTime > 0, Equals, true)
+	c.Assert(unsafe.Sizeof(*agent), Equals, uintptr(16))
+}
+
+func (s *SelfLockSuite) TestLoaded(c *C) {
+	dir := c.MkDir()
+	l := NewLocker(dir)
+	c.Assert(l.Loaded(), Equals, false)
+	c.Assert(l.Update(os.Getpid(), dir), IsNil)
+
+	c.Assert(os.Mkdir(filepath.Join(dir, "selflock"), 0700), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dir, "selflock", OptionsMap), nil, 0600), IsNil)
+	c.Assert(l.Loaded(), Equals, true)
+}
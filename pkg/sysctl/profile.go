@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package sysctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+const (
+	// ProfileNone does not change any sysctl
+	ProfileNone = "none"
+
+	// ProfileBaseline hardens sysctls that can be reverted at runtime
+	ProfileBaseline = "baseline"
+
+	// ProfileRestricted also sets one way sysctls that can only be
+	// reverted by a reboot
+	ProfileRestricted = "restricted"
+)
+
+var (
+	profiles = map[string][]Setting{
+		ProfileNone: {},
+		ProfileBaseline: {
+			{Name: "kernel.unprivileged_bpf_disabled", Val: "2", IgnoreErr: true},
+			{Name: "kernel.kptr_restrict", Val: "1"},
+			{Name: "kernel.perf_event_paranoid", Val: "2"},
+		},
+		ProfileRestricted: {
+			{Name: "kernel.unprivileged_bpf_disabled", Val: "1", IgnoreErr: true},
+			{Name: "kernel.kptr_restrict", Val: "2"},
+			{Name: "kernel.perf_event_paranoid", Val: "3"},
+			{Name: "kernel.kexec_load_disabled", Val: "1", IgnoreErr: true},
+			{Name: "kernel.modules_disabled", Val: "1", IgnoreErr: true},
+		},
+	}
+
+	// replaced in tests
+	readParam  = Read
+	writeParam = Write
+)
+
+// ProfileSettings returns the settings of the built-in profile, with
+// overrides replacing or adding settings. Settings are sorted by name.
+func ProfileSettings(profile string, overrides map[string]string) ([]Setting, error) {
+	if profile == "" {
+		profile = ProfileNone
+	}
+	base, ok := profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("sysctl profile '%s' not supported", profile)
+	}
+
+	settings := make(map[string]Setting, len(base)+len(overrides))
+	for _, s := range base {
+		settings[s.Name] = s
+	}
+	for name, val := range overrides {
+		if _, err := parameterPath(name); err != nil {
+			return nil, err
+		}
+		s := settings[name]
+		s.Name = name
+		s.Val = val
+		settings[name] = s
+	}
+
+	result := make([]Setting, 0, len(settings))
+	for _, s := range settings {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// readOriginals reads the original values stored at path.
+func readOriginals(path string) (map[string]string, error) {
+	originals := make(map[string]string)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return originals, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &originals); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	return originals, nil
+}
+
+// SaveOriginals records at path the current values of settings before
+// they are changed. Values that were already recorded by a previous run
+// are kept, so they are not replaced with the hardened ones.
+func SaveOriginals(path string, settings []Setting) error {
+	originals, err := readOriginals(path)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for _, s := range settings {
+		if _, ok := originals[s.Name]; ok {
+			continue
+		}
+		val, err := readParam(s.Name)
+		if err != nil {
+			log.WithError(err).Warnf("Unable to record original value of %s", s.Name)
+			continue
+		}
+		originals[s.Name] = val
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(originals, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// RestoreOriginals writes back the original values recorded at path and
+// removes it. One way sysctls can not be restored and are reported.
+func RestoreOriginals(path string) error {
+	originals, err := readOriginals(path)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(originals))
+	for name := range originals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failed []string
+	for _, name := range names {
+		cur, err := readParam(name)
+		if err == nil && cur == originals[name] {
+			continue
+		}
+		if err := writeParam(name, originals[name]); err != nil {
+			log.WithError(err).Warnf("Unable to restore %s to %s", name, originals[name])
+			failed = append(failed, name)
+			continue
+		}
+		log.Infof("Restored sysctl %s=%s", name, originals[name])
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to restore sysctls: %v", failed)
+	}
+	return nil
+}
+
+// Drift returns a message for each setting whose current value differs
+// from the applied one.
+func Drift(settings []Setting) []string {
+	var drift []string
+	for _, s := range settings {
+		val, err := readParam(s.Name)
+		if err != nil {
+			drift = append(drift, fmt.Sprintf("%s: %v", s.Name, err))
+		} else if val != s.Val {
+			drift = append(drift, fmt.Sprintf("%s is %s instead of %s", s.Name, val, s.Val))
+		}
+	}
+	return drift
+}
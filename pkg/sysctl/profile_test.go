@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package sysctl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+type ProfileTestSuite struct {
+	params map[string]string
+}
+
+var _ = Suite(&ProfileTestSuite{})
+
+func (s *ProfileTestSuite) SetUpTest(c *C) {
+	s.params = map[string]string{
+		"kernel.kptr_restrict":             "0",
+		"kernel.perf_event_paranoid":       "2",
+		"kernel.unprivileged_bpf_disabled": "0",
+		"kernel.modules_disabled":          "0",
+	}
+	readParam = func(name string) (string, error) {
+		v, ok := s.params[name]
+		if !ok {
+			return "", fmt.Errorf("no such sysctl %s", name)
+		}
+		return v, nil
+	}
+	writeParam = func(name, val string) error {
+		if name == "kernel.modules_disabled" && s.params[name] == "1" {
+			return fmt.Errorf("invalid argument")
+		}
+		s.params[name] = val
+		return nil
+	}
+}
+
+func (s *ProfileTestSuite) TearDownTest(c *C) {
+	readParam = Read
+	writeParam = Write
+}
+
+func (s *ProfileTestSuite) TestProfileSettings(c *C) {
+	settings, err := ProfileSettings("", nil)
+	c.Assert(err, IsNil)
+	c.Assert(settings, HasLen, 0)
+
+	settings, err = ProfileSettings(ProfileBaseline, map[string]string{
+		"kernel.kptr_restrict":     "2",
+		"kernel.yama.ptrace_scope": "1",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, []Setting{
+		{Name: "kernel.kptr_restrict", Val: "2"},
+		{Name: "kernel.perf_event_paranoid", Val: "2"},
+		{Name: "kernel.unprivileged_bpf_disabled", Val: "2", IgnoreErr: true},
+		{Name: "kernel.yama.ptrace_scope", Val: "1"},
+	})
+
+	_, err = ProfileSettings("paranoid", nil)
+	c.Assert(err, NotNil)
+	_, err = ProfileSettings(ProfileBaseline, map[string]string{"../etc/passwd": "1"})
+	c.Assert(err, NotNil)
+}
+
+func (s *ProfileTestSuite) TestOriginals(c *C) {
+	path := filepath.Join(c.MkDir(), "sysctl.json")
+	settings, err := ProfileSettings(ProfileBaseline, map[string]string{"kernel.modules_disabled": "1"})
+	c.Assert(err, IsNil)
+
+	c.Assert(SaveOriginals(path, settings), IsNil)
+	for _, st := range settings {
+		s.params[st.Name] = st.Val
+	}
+
+	// A restart must not record the hardened values
+	c.Assert(SaveOriginals(path, settings), IsNil)
+	originals, err := readOriginals(path)
+	c.Assert(err, IsNil)
+	c.Assert(originals["kernel.kptr_restrict"], Equals, "0")
+
+	c.Assert(Drift(settings), HasLen, 0)
+	s.params["kernel.kptr_restrict"] = "0"
+	c.Assert(Drift(settings), DeepEquals, []string{"kernel.kptr_restrict is 0 instead of 1"})
+
+	// modules_disabled is one way
+	err = RestoreOriginals(path)
+	c.Assert(err, ErrorMatches, ".*kernel.modules_disabled.*")
+	c.Assert(s.params["kernel.unprivileged_bpf_disabled"], Equals, "0")
+	c.Assert(s.params["kernel.perf_event_paranoid"], Equals, "2")
+	_, err = os.Stat(path)
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
@@ -0,0 +1,8 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package trust resolves the baseline trust criteria of a bpf program: the
+// namespace types that must be trusted and the namespaces of the host and
+// of trusted processes or containers, and loads them into the
+// <program>_trust_map pinned map of the program.
+package trust
@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package trust
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+
+	"golang.org/x/sys/unix"
+)
+
+// Kinds of user and group rules, they must match enum bpflock_id_kind of
+// bpf/bpflock_shared_defs.h
+const (
+	kindNone uint32 = iota
+	KindAllowUID
+	KindAllowGID
+	KindDenyUID
+	KindDenyGID
+)
+
+const (
+	// maxRules must match BPFLOCK_ID_MAX_RULES of bpf/bpflock_shared_defs.h
+	maxRules = 32
+
+	// ruleNameLen must match BPFLOCK_ID_RULE_LEN of
+	// bpf/bpflock_shared_defs.h
+	ruleNameLen = 48
+)
+
+var (
+	// lookupUser and lookupGroup return the id of a user or group name
+	lookupUser = func(name string) (string, error) {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", err
+		}
+		return u.Uid, nil
+	}
+	lookupGroup = func(name string) (string, error) {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return "", err
+		}
+		return g.Gid, nil
+	}
+
+	kindNames = map[uint32]string{
+		KindAllowUID: "allow-uid",
+		KindAllowGID: "allow-gid",
+		KindDenyUID:  "deny-uid",
+		KindDenyGID:  "deny-gid",
+	}
+)
+
+// Rule is a user or group rule, it must match struct bl_id_rule of
+// bpf/bpflock_shared_defs.h.
+type Rule struct {
+	Kind uint32
+	Min  uint32
+	Max  uint32
+
+	// Name is the rule as configured, it is reported in events
+	Name [ruleNameLen]byte
+}
+
+// String returns the rule as configured, like "deny-uid=svc-backup".
+func (r *Rule) String() string {
+	b := r.Name[:]
+	if i := strings.IndexByte(string(b), 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// HasRules returns true if the policy has user or group rules.
+func (p *Policy) HasRules() bool {
+	return len(p.AllowUsers)+len(p.AllowGroups)+len(p.DenyUsers)+len(p.DenyGroups) > 0
+}
+
+func parseID(s string) (uint32, error) {
+	id, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(id), nil
+}
+
+// isRange returns true if s is a "min-max" range of ids, user and group
+// names may contain dashes too.
+func isRange(s string) bool {
+	ids := strings.SplitN(s, "-", 2)
+	if len(ids) != 2 {
+		return false
+	}
+	_, err1 := parseID(ids[0])
+	_, err2 := parseID(ids[1])
+	return err1 == nil && err2 == nil
+}
+
+// newRule resolves value, a name, an id or a "min-max" range of ids, into
+// a rule of kind.
+func newRule(kind uint32, value string, lookup func(string) (string, error)) (Rule, error) {
+	r := Rule{Kind: kind}
+	copy(r.Name[:ruleNameLen-1], kindNames[kind]+"="+value)
+
+	if isRange(value) {
+		ids := strings.SplitN(value, "-", 2)
+		r.Min, _ = parseID(ids[0])
+		r.Max, _ = parseID(ids[1])
+		if r.Min > r.Max {
+			return r, fmt.Errorf("invalid %s range '%s'", kindNames[kind], value)
+		}
+		return r, nil
+	}
+
+	id, err := parseID(value)
+	if err != nil {
+		s, err := lookup(value)
+		if err != nil {
+			return r, fmt.Errorf("unable to resolve %s '%s': %w", kindNames[kind], value, err)
+		}
+		if id, err = parseID(s); err != nil {
+			return r, fmt.Errorf("invalid id of %s '%s': %w", kindNames[kind], value, err)
+		}
+	}
+	r.Min, r.Max = id, id
+	return r, nil
+}
+
+// ResolveRules returns the user and group rules of the policy with user and
+// group names resolved, deny rules come first.
+func ResolveRules(p *Policy) ([]Rule, error) {
+	var rules []Rule
+	for _, set := range []struct {
+		kind   uint32
+		values []string
+		lookup func(string) (string, error)
+	}{
+		{KindDenyUID, p.DenyUsers, lookupUser},
+		{KindDenyGID, p.DenyGroups, lookupGroup},
+		{KindAllowUID, p.AllowUsers, lookupUser},
+		{KindAllowGID, p.AllowGroups, lookupGroup},
+	} {
+		for _, v := range set.values {
+			r, err := newRule(set.kind, v, set.lookup)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, r)
+		}
+	}
+	if len(rules) > maxRules {
+		return nil, fmt.Errorf("too many user and group rules: %d, maximum is %d", len(rules), maxRules)
+	}
+	return rules, nil
+}
+
+// RulesMapName returns the name of the pinned map of user and group rules
+// of program.
+func RulesMapName(program string) string {
+	return program + "_ids_map"
+}
+
+// SetRules sets the user and group rules in order and ends the list after
+// them.
+func (l *Locker) SetRules(rules []Rule) error {
+	if len(rules) > maxRules {
+		return fmt.Errorf("too many user and group rules: %d, maximum is %d", len(rules), maxRules)
+	}
+
+	fd, err := bpf.ObjGet(l.idsPath)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	// Entries after the end of the list are not read
+	if len(rules) < maxRules {
+		rules = append(rules[:len(rules):len(rules)], Rule{Kind: kindNone})
+	}
+	for i := range rules {
+		k, r := uint32(i), rules[i]
+		if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&k), unsafe.Pointer(&r), unix.BPF_ANY); err != nil {
+			return fmt.Errorf("unable to set user and group rule %d: %w", i, err)
+		}
+	}
+
+	log.WithField(logfields.Path, l.idsPath).Debugf("Set %d user and group rules", len(rules))
+	return nil
+}
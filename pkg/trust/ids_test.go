@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package trust
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func fakeLookup(ids map[string]string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		if id, ok := ids[name]; ok {
+			return id, nil
+		}
+		return "", errors.New("unknown name")
+	}
+}
+
+func (s *TrustSuite) TestParseRules(c *C) {
+	p, err := ParseArgs([]string{"--profile=baseline"})
+	c.Assert(err, IsNil)
+	c.Assert(p.HasRules(), Equals, false)
+
+	p, err = ParseArgs([]string{"--profile=baseline", "--allow-uid=0", "--deny-uid=svc-backup, 1000-1999", "--deny-gid=services"})
+	c.Assert(err, IsNil)
+	c.Assert(p.HasRules(), Equals, true)
+	c.Assert(p.AllowUsers, DeepEquals, []string{"0"})
+	c.Assert(p.DenyUsers, DeepEquals, []string{"svc-backup", "1000-1999"})
+	c.Assert(p.DenyGroups, DeepEquals, []string{"services"})
+	c.Assert(p.AllowGroups, IsNil)
+
+	users := make([]string, maxRules+1)
+	for i := range users {
+		users[i] = fmt.Sprint(i)
+	}
+	_, err = ParseArgs([]string{"--deny-uid=" + strings.Join(users, ",")})
+	c.Assert(err, NotNil)
+}
+
+func (s *TrustSuite) TestResolveRules(c *C) {
+	lookupUser = fakeLookup(map[string]string{"svc-backup": "990", "root": "0"})
+	lookupGroup = fakeLookup(map[string]string{"services": "800"})
+
+	p, err := ParseArgs([]string{"--allow-uid=root", "--deny-uid=svc-backup,1000-1999", "--deny-gid=services"})
+	c.Assert(err, IsNil)
+	rules, err := ResolveRules(p)
+	c.Assert(err, IsNil)
+	c.Assert(rules, HasLen, 4)
+
+	// Deny rules come first
+	c.Assert(rules[0].Kind, Equals, KindDenyUID)
+	c.Assert(rules[0].Min, Equals, uint32(990))
+	c.Assert(rules[0].Max, Equals, uint32(990))
+	c.Assert(rules[0].String(), Equals, "deny-uid=svc-backup")
+	c.Assert(rules[1].Kind, Equals, KindDenyUID)
+	c.Assert(rules[1].Min, Equals, uint32(1000))
+	c.Assert(rules[1].Max, Equals, uint32(1999))
+	c.Assert(rules[2].Kind, Equals, KindDenyGID)
+	c.Assert(rules[2].Min, Equals, uint32(800))
+	c.Assert(rules[3].Kind, Equals, KindAllowUID)
+	c.Assert(rules[3].Min, Equals, uint32(0))
+	c.Assert(rules[3].String(), Equals, "allow-uid=root")
+
+	// Unknown names and invalid ranges are errors
+	p, err = ParseArgs([]string{"--deny-uid=nobody-here"})
+	c.Assert(err, IsNil)
+	_, err = ResolveRules(p)
+	c.Assert(err, ErrorMatches, ".*unable to resolve deny-uid 'nobody-here'.*")
+
+	p, err = ParseArgs([]string{"--allow-gid=2000-1000"})
+	c.Assert(err, IsNil)
+	_, err = ResolveRules(p)
+	c.Assert(err, ErrorMatches, "invalid allow-gid range '2000-1000'")
+}
+
+func (s *TrustSuite) TestRuleNameTruncated(c *C) {
+	r, err := newRule(KindDenyUID, strings.Repeat("1", 60), fakeLookup(nil))
+	c.Assert(err, NotNil)
+	c.Assert(len(r.String()), Equals, ruleNameLen-1)
+}
@@ -0,0 +1,405 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package trust
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/linux-lock/bpflock/pkg/bpf"
+	"github.com/linux-lock/bpflock/pkg/events"
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	subsystem = "trust"
+
+	// Prefixes of trusted names
+	ProcessPrefix   = "process:"
+	ContainerPrefix = "container:"
+
+	// maxNs must match BPFLOCK_TRUST_MAX_NS of bpf/bpflock_shared_defs.h
+	maxNs = 256
+)
+
+// nsType is a namespace type of the trust criteria
+type nsType struct {
+	// id must match enum bpflock_trust_ns of bpf/bpflock_shared_defs.h
+	id uint32
+
+	// file is the namespace file inside /proc/<pid>/ns/
+	file string
+}
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+	// ProcDir is the proc filesystem of the host
+	ProcDir = "/proc"
+
+	// containerOf returns the container ID of a task
+	containerOf = events.ContainerID
+
+	nsTypes = map[string]nsType{
+		"pid":    {id: 1, file: "pid_for_children"},
+		"mnt":    {id: 2, file: "mnt"},
+		"user":   {id: 3, file: "user"},
+		"cgroup": {id: 4, file: "cgroup"},
+	}
+)
+
+// Key is the namespace set of the host or of a trusted process or container,
+// only the types of the criteria are set. It must match struct bl_trust_key
+// of bpf/bpflock_shared_defs.h, the zero Key holds the criteria.
+type Key struct {
+	PidNs    uint32
+	MntNs    uint32
+	UserNs   uint32
+	CgroupNs uint32
+}
+
+func (k *Key) set(t nsType, inum uint32) {
+	switch t.id {
+	case 1:
+		k.PidNs = inum
+	case 2:
+		k.MntNs = inum
+	case 3:
+		k.UserNs = inum
+	case 4:
+		k.CgroupNs = inum
+	}
+}
+
+// fileID identifies an executable by its device and inode, so renaming the
+// task or copying the binary does not make it trusted.
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+func statID(path string) (fileID, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return fileID{}, err
+	}
+	return fileID{dev: uint64(st.Dev), ino: st.Ino}, nil
+}
+
+// Policy is the baseline trust configuration of the bpf program arguments.
+type Policy struct {
+	// Criteria are the namespace types that must be trusted
+	Criteria []string
+
+	// Trusted are the processes and containers whose namespaces are
+	// trusted, as "process:<path>" of their executable on the host or
+	// "container:<id>"
+	Trusted []string
+
+	// AllowUsers and AllowGroups are the users and groups whose tasks are
+	// trusted, if set only their tasks are trusted. DenyUsers and
+	// DenyGroups are never trusted. They are names, ids or "min-max"
+	// ranges of ids.
+	AllowUsers  []string
+	AllowGroups []string
+	DenyUsers   []string
+	DenyGroups  []string
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// ParseArgs returns the trust policy of the program arguments, only the
+// pid namespace is trusted by default.
+func ParseArgs(args []string) (*Policy, error) {
+	p := &Policy{}
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--trust="):
+			p.Criteria = splitList(strings.TrimPrefix(arg, "--trust="))
+		case strings.HasPrefix(arg, "--trust-ns="):
+			p.Trusted = splitList(strings.TrimPrefix(arg, "--trust-ns="))
+		case strings.HasPrefix(arg, "--allow-uid="):
+			p.AllowUsers = splitList(strings.TrimPrefix(arg, "--allow-uid="))
+		case strings.HasPrefix(arg, "--allow-gid="):
+			p.AllowGroups = splitList(strings.TrimPrefix(arg, "--allow-gid="))
+		case strings.HasPrefix(arg, "--deny-uid="):
+			p.DenyUsers = splitList(strings.TrimPrefix(arg, "--deny-uid="))
+		case strings.HasPrefix(arg, "--deny-gid="):
+			p.DenyGroups = splitList(strings.TrimPrefix(arg, "--deny-gid="))
+		}
+	}
+	if len(p.Criteria) == 0 {
+		p.Criteria = []string{"pid"}
+	}
+	if _, err := p.criteriaMask(); err != nil {
+		return nil, err
+	}
+	for _, t := range p.Trusted {
+		path := strings.TrimPrefix(t, ProcessPrefix)
+		id := strings.TrimPrefix(t, ContainerPrefix)
+		if (path == t || !filepath.IsAbs(path)) && (id == t || id == "") {
+			return nil, fmt.Errorf("invalid trusted namespace '%s': must be %s<path> or %s<id>", t, ProcessPrefix, ContainerPrefix)
+		}
+	}
+	if len(p.AllowUsers)+len(p.AllowGroups)+len(p.DenyUsers)+len(p.DenyGroups) > maxRules {
+		return nil, fmt.Errorf("too many user and group rules, maximum is %d", maxRules)
+	}
+	return p, nil
+}
+
+// Trusting returns true if the policy trusts the namespaces of processes
+// or containers.
+func (p *Policy) Trusting() bool {
+	return len(p.Trusted) > 0
+}
+
+func (p *Policy) criteriaMask() (uint32, error) {
+	var mask uint32
+	for _, name := range p.Criteria {
+		t, ok := nsTypes[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown namespace type '%s'", name)
+		}
+		mask |= 1 << t.id
+	}
+	return mask, nil
+}
+
+// namespaces returns the namespaces of the task pid of the criteria mask.
+func namespaces(pid string, criteria uint32) (Key, error) {
+	var key Key
+	for _, t := range nsTypes {
+		if criteria&(1<<t.id) == 0 {
+			continue
+		}
+		var st syscall.Stat_t
+		if err := syscall.Stat(filepath.Join(ProcDir, pid, "ns", t.file), &st); err != nil {
+			return Key{}, err
+		}
+		key.set(t, uint32(st.Ino))
+	}
+	return key, nil
+}
+
+// trustedSources are the resolved trusted processes and containers.
+type trustedSources struct {
+	executables map[fileID]struct{}
+	containers  []string
+}
+
+// sources resolves the executables of the trusted processes inside the
+// root filesystem of the host.
+func sources(trusted []string) *trustedSources {
+	s := &trustedSources{executables: make(map[fileID]struct{})}
+	for _, t := range trusted {
+		if path := strings.TrimPrefix(t, ProcessPrefix); path != t {
+			id, err := statID(filepath.Join(ProcDir, "1", "root", path))
+			if err != nil {
+				log.WithError(err).WithField(logfields.Path, path).Warn("Unable to find trusted executable")
+				continue
+			}
+			s.executables[id] = struct{}{}
+		} else if id := strings.TrimPrefix(t, ContainerPrefix); id != "" {
+			s.containers = append(s.containers, id)
+		}
+	}
+	return s
+}
+
+// matches returns true if the task pid runs one of the trusted executables
+// or is inside one of the trusted containers.
+func (s *trustedSources) matches(pid string) bool {
+	if len(s.executables) > 0 {
+		if id, err := statID(filepath.Join(ProcDir, pid, "exe")); err == nil {
+			if _, ok := s.executables[id]; ok {
+				return true
+			}
+		}
+	}
+	if len(s.containers) > 0 {
+		n, err := strconv.Atoi(pid)
+		if err != nil {
+			return false
+		}
+		container := containerOf(int32(n))
+		for _, id := range s.containers {
+			if container != "" && strings.HasPrefix(container, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Resolve returns the trusted namespace sets of the policy: the namespaces
+// of the host and those of each running trusted process and container.
+func Resolve(p *Policy) (map[Key]struct{}, error) {
+	criteria, err := p.criteriaMask()
+	if err != nil {
+		return nil, err
+	}
+	host, err := namespaces("1", criteria)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read host namespaces: %w", err)
+	}
+	keys := map[Key]struct{}{host: {}}
+	if !p.Trusting() {
+		return keys, nil
+	}
+
+	entries, err := ioutil.ReadDir(ProcDir)
+	if err != nil {
+		return nil, err
+	}
+	trusted := sources(p.Trusted)
+	found := 0
+	for _, e := range entries {
+		if _, err := strconv.Atoi(e.Name()); err != nil || !e.IsDir() {
+			continue
+		}
+		if !trusted.matches(e.Name()) {
+			continue
+		}
+		key, err := namespaces(e.Name(), criteria)
+		if err != nil {
+			// The task exited
+			continue
+		}
+		keys[key] = struct{}{}
+		found++
+	}
+	if found == 0 {
+		log.WithField("trusted", strings.Join(p.Trusted, ",")).Warn("No trusted process or container is running")
+	}
+
+	if len(keys) > maxNs {
+		return nil, fmt.Errorf("too many trusted namespace sets: %d, maximum is %d", len(keys), maxNs)
+	}
+	return keys, nil
+}
+
+// MapName returns the name of the pinned map of trusted namespaces of
+// program.
+func MapName(program string) string {
+	return program + "_trust_map"
+}
+
+// Locker updates the pinned maps of trusted namespaces and of user and
+// group rules of a program.
+type Locker struct {
+	// path is the pin of the trusted namespaces map
+	path string
+
+	// idsPath is the pin of the user and group rules map
+	idsPath string
+}
+
+// NewLocker returns a locker of the trusted namespaces of program pinned
+// inside pinPrefix, usually bpf.MapPrefixPath().
+func NewLocker(pinPrefix, program string) *Locker {
+	return &Locker{
+		path:    filepath.Join(pinPrefix, program, MapName(program)),
+		idsPath: filepath.Join(pinPrefix, program, RulesMapName(program)),
+	}
+}
+
+// Loaded returns true if the map of trusted namespaces is pinned.
+func (l *Locker) Loaded() bool {
+	_, err := os.Stat(l.path)
+	return err == nil
+}
+
+// Trust sets the trusted namespace sets to keys and removes the others, then
+// sets the criteria. Until the criteria are set only the initial pid
+// namespace is trusted.
+func (l *Locker) Trust(criteria uint32, keys map[Key]struct{}) error {
+	fd, err := bpf.ObjGet(l.path)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	// Add new namespaces first, so trusted ones are never denied
+	value := uint32(1)
+	for k := range keys {
+		k := k
+		if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&k), unsafe.Pointer(&value), unix.BPF_ANY); err != nil {
+			return fmt.Errorf("unable to trust namespaces %+v: %w", k, err)
+		}
+	}
+
+	var stale []Key
+	var key, next Key
+	var pkey unsafe.Pointer
+	for {
+		err := bpf.MapGetNextKey(fd, pkey, unsafe.Pointer(&next))
+		if errors.Is(err, unix.ENOENT) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("unable to list trusted namespaces: %w", err)
+		}
+		if _, ok := keys[next]; !ok && next != (Key{}) {
+			stale = append(stale, next)
+		}
+		key = next
+		pkey = unsafe.Pointer(&key)
+	}
+	for _, k := range stale {
+		k := k
+		if err := bpf.MapDeleteElem(fd, unsafe.Pointer(&k)); err != nil && !errors.Is(err, unix.ENOENT) {
+			return fmt.Errorf("unable to remove trusted namespaces %+v: %w", k, err)
+		}
+	}
+
+	k := Key{}
+	if err := bpf.MapUpdateElem(fd, unsafe.Pointer(&k), unsafe.Pointer(&criteria), unix.BPF_ANY); err != nil {
+		return fmt.Errorf("unable to set trust criteria: %w", err)
+	}
+
+	log.WithField(logfields.Path, l.path).Debugf("Trusting %d namespace sets, removed %d stale entries", len(keys), len(stale))
+	return nil
+}
+
+// Update loads the user and group rules and the trusted namespaces of the
+// policy, it does nothing if the program is not loaded. Nothing is changed
+// if the policy can not be resolved.
+func (l *Locker) Update(p *Policy) error {
+	if !l.Loaded() {
+		return nil
+	}
+	criteria, err := p.criteriaMask()
+	if err != nil {
+		return err
+	}
+	rules, err := ResolveRules(p)
+	if err != nil {
+		return err
+	}
+	keys, err := Resolve(p)
+	if err != nil {
+		return err
+	}
+	if err := l.SetRules(rules); err != nil {
+		return err
+	}
+	return l.Trust(criteria, keys)
+}
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package trust
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type TrustSuite struct {
+	procDir     string
+	containerOf func(int32) string
+	lookupUser  func(string) (string, error)
+	lookupGroup func(string) (string, error)
+}
+
+var _ = Suite(&TrustSuite{})
+
+func (s *TrustSuite) SetUpTest(c *C) {
+	s.procDir = ProcDir
+	s.containerOf = containerOf
+	s.lookupUser = lookupUser
+	s.lookupGroup = lookupGroup
+	ProcDir = c.MkDir()
+}
+
+func (s *TrustSuite) TearDownTest(c *C) {
+	ProcDir = s.procDir
+	containerOf = s.containerOf
+	lookupUser = s.lookupUser
+	lookupGroup = s.lookupGroup
+}
+
+// addTask creates a fake task with its namespace files, running the
+// executable exe of the host root filesystem.
+func addTask(c *C, pid, comm, exe string) {
+	dir := filepath.Join(ProcDir, pid)
+	c.Assert(os.MkdirAll(filepath.Join(dir, "ns"), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "comm"), []byte(comm+"\n"), 0644), IsNil)
+	for _, t := range nsTypes {
+		c.Assert(ioutil.WriteFile(filepath.Join(dir, "ns", t.file), nil, 0644), IsNil)
+	}
+	path := filepath.Join(ProcDir, "1", "root", exe)
+	c.Assert(os.MkdirAll(filepath.Dir(path), 0755), IsNil)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		c.Assert(ioutil.WriteFile(path, nil, 0755), IsNil)
+	}
+	c.Assert(os.Symlink(path, filepath.Join(dir, "exe")), IsNil)
+}
+
+func nsInum(c *C, pid, name string) uint32 {
+	var st syscall.Stat_t
+	c.Assert(syscall.Stat(filepath.Join(ProcDir, pid, "ns", nsTypes[name].file), &st), IsNil)
+	return uint32(st.Ino)
+}
+
+func (s *TrustSuite) TestParseArgs(c *C) {
+	p, err := ParseArgs([]string{"--profile=baseline"})
+	c.Assert(err, IsNil)
+	c.Assert(p.Criteria, DeepEquals, []string{"pid"})
+	c.Assert(p.Trusting(), Equals, false)
+	mask, err := p.criteriaMask()
+	c.Assert(err, IsNil)
+	c.Assert(mask, Equals, uint32(1<<1))
+
+	p, err = ParseArgs([]string{"--trust=pid, mnt,cgroup", "--trust-ns=process:/usr/bin/mgmt,container:0123abcd"})
+	c.Assert(err, IsNil)
+	c.Assert(p.Trusted, DeepEquals, []string{"process:/usr/bin/mgmt", "container:0123abcd"})
+	mask, err = p.criteriaMask()
+	c.Assert(err, IsNil)
+	c.Assert(mask, Equals, uint32(1<<1|1<<2|1<<4))
+
+	_, err = ParseArgs([]string{"--trust=pid,ipc"})
+	c.Assert(err, ErrorMatches, "unknown namespace type 'ipc'")
+	_, err = ParseArgs([]string{"--trust-ns=mgmt"})
+	c.Assert(err, ErrorMatches, "invalid trusted namespace 'mgmt'.*")
+	_, err = ParseArgs([]string{"--trust-ns=process:mgmt"})
+	c.Assert(err, ErrorMatches, "invalid trusted namespace 'process:mgmt'.*")
+	_, err = ParseArgs([]string{"--trust-ns=container:"})
+	c.Assert(err, ErrorMatches, "invalid trusted namespace 'container:'.*")
+}
+
+func (s *TrustSuite) TestResolve(c *C) {
+	addTask(c, "1", "systemd", "/usr/lib/systemd/systemd")
+	addTask(c, "42", "mgmt", "/usr/bin/mgmt")
+	// Renamed to the name of the trusted process
+	addTask(c, "43", "mgmt", "/usr/sbin/nginx")
+	addTask(c, "44", "runc", "/usr/bin/runc")
+	c.Assert(ioutil.WriteFile(filepath.Join(ProcDir, "meminfo"), nil, 0644), IsNil)
+	containerOf = func(pid int32) string {
+		if pid == 44 {
+			return "0123abcd"
+		}
+		return ""
+	}
+
+	keys, err := Resolve(&Policy{Criteria: []string{"pid"}})
+	c.Assert(err, IsNil)
+	c.Assert(keys, DeepEquals, map[Key]struct{}{
+		{PidNs: nsInum(c, "1", "pid")}: {},
+	})
+
+	keys, err = Resolve(&Policy{
+		Criteria: []string{"pid"},
+		Trusted:  []string{"process:/usr/bin/mgmt", "container:0123", "process:/usr/bin/missing"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(keys, HasLen, 3)
+	for _, pid := range []string{"1", "42", "44"} {
+		_, ok := keys[Key{PidNs: nsInum(c, pid, "pid")}]
+		c.Assert(ok, Equals, true)
+	}
+	_, ok := keys[Key{PidNs: nsInum(c, "43", "pid")}]
+	c.Assert(ok, Equals, false)
+
+	// Each source is trusted with all its namespaces, mixing the namespaces
+	// of the host and of a trusted process is not
+	keys, err = Resolve(&Policy{Criteria: []string{"pid", "mnt"}, Trusted: []string{"process:/usr/bin/mgmt"}})
+	c.Assert(err, IsNil)
+	c.Assert(keys, HasLen, 2)
+	_, ok = keys[Key{PidNs: nsInum(c, "42", "pid"), MntNs: nsInum(c, "42", "mnt")}]
+	c.Assert(ok, Equals, true)
+	_, ok = keys[Key{PidNs: nsInum(c, "1", "pid"), MntNs: nsInum(c, "42", "mnt")}]
+	c.Assert(ok, Equals, false)
+}
+
+func (s *TrustSuite) TestLockerNotLoaded(c *C) {
+	l := NewLocker(c.MkDir(), "kmodlock")
+	c.Assert(l.Loaded(), Equals, false)
+	c.Assert(l.Update(&Policy{Criteria: []string{"pid"}}), IsNil)
+	c.Assert(MapName("kmodlock"), Equals, "kmodlock_trust_map")
+}
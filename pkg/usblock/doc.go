@@ -0,0 +1,8 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+// Package usblock authorizes the USB devices that are allowed by the
+// usblock baseline profile. The usblock program stops the kernel from
+// authorizing new USB devices, the bpflock agent then authorizes those
+// that are in the allow lists.
+package usblock
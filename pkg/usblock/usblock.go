@@ -0,0 +1,336 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+package usblock
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/linux-lock/bpflock/pkg/logging"
+	"github.com/linux-lock/bpflock/pkg/logging/logfields"
+)
+
+const (
+	subsystem = "usblock"
+
+	ProfileAllow      = "allow"
+	ProfileBaseline   = "baseline"
+	ProfileRestricted = "restricted"
+)
+
+var (
+	log = logging.DefaultLogger.WithField(logfields.LogSubsys, subsystem)
+
+	// SysfsDevices is the sysfs directory of USB devices
+	SysfsDevices = "/sys/bus/usb/devices"
+
+	// classes are the USB device class names accepted by --allow-class,
+	// they must match bpf/usblock.c
+	classes = map[string]uint8{
+		"audio":    0x01,
+		"comm":     0x02,
+		"hid":      0x03,
+		"printer":  0x07,
+		"storage":  0x08,
+		"hub":      0x09,
+		"cdc-data": 0x0a,
+		"video":    0x0e,
+		"wireless": 0xe0,
+		"misc":     0xef,
+		"vendor":   0xff,
+	}
+)
+
+// Policy is the usblock configuration of the bpf program arguments.
+type Policy struct {
+	Profile string
+
+	// IDs are the allowed devices as vendor<<16|product
+	IDs map[uint32]struct{}
+
+	// Classes are the allowed device and interface classes
+	Classes map[uint8]struct{}
+}
+
+// Device is a USB device as shown in sysfs.
+type Device struct {
+	Name       string
+	Vendor     uint16
+	Product    uint16
+	Class      uint8
+	Authorized bool
+
+	// Interfaces are the classes of the interfaces of the device
+	Interfaces []uint8
+}
+
+// ID returns the device identifier as "vendor:product".
+func (d Device) ID() string {
+	return fmt.Sprintf("%04x:%04x", d.Vendor, d.Product)
+}
+
+// ParseArgs returns the policy of the usblock program arguments.
+func ParseArgs(args []string) (*Policy, error) {
+	p := &Policy{
+		Profile: ProfileAllow,
+		IDs:     make(map[uint32]struct{}),
+		Classes: make(map[uint8]struct{}),
+	}
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--profile="):
+			switch profile := strings.TrimPrefix(arg, "--profile="); profile {
+			case ProfileBaseline, ProfileRestricted:
+				p.Profile = profile
+			default:
+				p.Profile = ProfileAllow
+			}
+		case strings.HasPrefix(arg, "--allow="):
+			for _, id := range strings.Split(strings.TrimPrefix(arg, "--allow="), ",") {
+				vendor, product, err := parseID(id)
+				if err != nil {
+					return nil, err
+				}
+				p.IDs[uint32(vendor)<<16|uint32(product)] = struct{}{}
+			}
+		case strings.HasPrefix(arg, "--allow-class="):
+			for _, name := range strings.Split(strings.TrimPrefix(arg, "--allow-class="), ",") {
+				class, err := parseClass(name)
+				if err != nil {
+					return nil, err
+				}
+				p.Classes[class] = struct{}{}
+			}
+		}
+	}
+	return p, nil
+}
+
+func parseID(id string) (uint16, uint16, error) {
+	parts := strings.Split(id, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid USB device '%s': expected vendor:product", id)
+	}
+	vendor, err := strconv.ParseUint(parts[0], 16, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid USB vendor ID '%s'", parts[0])
+	}
+	product, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid USB product ID '%s'", parts[1])
+	}
+	return uint16(vendor), uint16(product), nil
+}
+
+func parseClass(name string) (uint8, error) {
+	if class, ok := classes[name]; ok {
+		return class, nil
+	}
+	class, err := strconv.ParseUint(name, 16, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid USB device class '%s'", name)
+	}
+	return uint8(class), nil
+}
+
+// Allowed returns true if the device may be authorized. With the baseline
+// profile, a device is allowed by its IDs, by its device class, or if the
+// classes of all its interfaces are allowed. Most devices report the device
+// class 00 and declare their class per interface.
+func (p *Policy) Allowed(d Device) bool {
+	switch p.Profile {
+	case ProfileRestricted:
+		return false
+	case ProfileBaseline:
+		if _, ok := p.IDs[uint32(d.Vendor)<<16|uint32(d.Product)]; ok {
+			return true
+		}
+		if _, ok := p.Classes[d.Class]; ok {
+			return true
+		}
+		if len(d.Interfaces) == 0 {
+			return false
+		}
+		for _, class := range d.Interfaces {
+			if _, ok := p.Classes[class]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
+
+func readHex(dir, attr string, bits int) (uint64, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, attr))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(b)), 16, bits)
+}
+
+// isRootHub returns true for the "usbN" root hubs of USB buses.
+func isRootHub(name string) bool {
+	return strings.HasPrefix(name, "usb")
+}
+
+// USB descriptor types and the offset of bInterfaceClass in interface
+// descriptors.
+const (
+	descConfig         = 0x02
+	descInterface      = 0x04
+	descInterfaceClass = 5
+)
+
+// descriptorClasses returns the interface classes of the first
+// configuration in the raw descriptors of a device. Devices that are not
+// authorized are not configured and have no interface entries, the kernel
+// still reads their descriptors.
+func descriptorClasses(dir string) []uint8 {
+	b, err := ioutil.ReadFile(filepath.Join(dir, "descriptors"))
+	if err != nil {
+		return nil
+	}
+
+	var classes []uint8
+	configs := 0
+	for len(b) >= 2 {
+		length := int(b[0])
+		if length < 2 || length > len(b) {
+			break
+		}
+		switch b[1] {
+		case descConfig:
+			configs++
+		case descInterface:
+			if configs == 1 && length > descInterfaceClass {
+				classes = append(classes, b[descInterfaceClass])
+			}
+		}
+		if configs > 1 {
+			break
+		}
+		b = b[length:]
+	}
+	return classes
+}
+
+// ListDevices returns the USB devices that are not root hubs, sorted by
+// name. The classes of the "N-M:C.I" interface entries are reported with
+// their devices, or those of the device descriptors if it has none.
+func ListDevices() ([]Device, error) {
+	entries, err := ioutil.ReadDir(SysfsDevices)
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces := make(map[string][]uint8)
+	for _, e := range entries {
+		name := e.Name()
+		i := strings.Index(name, ":")
+		if i < 0 {
+			continue
+		}
+		class, err := readHex(filepath.Join(SysfsDevices, name), "bInterfaceClass", 8)
+		if err != nil {
+			continue
+		}
+		interfaces[name[:i]] = append(interfaces[name[:i]], uint8(class))
+	}
+
+	var devices []Device
+	for _, e := range entries {
+		name := e.Name()
+		if isRootHub(name) || strings.Contains(name, ":") {
+			continue
+		}
+		dir := filepath.Join(SysfsDevices, name)
+		vendor, err := readHex(dir, "idVendor", 16)
+		if err != nil {
+			continue
+		}
+		product, err := readHex(dir, "idProduct", 16)
+		if err != nil {
+			continue
+		}
+		class, err := readHex(dir, "bDeviceClass", 8)
+		if err != nil {
+			continue
+		}
+		authorized, err := ioutil.ReadFile(filepath.Join(dir, "authorized"))
+		if err != nil {
+			continue
+		}
+		classes, ok := interfaces[name]
+		if !ok {
+			classes = descriptorClasses(dir)
+		}
+		devices = append(devices, Device{
+			Name:       name,
+			Vendor:     uint16(vendor),
+			Product:    uint16(product),
+			Class:      uint8(class),
+			Authorized: strings.TrimSpace(string(authorized)) == "1",
+			Interfaces: classes,
+		})
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Name < devices[j].Name })
+	return devices, nil
+}
+
+// Authorize authorizes the unauthorized devices that the policy allows. It
+// returns the devices that it authorized and those that stay blocked.
+func Authorize(p *Policy) (authorized, blocked []Device, err error) {
+	devices, err := ListDevices()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, d := range devices {
+		if d.Authorized {
+			continue
+		}
+		if !p.Allowed(d) {
+			blocked = append(blocked, d)
+			continue
+		}
+		path := filepath.Join(SysfsDevices, d.Name, "authorized")
+		if err := ioutil.WriteFile(path, []byte("1"), 0); err != nil {
+			log.WithError(err).WithField(logfields.Path, path).Warn("Unable to authorize USB device")
+			blocked = append(blocked, d)
+			continue
+		}
+		d.Authorized = true
+		authorized = append(authorized, d)
+	}
+	return authorized, blocked, nil
+}
+
+// RestoreDefaults lets the kernel authorize new devices on all USB buses
+// again. Devices that were blocked stay blocked until they are plugged
+// again.
+func RestoreDefaults() error {
+	entries, err := ioutil.ReadDir(SysfsDevices)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var lastErr error
+	for _, e := range entries {
+		if !isRootHub(e.Name()) {
+			continue
+		}
+		path := filepath.Join(SysfsDevices, e.Name(), "authorized_default")
+		if err := ioutil.WriteFile(path, []byte("1"), 0); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
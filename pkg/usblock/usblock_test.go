@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022 Djalal Harouni
+
+//go:build !privileged_tests
+// +build !privileged_tests
+
+package usblock
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type UsbLockSuite struct {
+	saved string
+}
+
+var _ = Suite(&UsbLockSuite{})
+
+func (s *UsbLockSuite) SetUpTest(c *C) {
+	s.saved = SysfsDevices
+	SysfsDevices = c.MkDir()
+}
+
+func (s *UsbLockSuite) TearDownTest(c *C) {
+	SysfsDevices = s.saved
+}
+
+func writeAttrs(c *C, name string, attrs map[string]string) {
+	dir := filepath.Join(SysfsDevices, name)
+	c.Assert(os.MkdirAll(dir, 0755), IsNil)
+	for k, v := range attrs {
+		c.Assert(ioutil.WriteFile(filepath.Join(dir, k), []byte(v+"\n"), 0644), IsNil)
+	}
+}
+
+func readAttr(c *C, name, attr string) string {
+	b, err := ioutil.ReadFile(filepath.Join(SysfsDevices, name, attr))
+	c.Assert(err, IsNil)
+	return string(b)
+}
+
+func (s *UsbLockSuite) TestParseArgs(c *C) {
+	p, err := ParseArgs([]string{"--profile=baseline", "--allow=046d:C31c,1d6b:0002", "--allow-class=hub,e0"})
+	c.Assert(err, IsNil)
+	c.Assert(p.Profile, Equals, ProfileBaseline)
+	c.Assert(p.IDs, HasLen, 2)
+	c.Assert(p.Classes, HasLen, 2)
+
+	c.Assert(p.Allowed(Device{Vendor: 0x046d, Product: 0xc31c, Class: 0x03}), Equals, true)
+	c.Assert(p.Allowed(Device{Vendor: 0x0781, Product: 0x5567, Class: 0x09}), Equals, true)
+	c.Assert(p.Allowed(Device{Vendor: 0x0781, Product: 0x5567, Class: 0x00}), Equals, false)
+	c.Assert(p.Allowed(Device{Class: 0x00, Interfaces: []uint8{0xe0, 0xe0}}), Equals, true)
+	c.Assert(p.Allowed(Device{Class: 0x00, Interfaces: []uint8{0xe0, 0x08}}), Equals, false)
+
+	p, err = ParseArgs(nil)
+	c.Assert(err, IsNil)
+	c.Assert(p.Profile, Equals, ProfileAllow)
+	c.Assert(p.Allowed(Device{Vendor: 0x0781, Product: 0x5567}), Equals, true)
+
+	p, err = ParseArgs([]string{"--profile=restricted", "--allow-class=hub"})
+	c.Assert(err, IsNil)
+	c.Assert(p.Allowed(Device{Class: 0x09}), Equals, false)
+
+	_, err = ParseArgs([]string{"--allow=046d"})
+	c.Assert(err, ErrorMatches, "invalid USB device '046d'.*")
+	_, err = ParseArgs([]string{"--allow=xyz:0001"})
+	c.Assert(err, ErrorMatches, "invalid USB vendor ID 'xyz'")
+	_, err = ParseArgs([]string{"--allow-class=floppy"})
+	c.Assert(err, ErrorMatches, "invalid USB device class 'floppy'")
+}
+
+func (s *UsbLockSuite) TestAuthorize(c *C) {
+	writeAttrs(c, "usb1", map[string]string{"idVendor": "1d6b", "idProduct": "0002",
+		"bDeviceClass": "09", "authorized": "1", "authorized_default": "0"})
+	writeAttrs(c, "1-1", map[string]string{"idVendor": "046d", "idProduct": "c31c",
+		"bDeviceClass": "00", "authorized": "0"})
+	writeAttrs(c, "1-1:1.0", map[string]string{"bInterfaceClass": "03"})
+	writeAttrs(c, "1-2", map[string]string{"idVendor": "0781", "idProduct": "5567",
+		"bDeviceClass": "00", "authorized": "0"})
+	writeAttrs(c, "1-3", map[string]string{"idVendor": "05e3", "idProduct": "0608",
+		"bDeviceClass": "09", "authorized": "1"})
+
+	devices, err := ListDevices()
+	c.Assert(err, IsNil)
+	c.Assert(devices, HasLen, 3)
+	c.Assert(devices[0].ID(), Equals, "046d:c31c")
+
+	p, err := ParseArgs([]string{"--profile=baseline", "--allow=046d:c31c"})
+	c.Assert(err, IsNil)
+	authorized, blocked, err := Authorize(p)
+	c.Assert(err, IsNil)
+	c.Assert(authorized, HasLen, 1)
+	c.Assert(authorized[0].Name, Equals, "1-1")
+	c.Assert(blocked, HasLen, 1)
+	c.Assert(blocked[0].Name, Equals, "1-2")
+	c.Assert(readAttr(c, "1-1", "authorized"), Equals, "1")
+	c.Assert(readAttr(c, "1-2", "authorized"), Equals, "0\n")
+
+	c.Assert(RestoreDefaults(), IsNil)
+	c.Assert(readAttr(c, "usb1", "authorized_default"), Equals, "1")
+}
+
+func (s *UsbLockSuite) TestAuthorizeInterfaces(c *C) {
+	// Keyboard of class 00 with a HID interface
+	writeAttrs(c, "1-1", map[string]string{"idVendor": "046d", "idProduct": "c31c",
+		"bDeviceClass": "00", "authorized": "0"})
+	writeAttrs(c, "1-1:1.0", map[string]string{"bInterfaceClass": "03"})
+	writeAttrs(c, "1-1:1.1", map[string]string{"bInterfaceClass": "03"})
+	// HID and storage composite device
+	writeAttrs(c, "1-2", map[string]string{"idVendor": "0781", "idProduct": "5567",
+		"bDeviceClass": "00", "authorized": "0"})
+	writeAttrs(c, "1-2:1.0", map[string]string{"bInterfaceClass": "03"})
+	writeAttrs(c, "1-2:1.1", map[string]string{"bInterfaceClass": "08"})
+	// Unconfigured keyboard of class 00, only its descriptors are read
+	writeAttrs(c, "1-3", map[string]string{"idVendor": "413c", "idProduct": "2113",
+		"bDeviceClass": "00", "authorized": "0"})
+	descriptors := []byte{
+		18, 0x01, 0x00, 0x02, 0x00, 0x00, 0x00, 8, 0x3c, 0x41, 0x13, 0x21, 0x00, 0x01, 1, 2, 0, 1,
+		9, 0x02, 34, 0, 1, 1, 0, 0xa0, 50,
+		9, 0x04, 0, 0, 1, 0x03, 0x01, 0x01, 0,
+		9, 0x21, 0x11, 0x01, 0, 1, 0x22, 65, 0,
+		7, 0x05, 0x81, 0x03, 8, 0, 10,
+		9, 0x02, 25, 0, 1, 2, 0, 0xa0, 50,
+		9, 0x04, 0, 0, 1, 0x08, 0x06, 0x50, 0,
+	}
+	c.Assert(ioutil.WriteFile(filepath.Join(SysfsDevices, "1-3", "descriptors"), descriptors, 0644), IsNil)
+
+	devices, err := ListDevices()
+	c.Assert(err, IsNil)
+	c.Assert(devices, HasLen, 3)
+	c.Assert(devices[0].Interfaces, DeepEquals, []uint8{0x03, 0x03})
+	c.Assert(devices[1].Interfaces, DeepEquals, []uint8{0x03, 0x08})
+	c.Assert(devices[2].Interfaces, DeepEquals, []uint8{0x03})
+
+	p, err := ParseArgs([]string{"--profile=baseline", "--allow-class=hub,hid"})
+	c.Assert(err, IsNil)
+	authorized, blocked, err := Authorize(p)
+	c.Assert(err, IsNil)
+	c.Assert(authorized, HasLen, 2)
+	c.Assert(authorized[0].Name, Equals, "1-1")
+	c.Assert(authorized[1].Name, Equals, "1-3")
+	c.Assert(blocked, HasLen, 1)
+	c.Assert(blocked[0].Name, Equals, "1-2")
+}